@@ -3,18 +3,47 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"expvar"
 	"fmt"
-	"io/ioutil"
 	"link-guardian/internal/config"
+	"link-guardian/internal/handlers/admin"
+	"link-guardian/internal/handlers/apikeys"
 	"link-guardian/internal/handlers/auth"
+	"link-guardian/internal/handlers/domains"
 	"link-guardian/internal/handlers/links"
 	"link-guardian/internal/handlers/logs"
 	"link-guardian/internal/handlers/middleware"
+	"link-guardian/internal/handlers/system"
+	"link-guardian/internal/handlers/workspaces"
+	"link-guardian/internal/lifecycle"
+	"link-guardian/internal/migrations"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
 	dbRepo "link-guardian/internal/repositories/db"
+	redisRepo "link-guardian/internal/repositories/redis"
 	authService "link-guardian/internal/services/auth"
+	"link-guardian/internal/services/cleanup"
+	"link-guardian/internal/services/clicklog"
+	domainService "link-guardian/internal/services/domains"
+	"link-guardian/internal/services/geoip"
+	"link-guardian/internal/services/invitations"
+	"link-guardian/internal/services/loginguard"
+	"link-guardian/internal/services/mailer"
+	"link-guardian/internal/services/passwordreset"
+	"link-guardian/internal/services/qrcode"
+	"link-guardian/internal/services/slugs"
+	"link-guardian/internal/services/twofactor"
+	"link-guardian/internal/services/unlock"
+	"link-guardian/internal/services/useragent"
+	"link-guardian/internal/services/verification"
 	"log"
+	"net"
+	"net/http"
 	"os"
-	"path/filepath"
+	"os/signal"
+	"syscall"
+	_ "time/tzdata" // Time zone names for link stats in minimal containers
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -34,30 +63,207 @@ func main() {
 	// Set Gin mode from configuration
 	gin.SetMode(cfg.Server.GinMode)
 
+	// Cancelled on SIGINT or SIGTERM to begin a graceful shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	services := lifecycle.New()
+
 	// Initialize database
 	if err = initDatabase(cfg); err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
+	services.Add(lifecycle.Component{
+		Name: "PostgreSQL connection",
+		Stop: func(ctx context.Context) error { return db.Close() },
+	})
+
+	// `main migrate ...` manages the schema and exits without starting the server
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		err := runMigrateCommand(ctx, os.Args[2:])
+		db.Close()
+		if err != nil {
+			log.Fatalf("Migration command failed: %v", err)
+		}
+		return
+	}
+
+	store := dbRepo.NewStore(db)
+
+	// `main set-role <email> <role>` makes a user an admin or revokes it and exits
+	if len(os.Args) > 1 && os.Args[1] == "set-role" {
+		err := runSetRoleCommand(store, os.Args[2:])
+		db.Close()
+		if err != nil {
+			log.Fatalf("Set role command failed: %v", err)
+		}
+		return
+	}
 
 	// Initialize Redis
 	redisClient, err := initRedis(cfg)
 	if err != nil {
 		log.Fatalf("Failed to initialize Redis: %v", err)
 	}
+	services.Add(lifecycle.Component{
+		Name: "Redis client",
+		Stop: func(ctx context.Context) error { return redisClient.Close() },
+	})
+
+	// `main unlock-login <email>` lifts a login lockout and exits
+	if len(os.Args) > 1 && os.Args[1] == "unlock-login" {
+		err := runUnlockLoginCommand(ctx, cfg, os.Args[2:])
+		redisClient.Close()
+		db.Close()
+		if err != nil {
+			log.Fatalf("Unlock command failed: %v", err)
+		}
+		return
+	}
+
+	// Apply pending migrations
+	if cfg.Migration.OnStart {
+		if err := applyMigrations(ctx); err != nil {
+			log.Fatalf("Failed to run migrations: %v", err)
+		}
+	}
+
+	// Initialize the mailer for password reset and lockout emails
+	mail, err := initMailer(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize mailer: %v", err)
+	}
+
+	// Initialize QR code generator
+	qrGenerator, err := initQRGenerator(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize QR code generator: %v", err)
+	}
+
+	// Initialize the custom slug rules
+	slugPolicy, err := slugs.NewPolicy(cfg.Links.SlugMinLength, cfg.Links.SlugMaxLength, cfg.Links.ReservedSlugs)
+	if err != nil {
+		log.Fatalf("Failed to initialize slug policy: %v", err)
+	}
+
+	// Initialize custom domain verification
+	domainSvc, err := domainService.NewService(domainService.NewDNSResolver(cfg.Domains.DNSServer), domainService.Options{
+		PublicBaseURL: cfg.Domains.PublicBaseURL,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize domain service: %v", err)
+	}
+
+	// Load the GeoIP databases
+	geoResolver, err := initGeoResolver(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize GeoIP resolver: %v", err)
+	}
+	if mmdbResolver, ok := geoResolver.(*geoip.MMDBResolver); ok {
+		services.Add(lifecycle.Component{
+			Name: "GeoIP database watcher",
+			Start: func(ctx context.Context) error {
+				mmdbResolver.WatchForChanges(cfg.GetGeoIPReloadInterval())
+				return nil
+			},
+			Stop: func(ctx context.Context) error { return mmdbResolver.Close() },
+		})
+	}
+
+	// Create the click log pipeline
+	clickLog, err := initClickLog(cfg, store, geoResolver)
+	if err != nil {
+		log.Fatalf("Failed to initialize click log pipeline: %v", err)
+	}
+	services.Add(lifecycle.Component{
+		Name:  "click log pipeline",
+		Start: func(ctx context.Context) error { clickLog.Start(); return nil },
+		// The pipeline gets its own budget so a slow HTTP drain cannot use it
+		// up; whatever is still queued when it runs out is spilled to disk
+		Stop: func(ctx context.Context) error {
+			ctx, cancel := context.WithTimeout(context.Background(), cfg.GetClickLogShutdownTimeout())
+			defer cancel()
+			return clickLog.Close(ctx)
+		},
+	})
+
+	// Sweep expired links and purge old deleted ones
+	cleanupService := initCleanupService(cfg)
+	if cleanupService != nil {
+		services.Add(lifecycle.Component{
+			Name:  "expired link cleanup",
+			Start: func(ctx context.Context) error { cleanupService.Start(); return nil },
+			Stop:  cleanupService.Stop,
+		})
+	}
+
+	// Setup router and HTTP server; the server is stopped first so in-flight
+	// requests finish before the components they use shut down
+	router := setupRouter(cfg, store, redisClient, mail, qrGenerator, slugPolicy, domainSvc, clickLog, cleanupService)
+	server, serverErrors := newHTTPServer(cfg, router)
+	services.Add(server)
+
+	if err := services.Start(ctx); err != nil {
+		log.Fatalf("Failed to start services: %v", err)
+	}
 
-	// Run migrations
-	if err := runMigrations(cfg); err != nil {
-		log.Fatalf("Failed to run migrations: %v", err)
+	exitCode := 0
+	select {
+	case <-ctx.Done():
+		log.Println("Shutdown signal received, draining requests...")
+	case err := <-serverErrors:
+		log.Printf("HTTP server failed: %v", err)
+		exitCode = 1
+	}
+	// Restore default signal handling so a second signal exits immediately
+	stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.GetShutdownTimeout())
+	defer cancel()
+	if err := services.Stop(shutdownCtx); err != nil {
+		log.Printf("Shutdown completed with errors: %v", err)
+		exitCode = 1
+	} else {
+		log.Println("👋 Shutdown complete")
 	}
 
-	// Setup router
-	router := setupRouter(cfg, redisClient)
+	if exitCode != 0 {
+		cancel()
+		os.Exit(exitCode)
+	}
+}
 
-	// Start server
-	log.Printf("🚀 Starting server on port %s", cfg.Server.Port)
-	if err := router.Run(":" + cfg.Server.Port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+// newHTTPServer returns the HTTP server as a lifecycle component. Errors
+// from serving after a successful start are sent on the returned channel.
+func newHTTPServer(cfg *config.Config, handler http.Handler) (lifecycle.Component, <-chan error) {
+	server := &http.Server{
+		Addr:              ":" + cfg.Server.Port,
+		Handler:           handler,
+		ReadTimeout:       cfg.GetServerReadTimeout(),
+		ReadHeaderTimeout: cfg.GetServerReadHeaderTimeout(),
+		WriteTimeout:      cfg.GetServerWriteTimeout(),
+		IdleTimeout:       cfg.GetServerIdleTimeout(),
 	}
+	serverErrors := make(chan error, 1)
+
+	return lifecycle.Component{
+		Name: "HTTP server",
+		Start: func(ctx context.Context) error {
+			listener, err := net.Listen("tcp", server.Addr)
+			if err != nil {
+				return err
+			}
+
+			log.Printf("🚀 Starting server on port %s", cfg.Server.Port)
+			go func() {
+				if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					serverErrors <- err
+				}
+			}()
+			return nil
+		},
+		Stop: server.Shutdown,
+	}, serverErrors
 }
 
 func initDatabase(cfg *config.Config) error {
@@ -72,7 +278,6 @@ func initDatabase(cfg *config.Config) error {
 	}
 
 	fmt.Println("✅ Successfully connected to PostgreSQL")
-	dbRepo.InitDB(db)
 	return nil
 }
 
@@ -102,10 +307,120 @@ func initRedis(cfg *config.Config) (*redis.Client, error) {
 	}
 
 	fmt.Println("✅ Successfully connected to Redis")
+	redisRepo.InitRedis(redisClient)
+	redisRepo.ConfigureLinkCache(cfg.GetLinkCacheTTL(), cfg.GetLinkCacheNegativeTTL())
 	return redisClient, nil
 }
 
-func setupRouter(cfg *config.Config, redisClient *redis.Client) *gin.Engine {
+func initMailer(cfg *config.Config) (mailer.Mailer, error) {
+	switch cfg.Mail.Driver {
+	case "log":
+		log.Println("⚠️  MAIL_DRIVER is log, emails are written to the server log")
+		return mailer.LogMailer{}, nil
+	case "file":
+		fmt.Printf("✅ Writing emails to %s\n", cfg.Mail.FileDir)
+		return mailer.NewFileMailer(cfg.Mail.FileDir, cfg.Mail.From)
+	case "smtp":
+		fmt.Printf("✅ Sending emails through %s:%d\n", cfg.Mail.SMTPHost, cfg.Mail.SMTPPort)
+		return mailer.NewSMTPMailer(mailer.SMTPConfig{
+			Host:     cfg.Mail.SMTPHost,
+			Port:     cfg.Mail.SMTPPort,
+			Username: cfg.Mail.SMTPUsername,
+			Password: cfg.Mail.SMTPPassword,
+			From:     cfg.Mail.From,
+		})
+	default:
+		return nil, fmt.Errorf("unknown MAIL_DRIVER %q (want log, file or smtp)", cfg.Mail.Driver)
+	}
+}
+
+func initQRGenerator(cfg *config.Config) (*qrcode.Generator, error) {
+	if cfg.QR.LogoPath == "" {
+		return qrcode.NewGenerator(nil)
+	}
+
+	logo, err := qrcode.LoadLogo(cfg.QR.LogoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("✅ Loaded QR logo from %s\n", cfg.QR.LogoPath)
+	return qrcode.NewGenerator(logo)
+}
+
+func initGeoResolver(cfg *config.Config) (geoip.GeoResolver, error) {
+	if cfg.GeoIP.CityDBPath == "" && cfg.GeoIP.ASNDBPath == "" {
+		log.Println("⚠️  No GeoIP databases configured, access logs will not include locations")
+		return geoip.NoopResolver{}, nil
+	}
+
+	resolver, err := geoip.NewMMDBResolver(cfg.GeoIP.CityDBPath, cfg.GeoIP.ASNDBPath)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Println("✅ Loaded GeoIP databases")
+	return resolver, nil
+}
+
+func initClickLog(cfg *config.Config, accessLogs repositories.AccessLogRepository, geoResolver geoip.GeoResolver) (*clicklog.Pipeline, error) {
+	pipeline, err := clicklog.New(accessLogs.InsertAccessLogs, clicklog.Options{
+		QueueSize:     cfg.ClickLog.QueueSize,
+		Workers:       cfg.ClickLog.Workers,
+		BatchSize:     cfg.ClickLog.BatchSize,
+		FlushInterval: cfg.GetClickLogFlushInterval(),
+		Overflow:      clicklog.OverflowPolicy(cfg.ClickLog.OverflowPolicy),
+		SpillPath:     cfg.ClickLog.SpillPath,
+		Enrich:        accessLogEnricher(geoResolver),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	expvar.Publish("click_log", expvar.Func(func() interface{} {
+		return pipeline.Stats()
+	}))
+
+	return pipeline, nil
+}
+
+// accessLogEnricher derives device, browser and OS from the user agent and
+// the visitor's location from the GeoIP databases
+func accessLogEnricher(geoResolver geoip.GeoResolver) clicklog.Enricher {
+	return func(entry *models.AccessLog) {
+		if entry.DeviceType == "" {
+			entry.DeviceType, entry.Browser, entry.OS = useragent.Parse(entry.UserAgent)
+		}
+
+		if entry.Country == "" {
+			location, err := geoResolver.Lookup(entry.IPAddress)
+			if err != nil {
+				return
+			}
+			entry.Country = location.Country
+			entry.Region = location.Region
+			entry.City = location.City
+			entry.ASN = int64(location.ASN)
+		}
+	}
+}
+
+// initCleanupService returns nil when the cleanup service is disabled
+func initCleanupService(cfg *config.Config) *cleanup.ExpiredLinkCleanupService {
+	if !cfg.Cleanup.Enabled {
+		log.Println("⚠️  Expired link cleanup is disabled")
+		return nil
+	}
+
+	service := cleanup.NewExpiredLinkCleanupService(db, cfg.GetCleanupInterval(), cfg.GetCleanupRetention())
+	expvar.Publish("cleanup", expvar.Func(func() interface{} {
+		return service.Status()
+	}))
+	return service
+}
+
+func setupRouter(cfg *config.Config, store repositories.Store, redisClient *redis.Client, mail mailer.Mailer, qrGenerator *qrcode.Generator,
+	slugPolicy *slugs.Policy, domainSvc *domainService.Service, clickLog *clicklog.Pipeline, cleanupService *cleanup.ExpiredLinkCleanupService) *gin.Engine {
 	router := gin.New()
 
 	// Add default middleware manually
@@ -113,16 +428,23 @@ func setupRouter(cfg *config.Config, redisClient *redis.Client) *gin.Engine {
 	router.Use(gin.Recovery())
 
 	// Create auth service with JWT secret from config
-	authService := authService.NewAuthService(cfg.JWT.Secret)
+	authService := authService.NewAuthService(cfg.JWT.Secret, cfg.GetAccessTokenTTL(), cfg.GetRefreshTokenTTL())
 
 	// Inject auth service into context for all routes
 	router.Use(middleware.AuthServiceMiddleware(authService))
+	router.Use(middleware.RepositoriesMiddleware(store))
+	router.Use(middleware.SlugPolicyMiddleware(slugPolicy))
+	router.Use(middleware.QRGeneratorMiddleware(qrGenerator))
+	router.Use(middleware.DomainsMiddleware(domainSvc))
+	router.Use(middleware.ClickLogMiddleware(clickLog))
+	router.Use(middleware.LinkUnlockMiddleware(unlock.NewService(
+		authService, cfg.GetUnlockTTL(), cfg.Links.UnlockMaxAttempts, cfg.GetUnlockLockout())))
 
 	// CORS configuration
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     cfg.CORS.AllowedOrigins,
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Authorization", "Content-Type"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Authorization", "Content-Type", middleware.WorkspaceHeader},
 		AllowCredentials: cfg.CORS.AllowCredentials,
 	}))
 
@@ -137,90 +459,147 @@ func setupRouter(cfg *config.Config, redisClient *redis.Client) *gin.Engine {
 	router.Use(middleware.SecureHeader())
 	router.Use(rateLimiter)
 
-	// Public routes
-	router.GET("/l/:slug", links.GetLinkHandler)
-	router.GET("/logs/user", logs.ListAccessLogsByUserHandler)
-	router.POST("/signup", auth.SignupHandler)
-	router.POST("/login", auth.LoginHandler)
+	// Runtime and pipeline metrics
+	if cfg.Metrics.Enabled {
+		router.GET("/debug/vars", gin.WrapH(expvar.Handler()))
+	}
+
+	emailVerification := middleware.EmailVerificationMiddleware(verification.NewService(authService, mail, verification.Options{
+		TokenTTL:       cfg.GetEmailVerificationTTL(),
+		ResendInterval: cfg.GetEmailVerificationResendInterval(),
+		VerifyURL:      cfg.Mail.AppURL + "/verify-email",
+	}))
+	unverifiedPolicy := verification.Policy{
+		MaxLinks:     cfg.Verify.UnverifiedMaxLinks,
+		AllowAPIKeys: cfg.Verify.UnverifiedAPIKeys,
+	}
+
+	// Public routes; custom domains also serve their links from the root
+	linkDomainFromHost := middleware.LinkDomainFromHost()
+	router.GET("/l/:slug", linkDomainFromHost, links.GetLinkHandler)
+	router.POST("/l/:slug/unlock", linkDomainFromHost, links.UnlockLinkHandler)
+	router.GET("/:slug", linkDomainFromHost, links.CustomDomainLinkHandler)
+	router.POST("/signup", emailVerification, auth.SignupHandler)
+	loginGuard := middleware.LoginGuardMiddleware(newLoginGuard(cfg, loginguard.MailNotifier{Mailer: mail}))
+	twoFactor := middleware.TwoFactorMiddleware(twofactor.NewService(authService, twofactor.Options{
+		Issuer:       cfg.TwoFactor.Issuer,
+		ChallengeTTL: cfg.GetTwoFactorChallengeTTL(),
+	}))
+	router.POST("/login", loginGuard, twoFactor, auth.LoginHandler)
+	router.POST("/login/2fa", loginGuard, twoFactor, auth.TwoFactorLoginHandler)
+	router.POST("/auth/refresh", auth.RefreshHandler)
+
+	passwordReset := middleware.PasswordResetMiddleware(passwordreset.NewService(authService, mail, passwordreset.Options{
+		TokenTTL:      cfg.GetPasswordResetTTL(),
+		EmailInterval: cfg.GetPasswordResetEmailInterval(),
+		ResetURL:      cfg.Mail.AppURL + "/reset-password",
+	}))
+	router.POST("/auth/forgot", passwordReset, auth.ForgotPasswordHandler)
+	router.POST("/auth/reset", passwordReset, auth.ResetPasswordHandler)
+	router.POST("/auth/verify", emailVerification, auth.VerifyEmailHandler)
+
+	// Protected routes; API keys may only use the routes their scopes allow
+	readLinks := middleware.RequireScope(models.ScopeLinksRead)
+	writeLinks := middleware.RequireScope(models.ScopeLinksWrite)
+	readAnalytics := middleware.RequireScope(models.ScopeAnalyticsRead)
+	sessionOnly := middleware.RequireSession()
+
+	// Link routes act on the custom domain named by the domain query parameter
+	linkDomain := middleware.LinkDomainFromQuery()
+
+	// Links and logs act on the workspace named by the request, the personal one by default
+	workspaceViewer := middleware.RequireWorkspaceRole(models.WorkspaceRoleViewer)
+	workspaceEditor := middleware.RequireWorkspaceRole(models.WorkspaceRoleEditor)
+	workspaceOwner := middleware.RequireWorkspaceRole(models.WorkspaceRoleOwner)
+	workspaceInvitations := middleware.InvitationsMiddleware(invitations.NewService(authService, mail, invitations.Options{
+		TokenTTL:  cfg.GetWorkspaceInvitationTTL(),
+		AcceptURL: cfg.Mail.AppURL + "/accept-invitation",
+	}))
 
-	// Protected routes
 	protected := router.Group("")
 	protected.Use(middleware.JWTAuthMiddleware())
 	{
-		protected.POST("/links", links.CreateLinkHandler)
-		protected.GET("/links", links.ListLinksHandler)
-		protected.DELETE("/links/:slug", links.DeleteLinkHandler)
+		protected.POST("/auth/logout", sessionOnly, auth.LogoutHandler)
+		protected.POST("/auth/verify/resend", sessionOnly, emailVerification, auth.ResendVerificationHandler)
+		protected.GET("/auth/2fa", sessionOnly, auth.TwoFactorStatusHandler)
+		protected.POST("/auth/2fa/setup", sessionOnly, twoFactor, auth.SetupTwoFactorHandler)
+		protected.POST("/auth/2fa/enable", sessionOnly, twoFactor, auth.EnableTwoFactorHandler)
+		protected.POST("/auth/2fa/disable", sessionOnly, loginGuard, twoFactor, auth.DisableTwoFactorHandler)
+		protected.POST("/api-keys", sessionOnly, middleware.UnverifiedAPIKeyPolicy(unverifiedPolicy), apikeys.CreateAPIKeyHandler)
+		protected.GET("/api-keys", sessionOnly, apikeys.ListAPIKeysHandler)
+		protected.DELETE("/api-keys/:id", sessionOnly, apikeys.RevokeAPIKeyHandler)
+		protected.GET("/workspaces", sessionOnly, workspaces.ListWorkspacesHandler)
+		protected.POST("/workspaces", sessionOnly, workspaces.CreateWorkspaceHandler)
+		protected.GET("/workspaces/:workspace_id/members", sessionOnly, workspaceViewer, workspaces.ListWorkspaceMembersHandler)
+		protected.PATCH("/workspaces/:workspace_id/members/:user_id", sessionOnly, workspaceOwner, workspaces.UpdateWorkspaceMemberHandler)
+		protected.DELETE("/workspaces/:workspace_id/members/:user_id", sessionOnly, workspaceViewer, workspaces.RemoveWorkspaceMemberHandler)
+		protected.POST("/workspaces/:workspace_id/invitations", sessionOnly, workspaceOwner, workspaceInvitations, workspaces.CreateWorkspaceInvitationHandler)
+		protected.GET("/workspaces/:workspace_id/invitations", sessionOnly, workspaceOwner, workspaces.ListWorkspaceInvitationsHandler)
+		protected.DELETE("/workspaces/:workspace_id/invitations/:invitation_id", sessionOnly, workspaceOwner, workspaces.RevokeWorkspaceInvitationHandler)
+		protected.GET("/workspaces/:workspace_id/domains", sessionOnly, workspaceViewer, domains.ListDomainsHandler)
+		protected.POST("/workspaces/:workspace_id/domains", sessionOnly, workspaceOwner, domains.CreateDomainHandler)
+		protected.POST("/workspaces/:workspace_id/domains/:domain_id/verify", sessionOnly, workspaceOwner, domains.VerifyDomainHandler)
+		protected.DELETE("/workspaces/:workspace_id/domains/:domain_id", sessionOnly, workspaceOwner, domains.DeleteDomainHandler)
+		protected.POST("/invitations/accept", sessionOnly, workspaceInvitations, workspaces.AcceptWorkspaceInvitationHandler)
+		protected.POST("/links", writeLinks, workspaceEditor, middleware.UnverifiedLinkLimit(unverifiedPolicy), links.CreateLinkHandler)
+		protected.GET("/links", readLinks, workspaceViewer, links.ListLinksHandler)
+		protected.GET("/links/slug-availability", readLinks, linkDomain, links.SlugAvailabilityHandler)
+		protected.GET("/tags", readLinks, workspaceViewer, links.ListTagsHandler)
+		protected.PATCH("/tags/:tag_id", writeLinks, workspaceEditor, links.RenameTagHandler)
+		protected.POST("/tags/:tag_id/merge", writeLinks, workspaceEditor, links.MergeTagHandler)
+		protected.GET("/folders", readLinks, workspaceViewer, links.ListFoldersHandler)
+		protected.PATCH("/links/:slug", writeLinks, linkDomain, links.UpdateLinkHandler)
+		protected.DELETE("/links/:slug", writeLinks, linkDomain, links.DeleteLinkHandler)
+		protected.GET("/links/:slug/history", readLinks, linkDomain, links.LinkHistoryHandler)
+		protected.POST("/links/:slug/history/:revision_id/rollback", writeLinks, linkDomain, links.RollbackLinkHandler)
+		protected.GET("/links/:slug/logs", readAnalytics, linkDomain, logs.ListLinkAccessLogsHandler)
+		protected.GET("/links/:slug/stats", readAnalytics, linkDomain, logs.LinkStatsHandler)
+		protected.GET("/links/:slug/qr", readLinks, linkDomain, links.QRCodeHandler)
+		protected.GET("/logs", readAnalytics, workspaceViewer, logs.ListAccessLogsHandler)
+		protected.GET("/logs/user", readAnalytics, workspaceViewer, logs.ListAccessLogsByUserHandler)
+	}
+
+	// Instance administration; admins are appointed with `main set-role`
+	adminRoutes := protected.Group("/admin", sessionOnly, middleware.RequireAdmin())
+	{
+		adminRoutes.GET("/users", admin.ListUsersHandler)
+		adminRoutes.POST("/users/:id/disable", admin.DisableUserHandler)
+		adminRoutes.POST("/users/:id/enable", admin.EnableUserHandler)
+		adminRoutes.POST("/users/:id/unlock-login", loginGuard, admin.UnlockLoginHandler)
+		adminRoutes.GET("/links", admin.ListLinksHandler)
+		adminRoutes.POST("/links/:slug/takedown", linkDomain, admin.TakeDownLinkHandler)
+		adminRoutes.DELETE("/links/:slug/takedown", linkDomain, admin.RestoreLinkHandler)
+		adminRoutes.GET("/stats", admin.InstanceStatsHandler)
+		adminRoutes.GET("/system/cleanup", middleware.CleanupServiceMiddleware(cleanupService), system.CleanupStatusHandler)
 	}
 
 	return router
 }
 
-func runMigrations(cfg *config.Config) error {
-	migrationDir := cfg.Migration.Path
-	if !filepath.IsAbs(migrationDir) {
-		// Convert relative path to absolute path based on current working directory
-		cwd, err := os.Getwd()
-		if err != nil {
-			return fmt.Errorf("failed to get current working directory: %v", err)
-		}
-		migrationDir = filepath.Join(cwd, migrationDir)
-	}
-
-	log.Printf("🔍 Looking for migrations in: %s", migrationDir)
+// newLoginGuard creates the login brute-force guard, keeping attempts in Redis
+func newLoginGuard(cfg *config.Config, notifier loginguard.Notifier) *loginguard.Guard {
+	return loginguard.New(loginguard.RedisStore{}, loginguard.Options{
+		MaxAttempts:   cfg.Login.MaxAttempts,
+		Lockout:       cfg.GetLoginLockout(),
+		FreeAttempts:  cfg.Login.FreeAttempts,
+		BaseDelay:     cfg.GetLoginBaseDelay(),
+		MaxDelay:      cfg.GetLoginMaxDelay(),
+		IPMaxAttempts: cfg.Login.IPMaxAttempts,
+		IPWindow:      cfg.GetLoginIPWindow(),
+	}, notifier)
+}
 
-	// Read migration files from directory
-	files, err := filepath.Glob(filepath.Join(migrationDir, "*.sql"))
+func applyMigrations(ctx context.Context) error {
+	runner, err := migrations.NewRunner(db)
 	if err != nil {
-		return fmt.Errorf("failed to find migration files: %v", err)
+		return err
 	}
 
-	if len(files) == 0 {
-		log.Printf("⚠️  No migration files found in %s", migrationDir)
-
-		// Try alternative paths if not found
-		altPaths := []string{
-			"./scripts/migrations",
-			"../../scripts/migrations",
-			"../../../scripts/migrations",
-		}
-
-		for _, altPath := range altPaths {
-			var altDir string
-			if filepath.IsAbs(altPath) {
-				altDir = altPath
-			} else {
-				cwd, _ := os.Getwd()
-				altDir = filepath.Join(cwd, altPath)
-			}
-
-			altFiles, err := filepath.Glob(filepath.Join(altDir, "*.sql"))
-			if err == nil && len(altFiles) > 0 {
-				log.Printf("✅ Found migrations in alternative path: %s", altDir)
-				files = altFiles
-				migrationDir = altDir
-				break
-			}
-		}
-
-		if len(files) == 0 {
-			log.Println("⚠️  No migration files found in any location")
-			return nil
-		}
-	}
-
-	for _, file := range files {
-		content, err := ioutil.ReadFile(file)
-		if err != nil {
-			return fmt.Errorf("failed to read migration script %s: %v", file, err)
-		}
-
-		if _, err := db.Exec(string(content)); err != nil {
-			return fmt.Errorf("failed to execute migration script %s: %v", file, err)
-		}
-
-		log.Printf("✅ Executed migration: %s", filepath.Base(file))
+	applied, err := runner.Up(ctx)
+	if err != nil {
+		return err
 	}
 
-	fmt.Println("✅ All migrations executed successfully")
+	log.Printf("✅ Database schema at version %d (%d migrations applied)", runner.Latest(), len(applied))
 	return nil
 }
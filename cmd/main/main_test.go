@@ -0,0 +1,46 @@
+package main
+
+import (
+	"link-guardian/internal/config"
+	"link-guardian/internal/repositories/memory"
+	"link-guardian/internal/services/domains"
+	"link-guardian/internal/services/mailer"
+	"link-guardian/internal/services/slugs"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Custom domains serve links from the root, so a slug equal to the first
+// segment of a route would be shadowed by it
+func TestDefaultReservedSlugsCoverRoutes(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	t.Setenv("METRICS_ENABLED", "true")
+	t.Setenv("RESERVED_SLUGS", "")
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	slugPolicy, err := slugs.NewPolicy(cfg.Links.SlugMinLength, cfg.Links.SlugMaxLength, cfg.Links.ReservedSlugs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	domainSvc, err := domains.NewService(domains.StaticResolver{}, domains.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := setupRouter(cfg, memory.NewStore(), nil, mailer.LogMailer{}, nil, slugPolicy, domainSvc, nil, nil)
+	for _, route := range router.Routes() {
+		segment := strings.SplitN(strings.TrimPrefix(route.Path, "/"), "/", 2)[0]
+		if segment == "" || strings.HasPrefix(segment, ":") {
+			continue
+		}
+		if !slugPolicy.IsReserved(segment) {
+			t.Errorf("route %s %s is not covered by a reserved slug %q", route.Method, route.Path, segment)
+		}
+	}
+}
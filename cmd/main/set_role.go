@@ -0,0 +1,35 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"strings"
+)
+
+const setRoleUsage = "usage: main set-role <email> <user|admin>"
+
+// runSetRoleCommand handles `main set-role <email> <role>`, which appoints or
+// removes instance admins. The first admin can only be created this way.
+func runSetRoleCommand(store repositories.Store, args []string) error {
+	if len(args) != 2 || strings.TrimSpace(args[0]) == "" {
+		return errors.New(setRoleUsage)
+	}
+
+	role := strings.ToLower(args[1])
+	if role != models.UserRoleUser && role != models.UserRoleAdmin {
+		return errors.New(setRoleUsage)
+	}
+
+	user, err := store.GetUserByEmail(strings.TrimSpace(args[0]))
+	if err != nil {
+		return err
+	}
+	if err := store.SetUserRole(user.ID, role); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s is now %s\n", user.Email, role)
+	return nil
+}
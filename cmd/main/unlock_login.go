@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"link-guardian/internal/config"
+	"link-guardian/internal/services/loginguard"
+	"strings"
+)
+
+const unlockLoginUsage = "usage: main unlock-login <email>"
+
+// runUnlockLoginCommand handles `main unlock-login <email>`, which lifts a
+// lockout caused by failed logins and forgets the email's failures
+func runUnlockLoginCommand(ctx context.Context, cfg *config.Config, args []string) error {
+	if len(args) != 1 || strings.TrimSpace(args[0]) == "" {
+		return errors.New(unlockLoginUsage)
+	}
+
+	email := strings.TrimSpace(strings.ToLower(args[0]))
+	if err := newLoginGuard(cfg, loginguard.LogNotifier{}).Unlock(ctx, email); err != nil {
+		return err
+	}
+
+	fmt.Printf("Unlocked logins for %s\n", email)
+	return nil
+}
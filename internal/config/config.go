@@ -17,7 +17,21 @@ type Config struct {
 	JWT       JWTConfig
 	CORS      CORSConfig
 	RateLimit RateLimitConfig
+	Login     LoginConfig
+	Mail      MailConfig
+	Reset     PasswordResetConfig
+	Verify    VerificationConfig
+	TwoFactor TwoFactorConfig
+	Workspace WorkspaceConfig
 	Migration MigrationConfig
+	Links     LinksConfig
+	Domains   DomainsConfig
+	QR        QRConfig
+	Cache     CacheConfig
+	ClickLog  ClickLogConfig
+	Metrics   MetricsConfig
+	GeoIP     GeoIPConfig
+	Cleanup   CleanupConfig
 }
 
 type DatabaseConfig struct {
@@ -38,12 +52,19 @@ type RedisConfig struct {
 }
 
 type ServerConfig struct {
-	Port    string
-	GinMode string
+	Port                     string
+	GinMode                  string
+	ReadTimeoutSeconds       int
+	ReadHeaderTimeoutSeconds int
+	WriteTimeoutSeconds      int
+	IdleTimeoutSeconds       int
+	ShutdownTimeoutSeconds   int // Budget for draining requests and stopping components
 }
 
 type JWTConfig struct {
-	Secret string
+	Secret           string
+	AccessTTLMinutes int
+	RefreshTTLHours  int
 }
 
 type CORSConfig struct {
@@ -56,8 +77,99 @@ type RateLimitConfig struct {
 	WindowMinutes int
 }
 
+type LoginConfig struct {
+	MaxAttempts     int // Failed logins per email before the account is locked
+	LockoutMinutes  int
+	FreeAttempts    int // Failed logins per email before progressive delays start
+	BaseDelayMs     int
+	MaxDelaySeconds int
+	IPMaxAttempts   int // Failed logins per IP, across all emails, before the IP is blocked
+	IPWindowMinutes int
+}
+
+type MailConfig struct {
+	Driver       string // log, file or smtp
+	From         string
+	FileDir      string // Directory the file driver writes .eml files to
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	AppURL       string // Base URL of the web app, used for links in emails
+}
+
+type PasswordResetConfig struct {
+	TokenTTLMinutes      int
+	EmailIntervalSeconds int // Minimum time between reset emails to one address
+}
+
+type VerificationConfig struct {
+	TokenTTLHours         int
+	ResendIntervalSeconds int  // Minimum time between verification emails to one address
+	UnverifiedMaxLinks    int  // Active links a user may own before verifying their email; negative means no limit
+	UnverifiedAPIKeys     bool // Whether users may create API keys before verifying their email
+}
+
+type TwoFactorConfig struct {
+	Issuer              string // Name authenticator apps show next to the account
+	ChallengeTTLMinutes int    // Time allowed for entering the code after the password
+}
+
+type WorkspaceConfig struct {
+	InvitationTTLHours int
+}
+
 type MigrationConfig struct {
-	Path string
+	OnStart bool
+}
+
+type LinksConfig struct {
+	SlugMinLength        int
+	SlugMaxLength        int
+	ReservedSlugs        []string
+	UnlockTTLMinutes     int
+	UnlockMaxAttempts    int
+	UnlockLockoutMinutes int
+}
+
+type DomainsConfig struct {
+	PublicBaseURL string // Base URL of the default short domain, e.g. https://sho.rt; empty uses the request host
+	DNSServer     string // host:port of the DNS server verification records are looked up on; empty uses the system resolver
+}
+
+type CacheConfig struct {
+	LinkTTLSeconds         int
+	LinkNegativeTTLSeconds int
+}
+
+type ClickLogConfig struct {
+	QueueSize       int
+	Workers         int
+	BatchSize       int
+	FlushIntervalMs int
+	OverflowPolicy  string // drop, block or spill
+	SpillPath       string
+	ShutdownSeconds int
+}
+
+type GeoIPConfig struct {
+	CityDBPath            string // MaxMind or DB-IP city .mmdb file
+	ASNDBPath             string // MaxMind or DB-IP ASN .mmdb file
+	ReloadIntervalSeconds int
+}
+
+type CleanupConfig struct {
+	Enabled         bool
+	IntervalMinutes int
+	RetentionDays   int // Days soft-deleted links are kept before being purged; 0 keeps them forever
+}
+
+type MetricsConfig struct {
+	Enabled bool // Expose expvar metrics on /debug/vars
+}
+
+type QRConfig struct {
+	LogoPath string // Optional PNG/JPEG logo centred in QR codes
 }
 
 // LoadConfig loads configuration from environment variables
@@ -104,12 +216,19 @@ func LoadConfig() (*Config, error) {
 	// Railway provides PORT env var, fallback to SERVER_PORT, then default to 8081
 	config.Server.Port = getEnv("PORT", getEnv("SERVER_PORT", "8081"))
 	config.Server.GinMode = getEnv("GIN_MODE", "debug")
+	config.Server.ReadTimeoutSeconds = getEnvAsInt("SERVER_READ_TIMEOUT_SECONDS", 15)
+	config.Server.ReadHeaderTimeoutSeconds = getEnvAsInt("SERVER_READ_HEADER_TIMEOUT_SECONDS", 5)
+	config.Server.WriteTimeoutSeconds = getEnvAsInt("SERVER_WRITE_TIMEOUT_SECONDS", 30)
+	config.Server.IdleTimeoutSeconds = getEnvAsInt("SERVER_IDLE_TIMEOUT_SECONDS", 120)
+	config.Server.ShutdownTimeoutSeconds = getEnvAsInt("SERVER_SHUTDOWN_TIMEOUT_SECONDS", 30)
 
 	// JWT configuration
 	config.JWT.Secret = getEnv("JWT_SECRET", "")
 	if config.JWT.Secret == "" {
 		return nil, fmt.Errorf("JWT_SECRET environment variable is required")
 	}
+	config.JWT.AccessTTLMinutes = getEnvAsInt("ACCESS_TOKEN_TTL_MINUTES", 15)
+	config.JWT.RefreshTTLHours = getEnvAsInt("REFRESH_TOKEN_TTL_HOURS", 720)
 
 	// CORS configuration
 	originsStr := getEnv("CORS_ALLOWED_ORIGINS", "http://localhost:3000")
@@ -120,8 +239,88 @@ func LoadConfig() (*Config, error) {
 	config.RateLimit.Requests = getEnvAsInt("RATE_LIMIT_REQUESTS", 500)
 	config.RateLimit.WindowMinutes = getEnvAsInt("RATE_LIMIT_WINDOW_MINUTES", 1)
 
+	// Login brute-force protection configuration
+	config.Login.MaxAttempts = getEnvAsInt("LOGIN_MAX_ATTEMPTS", 10)
+	config.Login.LockoutMinutes = getEnvAsInt("LOGIN_LOCKOUT_MINUTES", 15)
+	config.Login.FreeAttempts = getEnvAsInt("LOGIN_FREE_ATTEMPTS", 3)
+	config.Login.BaseDelayMs = getEnvAsInt("LOGIN_BASE_DELAY_MS", 1000)
+	config.Login.MaxDelaySeconds = getEnvAsInt("LOGIN_MAX_DELAY_SECONDS", 30)
+	config.Login.IPMaxAttempts = getEnvAsInt("LOGIN_IP_MAX_ATTEMPTS", 50)
+	config.Login.IPWindowMinutes = getEnvAsInt("LOGIN_IP_WINDOW_MINUTES", 15)
+
+	// Mail configuration
+	config.Mail.Driver = getEnv("MAIL_DRIVER", "log")
+	config.Mail.From = getEnv("MAIL_FROM", "Link Guardian <no-reply@localhost>")
+	config.Mail.FileDir = getEnv("MAIL_FILE_DIR", "./data/mail")
+	config.Mail.SMTPHost = getEnv("SMTP_HOST", "")
+	config.Mail.SMTPPort = getEnvAsInt("SMTP_PORT", 587)
+	config.Mail.SMTPUsername = getEnv("SMTP_USERNAME", "")
+	config.Mail.SMTPPassword = getEnv("SMTP_PASSWORD", "")
+	config.Mail.AppURL = strings.TrimRight(getEnv("APP_URL", "http://localhost:3000"), "/")
+
+	// Password reset configuration
+	config.Reset.TokenTTLMinutes = getEnvAsInt("PASSWORD_RESET_TTL_MINUTES", 30)
+	config.Reset.EmailIntervalSeconds = getEnvAsInt("PASSWORD_RESET_EMAIL_INTERVAL_SECONDS", 60)
+
+	// Email verification configuration
+	config.Verify.TokenTTLHours = getEnvAsInt("EMAIL_VERIFICATION_TTL_HOURS", 48)
+	config.Verify.ResendIntervalSeconds = getEnvAsInt("EMAIL_VERIFICATION_RESEND_SECONDS", 60)
+	config.Verify.UnverifiedMaxLinks = getEnvAsInt("UNVERIFIED_MAX_LINKS", 3)
+	config.Verify.UnverifiedAPIKeys = getEnvAsBool("UNVERIFIED_ALLOW_API_KEYS", false)
+
+	// Two-factor authentication configuration
+	config.TwoFactor.Issuer = getEnv("TOTP_ISSUER", "Link Guardian")
+	config.TwoFactor.ChallengeTTLMinutes = getEnvAsInt("TWO_FACTOR_CHALLENGE_TTL_MINUTES", 5)
+
+	// Workspace configuration
+	config.Workspace.InvitationTTLHours = getEnvAsInt("WORKSPACE_INVITATION_TTL_HOURS", 168)
+
 	// Migration configuration
-	config.Migration.Path = getEnv("MIGRATION_PATH", "./scripts/migrations")
+	config.Migration.OnStart = getEnvAsBool("MIGRATE_ON_START", true)
+
+	// Custom slug configuration
+	config.Links.SlugMinLength = getEnvAsInt("SLUG_MIN_LENGTH", 3)
+	config.Links.SlugMaxLength = getEnvAsInt("SLUG_MAX_LENGTH", 64)
+	config.Links.ReservedSlugs = getEnvAsList("RESERVED_SLUGS",
+		"login,signup,logout,links,logs,admin,api,auth,l,static,assets,health,metrics,dashboard,analytics,workspaces,invitations,tags,folders,api-keys,debug")
+
+	// Password-protected link configuration
+	config.Links.UnlockTTLMinutes = getEnvAsInt("LINK_UNLOCK_TTL_MINUTES", 15)
+	config.Links.UnlockMaxAttempts = getEnvAsInt("LINK_UNLOCK_MAX_ATTEMPTS", 5)
+	config.Links.UnlockLockoutMinutes = getEnvAsInt("LINK_UNLOCK_LOCKOUT_MINUTES", 15)
+
+	// Custom domain configuration
+	config.Domains.PublicBaseURL = strings.TrimRight(getEnv("PUBLIC_BASE_URL", ""), "/")
+	config.Domains.DNSServer = getEnv("DOMAIN_DNS_SERVER", "")
+
+	// Slug cache configuration
+	config.Cache.LinkTTLSeconds = getEnvAsInt("LINK_CACHE_TTL_SECONDS", 300)
+	config.Cache.LinkNegativeTTLSeconds = getEnvAsInt("LINK_CACHE_NEGATIVE_TTL_SECONDS", 30)
+
+	// QR code configuration
+	config.QR.LogoPath = getEnv("QR_LOGO_PATH", "")
+
+	// Click log pipeline configuration
+	config.ClickLog.QueueSize = getEnvAsInt("CLICK_LOG_QUEUE_SIZE", 10000)
+	config.ClickLog.Workers = getEnvAsInt("CLICK_LOG_WORKERS", 2)
+	config.ClickLog.BatchSize = getEnvAsInt("CLICK_LOG_BATCH_SIZE", 500)
+	config.ClickLog.FlushIntervalMs = getEnvAsInt("CLICK_LOG_FLUSH_INTERVAL_MS", 1000)
+	config.ClickLog.OverflowPolicy = getEnv("CLICK_LOG_OVERFLOW_POLICY", "drop")
+	config.ClickLog.SpillPath = getEnv("CLICK_LOG_SPILL_PATH", "./data/click-log-spill.jsonl")
+	config.ClickLog.ShutdownSeconds = getEnvAsInt("CLICK_LOG_SHUTDOWN_SECONDS", 10)
+
+	// GeoIP configuration
+	config.GeoIP.CityDBPath = getEnv("GEOIP_CITY_DB_PATH", "")
+	config.GeoIP.ASNDBPath = getEnv("GEOIP_ASN_DB_PATH", "")
+	config.GeoIP.ReloadIntervalSeconds = getEnvAsInt("GEOIP_RELOAD_INTERVAL_SECONDS", 60)
+
+	// Expired link cleanup configuration
+	config.Cleanup.Enabled = getEnvAsBool("CLEANUP_ENABLED", true)
+	config.Cleanup.IntervalMinutes = getEnvAsInt("CLEANUP_INTERVAL_MINUTES", 60)
+	config.Cleanup.RetentionDays = getEnvAsInt("CLEANUP_RETENTION_DAYS", 30)
+
+	// Metrics configuration
+	config.Metrics.Enabled = getEnvAsBool("METRICS_ENABLED", false)
 
 	return config, nil
 }
@@ -143,11 +342,142 @@ func (c *Config) GetRedisURL() string {
 	return c.Redis.URL
 }
 
+// GetServerReadTimeout returns the maximum time to read a whole request
+func (c *Config) GetServerReadTimeout() time.Duration {
+	return time.Duration(c.Server.ReadTimeoutSeconds) * time.Second
+}
+
+// GetServerReadHeaderTimeout returns the maximum time to read request headers
+func (c *Config) GetServerReadHeaderTimeout() time.Duration {
+	return time.Duration(c.Server.ReadHeaderTimeoutSeconds) * time.Second
+}
+
+// GetServerWriteTimeout returns the maximum time to write a response
+func (c *Config) GetServerWriteTimeout() time.Duration {
+	return time.Duration(c.Server.WriteTimeoutSeconds) * time.Second
+}
+
+// GetServerIdleTimeout returns how long idle keep-alive connections stay open
+func (c *Config) GetServerIdleTimeout() time.Duration {
+	return time.Duration(c.Server.IdleTimeoutSeconds) * time.Second
+}
+
+// GetShutdownTimeout returns how long a graceful shutdown may take
+func (c *Config) GetShutdownTimeout() time.Duration {
+	return time.Duration(c.Server.ShutdownTimeoutSeconds) * time.Second
+}
+
 // GetRateLimitWindow returns the rate limit window as time.Duration
 func (c *Config) GetRateLimitWindow() time.Duration {
 	return time.Duration(c.RateLimit.WindowMinutes) * time.Minute
 }
 
+// GetAccessTokenTTL returns how long access tokens are valid
+func (c *Config) GetAccessTokenTTL() time.Duration {
+	return time.Duration(c.JWT.AccessTTLMinutes) * time.Minute
+}
+
+// GetRefreshTokenTTL returns how long refresh tokens are valid
+func (c *Config) GetRefreshTokenTTL() time.Duration {
+	return time.Duration(c.JWT.RefreshTTLHours) * time.Hour
+}
+
+// GetLoginLockout returns how long an account stays locked after too many failed logins
+func (c *Config) GetLoginLockout() time.Duration {
+	return time.Duration(c.Login.LockoutMinutes) * time.Minute
+}
+
+// GetLoginBaseDelay returns the delay after the first delayed failed login
+func (c *Config) GetLoginBaseDelay() time.Duration {
+	return time.Duration(c.Login.BaseDelayMs) * time.Millisecond
+}
+
+// GetLoginMaxDelay returns the longest delay between failed logins before a lockout
+func (c *Config) GetLoginMaxDelay() time.Duration {
+	return time.Duration(c.Login.MaxDelaySeconds) * time.Second
+}
+
+// GetLoginIPWindow returns the window failed logins per IP are counted in
+func (c *Config) GetLoginIPWindow() time.Duration {
+	return time.Duration(c.Login.IPWindowMinutes) * time.Minute
+}
+
+// GetPasswordResetTTL returns how long password reset links stay valid
+func (c *Config) GetPasswordResetTTL() time.Duration {
+	return time.Duration(c.Reset.TokenTTLMinutes) * time.Minute
+}
+
+// GetPasswordResetEmailInterval returns the minimum time between reset emails to one address
+func (c *Config) GetPasswordResetEmailInterval() time.Duration {
+	return time.Duration(c.Reset.EmailIntervalSeconds) * time.Second
+}
+
+// GetEmailVerificationTTL returns how long email verification links stay valid
+func (c *Config) GetEmailVerificationTTL() time.Duration {
+	return time.Duration(c.Verify.TokenTTLHours) * time.Hour
+}
+
+// GetEmailVerificationResendInterval returns the minimum time between verification emails to one address
+func (c *Config) GetEmailVerificationResendInterval() time.Duration {
+	return time.Duration(c.Verify.ResendIntervalSeconds) * time.Second
+}
+
+// GetTwoFactorChallengeTTL returns how long users have to enter their second factor after their password
+func (c *Config) GetTwoFactorChallengeTTL() time.Duration {
+	return time.Duration(c.TwoFactor.ChallengeTTLMinutes) * time.Minute
+}
+
+// GetWorkspaceInvitationTTL returns how long workspace invitations stay valid
+func (c *Config) GetWorkspaceInvitationTTL() time.Duration {
+	return time.Duration(c.Workspace.InvitationTTLHours) * time.Hour
+}
+
+// GetUnlockTTL returns how long a password-protected link stays unlocked
+func (c *Config) GetUnlockTTL() time.Duration {
+	return time.Duration(c.Links.UnlockTTLMinutes) * time.Minute
+}
+
+// GetUnlockLockout returns how long failed unlock attempts are remembered
+func (c *Config) GetUnlockLockout() time.Duration {
+	return time.Duration(c.Links.UnlockLockoutMinutes) * time.Minute
+}
+
+// GetLinkCacheTTL returns how long resolved links stay cached
+func (c *Config) GetLinkCacheTTL() time.Duration {
+	return time.Duration(c.Cache.LinkTTLSeconds) * time.Second
+}
+
+// GetLinkCacheNegativeTTL returns how long unknown slugs stay cached
+func (c *Config) GetLinkCacheNegativeTTL() time.Duration {
+	return time.Duration(c.Cache.LinkNegativeTTLSeconds) * time.Second
+}
+
+// GetClickLogFlushInterval returns how often partial click log batches are written
+func (c *Config) GetClickLogFlushInterval() time.Duration {
+	return time.Duration(c.ClickLog.FlushIntervalMs) * time.Millisecond
+}
+
+// GetClickLogShutdownTimeout returns how long shutdown waits for queued clicks
+// to be written before spilling the rest
+func (c *Config) GetClickLogShutdownTimeout() time.Duration {
+	return time.Duration(c.ClickLog.ShutdownSeconds) * time.Second
+}
+
+// GetGeoIPReloadInterval returns how often GeoIP database files are checked for changes
+func (c *Config) GetGeoIPReloadInterval() time.Duration {
+	return time.Duration(c.GeoIP.ReloadIntervalSeconds) * time.Second
+}
+
+// GetCleanupInterval returns how often the expired link cleanup runs
+func (c *Config) GetCleanupInterval() time.Duration {
+	return time.Duration(c.Cleanup.IntervalMinutes) * time.Minute
+}
+
+// GetCleanupRetention returns how long soft-deleted links are kept before being purged
+func (c *Config) GetCleanupRetention() time.Duration {
+	return time.Duration(c.Cleanup.RetentionDays) * 24 * time.Hour
+}
+
 // Helper functions
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -173,3 +503,13 @@ func getEnvAsBool(key string, defaultValue bool) bool {
 	}
 	return defaultValue
 }
+
+func getEnvAsList(key, defaultValue string) []string {
+	var values []string
+	for _, value := range strings.Split(getEnv(key, defaultValue), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,209 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"link-guardian/internal/handlers/middleware"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"link-guardian/internal/repositories/memory"
+	"link-guardian/internal/repositories/repotest"
+	"link-guardian/internal/services/loginguard"
+	"link-guardian/internal/testutil/handlertest"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newTestLoginGuard locks an account after two failed logins
+func newTestLoginGuard() *loginguard.Guard {
+	return loginguard.New(loginguard.NewMemoryStore(), loginguard.Options{
+		MaxAttempts:   2,
+		Lockout:       time.Minute,
+		FreeAttempts:  2,
+		IPMaxAttempts: 100,
+		IPWindow:      time.Minute,
+	}, nil)
+}
+
+// newTestRouter serves the admin routes with the same middleware as the server
+func newTestRouter(store *memory.Store) *gin.Engine {
+	return newTestRouterWithGuard(store, newTestLoginGuard())
+}
+
+func newTestRouterWithGuard(store *memory.Store, guard *loginguard.Guard) *gin.Engine {
+	router := handlertest.NewRouter(store)
+
+	protected := router.Group("")
+	protected.Use(middleware.JWTAuthMiddleware())
+	protected.GET("/me", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	adminRoutes := protected.Group("/admin", middleware.RequireSession(), middleware.RequireAdmin())
+	adminRoutes.GET("/users", ListUsersHandler)
+	adminRoutes.POST("/users/:id/disable", DisableUserHandler)
+	adminRoutes.POST("/users/:id/enable", EnableUserHandler)
+	adminRoutes.POST("/users/:id/unlock-login", middleware.LoginGuardMiddleware(guard), UnlockLoginHandler)
+	adminRoutes.GET("/links", ListLinksHandler)
+	adminRoutes.POST("/links/:slug/takedown", TakeDownLinkHandler)
+	adminRoutes.DELETE("/links/:slug/takedown", RestoreLinkHandler)
+	adminRoutes.GET("/stats", InstanceStatsHandler)
+	return router
+}
+
+func newAdmin(t *testing.T, store *memory.Store) int {
+	t.Helper()
+	adminID := repotest.CreateUser(t, store)
+	if err := store.SetUserRole(adminID, models.UserRoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+	return adminID
+}
+
+func TestAdminRoutesRequireAdmin(t *testing.T) {
+	store := memory.NewStore()
+	router := newTestRouter(store)
+	userID := repotest.CreateUser(t, store)
+
+	if w := handlertest.Request(t, router, userID, http.MethodGet, "/admin/stats", ""); w.Code != http.StatusForbidden {
+		t.Errorf("stats as a user: got %d, want 403", w.Code)
+	}
+	if w := handlertest.Request(t, router, newAdmin(t, store), http.MethodGet, "/admin/stats", ""); w.Code != http.StatusOK {
+		t.Errorf("stats as an admin: got %d: %s", w.Code, w.Body)
+	}
+}
+
+func TestDisableUser(t *testing.T) {
+	store := memory.NewStore()
+	router := newTestRouter(store)
+	adminID := newAdmin(t, store)
+	userID := repotest.CreateUser(t, store)
+	user, err := store.GetUserByID(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := handlertest.Request(t, router, adminID, http.MethodGet, "/admin/users?q="+user.Username, "")
+	var listed struct {
+		Users []models.AdminUserResponse `json:"users"`
+		Total int                        `json:"total"`
+	}
+	handlertest.Decode(t, w, &listed)
+	if w.Code != http.StatusOK || listed.Total != 1 || listed.Users[0].ID != userID || strings.Contains(w.Body.String(), "password") {
+		t.Fatalf("search users: got %d: %s", w.Code, w.Body)
+	}
+
+	refresh, err := store.CreateRefreshToken(models.RefreshToken{
+		UserID: userID, FamilyID: "family", TokenHash: "refresh-hash", ExpiresAt: time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	userPath := "/admin/users/" + strconv.Itoa(userID)
+	if w := handlertest.Request(t, router, adminID, http.MethodPost, "/admin/users/"+strconv.Itoa(adminID)+"/disable", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("disabling yourself: got %d, want 400", w.Code)
+	}
+	if w := handlertest.Request(t, router, adminID, http.MethodPost, userPath+"/disable", ""); w.Code != http.StatusOK {
+		t.Fatalf("disable: got %d: %s", w.Code, w.Body)
+	}
+	if w := handlertest.Request(t, router, userID, http.MethodGet, "/me", ""); w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), "Account disabled") {
+		t.Errorf("request by a disabled user: got %d: %s", w.Code, w.Body)
+	}
+	next := models.RefreshToken{UserID: userID, FamilyID: "family", TokenHash: "next-hash", ExpiresAt: time.Now().Add(time.Hour)}
+	if _, err := store.RotateRefreshToken(refresh.TokenHash, next); !errors.Is(err, repositories.ErrRefreshTokenReused) {
+		t.Errorf("refreshing the session of a disabled user: got %v, want ErrRefreshTokenReused", err)
+	}
+
+	if w := handlertest.Request(t, router, adminID, http.MethodPost, userPath+"/enable", ""); w.Code != http.StatusOK {
+		t.Fatalf("enable: got %d: %s", w.Code, w.Body)
+	}
+	if w := handlertest.Request(t, router, userID, http.MethodGet, "/me", ""); w.Code != http.StatusNoContent {
+		t.Errorf("request by an enabled user: got %d: %s", w.Code, w.Body)
+	}
+
+	if w := handlertest.Request(t, router, adminID, http.MethodPost, "/admin/users/999/disable", ""); w.Code != http.StatusNotFound {
+		t.Errorf("disabling an unknown user: got %d, want 404", w.Code)
+	}
+}
+
+func TestUnlockLogin(t *testing.T) {
+	store := memory.NewStore()
+	guard := newTestLoginGuard()
+	router := newTestRouterWithGuard(store, guard)
+	adminID := newAdmin(t, store)
+	userID := repotest.CreateUser(t, store)
+	user, err := store.GetUserByID(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		attempt, _, err := guard.Begin(ctx, user.Email, "192.0.2.1")
+		if err != nil || attempt == nil {
+			t.Fatalf("Begin = %v, %v", attempt, err)
+		}
+		if _, err := guard.RecordFailure(ctx, attempt, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if wait, err := guard.Check(ctx, user.Email, "192.0.2.2"); err != nil || wait == 0 {
+		t.Fatalf("account not locked after failed logins: %v, %v", wait, err)
+	}
+
+	unlockPath := "/admin/users/" + strconv.Itoa(userID) + "/unlock-login"
+	if w := handlertest.Request(t, router, userID, http.MethodPost, unlockPath, ""); w.Code != http.StatusForbidden {
+		t.Errorf("unlock as a user: got %d, want 403", w.Code)
+	}
+	if w := handlertest.Request(t, router, adminID, http.MethodPost, unlockPath, ""); w.Code != http.StatusOK {
+		t.Fatalf("unlock: got %d: %s", w.Code, w.Body)
+	}
+	if wait, err := guard.Check(ctx, user.Email, "192.0.2.2"); err != nil || wait != 0 {
+		t.Errorf("account still locked after unlocking: %v, %v", wait, err)
+	}
+
+	if w := handlertest.Request(t, router, adminID, http.MethodPost, "/admin/users/999/unlock-login", ""); w.Code != http.StatusNotFound {
+		t.Errorf("unlocking an unknown user: got %d, want 404", w.Code)
+	}
+}
+
+func TestTakeDownLink(t *testing.T) {
+	store := memory.NewStore()
+	router := newTestRouter(store)
+	adminID := newAdmin(t, store)
+	link := repotest.CreateLink(t, store, repotest.CreateUser(t, store), nil)
+	takedownPath := "/admin/links/" + link.Slug + "/takedown"
+
+	if w := handlertest.Request(t, router, adminID, http.MethodPost, takedownPath, `{"reason":"  "}`); w.Code != http.StatusBadRequest {
+		t.Errorf("takedown without a reason: got %d, want 400", w.Code)
+	}
+	w := handlertest.Request(t, router, adminID, http.MethodPost, takedownPath, `{"reason":"phishing"}`)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"takedown_reason":"phishing"`) {
+		t.Fatalf("takedown: got %d: %s", w.Code, w.Body)
+	}
+
+	w = handlertest.Request(t, router, adminID, http.MethodGet, "/admin/links?q="+link.Slug, "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"total":1`) || !strings.Contains(w.Body.String(), "taken_down_at") {
+		t.Errorf("search links: got %d: %s", w.Code, w.Body)
+	}
+
+	w = handlertest.Request(t, router, adminID, http.MethodGet, "/admin/stats", "")
+	var stats struct {
+		Stats models.InstanceStats `json:"stats"`
+	}
+	handlertest.Decode(t, w, &stats)
+	if stats.Stats.Users != 2 || stats.Stats.AdminUsers != 1 || stats.Stats.Links != 1 || stats.Stats.TakenDownLinks != 1 {
+		t.Errorf("stats = %+v", stats.Stats)
+	}
+
+	if w := handlertest.Request(t, router, adminID, http.MethodDelete, takedownPath, ""); w.Code != http.StatusOK || strings.Contains(w.Body.String(), "taken_down_at") {
+		t.Errorf("restore: got %d: %s", w.Code, w.Body)
+	}
+	if w := handlertest.Request(t, router, adminID, http.MethodDelete, "/admin/links/missing/takedown", ""); w.Code != http.StatusNotFound {
+		t.Errorf("restoring an unknown link: got %d, want 404", w.Code)
+	}
+}
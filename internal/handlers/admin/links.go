@@ -0,0 +1,130 @@
+package admin
+
+import (
+	"errors"
+	"link-guardian/internal/handlers/middleware"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"link-guardian/internal/repositories/redis"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+var adminValidator = validator.New()
+
+// ListLinksHandler lists links across all workspaces, newest first,
+// optionally filtered by the q query parameter against slug and target URL
+func ListLinksHandler(c *gin.Context) {
+	repo, ok := adminRepositoryFrom(c)
+	if !ok {
+		return
+	}
+
+	limit, offset := parsePage(c)
+	links, total, err := repo.SearchLinks(strings.TrimSpace(c.Query("q")), limit, offset)
+	if err != nil {
+		log.Printf("Failed to search links: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch links"})
+		return
+	}
+
+	responses := make([]models.LinkResponse, 0, len(links))
+	for _, link := range links {
+		responses = append(responses, link.ToResponse())
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"links":  responses,
+		"count":  len(responses),
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// TakeDownLinkHandler stops a link from redirecting. Visitors get 410 Gone;
+// the owner keeps the link and sees the reason.
+func TakeDownLinkHandler(c *gin.Context) {
+	var req models.TakedownLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request or payload"})
+		return
+	}
+
+	req.Reason = strings.TrimSpace(req.Reason)
+	if err := adminValidator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+		return
+	}
+
+	adminID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	repo, ok := adminRepositoryFrom(c)
+	if !ok {
+		return
+	}
+
+	slug := c.Param("slug")
+	link, err := repo.TakeDownLink(middleware.LinkDomainFrom(c).ID, slug, adminID, req.Reason)
+	if !respondModeratedLink(c, slug, link, err) {
+		return
+	}
+
+	log.Printf("Link %s taken down by admin ID %d: %s", slug, adminID, req.Reason)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Link taken down successfully",
+		"link":    link.ToResponse(),
+	})
+}
+
+// RestoreLinkHandler lifts a takedown so the link redirects again
+func RestoreLinkHandler(c *gin.Context) {
+	adminID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	repo, ok := adminRepositoryFrom(c)
+	if !ok {
+		return
+	}
+
+	slug := c.Param("slug")
+	link, err := repo.RestoreLink(middleware.LinkDomainFrom(c).ID, slug)
+	if !respondModeratedLink(c, slug, link, err) {
+		return
+	}
+
+	log.Printf("Link %s restored by admin ID %d", slug, adminID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Link restored successfully",
+		"link":    link.ToResponse(),
+	})
+}
+
+// respondModeratedLink handles the result of a takedown change, dropping the
+// link from the slug cache when it succeeded. It reports false after
+// responding with an error.
+func respondModeratedLink(c *gin.Context, slug string, link models.Link, err error) bool {
+	if errors.Is(err, repositories.ErrLinkNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Link not found"})
+		return false
+	}
+	if err != nil {
+		log.Printf("Failed to moderate link %s: %v", slug, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update link"})
+		return false
+	}
+
+	if err := redis.InvalidateLink(c.Request.Context(), int(link.DomainID.Int32), link.Slug); err != nil {
+		log.Printf("Failed to invalidate cached link %s: %v", link.Slug, err)
+	}
+	return true
+}
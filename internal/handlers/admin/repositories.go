@@ -0,0 +1,53 @@
+package admin
+
+import (
+	"link-guardian/internal/repositories"
+	"link-guardian/internal/services/auth"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// adminRepositoryFrom reads the admin repository injected by RepositoriesMiddleware
+func adminRepositoryFrom(c *gin.Context) (repositories.AdminRepository, bool) {
+	repo, exists := c.Get("adminRepository")
+	if !exists {
+		log.Printf("Admin repository not found in context for request from IP %s", c.ClientIP())
+		respondServiceUnavailable(c)
+		return nil, false
+	}
+	return repo.(repositories.AdminRepository), true
+}
+
+// authServiceFrom reads the auth service injected by AuthServiceMiddleware
+func authServiceFrom(c *gin.Context) (*auth.AuthService, bool) {
+	service, exists := c.Get("authService")
+	if !exists {
+		log.Printf("Auth service not found in context for request from IP %s", c.ClientIP())
+		respondServiceUnavailable(c)
+		return nil, false
+	}
+	return service.(*auth.AuthService), true
+}
+
+// parsePage reads the limit and offset query parameters. The limit defaults
+// to 50 and caps at 200; invalid values fall back to the defaults.
+func parsePage(c *gin.Context) (limit, offset int) {
+	limit = 50
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 200 {
+		limit = l
+	}
+	if o, err := strconv.Atoi(c.Query("offset")); err == nil && o > 0 {
+		offset = o
+	}
+	return limit, offset
+}
+
+func respondServiceUnavailable(c *gin.Context) {
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error":   "Service unavailable",
+		"message": "Please try again later",
+	})
+}
@@ -0,0 +1,25 @@
+package admin
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InstanceStatsHandler reports instance-wide user, workspace, link and click totals
+func InstanceStatsHandler(c *gin.Context) {
+	repo, ok := adminRepositoryFrom(c)
+	if !ok {
+		return
+	}
+
+	stats, err := repo.GetInstanceStats()
+	if err != nil {
+		log.Printf("Failed to count instance stats: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch stats"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"stats": stats})
+}
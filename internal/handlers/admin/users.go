@@ -0,0 +1,175 @@
+// Package admin serves the /admin endpoints instance administrators use to
+// moderate users and links. Every route is served behind RequireAdmin.
+package admin
+
+import (
+	"errors"
+	"link-guardian/internal/handlers/middleware"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"link-guardian/internal/repositories/redis"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListUsersHandler lists users, newest first, optionally filtered by the q
+// query parameter against username and email
+func ListUsersHandler(c *gin.Context) {
+	repo, ok := adminRepositoryFrom(c)
+	if !ok {
+		return
+	}
+
+	limit, offset := parsePage(c)
+	users, total, err := repo.SearchUsers(strings.TrimSpace(c.Query("q")), limit, offset)
+	if err != nil {
+		log.Printf("Failed to search users: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		return
+	}
+
+	responses := make([]models.AdminUserResponse, 0, len(users))
+	for _, user := range users {
+		responses = append(responses, user.ToAdminResponse())
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"users":  responses,
+		"count":  len(responses),
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// DisableUserHandler disables an account. The user's sessions end at once:
+// their refresh tokens are revoked along with the account change and their
+// access tokens are denied. They cannot log in or use API keys until the
+// account is enabled again. A 500 after the account change means the access
+// tokens were not denied, and disabling again retries.
+func DisableUserHandler(c *gin.Context) {
+	setUserDisabled(c, true)
+}
+
+// EnableUserHandler re-enables a disabled account
+func EnableUserHandler(c *gin.Context) {
+	setUserDisabled(c, false)
+}
+
+func setUserDisabled(c *gin.Context, disabled bool) {
+	targetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	adminID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+	if disabled && targetID == adminID {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Cannot disable your own account",
+			"message": "Ask another administrator to disable it",
+		})
+		return
+	}
+
+	repo, ok := adminRepositoryFrom(c)
+	if !ok {
+		return
+	}
+	authService, ok := authServiceFrom(c)
+	if !ok {
+		return
+	}
+
+	// Disabling revokes the refresh tokens in the same repository operation
+	if err := repo.SetUserDisabled(targetID, disabled); err != nil {
+		if errors.Is(err, repositories.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		log.Printf("Failed to update user ID %d by admin ID %d: %v", targetID, adminID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+		return
+	}
+
+	if !disabled {
+		log.Printf("User ID %d enabled by admin ID %d", targetID, adminID)
+		c.JSON(http.StatusOK, gin.H{
+			"message": "User enabled successfully",
+			"user_id": targetID,
+		})
+		return
+	}
+
+	// Access tokens issued so far must not work again once the account is
+	// enabled. Disabling again is safe, so the admin can retry a failure.
+	if err := redis.DenyUserAccessTokens(c.Request.Context(), targetID, time.Now(), authService.AccessTokenTTL()); err != nil {
+		log.Printf("Failed to revoke access tokens of disabled user ID %d by admin ID %d: %v", targetID, adminID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to revoke access tokens",
+			"message": "The user is disabled but their access tokens still work, please try again",
+		})
+		return
+	}
+
+	log.Printf("User ID %d disabled by admin ID %d", targetID, adminID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User disabled successfully",
+		"user_id": targetID,
+	})
+}
+
+// UnlockLoginHandler lifts a lockout after failed logins to an account and
+// forgets its failures, like the unlock-login command
+func UnlockLoginHandler(c *gin.Context) {
+	targetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	adminID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	users, ok := middleware.UserRepositoryFrom(c)
+	if !ok {
+		return
+	}
+	guard, ok := middleware.LoginGuardFrom(c)
+	if !ok {
+		return
+	}
+
+	user, err := users.GetUserByID(targetID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		log.Printf("Failed to get user ID %d for admin ID %d: %v", targetID, adminID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock logins"})
+		return
+	}
+
+	if err := guard.Unlock(c.Request.Context(), user.Email); err != nil {
+		log.Printf("Failed to unlock logins of user ID %d by admin ID %d: %v", targetID, adminID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock logins"})
+		return
+	}
+
+	log.Printf("Logins of user ID %d unlocked by admin ID %d", targetID, adminID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Logins unlocked successfully",
+		"user_id": targetID,
+	})
+}
@@ -0,0 +1,162 @@
+// Package apikeys serves the endpoints users manage their personal API keys with
+package apikeys
+
+import (
+	"errors"
+	"link-guardian/internal/handlers/middleware"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	authService "link-guardian/internal/services/auth"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+var apiKeyValidator = validator.New()
+
+// CreateAPIKeyHandler creates a named API key with the requested scopes. The
+// key is only ever returned in this response.
+func CreateAPIKeyHandler(c *gin.Context) {
+	var req models.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request or payload"})
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if err := apiKeyValidator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+		return
+	}
+
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	authSvc, exists := c.Get("authService")
+	if !exists {
+		log.Printf("Auth service not found in context for API key creation from IP %s", c.ClientIP())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Service unavailable",
+			"message": "Please try again later",
+		})
+		return
+	}
+
+	repo, ok := apiKeyRepositoryFrom(c)
+	if !ok {
+		return
+	}
+
+	key, prefix, hash, err := authSvc.(*authService.AuthService).GenerateAPIKey()
+	if err != nil {
+		log.Printf("API key generation failed for user ID %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key"})
+		return
+	}
+
+	apiKey, err := repo.CreateAPIKey(models.APIKey{
+		UserID:  userID,
+		Name:    req.Name,
+		Prefix:  prefix,
+		KeyHash: hash,
+		Scopes:  uniqueScopes(req.Scopes),
+	})
+	if err != nil {
+		log.Printf("API key creation failed for user ID %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key"})
+		return
+	}
+
+	log.Printf("API key %d (%s) created for user ID %d with scopes %v", apiKey.ID, apiKey.Prefix, userID, apiKey.Scopes)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "API key created; store it now, it will not be shown again",
+		"api_key": apiKey.ToResponse(),
+		"key":     key,
+	})
+}
+
+// ListAPIKeysHandler lists the caller's active API keys without their secrets
+func ListAPIKeysHandler(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	repo, ok := apiKeyRepositoryFrom(c)
+	if !ok {
+		return
+	}
+
+	keys, err := repo.ListAPIKeysByUser(userID)
+	if err != nil {
+		log.Printf("Failed to list API keys for user ID %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch API keys"})
+		return
+	}
+
+	responses := make([]models.APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		responses = append(responses, key.ToResponse())
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"api_keys": responses,
+		"count":    len(responses),
+	})
+}
+
+// RevokeAPIKeyHandler revokes one of the caller's API keys
+func RevokeAPIKeyHandler(c *gin.Context) {
+	keyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid API key ID"})
+		return
+	}
+
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	repo, ok := apiKeyRepositoryFrom(c)
+	if !ok {
+		return
+	}
+
+	if err := repo.RevokeAPIKey(keyID, userID); err != nil {
+		if errors.Is(err, repositories.ErrAPIKeyNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "API key not found or already revoked"})
+			return
+		}
+		log.Printf("Failed to revoke API key %d for user ID %d: %v", keyID, userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke API key"})
+		return
+	}
+
+	log.Printf("API key %d revoked by user ID %d", keyID, userID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "API key revoked successfully",
+		"id":      keyID,
+	})
+}
+
+// uniqueScopes drops repeated scopes, keeping the first occurrence
+func uniqueScopes(scopes []string) []string {
+	seen := map[string]bool{}
+	unique := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !seen[scope] {
+			seen[scope] = true
+			unique = append(unique, scope)
+		}
+	}
+	return unique
+}
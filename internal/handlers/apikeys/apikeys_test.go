@@ -0,0 +1,122 @@
+package apikeys
+
+import (
+	"link-guardian/internal/handlers/middleware"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories/memory"
+	"link-guardian/internal/repositories/repotest"
+	"link-guardian/internal/services/auth"
+	"link-guardian/internal/testutil/handlertest"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newTestRouter serves the API key routes and one route per scope behind the
+// same authentication middleware as the server
+func newTestRouter(store *memory.Store) *gin.Engine {
+	router := handlertest.NewRouter(store)
+
+	whoami := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user_id": c.MustGet("user_id")})
+	}
+
+	protected := router.Group("")
+	protected.Use(middleware.JWTAuthMiddleware())
+	protected.POST("/api-keys", middleware.RequireSession(), CreateAPIKeyHandler)
+	protected.GET("/api-keys", middleware.RequireSession(), ListAPIKeysHandler)
+	protected.DELETE("/api-keys/:id", middleware.RequireSession(), RevokeAPIKeyHandler)
+	protected.GET("/read", middleware.RequireScope(models.ScopeLinksRead), whoami)
+	protected.GET("/write", middleware.RequireScope(models.ScopeLinksWrite), whoami)
+	return router
+}
+
+// request sends a request authenticated with credential, a JWT or an API key
+func request(router http.Handler, credential, method, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+credential)
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestAPIKeyLifecycleAndScopes(t *testing.T) {
+	store := memory.NewStore()
+	userID := repotest.CreateUser(t, store)
+	router := newTestRouter(store)
+	session := handlertest.Token(t, userID)
+
+	w := request(router, session, http.MethodPost, "/api-keys", `{"name":"CI","scopes":["links:read","links:read"]}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: got %d: %s", w.Code, w.Body)
+	}
+	var created struct {
+		Key    string                `json:"key"`
+		APIKey models.APIKeyResponse `json:"api_key"`
+	}
+	handlertest.Decode(t, w, &created)
+	if !strings.HasPrefix(created.Key, auth.APIKeyPrefix) || !strings.HasPrefix(created.Key, created.APIKey.Prefix) {
+		t.Errorf("key %q does not start with %q and its prefix %q", created.Key, auth.APIKeyPrefix, created.APIKey.Prefix)
+	}
+	if len(created.APIKey.Scopes) != 1 || created.APIKey.Scopes[0] != models.ScopeLinksRead {
+		t.Errorf("scopes = %v, want [links:read]", created.APIKey.Scopes)
+	}
+
+	// The key authenticates as its owner on routes within its scopes only
+	w = request(router, created.Key, http.MethodGet, "/read", "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"user_id":`+strconv.Itoa(userID)) {
+		t.Errorf("read with key: got %d: %s", w.Code, w.Body)
+	}
+	if w := request(router, created.Key, http.MethodGet, "/write", ""); w.Code != http.StatusForbidden {
+		t.Errorf("write with read-only key: got %d, want 403", w.Code)
+	}
+	if w := request(router, session, http.MethodGet, "/write", ""); w.Code != http.StatusOK {
+		t.Errorf("write with session: got %d, want 200", w.Code)
+	}
+
+	// Keys cannot manage keys
+	if w := request(router, created.Key, http.MethodPost, "/api-keys", `{"name":"x","scopes":["links:write"]}`); w.Code != http.StatusForbidden {
+		t.Errorf("create with key: got %d, want 403", w.Code)
+	}
+
+	w = request(router, session, http.MethodGet, "/api-keys", "")
+	var list struct {
+		APIKeys []models.APIKeyResponse `json:"api_keys"`
+	}
+	handlertest.Decode(t, w, &list)
+	if len(list.APIKeys) != 1 || list.APIKeys[0].LastUsedAt == nil || strings.Contains(w.Body.String(), created.Key) {
+		t.Errorf("list: got %s, want one used key without its secret", w.Body)
+	}
+
+	path := "/api-keys/" + strconv.FormatInt(created.APIKey.ID, 10)
+	if w := request(router, handlertest.Token(t, repotest.CreateUser(t, store)), http.MethodDelete, path, ""); w.Code != http.StatusNotFound {
+		t.Errorf("revoke another user's key: got %d, want 404", w.Code)
+	}
+	if w := request(router, session, http.MethodDelete, path, ""); w.Code != http.StatusOK {
+		t.Fatalf("revoke: got %d: %s", w.Code, w.Body)
+	}
+	if w := request(router, created.Key, http.MethodGet, "/read", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("revoked key: got %d, want 401", w.Code)
+	}
+}
+
+func TestCreateAPIKeyValidatesScopes(t *testing.T) {
+	store := memory.NewStore()
+	router := newTestRouter(store)
+	session := handlertest.Token(t, repotest.CreateUser(t, store))
+
+	for name, body := range map[string]string{
+		"unknown scope": `{"name":"CI","scopes":["admin"]}`,
+		"no scopes":     `{"name":"CI","scopes":[]}`,
+		"blank name":    `{"name":"  ","scopes":["links:read"]}`,
+	} {
+		if w := request(router, session, http.MethodPost, "/api-keys", body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: got %d, want 400", name, w.Code)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package apikeys
+
+import (
+	"link-guardian/internal/repositories"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiKeyRepositoryFrom reads the API key repository injected by RepositoriesMiddleware
+func apiKeyRepositoryFrom(c *gin.Context) (repositories.APIKeyRepository, bool) {
+	repo, exists := c.Get("apiKeyRepository")
+	if !exists {
+		log.Printf("API key repository not found in context for request from IP %s", c.ClientIP())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Service unavailable",
+			"message": "Please try again later",
+		})
+		return nil, false
+	}
+	return repo.(repositories.APIKeyRepository), true
+}
@@ -0,0 +1,245 @@
+package auth
+
+import (
+	"encoding/json"
+	"link-guardian/internal/handlers/middleware"
+	"link-guardian/internal/repositories/memory"
+	authService "link-guardian/internal/services/auth"
+	"link-guardian/internal/services/loginguard"
+	"link-guardian/internal/services/mailer"
+	"link-guardian/internal/services/passwordreset"
+	"link-guardian/internal/services/qrcode"
+	"link-guardian/internal/services/twofactor"
+	"link-guardian/internal/services/verification"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newTestRouter(store *memory.Store) *gin.Engine {
+	return newTestRouterWithMailer(store, mailer.LogMailer{})
+}
+
+func newTestRouterWithMailer(store *memory.Store, mail mailer.Mailer) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	svc := authService.NewAuthService("test-secret", 15*time.Minute, time.Hour)
+	router.Use(middleware.AuthServiceMiddleware(svc))
+	router.Use(middleware.RepositoriesMiddleware(store))
+	emailVerification := middleware.EmailVerificationMiddleware(verification.NewService(svc, mail, verification.Options{
+		TokenTTL:  time.Hour,
+		VerifyURL: "http://app.test/verify-email",
+	}))
+	router.POST("/signup", emailVerification, SignupHandler)
+	loginGuard := middleware.LoginGuardMiddleware(loginguard.New(loginguard.NewMemoryStore(), loginguard.Options{
+		MaxAttempts:   3,
+		Lockout:       time.Minute,
+		FreeAttempts:  3,
+		IPMaxAttempts: 100,
+		IPWindow:      time.Minute,
+	}, nil))
+	twoFactor := middleware.TwoFactorMiddleware(twofactor.NewService(svc, twofactor.Options{
+		Issuer:       "Link Guardian",
+		ChallengeTTL: time.Minute,
+	}))
+	router.POST("/login", loginGuard, twoFactor, LoginHandler)
+	router.POST("/login/2fa", loginGuard, twoFactor, TwoFactorLoginHandler)
+	router.POST("/auth/refresh", RefreshHandler)
+	router.POST("/auth/logout", middleware.JWTAuthMiddleware(), LogoutHandler)
+
+	passwordReset := middleware.PasswordResetMiddleware(passwordreset.NewService(svc, mail, passwordreset.Options{
+		TokenTTL: time.Hour,
+		ResetURL: "http://app.test/reset-password",
+	}))
+	router.POST("/auth/forgot", passwordReset, ForgotPasswordHandler)
+	router.POST("/auth/reset", passwordReset, ResetPasswordHandler)
+	router.POST("/auth/verify", emailVerification, VerifyEmailHandler)
+	router.POST("/auth/verify/resend", middleware.JWTAuthMiddleware(), emailVerification, ResendVerificationHandler)
+
+	qrGenerator, err := qrcode.NewGenerator(nil)
+	if err != nil {
+		panic(err)
+	}
+	twoFactorRoutes := router.Group("/auth/2fa", middleware.JWTAuthMiddleware(), middleware.QRGeneratorMiddleware(qrGenerator), twoFactor)
+	twoFactorRoutes.GET("", TwoFactorStatusHandler)
+	twoFactorRoutes.POST("/setup", SetupTwoFactorHandler)
+	twoFactorRoutes.POST("/enable", EnableTwoFactorHandler)
+	twoFactorRoutes.POST("/disable", loginGuard, DisableTwoFactorHandler)
+	return router
+}
+
+func post(router http.Handler, path, body string) *httptest.ResponseRecorder {
+	return postWithToken(router, path, "", body)
+}
+
+func postWithToken(router http.Handler, path, token, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// tokenResponse is the token part of the login, signup and refresh responses
+type tokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+func decodeTokens(t *testing.T, w *httptest.ResponseRecorder) tokenResponse {
+	t.Helper()
+	var tokens tokenResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &tokens); err != nil {
+		t.Fatalf("invalid JSON response %q: %v", w.Body.String(), err)
+	}
+	if tokens.Token == "" || tokens.RefreshToken == "" {
+		t.Fatalf("response %s lacks tokens", w.Body)
+	}
+	return tokens
+}
+
+func refreshBody(refreshToken string) string {
+	return `{"refresh_token":"` + refreshToken + `"}`
+}
+
+func TestSignupAndLogin(t *testing.T) {
+	store := memory.NewStore()
+	router := newTestRouter(store)
+
+	w := post(router, "/signup", `{"username":"alice","email":"Alice@Example.com","password":"Secret123!"}`)
+	if w.Code != http.StatusCreated || !strings.Contains(w.Body.String(), `"token"`) {
+		t.Fatalf("signup: got %d: %s", w.Code, w.Body)
+	}
+
+	user, err := store.GetUserByEmail("alice@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Email != "alice@example.com" || user.Password == "Secret123!" {
+		t.Errorf("stored user = %+v, want a lowercased email and a hashed password", user)
+	}
+
+	conflicts := map[string]string{
+		"email":    `{"username":"bob","email":"alice@example.com","password":"Secret123!"}`,
+		"username": `{"username":"alice","email":"other@example.com","password":"Secret123!"}`,
+	}
+	for name, body := range conflicts {
+		if w := post(router, "/signup", body); w.Code != http.StatusConflict {
+			t.Errorf("duplicate %s: got %d, want 409", name, w.Code)
+		}
+	}
+
+	if w := post(router, "/login", `{"email":"ALICE@example.com","password":"Secret123!"}`); w.Code != http.StatusOK {
+		t.Errorf("login: got %d: %s", w.Code, w.Body)
+	}
+	if w := post(router, "/login", `{"email":"alice@example.com","password":"wrong-password"}`); w.Code != http.StatusUnauthorized {
+		t.Errorf("wrong password: got %d, want 401", w.Code)
+	}
+	if w := post(router, "/login", `{"email":"nobody@example.com","password":"Secret123!"}`); w.Code != http.StatusUnauthorized {
+		t.Errorf("unknown email: got %d, want 401", w.Code)
+	}
+}
+
+func TestRefreshRotatesTokensAndDetectsReuse(t *testing.T) {
+	router := newTestRouter(memory.NewStore())
+
+	w := post(router, "/signup", `{"username":"carol","email":"carol@example.com","password":"Secret123!"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("signup: got %d: %s", w.Code, w.Body)
+	}
+	first := decodeTokens(t, w)
+	if first.ExpiresIn <= 0 || first.ExpiresIn > 15*60 {
+		t.Errorf("expires_in = %d, want at most the 15 minute access token lifetime", first.ExpiresIn)
+	}
+
+	w = post(router, "/auth/refresh", refreshBody(first.RefreshToken))
+	if w.Code != http.StatusOK {
+		t.Fatalf("refresh: got %d: %s", w.Code, w.Body)
+	}
+	second := decodeTokens(t, w)
+	if second.RefreshToken == first.RefreshToken {
+		t.Error("refresh did not rotate the refresh token")
+	}
+
+	// Replaying the first token revokes the whole session, including the
+	// refresh token that replaced it
+	if w := post(router, "/auth/refresh", refreshBody(first.RefreshToken)); w.Code != http.StatusUnauthorized {
+		t.Errorf("reused refresh token: got %d, want 401", w.Code)
+	}
+	if w := post(router, "/auth/refresh", refreshBody(second.RefreshToken)); w.Code != http.StatusUnauthorized {
+		t.Errorf("refresh token of revoked session: got %d, want 401", w.Code)
+	}
+
+	if w := post(router, "/auth/refresh", refreshBody("unknown")); w.Code != http.StatusUnauthorized {
+		t.Errorf("unknown refresh token: got %d, want 401", w.Code)
+	}
+	if w := post(router, "/auth/refresh", `{}`); w.Code != http.StatusBadRequest {
+		t.Errorf("missing refresh token: got %d, want 400", w.Code)
+	}
+}
+
+func TestLogoutRevokesRefreshToken(t *testing.T) {
+	router := newTestRouter(memory.NewStore())
+
+	w := post(router, "/signup", `{"username":"dave","email":"dave@example.com","password":"Secret123!"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("signup: got %d: %s", w.Code, w.Body)
+	}
+	session := decodeTokens(t, w)
+
+	w = post(router, "/login", `{"email":"dave@example.com","password":"Secret123!"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("login: got %d: %s", w.Code, w.Body)
+	}
+	otherSession := decodeTokens(t, w)
+
+	if w := post(router, "/auth/logout", refreshBody(session.RefreshToken)); w.Code != http.StatusUnauthorized {
+		t.Errorf("logout without access token: got %d, want 401", w.Code)
+	}
+	if w := postWithToken(router, "/auth/logout", session.Token, refreshBody(session.RefreshToken)); w.Code != http.StatusOK {
+		t.Fatalf("logout: got %d: %s", w.Code, w.Body)
+	}
+
+	if w := post(router, "/auth/refresh", refreshBody(session.RefreshToken)); w.Code != http.StatusUnauthorized {
+		t.Errorf("refresh after logout: got %d, want 401", w.Code)
+	}
+	if w := post(router, "/auth/refresh", refreshBody(otherSession.RefreshToken)); w.Code != http.StatusOK {
+		t.Errorf("logout revoked another session: got %d: %s", w.Code, w.Body)
+	}
+}
+
+func TestLoginLockoutDoesNotRevealAccounts(t *testing.T) {
+	router := newTestRouter(memory.NewStore())
+
+	if w := post(router, "/signup", `{"username":"erin","email":"erin@example.com","password":"Secret123!"}`); w.Code != http.StatusCreated {
+		t.Fatalf("signup: got %d: %s", w.Code, w.Body)
+	}
+
+	// The same number of failures locks a registered and an unknown email alike
+	locked := map[string]string{}
+	for _, email := range []string{"erin@example.com", "nobody@example.com"} {
+		for i := 0; i < 3; i++ {
+			if w := post(router, "/login", `{"email":"`+email+`","password":"wrong-password"}`); w.Code != http.StatusUnauthorized {
+				t.Fatalf("%s failure %d: got %d, want 401", email, i+1, w.Code)
+			}
+		}
+
+		w := post(router, "/login", `{"email":"`+email+`","password":"Secret123!"}`)
+		if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+			t.Errorf("%s after lockout: got %d with Retry-After %q, want 429", email, w.Code, w.Header().Get("Retry-After"))
+		}
+		locked[email] = w.Body.String()
+	}
+
+	if locked["erin@example.com"] != locked["nobody@example.com"] {
+		t.Errorf("lockout responses differ:\n%s\n%s", locked["erin@example.com"], locked["nobody@example.com"])
+	}
+}
@@ -1,27 +1,35 @@
 package auth
 
 import (
+	"errors"
+	"link-guardian/internal/handlers/middleware"
 	"link-guardian/internal/models"
-	dbRepo "link-guardian/internal/repositories/db"
+	"link-guardian/internal/repositories"
 	authService "link-guardian/internal/services/auth"
+	"link-guardian/internal/services/loginguard"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
+	"golang.org/x/crypto/bcrypt"
 )
 
 var loginValidator = validator.New()
 
-// LoginAttempt represents a login attempt for rate limiting
-type LoginAttempt struct {
-	IP        string
-	Email     string
-	Timestamp time.Time
-	Success   bool
-}
+// dummyPasswordHash is compared against when the email has no account, so
+// unknown and known emails take the same time to reject
+var dummyPasswordHash = sync.OnceValue(func() string {
+	hash, err := bcrypt.GenerateFromPassword([]byte("link-guardian-no-such-user"), bcrypt.DefaultCost)
+	if err != nil {
+		panic(err)
+	}
+	return string(hash)
+})
 
 // LoginHandler handles user authentication with security measures
 func LoginHandler(c *gin.Context) {
@@ -63,10 +71,44 @@ func LoginHandler(c *gin.Context) {
 	req.Email = strings.TrimSpace(strings.ToLower(req.Email))
 	req.Password = strings.TrimSpace(req.Password)
 
-	// Get user by email
-	user, err := dbRepo.GetUserByEmail(req.Email)
+	repo, ok := middleware.UserRepositoryFrom(c)
+	if !ok {
+		return
+	}
+	tokens, ok := refreshTokenRepositoryFrom(c)
+	if !ok {
+		return
+	}
+	guard, ok := middleware.LoginGuardFrom(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	ip := c.ClientIP()
+
+	// Reserve the attempt, or refuse it while the email or IP is delayed or locked out
+	attempt, wait, err := guard.Begin(ctx, req.Email, ip)
 	if err != nil {
-		log.Printf("Login attempt with non-existent email %s from IP %s", req.Email, c.ClientIP())
+		log.Printf("Login attempt check failed from IP %s: %v", ip, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Authentication failed",
+			"message": "Please try again later",
+		})
+		return
+	}
+	if wait > 0 {
+		log.Printf("Throttled login attempt for %s from IP %s", req.Email, ip)
+		respondTooManyAttempts(c, wait)
+		return
+	}
+
+	// Get user by email
+	user, err := repo.GetUserByEmail(req.Email)
+	if errors.Is(err, repositories.ErrUserNotFound) {
+		log.Printf("Login attempt with non-existent email %s from IP %s", req.Email, ip)
+		authService.VerifyPassword(req.Password, dummyPasswordHash())
+		recordLoginFailure(c, guard, attempt, nil)
 		// Generic error message to prevent user enumeration
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"error":   "Authentication failed",
@@ -74,10 +116,20 @@ func LoginHandler(c *gin.Context) {
 		})
 		return
 	}
+	if err != nil {
+		log.Printf("User lookup failed for login from IP %s: %v", c.ClientIP(), err)
+		releaseLoginAttempt(c, guard, attempt)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Authentication failed",
+			"message": "Please try again later",
+		})
+		return
+	}
 
 	// Verify password
 	if err := authService.VerifyPassword(req.Password, user.Password); err != nil {
 		log.Printf("Failed login attempt for user %s from IP %s: invalid password", req.Email, c.ClientIP())
+		recordLoginFailure(c, guard, attempt, user)
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"error":   "Authentication failed",
 			"message": "Invalid email or password",
@@ -85,8 +137,28 @@ func LoginHandler(c *gin.Context) {
 		return
 	}
 
-	// Generate JWT token
-	tokenString, err := authService.GenerateJWTToken(user.ID, user.Username)
+	// Disabled accounts are only told so once they prove the password
+	if user.DisabledAt.Valid {
+		log.Printf("Login attempt for disabled user %s (ID: %d) from IP %s", user.Username, user.ID, c.ClientIP())
+		releaseLoginAttempt(c, guard, attempt)
+		respondAccountDisabled(c)
+		return
+	}
+
+	// With two-factor authentication the password only earns a challenge.
+	// Earlier failures are not reset yet, so codes cannot be guessed indefinitely.
+	if user.TwoFactorEnabled {
+		releaseLoginAttempt(c, guard, attempt)
+		respondTwoFactorChallenge(c, user)
+		return
+	}
+
+	if err := guard.RecordSuccess(ctx, attempt); err != nil {
+		log.Printf("Failed to reset login failures for %s: %v", req.Email, err)
+	}
+
+	// Issue an access token and a refresh token for a new session
+	session, err := startSession(authService, tokens, user.ID, user.Username)
 	if err != nil {
 		log.Printf("Token generation failed for user %s (ID: %d) from IP %s: %v", user.Username, user.ID, c.ClientIP(), err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -100,13 +172,77 @@ func LoginHandler(c *gin.Context) {
 	log.Printf("User logged in successfully: %s (ID: %d) from IP %s", user.Username, user.ID, c.ClientIP())
 
 	// Return success response
-	c.JSON(http.StatusOK, gin.H{
+	session.respond(c, http.StatusOK, gin.H{
 		"message": "Login successful",
 		"user": gin.H{
-			"id":       user.ID,
-			"username": user.Username,
-			"email":    user.Email,
+			"id":                 user.ID,
+			"username":           user.Username,
+			"email":              user.Email,
+			"email_verified":     user.EmailVerified,
+			"two_factor_enabled": user.TwoFactorEnabled,
+			"role":               user.Role,
 		},
-		"token": tokenString,
+	})
+}
+
+// respondTwoFactorChallenge answers a correct password of a user with
+// two-factor authentication enabled with the token for POST /login/2fa
+func respondTwoFactorChallenge(c *gin.Context, user *models.User) {
+	twoFactorService, ok := twoFactorFrom(c)
+	if !ok {
+		return
+	}
+
+	challenge, err := twoFactorService.Challenge(user.ID)
+	if err != nil {
+		log.Printf("Two-factor challenge generation failed for user ID %d from IP %s: %v", user.ID, c.ClientIP(), err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Authentication failed",
+			"message": "Please try again later",
+		})
+		return
+	}
+
+	log.Printf("Password accepted for user %s (ID: %d) from IP %s; awaiting second factor", user.Username, user.ID, c.ClientIP())
+	c.JSON(http.StatusOK, gin.H{
+		"message":             "Enter the code from your authenticator app",
+		"two_factor_required": true,
+		"challenge_token":     challenge,
+		"expires_in":          int(twoFactorService.ChallengeTTL().Seconds()),
+	})
+}
+
+// recordLoginFailure records that the credential of attempt was wrong; user
+// is nil when the email has no account
+func recordLoginFailure(c *gin.Context, guard *loginguard.Guard, attempt *loginguard.Attempt, user *models.User) {
+	if _, err := guard.RecordFailure(c.Request.Context(), attempt, user); err != nil {
+		log.Printf("Failed to record login failure from IP %s: %v", c.ClientIP(), err)
+	}
+}
+
+// releaseLoginAttempt stops counting an attempt that ended without a wrong credential
+func releaseLoginAttempt(c *gin.Context, guard *loginguard.Guard, attempt *loginguard.Attempt) {
+	if err := guard.Release(c.Request.Context(), attempt); err != nil {
+		log.Printf("Failed to release login attempt from IP %s: %v", c.ClientIP(), err)
+	}
+}
+
+// respondAccountDisabled rejects a login to an account an admin disabled
+func respondAccountDisabled(c *gin.Context) {
+	c.JSON(http.StatusForbidden, gin.H{
+		"error":   "Account disabled",
+		"message": "This account has been disabled. Contact an administrator.",
+	})
+}
+
+// respondTooManyAttempts rejects a login that must wait. The response is the
+// same whether or not the email has an account.
+func respondTooManyAttempts(c *gin.Context, wait time.Duration) {
+	seconds := int((wait + time.Second - 1) / time.Second)
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":       "Too many failed login attempts",
+		"message":     "Please try again later",
+		"retry_after": seconds,
 	})
 }
@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"link-guardian/internal/handlers/middleware"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	authService "link-guardian/internal/services/auth"
+	"link-guardian/internal/services/passwordreset"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+var passwordResetValidator = validator.New()
+
+// emailTimeout bounds sending an email after the response was written
+const emailTimeout = 30 * time.Second
+
+// ForgotPasswordHandler emails a password reset link when the address has an
+// account. The response is the same either way and is written before the
+// email is sent, so neither its content nor its timing reveals which
+// addresses are registered.
+func ForgotPasswordHandler(c *gin.Context) {
+	var req models.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil || passwordResetValidator.Struct(req) != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request format",
+			"message": "A valid email address is required",
+		})
+		return
+	}
+	req.Email = strings.TrimSpace(strings.ToLower(req.Email))
+
+	users, ok := middleware.UserRepositoryFrom(c)
+	if !ok {
+		return
+	}
+	resets, ok := passwordResetRepositoryFrom(c)
+	if !ok {
+		return
+	}
+	resetService, ok := passwordResetFrom(c)
+	if !ok {
+		return
+	}
+
+	ip := c.ClientIP()
+	ctx := context.WithoutCancel(c.Request.Context())
+	go func() {
+		ctx, cancel := context.WithTimeout(ctx, emailTimeout)
+		defer cancel()
+
+		user, err := users.GetUserByEmail(req.Email)
+		if errors.Is(err, repositories.ErrUserNotFound) {
+			log.Printf("Password reset requested for non-existent email %s from IP %s", req.Email, ip)
+			return
+		}
+		if err != nil {
+			log.Printf("User lookup failed for password reset from IP %s: %v", ip, err)
+			return
+		}
+
+		sent, err := resetService.SendResetEmail(ctx, resets, *user)
+		switch {
+		case err != nil:
+			log.Printf("Failed to send password reset email to user ID %d: %v", user.ID, err)
+		case !sent:
+			log.Printf("Throttled password reset email to user ID %d from IP %s", user.ID, ip)
+		default:
+			log.Printf("Password reset email sent to user ID %d, requested from IP %s", user.ID, ip)
+		}
+	}()
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "If an account exists for this email, a password reset link has been sent",
+	})
+}
+
+// ResetPasswordHandler sets a new password using a token from a reset email.
+// Every existing session of the account ends, so the user logs in again.
+func ResetPasswordHandler(c *gin.Context) {
+	var req models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil || passwordResetValidator.Struct(req) != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request format",
+			"message": "A reset token and a new password are required",
+		})
+		return
+	}
+
+	authSvc, exists := c.Get("authService")
+	if !exists {
+		log.Printf("Auth service not found in context for password reset from IP %s", c.ClientIP())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Service unavailable",
+			"message": "Please try again later",
+		})
+		return
+	}
+	authService := authSvc.(*authService.AuthService)
+
+	if err := authService.ValidatePassword(req.Password); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid password",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	resets, ok := passwordResetRepositoryFrom(c)
+	if !ok {
+		return
+	}
+	resetService, ok := passwordResetFrom(c)
+	if !ok {
+		return
+	}
+
+	userID, err := resetService.Reset(c.Request.Context(), resets, req.Token, req.Password)
+	if errors.Is(err, repositories.ErrPasswordResetTokenInvalid) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid reset token",
+			"message": "This reset link is invalid or has expired. Please request a new one.",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Password reset failed from IP %s: %v", c.ClientIP(), err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Password reset failed",
+			"message": "Please try again later",
+		})
+		return
+	}
+
+	log.Printf("Password reset for user ID %d from IP %s", userID, c.ClientIP())
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset. Please log in with your new password."})
+}
+
+// passwordResetFrom reads the password reset service injected by PasswordResetMiddleware
+func passwordResetFrom(c *gin.Context) (*passwordreset.Service, bool) {
+	resetService, exists := c.Get("passwordReset")
+	if !exists {
+		log.Printf("Password reset service not found in context for request from IP %s", c.ClientIP())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Service unavailable",
+			"message": "Please try again later",
+		})
+		return nil, false
+	}
+	return resetService.(*passwordreset.Service), true
+}
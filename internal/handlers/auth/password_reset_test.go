@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"link-guardian/internal/repositories/memory"
+	"link-guardian/internal/testutil/mailtest"
+	"net/http"
+	"regexp"
+	"testing"
+)
+
+var resetLinkPattern = regexp.MustCompile(`http://app\.test/reset-password\?token=([A-Za-z0-9_-]+)`)
+
+func TestPasswordResetFlow(t *testing.T) {
+	mail := mailtest.NewRecordingMailer()
+	router := newTestRouterWithMailer(memory.NewStore(), mail)
+
+	w := post(router, "/signup", `{"username":"dave","email":"dave@example.com","password":"Secret123!"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("signup: got %d: %s", w.Code, w.Body)
+	}
+	session := decodeTokens(t, w)
+
+	if w := post(router, "/auth/forgot", `{"email":"DAVE@example.com"}`); w.Code != http.StatusAccepted {
+		t.Fatalf("forgot: got %d: %s", w.Code, w.Body)
+	}
+	msg := mail.Next(t, "Reset")
+	if msg.To != "dave@example.com" {
+		t.Errorf("email sent to %q, want dave@example.com", msg.To)
+	}
+	match := resetLinkPattern.FindStringSubmatch(msg.Body)
+	if match == nil {
+		t.Fatalf("email body has no reset link:\n%s", msg.Body)
+	}
+	token := match[1]
+
+	if w := post(router, "/auth/reset", `{"token":"`+token+`","password":"short"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("weak password: got %d, want 400", w.Code)
+	}
+
+	if w := post(router, "/auth/reset", `{"token":"`+token+`","password":"NewSecret456!"}`); w.Code != http.StatusOK {
+		t.Fatalf("reset: got %d: %s", w.Code, w.Body)
+	}
+
+	// The token is single-use and the sessions from before the reset are gone
+	if w := post(router, "/auth/reset", `{"token":"`+token+`","password":"Another789!"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("reused reset token: got %d, want 400", w.Code)
+	}
+	if w := post(router, "/auth/refresh", refreshBody(session.RefreshToken)); w.Code != http.StatusUnauthorized {
+		t.Errorf("refresh token from before the reset: got %d, want 401", w.Code)
+	}
+
+	if w := post(router, "/login", `{"email":"dave@example.com","password":"Secret123!"}`); w.Code != http.StatusUnauthorized {
+		t.Errorf("login with old password: got %d, want 401", w.Code)
+	}
+	if w := post(router, "/login", `{"email":"dave@example.com","password":"NewSecret456!"}`); w.Code != http.StatusOK {
+		t.Errorf("login with new password: got %d: %s", w.Code, w.Body)
+	}
+}
+
+func TestForgotPasswordDoesNotRevealAccounts(t *testing.T) {
+	mail := mailtest.NewRecordingMailer()
+	router := newTestRouterWithMailer(memory.NewStore(), mail)
+
+	if w := post(router, "/signup", `{"username":"erin","email":"erin@example.com","password":"Secret123!"}`); w.Code != http.StatusCreated {
+		t.Fatalf("signup: got %d: %s", w.Code, w.Body)
+	}
+
+	known := post(router, "/auth/forgot", `{"email":"erin@example.com"}`)
+	mail.Next(t, "Reset")
+	unknown := post(router, "/auth/forgot", `{"email":"nobody@example.com"}`)
+
+	if known.Code != unknown.Code || known.Body.String() != unknown.Body.String() {
+		t.Errorf("responses differ: %d %s vs %d %s", known.Code, known.Body, unknown.Code, unknown.Body)
+	}
+
+	mail.None(t, "Reset")
+
+	if w := post(router, "/auth/reset", `{"token":"made-up","password":"NewSecret456!"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown reset token: got %d, want 400", w.Code)
+	}
+}
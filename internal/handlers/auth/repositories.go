@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"link-guardian/internal/repositories"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// refreshTokenRepositoryFrom reads the refresh token repository injected by RepositoriesMiddleware
+func refreshTokenRepositoryFrom(c *gin.Context) (repositories.RefreshTokenRepository, bool) {
+	repo, exists := c.Get("refreshTokenRepository")
+	if !exists {
+		log.Printf("Refresh token repository not found in context for request from IP %s", c.ClientIP())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Service unavailable",
+			"message": "Please try again later",
+		})
+		return nil, false
+	}
+	return repo.(repositories.RefreshTokenRepository), true
+}
+
+// passwordResetRepositoryFrom reads the password reset repository injected by RepositoriesMiddleware
+func passwordResetRepositoryFrom(c *gin.Context) (repositories.PasswordResetRepository, bool) {
+	repo, exists := c.Get("passwordResetRepository")
+	if !exists {
+		log.Printf("Password reset repository not found in context for request from IP %s", c.ClientIP())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Service unavailable",
+			"message": "Please try again later",
+		})
+		return nil, false
+	}
+	return repo.(repositories.PasswordResetRepository), true
+}
+
+// twoFactorRepositoryFrom reads the two-factor repository injected by RepositoriesMiddleware
+func twoFactorRepositoryFrom(c *gin.Context) (repositories.TwoFactorRepository, bool) {
+	repo, exists := c.Get("twoFactorRepository")
+	if !exists {
+		log.Printf("Two-factor repository not found in context for request from IP %s", c.ClientIP())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Service unavailable",
+			"message": "Please try again later",
+		})
+		return nil, false
+	}
+	return repo.(repositories.TwoFactorRepository), true
+}
@@ -1,8 +1,10 @@
 package auth
 
 import (
+	"errors"
+	"link-guardian/internal/handlers/middleware"
 	"link-guardian/internal/models"
-	dbRepo "link-guardian/internal/repositories/db"
+	"link-guardian/internal/repositories"
 	authService "link-guardian/internal/services/auth"
 	"log"
 	"net/http"
@@ -72,22 +74,39 @@ func SignupHandler(c *gin.Context) {
 		return
 	}
 
+	repo, ok := middleware.UserRepositoryFrom(c)
+	if !ok {
+		return
+	}
+	tokens, ok := refreshTokenRepositoryFrom(c)
+	if !ok {
+		return
+	}
+	verificationService, ok := emailVerificationFrom(c)
+	if !ok {
+		return
+	}
+
 	// Check if email and username are unique
-	if err := dbRepo.IsEmailUnique(req.Email); err != nil {
-		log.Printf("Email uniqueness check failed for %s from IP %s", req.Email, c.ClientIP())
-		c.JSON(http.StatusConflict, gin.H{
-			"error":   "Registration failed",
-			"message": "An account with this email already exists",
-		})
+	emailTaken, err := repo.EmailExists(req.Email)
+	if err != nil {
+		log.Printf("Email uniqueness check failed for %s from IP %s: %v", req.Email, c.ClientIP(), err)
+		respondRegistrationFailed(c)
+		return
+	}
+	if emailTaken {
+		respondEmailTaken(c, req.Email)
 		return
 	}
 
-	if err := dbRepo.IsUsernameUnique(req.Username); err != nil {
-		log.Printf("Username uniqueness check failed for %s from IP %s", req.Username, c.ClientIP())
-		c.JSON(http.StatusConflict, gin.H{
-			"error":   "Registration failed",
-			"message": "This username is already taken",
-		})
+	usernameTaken, err := repo.UsernameExists(req.Username)
+	if err != nil {
+		log.Printf("Username uniqueness check failed for %s from IP %s: %v", req.Username, c.ClientIP(), err)
+		respondRegistrationFailed(c)
+		return
+	}
+	if usernameTaken {
+		respondUsernameTaken(c, req.Username)
 		return
 	}
 
@@ -102,19 +121,23 @@ func SignupHandler(c *gin.Context) {
 		return
 	}
 
-	// Create user with hashed password
-	userID, err := dbRepo.InsertUserToDB(req.Username, req.Email, hashedPassword)
-	if err != nil {
+	// Create user with hashed password; a concurrent signup can still claim the email or username
+	userID, err := repo.CreateUser(req.Username, req.Email, hashedPassword)
+	switch {
+	case errors.Is(err, repositories.ErrEmailTaken):
+		respondEmailTaken(c, req.Email)
+		return
+	case errors.Is(err, repositories.ErrUsernameTaken):
+		respondUsernameTaken(c, req.Username)
+		return
+	case err != nil:
 		log.Printf("User creation failed for %s from IP %s: %v", req.Username, c.ClientIP(), err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Registration failed",
-			"message": "Please try again later",
-		})
+		respondRegistrationFailed(c)
 		return
 	}
 
-	// Generate JWT token
-	tokenString, err := authService.GenerateJWTToken(userID, req.Username)
+	// Issue an access token and a refresh token for a new session
+	session, err := startSession(authService, tokens, userID, req.Username)
 	if err != nil {
 		log.Printf("Token generation failed for user %s (ID: %d) from IP %s: %v", req.Username, userID, c.ClientIP(), err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -124,17 +147,42 @@ func SignupHandler(c *gin.Context) {
 		return
 	}
 
+	sendVerificationEmailAsync(c, verificationService, models.User{ID: userID, Username: req.Username, Email: req.Email})
+
 	// Log successful registration
 	log.Printf("User registered successfully: %s (ID: %d) from IP %s", req.Username, userID, c.ClientIP())
 
 	// Return success response
-	c.JSON(http.StatusCreated, gin.H{
-		"message": "User created successfully",
+	session.respond(c, http.StatusCreated, gin.H{
+		"message": "User created successfully. Please check your email to verify your address.",
 		"user": gin.H{
-			"id":       userID,
-			"username": req.Username,
-			"email":    req.Email,
+			"id":             userID,
+			"username":       req.Username,
+			"email":          req.Email,
+			"email_verified": false,
 		},
-		"token": tokenString,
+	})
+}
+
+func respondEmailTaken(c *gin.Context, email string) {
+	log.Printf("Signup with existing email %s from IP %s", email, c.ClientIP())
+	c.JSON(http.StatusConflict, gin.H{
+		"error":   "Registration failed",
+		"message": "An account with this email already exists",
+	})
+}
+
+func respondUsernameTaken(c *gin.Context, username string) {
+	log.Printf("Signup with existing username %s from IP %s", username, c.ClientIP())
+	c.JSON(http.StatusConflict, gin.H{
+		"error":   "Registration failed",
+		"message": "This username is already taken",
+	})
+}
+
+func respondRegistrationFailed(c *gin.Context) {
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error":   "Registration failed",
+		"message": "Please try again later",
 	})
 }
@@ -0,0 +1,224 @@
+package auth
+
+import (
+	"errors"
+	"link-guardian/internal/handlers/middleware"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"link-guardian/internal/repositories/redis"
+	authService "link-guardian/internal/services/auth"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+var refreshValidator = validator.New()
+
+// session holds the tokens handed to a client after login, signup or refresh
+type session struct {
+	access       authService.AccessToken
+	refreshToken string
+}
+
+// startSession issues an access token and the first refresh token of a new family
+func startSession(svc *authService.AuthService, tokens repositories.RefreshTokenRepository, userID int, username string) (session, error) {
+	familyID, err := svc.NewTokenFamilyID()
+	if err != nil {
+		return session{}, err
+	}
+
+	refreshToken, hash, err := svc.GenerateOpaqueToken()
+	if err != nil {
+		return session{}, err
+	}
+
+	_, err = tokens.CreateRefreshToken(models.RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(svc.RefreshTokenTTL()),
+	})
+	if err != nil {
+		return session{}, err
+	}
+
+	access, err := svc.GenerateAccessToken(userID, username)
+	if err != nil {
+		return session{}, err
+	}
+
+	return session{access: access, refreshToken: refreshToken}, nil
+}
+
+// respond writes the tokens together with the given fields
+func (s session) respond(c *gin.Context, status int, fields gin.H) {
+	fields["token"] = s.access.Token
+	fields["refresh_token"] = s.refreshToken
+	fields["expires_in"] = int(time.Until(s.access.ExpiresAt).Seconds())
+	c.JSON(status, fields)
+}
+
+// RefreshHandler exchanges a refresh token for a new access token and a new
+// refresh token. Presenting a refresh token that was already exchanged
+// revokes every token descended from the same login.
+func RefreshHandler(c *gin.Context) {
+	var req models.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil || refreshValidator.Struct(req) != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request format",
+			"message": "A refresh token is required",
+		})
+		return
+	}
+
+	authSvc, exists := c.Get("authService")
+	if !exists {
+		log.Printf("Auth service not found in context for token refresh from IP %s", c.ClientIP())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Service unavailable",
+			"message": "Please try again later",
+		})
+		return
+	}
+	svc := authSvc.(*authService.AuthService)
+
+	tokens, ok := refreshTokenRepositoryFrom(c)
+	if !ok {
+		return
+	}
+	users, ok := middleware.UserRepositoryFrom(c)
+	if !ok {
+		return
+	}
+
+	refreshToken, hash, err := svc.GenerateOpaqueToken()
+	if err != nil {
+		log.Printf("Refresh token generation failed from IP %s: %v", c.ClientIP(), err)
+		respondRefreshFailed(c)
+		return
+	}
+
+	rotated, err := tokens.RotateRefreshToken(svc.HashOpaqueToken(req.RefreshToken), models.RefreshToken{
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(svc.RefreshTokenTTL()),
+	})
+	switch {
+	case errors.Is(err, repositories.ErrRefreshTokenReused):
+		log.Printf("⚠️  Reused refresh token for user ID %d from IP %s; revoked its token family", rotated.UserID, c.ClientIP())
+		respondInvalidRefreshToken(c)
+		return
+	case errors.Is(err, repositories.ErrRefreshTokenNotFound):
+		respondInvalidRefreshToken(c)
+		return
+	case err != nil:
+		log.Printf("Refresh token rotation failed from IP %s: %v", c.ClientIP(), err)
+		respondRefreshFailed(c)
+		return
+	}
+
+	user, err := users.GetUserByID(rotated.UserID)
+	if errors.Is(err, repositories.ErrUserNotFound) {
+		respondInvalidRefreshToken(c)
+		return
+	}
+	if err != nil {
+		log.Printf("User lookup failed for token refresh from IP %s: %v", c.ClientIP(), err)
+		respondRefreshFailed(c)
+		return
+	}
+	if user.DisabledAt.Valid {
+		respondAccountDisabled(c)
+		return
+	}
+
+	access, err := svc.GenerateAccessToken(user.ID, user.Username)
+	if err != nil {
+		log.Printf("Token generation failed for user %s (ID: %d) from IP %s: %v", user.Username, user.ID, c.ClientIP(), err)
+		respondRefreshFailed(c)
+		return
+	}
+
+	session{access: access, refreshToken: refreshToken}.respond(c, http.StatusOK, gin.H{
+		"message": "Token refreshed",
+	})
+}
+
+// LogoutHandler revokes the caller's access token and, when the body carries
+// one, the refresh token together with every token rotated from the same login
+func LogoutHandler(c *gin.Context) {
+	var req models.RefreshRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request format",
+				"message": "Please check your input and try again",
+			})
+			return
+		}
+	}
+
+	authSvc, exists := c.Get("authService")
+	if !exists {
+		log.Printf("Auth service not found in context for logout from IP %s", c.ClientIP())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Service unavailable",
+			"message": "Please try again later",
+		})
+		return
+	}
+	svc := authSvc.(*authService.AuthService)
+
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	if req.RefreshToken != "" {
+		tokens, ok := refreshTokenRepositoryFrom(c)
+		if !ok {
+			return
+		}
+
+		err := tokens.RevokeRefreshTokenFamily(svc.HashOpaqueToken(req.RefreshToken), userID)
+		if err != nil && !errors.Is(err, repositories.ErrRefreshTokenNotFound) {
+			log.Printf("Refresh token revocation failed for user ID %d from IP %s: %v", userID, c.ClientIP(), err)
+			respondLogoutFailed(c)
+			return
+		}
+	}
+
+	tokenID := c.GetString("token_id")
+	expiresAt := c.GetTime("token_expires_at")
+	if err := redis.DenyAccessToken(c.Request.Context(), tokenID, time.Until(expiresAt)); err != nil {
+		log.Printf("Access token revocation failed for user ID %d from IP %s: %v", userID, c.ClientIP(), err)
+		respondLogoutFailed(c)
+		return
+	}
+
+	log.Printf("User logged out: ID %d from IP %s", userID, c.ClientIP())
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+func respondInvalidRefreshToken(c *gin.Context) {
+	c.JSON(http.StatusUnauthorized, gin.H{
+		"error":   "Invalid refresh token",
+		"message": "Please login again",
+	})
+}
+
+func respondRefreshFailed(c *gin.Context) {
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error":   "Token refresh failed",
+		"message": "Please try again later",
+	})
+}
+
+func respondLogoutFailed(c *gin.Context) {
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error":   "Logout failed",
+		"message": "Please try again later",
+	})
+}
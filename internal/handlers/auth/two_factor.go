@@ -0,0 +1,435 @@
+package auth
+
+import (
+	"encoding/base64"
+	"errors"
+	"link-guardian/internal/handlers/middleware"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	authService "link-guardian/internal/services/auth"
+	"link-guardian/internal/services/qrcode"
+	"link-guardian/internal/services/twofactor"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+var twoFactorValidator = validator.New()
+
+// TwoFactorLoginHandler finishes a login that LoginHandler answered with a
+// challenge token by checking the user's authenticator or recovery code.
+// Wrong codes count as failed logins for the account.
+func TwoFactorLoginHandler(c *gin.Context) {
+	var req models.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil || twoFactorValidator.Struct(req) != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request format",
+			"message": "A challenge token and a code are required",
+		})
+		return
+	}
+
+	authSvc, exists := c.Get("authService")
+	if !exists {
+		log.Printf("Auth service not found in context for two-factor login from IP %s", c.ClientIP())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Service unavailable",
+			"message": "Please try again later",
+		})
+		return
+	}
+	svc := authSvc.(*authService.AuthService)
+
+	twoFactorService, ok := twoFactorFrom(c)
+	if !ok {
+		return
+	}
+	users, ok := middleware.UserRepositoryFrom(c)
+	if !ok {
+		return
+	}
+	repo, ok := twoFactorRepositoryFrom(c)
+	if !ok {
+		return
+	}
+	tokens, ok := refreshTokenRepositoryFrom(c)
+	if !ok {
+		return
+	}
+	guard, ok := middleware.LoginGuardFrom(c)
+	if !ok {
+		return
+	}
+
+	userID, err := twoFactorService.ValidateChallenge(req.ChallengeToken)
+	if err != nil {
+		respondInvalidChallenge(c)
+		return
+	}
+
+	user, err := users.GetUserByID(userID)
+	if errors.Is(err, repositories.ErrUserNotFound) {
+		respondInvalidChallenge(c)
+		return
+	}
+	if err != nil {
+		log.Printf("User lookup failed for two-factor login from IP %s: %v", c.ClientIP(), err)
+		respondTwoFactorFailed(c)
+		return
+	}
+	if user.DisabledAt.Valid {
+		respondAccountDisabled(c)
+		return
+	}
+
+	ctx := c.Request.Context()
+	ip := c.ClientIP()
+	email := strings.ToLower(user.Email)
+
+	attempt, wait, err := guard.Begin(ctx, email, ip)
+	if err != nil {
+		log.Printf("Login attempt check failed from IP %s: %v", ip, err)
+		respondTwoFactorFailed(c)
+		return
+	}
+	if wait > 0 {
+		log.Printf("Throttled two-factor login for %s from IP %s", email, ip)
+		respondTooManyAttempts(c, wait)
+		return
+	}
+
+	err = twoFactorService.Verify(repo, user.ID, req.Code)
+	if errors.Is(err, twofactor.ErrInvalidCode) {
+		log.Printf("Failed two-factor login for user %s from IP %s: invalid code", email, ip)
+		recordLoginFailure(c, guard, attempt, user)
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Authentication failed",
+			"message": "Invalid authentication code",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Two-factor check failed for user ID %d from IP %s: %v", user.ID, ip, err)
+		releaseLoginAttempt(c, guard, attempt)
+		respondTwoFactorFailed(c)
+		return
+	}
+
+	if err := guard.RecordSuccess(ctx, attempt); err != nil {
+		log.Printf("Failed to reset login failures for %s: %v", email, err)
+	}
+
+	session, err := startSession(svc, tokens, user.ID, user.Username)
+	if err != nil {
+		log.Printf("Token generation failed for user %s (ID: %d) from IP %s: %v", user.Username, user.ID, ip, err)
+		respondTwoFactorFailed(c)
+		return
+	}
+
+	log.Printf("User logged in with two-factor authentication: %s (ID: %d) from IP %s", user.Username, user.ID, ip)
+	session.respond(c, http.StatusOK, gin.H{
+		"message": "Login successful",
+		"user": gin.H{
+			"id":                 user.ID,
+			"username":           user.Username,
+			"email":              user.Email,
+			"email_verified":     user.EmailVerified,
+			"two_factor_enabled": true,
+			"role":               user.Role,
+		},
+	})
+}
+
+// TwoFactorStatusHandler reports whether the logged-in user has two-factor
+// authentication enabled and how many recovery codes they have left
+func TwoFactorStatusHandler(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+	repo, ok := twoFactorRepositoryFrom(c)
+	if !ok {
+		return
+	}
+
+	enrolment, err := repo.GetTwoFactor(userID)
+	if err != nil && !errors.Is(err, repositories.ErrTwoFactorNotFound) {
+		log.Printf("Failed to get two-factor status of user ID %d: %v", userID, err)
+		respondTwoFactorFailed(c)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":             enrolment.Enabled(),
+		"recovery_codes_left": enrolment.RecoveryCodesLeft,
+	})
+}
+
+// SetupTwoFactorHandler starts enrolment by creating a TOTP secret and
+// returning it as a provisioning URI and a QR code of that URI
+func SetupTwoFactorHandler(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+	twoFactorService, ok := twoFactorFrom(c)
+	if !ok {
+		return
+	}
+	users, ok := middleware.UserRepositoryFrom(c)
+	if !ok {
+		return
+	}
+	repo, ok := twoFactorRepositoryFrom(c)
+	if !ok {
+		return
+	}
+	generator, ok := middleware.QRGeneratorFrom(c)
+	if !ok {
+		return
+	}
+
+	user, err := users.GetUserByID(userID)
+	if err != nil {
+		log.Printf("User lookup failed for two-factor setup, user ID %d: %v", userID, err)
+		respondTwoFactorFailed(c)
+		return
+	}
+
+	enrolment, err := twoFactorService.Setup(repo, *user)
+	if errors.Is(err, repositories.ErrTwoFactorEnabled) {
+		respondTwoFactorAlreadyEnabled(c)
+		return
+	}
+	if err != nil {
+		log.Printf("Two-factor setup failed for user ID %d: %v", userID, err)
+		respondTwoFactorFailed(c)
+		return
+	}
+
+	image, contentType, err := generator.Render(enrolment.URI, qrcode.DefaultOptions())
+	if err != nil {
+		log.Printf("QR generation failed for two-factor setup of user ID %d: %v", userID, err)
+		respondTwoFactorFailed(c)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Scan the QR code with your authenticator app, then confirm with a code",
+		"secret":      enrolment.Secret,
+		"otpauth_url": enrolment.URI,
+		"qr_code":     "data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(image),
+	})
+}
+
+// EnableTwoFactorHandler enables two-factor authentication once the user
+// confirms the pending secret with a code, and returns the recovery codes
+func EnableTwoFactorHandler(c *gin.Context) {
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil || twoFactorValidator.Struct(req) != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request format",
+			"message": "A code from your authenticator app is required",
+		})
+		return
+	}
+
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+	twoFactorService, ok := twoFactorFrom(c)
+	if !ok {
+		return
+	}
+	repo, ok := twoFactorRepositoryFrom(c)
+	if !ok {
+		return
+	}
+
+	codes, err := twoFactorService.Enable(repo, userID, req.Code)
+	switch {
+	case errors.Is(err, repositories.ErrTwoFactorEnabled):
+		respondTwoFactorAlreadyEnabled(c)
+		return
+	case errors.Is(err, repositories.ErrTwoFactorNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Two-factor setup not started",
+			"message": "Start two-factor setup before enabling it",
+		})
+		return
+	case errors.Is(err, twofactor.ErrInvalidCode):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid code",
+			"message": "The code does not match. Check that your device's clock is correct and try again.",
+		})
+		return
+	case err != nil:
+		log.Printf("Enabling two-factor authentication failed for user ID %d: %v", userID, err)
+		respondTwoFactorFailed(c)
+		return
+	}
+
+	log.Printf("Two-factor authentication enabled for user ID %d from IP %s", userID, c.ClientIP())
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Two-factor authentication enabled. Store these recovery codes somewhere safe; each can be used once.",
+		"recovery_codes": codes,
+	})
+}
+
+// DisableTwoFactorHandler turns two-factor authentication off after the user
+// re-authenticates with their password and a current or recovery code.
+// Failed attempts count as failed logins for the account.
+func DisableTwoFactorHandler(c *gin.Context) {
+	var req models.DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil || twoFactorValidator.Struct(req) != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request format",
+			"message": "Your password and a code are required",
+		})
+		return
+	}
+
+	authSvc, exists := c.Get("authService")
+	if !exists {
+		log.Printf("Auth service not found in context for disabling two-factor authentication from IP %s", c.ClientIP())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Service unavailable",
+			"message": "Please try again later",
+		})
+		return
+	}
+	svc := authSvc.(*authService.AuthService)
+
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+	twoFactorService, ok := twoFactorFrom(c)
+	if !ok {
+		return
+	}
+	users, ok := middleware.UserRepositoryFrom(c)
+	if !ok {
+		return
+	}
+	repo, ok := twoFactorRepositoryFrom(c)
+	if !ok {
+		return
+	}
+	guard, ok := middleware.LoginGuardFrom(c)
+	if !ok {
+		return
+	}
+
+	user, err := users.GetUserByID(userID)
+	if err != nil {
+		log.Printf("User lookup failed for disabling two-factor authentication, user ID %d: %v", userID, err)
+		respondTwoFactorFailed(c)
+		return
+	}
+	if !user.TwoFactorEnabled {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Two-factor authentication not enabled",
+			"message": "Two-factor authentication is already off",
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	ip := c.ClientIP()
+	email := strings.ToLower(user.Email)
+
+	attempt, wait, err := guard.Begin(ctx, email, ip)
+	if err != nil {
+		log.Printf("Login attempt check failed from IP %s: %v", ip, err)
+		respondTwoFactorFailed(c)
+		return
+	}
+	if wait > 0 {
+		respondTooManyAttempts(c, wait)
+		return
+	}
+
+	if err := svc.VerifyPassword(strings.TrimSpace(req.Password), user.Password); err != nil {
+		log.Printf("Failed to disable two-factor authentication for user ID %d from IP %s: invalid password", userID, ip)
+		recordLoginFailure(c, guard, attempt, user)
+		respondReauthenticationFailed(c)
+		return
+	}
+
+	err = twoFactorService.Verify(repo, userID, req.Code)
+	if errors.Is(err, twofactor.ErrInvalidCode) {
+		log.Printf("Failed to disable two-factor authentication for user ID %d from IP %s: invalid code", userID, ip)
+		recordLoginFailure(c, guard, attempt, user)
+		respondReauthenticationFailed(c)
+		return
+	}
+	if err != nil {
+		log.Printf("Two-factor check failed for user ID %d from IP %s: %v", userID, ip, err)
+		releaseLoginAttempt(c, guard, attempt)
+		respondTwoFactorFailed(c)
+		return
+	}
+
+	if err := repo.DisableTwoFactor(userID); err != nil {
+		log.Printf("Disabling two-factor authentication failed for user ID %d: %v", userID, err)
+		releaseLoginAttempt(c, guard, attempt)
+		respondTwoFactorFailed(c)
+		return
+	}
+
+	if err := guard.RecordSuccess(ctx, attempt); err != nil {
+		log.Printf("Failed to reset login failures for %s: %v", email, err)
+	}
+
+	log.Printf("Two-factor authentication disabled for user ID %d from IP %s", userID, ip)
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+func respondInvalidChallenge(c *gin.Context) {
+	c.JSON(http.StatusUnauthorized, gin.H{
+		"error":   "Invalid challenge",
+		"message": "Your login has expired. Please login again.",
+	})
+}
+
+func respondReauthenticationFailed(c *gin.Context) {
+	c.JSON(http.StatusForbidden, gin.H{
+		"error":   "Re-authentication failed",
+		"message": "Invalid password or code",
+	})
+}
+
+func respondTwoFactorAlreadyEnabled(c *gin.Context) {
+	c.JSON(http.StatusConflict, gin.H{
+		"error":   "Two-factor authentication already enabled",
+		"message": "Disable two-factor authentication before setting it up again",
+	})
+}
+
+func respondTwoFactorFailed(c *gin.Context) {
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error":   "Two-factor authentication failed",
+		"message": "Please try again later",
+	})
+}
+
+// twoFactorFrom reads the two-factor service injected by TwoFactorMiddleware
+func twoFactorFrom(c *gin.Context) (*twofactor.Service, bool) {
+	twoFactorService, exists := c.Get("twoFactor")
+	if !exists {
+		log.Printf("Two-factor service not found in context for request from IP %s", c.ClientIP())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Service unavailable",
+			"message": "Please try again later",
+		})
+		return nil, false
+	}
+	return twoFactorService.(*twofactor.Service), true
+}
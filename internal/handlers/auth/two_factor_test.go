@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"encoding/json"
+	"link-guardian/internal/repositories/memory"
+	"link-guardian/internal/services/totp"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// decodeJSON unmarshals a response body into v
+func decodeJSON(t *testing.T, w *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("invalid JSON response %q: %v", w.Body.String(), err)
+	}
+}
+
+// enableTwoFactor signs up a user, enables two-factor authentication for
+// them and returns their recovery codes
+func enableTwoFactor(t *testing.T, router http.Handler, username string) []string {
+	t.Helper()
+
+	w := post(router, "/signup", `{"username":"`+username+`","email":"`+username+`@example.com","password":"Secret123!"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("signup: got %d: %s", w.Code, w.Body)
+	}
+	session := decodeTokens(t, w)
+
+	w = postWithToken(router, "/auth/2fa/setup", session.Token, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("setup: got %d: %s", w.Code, w.Body)
+	}
+	var setup struct {
+		Secret     string `json:"secret"`
+		OTPAuthURL string `json:"otpauth_url"`
+		QRCode     string `json:"qr_code"`
+	}
+	decodeJSON(t, w, &setup)
+	if !strings.HasPrefix(setup.OTPAuthURL, "otpauth://totp/") || !strings.HasPrefix(setup.QRCode, "data:image/png;base64,") {
+		t.Errorf("setup response = %s, want a provisioning URI and a PNG QR code", w.Body)
+	}
+
+	if w := postWithToken(router, "/auth/2fa/enable", session.Token, `{"code":"abcdef"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("enable with a wrong code: got %d, want 400", w.Code)
+	}
+
+	code, err := totp.Code(setup.Secret, totp.Step(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w = postWithToken(router, "/auth/2fa/enable", session.Token, `{"code":"`+code+`"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("enable: got %d: %s", w.Code, w.Body)
+	}
+	var enabled struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	}
+	decodeJSON(t, w, &enabled)
+	if len(enabled.RecoveryCodes) == 0 {
+		t.Fatalf("enable response %s has no recovery codes", w.Body)
+	}
+
+	if w := postWithToken(router, "/auth/2fa/setup", session.Token, ""); w.Code != http.StatusConflict {
+		t.Errorf("setup while enabled: got %d, want 409", w.Code)
+	}
+	return enabled.RecoveryCodes
+}
+
+// loginChallenge logs in with a password and returns the two-factor challenge token
+func loginChallenge(t *testing.T, router http.Handler, username string) string {
+	t.Helper()
+
+	w := post(router, "/login", `{"email":"`+username+`@example.com","password":"Secret123!"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("login: got %d: %s", w.Code, w.Body)
+	}
+	var login struct {
+		Token             string `json:"token"`
+		TwoFactorRequired bool   `json:"two_factor_required"`
+		ChallengeToken    string `json:"challenge_token"`
+	}
+	decodeJSON(t, w, &login)
+	if login.Token != "" || !login.TwoFactorRequired || login.ChallengeToken == "" {
+		t.Fatalf("login response = %s, want a challenge and no session", w.Body)
+	}
+	return login.ChallengeToken
+}
+
+func twoFactorLoginBody(challenge, code string) string {
+	return `{"challenge_token":"` + challenge + `","code":"` + code + `"}`
+}
+
+func TestTwoFactorLoginAndDisable(t *testing.T) {
+	store := memory.NewStore()
+	router := newTestRouter(store)
+	recoveryCodes := enableTwoFactor(t, router, "ivan")
+
+	challenge := loginChallenge(t, router, "ivan")
+	if w := post(router, "/login/2fa", twoFactorLoginBody("not-a-token", recoveryCodes[0])); w.Code != http.StatusUnauthorized {
+		t.Errorf("invalid challenge: got %d, want 401", w.Code)
+	}
+	w := post(router, "/login/2fa", twoFactorLoginBody(challenge, recoveryCodes[0]))
+	if w.Code != http.StatusOK {
+		t.Fatalf("two-factor login: got %d: %s", w.Code, w.Body)
+	}
+	session := decodeTokens(t, w)
+
+	// Recovery codes are single-use
+	if w := post(router, "/login/2fa", twoFactorLoginBody(challenge, recoveryCodes[0])); w.Code != http.StatusUnauthorized {
+		t.Errorf("reused recovery code: got %d, want 401", w.Code)
+	}
+
+	if w := postWithToken(router, "/auth/2fa/disable", session.Token, `{"password":"Wrong123!","code":"`+recoveryCodes[1]+`"}`); w.Code != http.StatusForbidden {
+		t.Errorf("disable with a wrong password: got %d, want 403", w.Code)
+	}
+	if w := postWithToken(router, "/auth/2fa/disable", session.Token, `{"password":"Secret123!","code":"`+recoveryCodes[1]+`"}`); w.Code != http.StatusOK {
+		t.Fatalf("disable: got %d: %s", w.Code, w.Body)
+	}
+
+	// Without two-factor authentication the password alone logs in again
+	w = post(router, "/login", `{"email":"ivan@example.com","password":"Secret123!"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("login after disabling: got %d: %s", w.Code, w.Body)
+	}
+	decodeTokens(t, w)
+}
+
+func TestTwoFactorWrongCodesLockOut(t *testing.T) {
+	store := memory.NewStore()
+	router := newTestRouter(store)
+	recoveryCodes := enableTwoFactor(t, router, "judy")
+
+	challenge := loginChallenge(t, router, "judy")
+	for i := 0; i < 3; i++ {
+		if w := post(router, "/login/2fa", twoFactorLoginBody(challenge, "zzzzz-zzzzz")); w.Code != http.StatusUnauthorized {
+			t.Fatalf("wrong code %d: got %d, want 401", i+1, w.Code)
+		}
+	}
+
+	// Neither the second factor nor a fresh password login resets the lockout
+	if w := post(router, "/login/2fa", twoFactorLoginBody(challenge, recoveryCodes[0])); w.Code != http.StatusTooManyRequests {
+		t.Errorf("code after lockout: got %d, want 429", w.Code)
+	}
+	if w := post(router, "/login", `{"email":"judy@example.com","password":"Secret123!"}`); w.Code != http.StatusTooManyRequests {
+		t.Errorf("login after lockout: got %d, want 429", w.Code)
+	}
+}
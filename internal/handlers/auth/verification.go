@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"link-guardian/internal/handlers/middleware"
+	"link-guardian/internal/models"
+	"link-guardian/internal/services/verification"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+var verificationValidator = validator.New()
+
+// VerifyEmailHandler marks the email address a verification link was sent to
+// as verified
+func VerifyEmailHandler(c *gin.Context) {
+	var req models.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil || verificationValidator.Struct(req) != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request format",
+			"message": "A verification token is required",
+		})
+		return
+	}
+
+	users, ok := middleware.UserRepositoryFrom(c)
+	if !ok {
+		return
+	}
+	verificationService, ok := emailVerificationFrom(c)
+	if !ok {
+		return
+	}
+
+	user, err := verificationService.Verify(users, req.Token)
+	if errors.Is(err, verification.ErrInvalidToken) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid verification token",
+			"message": "This verification link is invalid or has expired. Please request a new one.",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Email verification failed from IP %s: %v", c.ClientIP(), err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Verification failed",
+			"message": "Please try again later",
+		})
+		return
+	}
+
+	log.Printf("Email verified for user ID %d from IP %s", user.ID, c.ClientIP())
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Email address verified",
+		"user": gin.H{
+			"id":             user.ID,
+			"username":       user.Username,
+			"email":          user.Email,
+			"email_verified": true,
+		},
+	})
+}
+
+// ResendVerificationHandler sends the logged-in user a new verification email
+func ResendVerificationHandler(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	users, ok := middleware.UserRepositoryFrom(c)
+	if !ok {
+		return
+	}
+	verificationService, ok := emailVerificationFrom(c)
+	if !ok {
+		return
+	}
+
+	user, err := users.GetUserByID(userID)
+	if err != nil {
+		log.Printf("User lookup failed for verification resend, user ID %d: %v", userID, err)
+		respondResendFailed(c)
+		return
+	}
+	if user.EmailVerified {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Already verified",
+			"message": "Your email address is already verified",
+		})
+		return
+	}
+
+	sent, err := verificationService.SendVerificationEmail(c.Request.Context(), *user)
+	if err != nil {
+		log.Printf("Failed to send verification email to user ID %d: %v", user.ID, err)
+		respondResendFailed(c)
+		return
+	}
+	if !sent {
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error":   "Too many requests",
+			"message": "A verification email was sent recently. Please check your inbox or try again later.",
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Verification email sent"})
+}
+
+// sendVerificationEmailAsync sends the verification email for a new account
+// without holding up the response
+func sendVerificationEmailAsync(c *gin.Context, verificationService *verification.Service, user models.User) {
+	ctx := context.WithoutCancel(c.Request.Context())
+	go func() {
+		ctx, cancel := context.WithTimeout(ctx, emailTimeout)
+		defer cancel()
+
+		if _, err := verificationService.SendVerificationEmail(ctx, user); err != nil {
+			log.Printf("Failed to send verification email to user ID %d: %v", user.ID, err)
+		}
+	}()
+}
+
+func respondResendFailed(c *gin.Context) {
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error":   "Sending failed",
+		"message": "Please try again later",
+	})
+}
+
+// emailVerificationFrom reads the verification service injected by EmailVerificationMiddleware
+func emailVerificationFrom(c *gin.Context) (*verification.Service, bool) {
+	verificationService, exists := c.Get("emailVerification")
+	if !exists {
+		log.Printf("Email verification service not found in context for request from IP %s", c.ClientIP())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Service unavailable",
+			"message": "Please try again later",
+		})
+		return nil, false
+	}
+	return verificationService.(*verification.Service), true
+}
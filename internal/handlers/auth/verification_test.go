@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"encoding/json"
+	"link-guardian/internal/repositories/memory"
+	"link-guardian/internal/testutil/mailtest"
+	"net/http"
+	"net/url"
+	"regexp"
+	"testing"
+)
+
+var verifyLinkPattern = regexp.MustCompile(`http://app\.test/verify-email\?token=([^\s]+)`)
+
+func TestSignupSendsVerificationEmail(t *testing.T) {
+	mail := mailtest.NewRecordingMailer()
+	store := memory.NewStore()
+	router := newTestRouterWithMailer(store, mail)
+
+	w := post(router, "/signup", `{"username":"heidi","email":"heidi@example.com","password":"Secret123!"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("signup: got %d: %s", w.Code, w.Body)
+	}
+	session := decodeTokens(t, w)
+
+	msg := mail.Next(t, "Verify")
+	if msg.To != "heidi@example.com" {
+		t.Errorf("email sent to %q, want heidi@example.com", msg.To)
+	}
+	match := verifyLinkPattern.FindStringSubmatch(msg.Body)
+	if match == nil {
+		t.Fatalf("email body has no verification link:\n%s", msg.Body)
+	}
+	token, err := url.QueryUnescape(match[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Unverified users may ask for another email
+	if w := postWithToken(router, "/auth/verify/resend", session.Token, ""); w.Code != http.StatusAccepted {
+		t.Errorf("resend: got %d: %s", w.Code, w.Body)
+	}
+	mail.Next(t, "Verify")
+
+	if w := post(router, "/auth/verify", `{"token":"not-a-token"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid token: got %d, want 400", w.Code)
+	}
+	if w := post(router, "/auth/verify", `{"token":"`+token+`"}`); w.Code != http.StatusOK {
+		t.Fatalf("verify: got %d: %s", w.Code, w.Body)
+	}
+
+	w = post(router, "/login", `{"email":"heidi@example.com","password":"Secret123!"}`)
+	var login struct {
+		User struct {
+			EmailVerified bool `json:"email_verified"`
+		} `json:"user"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &login); err != nil || !login.User.EmailVerified {
+		t.Errorf("login after verification = %s, want email_verified true", w.Body)
+	}
+
+	if w := postWithToken(router, "/auth/verify/resend", session.Token, ""); w.Code != http.StatusConflict {
+		t.Errorf("resend after verification: got %d, want 409", w.Code)
+	}
+}
@@ -0,0 +1,244 @@
+// Package domains serves the endpoints workspace owners register and verify
+// custom short domains with
+package domains
+
+import (
+	"errors"
+	"link-guardian/internal/handlers/middleware"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"link-guardian/internal/repositories/redis"
+	"link-guardian/internal/services/domains"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+var domainValidator = validator.New()
+
+// ListDomainsHandler lists the custom domains of the workspace selected by
+// RequireWorkspaceRole. Unverified domains include the TXT record that verifies them.
+func ListDomainsHandler(c *gin.Context) {
+	workspace, ok := middleware.CurrentWorkspace(c)
+	if !ok {
+		return
+	}
+
+	repo, ok := middleware.DomainRepositoryFrom(c)
+	if !ok {
+		return
+	}
+
+	list, err := repo.ListDomainsByWorkspace(workspace.ID)
+	if err != nil {
+		log.Printf("Failed to list domains of workspace %d: %v", workspace.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch domains"})
+		return
+	}
+
+	responses := make([]models.DomainResponse, 0, len(list))
+	for _, domain := range list {
+		responses = append(responses, domainResponse(domain))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"domains": responses,
+		"count":   len(responses),
+	})
+}
+
+// CreateDomainHandler registers a hostname as an unverified domain of the
+// workspace and returns the TXT record that verifies it. It is served behind
+// RequireWorkspaceRole(owner).
+func CreateDomainHandler(c *gin.Context) {
+	var req models.CreateDomainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request or payload"})
+		return
+	}
+
+	req.Hostname = domains.NormalizeHostname(req.Hostname)
+	if err := domainValidator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+		return
+	}
+
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	workspace, ok := middleware.CurrentWorkspace(c)
+	if !ok {
+		return
+	}
+
+	service, ok := domainServiceFrom(c)
+	if !ok {
+		return
+	}
+
+	repo, ok := middleware.DomainRepositoryFrom(c)
+	if !ok {
+		return
+	}
+
+	domain, err := service.Register(repo, workspace.ID, userID, req.Hostname)
+	switch {
+	case errors.Is(err, domains.ErrDefaultDomain):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The default short domain cannot be added"})
+		return
+	case errors.Is(err, repositories.ErrDomainTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": "Domain is already registered"})
+		return
+	case err != nil:
+		log.Printf("Domain registration failed for workspace %d: %v", workspace.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add domain"})
+		return
+	}
+
+	log.Printf("Domain %s added to workspace %d by user ID %d", domain.Hostname, workspace.ID, userID)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Domain added; publish the verification record and verify it",
+		"domain":  domainResponse(domain),
+	})
+}
+
+// VerifyDomainHandler looks up the verification record of a domain and marks
+// the domain verified when it is published. It is served behind
+// RequireWorkspaceRole(owner).
+func VerifyDomainHandler(c *gin.Context) {
+	workspace, domain, ok := loadDomain(c)
+	if !ok {
+		return
+	}
+
+	service, ok := domainServiceFrom(c)
+	if !ok {
+		return
+	}
+
+	repo, ok := middleware.DomainRepositoryFrom(c)
+	if !ok {
+		return
+	}
+
+	verified, err := service.Verify(c.Request.Context(), repo, domain)
+	switch {
+	case errors.Is(err, domains.ErrRecordNotFound):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":  "Verification record not found",
+			"record": domains.Record(domain),
+		})
+		return
+	case errors.Is(err, repositories.ErrDomainTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": "Domain is already verified by another workspace"})
+		return
+	case err != nil:
+		log.Printf("Failed to verify domain %s of workspace %d: %v", domain.Hostname, workspace.ID, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to look up the verification record"})
+		return
+	}
+
+	// Drop any cached "not found" entry for the hostname
+	invalidateCachedDomain(c, verified.Hostname)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Domain verified successfully",
+		"domain":  domainResponse(verified),
+	})
+}
+
+// DeleteDomainHandler removes a domain from the workspace. Domains still
+// serving links cannot be removed. It is served behind RequireWorkspaceRole(owner).
+func DeleteDomainHandler(c *gin.Context) {
+	workspace, domain, ok := loadDomain(c)
+	if !ok {
+		return
+	}
+
+	repo, ok := middleware.DomainRepositoryFrom(c)
+	if !ok {
+		return
+	}
+
+	err := repo.DeleteDomain(workspace.ID, domain.ID)
+	switch {
+	case errors.Is(err, repositories.ErrDomainNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+		return
+	case errors.Is(err, repositories.ErrDomainInUse):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Domain has links",
+			"message": "Delete the domain's links first",
+		})
+		return
+	case err != nil:
+		log.Printf("Failed to delete domain %s of workspace %d: %v", domain.Hostname, workspace.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete domain"})
+		return
+	}
+
+	invalidateCachedDomain(c, domain.Hostname)
+
+	log.Printf("Domain %s removed from workspace %d", domain.Hostname, workspace.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Domain deleted successfully",
+		"id":      domain.ID,
+	})
+}
+
+// loadDomain reads the workspace and the domain named by the domain_id path
+// parameter. When it returns false a response has already been written.
+func loadDomain(c *gin.Context) (models.Workspace, models.Domain, bool) {
+	domainID, err := strconv.Atoi(c.Param("domain_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
+		return models.Workspace{}, models.Domain{}, false
+	}
+
+	workspace, ok := middleware.CurrentWorkspace(c)
+	if !ok {
+		return models.Workspace{}, models.Domain{}, false
+	}
+
+	repo, ok := middleware.DomainRepositoryFrom(c)
+	if !ok {
+		return models.Workspace{}, models.Domain{}, false
+	}
+
+	domain, err := repo.GetDomain(workspace.ID, domainID)
+	if errors.Is(err, repositories.ErrDomainNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+		return models.Workspace{}, models.Domain{}, false
+	}
+	if err != nil {
+		log.Printf("Failed to load domain %d of workspace %d: %v", domainID, workspace.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch domain"})
+		return models.Workspace{}, models.Domain{}, false
+	}
+	return workspace, domain, true
+}
+
+// domainResponse converts domain for responses, adding the verification
+// record while the domain is unverified
+func domainResponse(domain models.Domain) models.DomainResponse {
+	response := domain.ToResponse()
+	if !domain.VerifiedAt.Valid {
+		record := domains.Record(domain)
+		response.VerificationRecord = &record
+	}
+	return response
+}
+
+// invalidateCachedDomain drops hostname from the domain cache, logging failures
+func invalidateCachedDomain(c *gin.Context, hostname string) {
+	if err := redis.InvalidateDomain(c.Request.Context(), hostname); err != nil {
+		log.Printf("Failed to invalidate cached domain %s: %v", hostname, err)
+	}
+}
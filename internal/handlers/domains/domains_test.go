@@ -0,0 +1,115 @@
+package domains
+
+import (
+	"database/sql"
+	"link-guardian/internal/handlers/middleware"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories/memory"
+	"link-guardian/internal/repositories/repotest"
+	"link-guardian/internal/services/domains"
+	"link-guardian/internal/testutil/handlertest"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newTestRouter serves the domain routes with the same middleware as the server
+func newTestRouter(t *testing.T, store *memory.Store, resolver domains.Resolver) *gin.Engine {
+	t.Helper()
+
+	service, err := domains.NewService(resolver, domains.Options{PublicBaseURL: "https://sho.rt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := handlertest.NewRouter(store)
+	router.Use(middleware.DomainsMiddleware(service))
+
+	viewer := middleware.RequireWorkspaceRole(models.WorkspaceRoleViewer)
+	owner := middleware.RequireWorkspaceRole(models.WorkspaceRoleOwner)
+
+	protected := router.Group("")
+	protected.Use(middleware.JWTAuthMiddleware())
+	protected.GET("/workspaces/:workspace_id/domains", viewer, ListDomainsHandler)
+	protected.POST("/workspaces/:workspace_id/domains", owner, CreateDomainHandler)
+	protected.POST("/workspaces/:workspace_id/domains/:domain_id/verify", owner, VerifyDomainHandler)
+	protected.DELETE("/workspaces/:workspace_id/domains/:domain_id", owner, DeleteDomainHandler)
+	return router
+}
+
+func TestDomainVerificationFlow(t *testing.T) {
+	store := memory.NewStore()
+	resolver := domains.StaticResolver{}
+	router := newTestRouter(t, store, resolver)
+	ownerID := repotest.CreateUser(t, store)
+	outsiderID := repotest.CreateUser(t, store)
+	workspace, err := store.GetPersonalWorkspace(ownerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := "/workspaces/" + strconv.Itoa(workspace.ID) + "/domains"
+
+	for name, body := range map[string]string{
+		"invalid hostname": `{"hostname":"not a hostname"}`,
+		"default domain":   `{"hostname":"sho.rt"}`,
+	} {
+		if w := handlertest.Request(t, router, ownerID, http.MethodPost, base, body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: got %d, want 400", name, w.Code)
+		}
+	}
+	if w := handlertest.Request(t, router, outsiderID, http.MethodPost, base, `{"hostname":"go.example.com"}`); w.Code != http.StatusNotFound {
+		t.Errorf("create by a non-member: got %d, want 404", w.Code)
+	}
+
+	w := handlertest.Request(t, router, ownerID, http.MethodPost, base, `{"hostname":"Go.Example.com"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: got %d: %s", w.Code, w.Body)
+	}
+	var created struct {
+		Domain models.DomainResponse `json:"domain"`
+	}
+	handlertest.Decode(t, w, &created)
+	record := created.Domain.VerificationRecord
+	if created.Domain.Hostname != "go.example.com" || created.Domain.Verified || record == nil || record.Name != "_link-guardian.go.example.com" {
+		t.Fatalf("create response = %s", w.Body)
+	}
+	if w := handlertest.Request(t, router, ownerID, http.MethodPost, base, `{"hostname":"go.example.com"}`); w.Code != http.StatusConflict {
+		t.Errorf("duplicate hostname: got %d, want 409", w.Code)
+	}
+
+	domainPath := base + "/" + strconv.Itoa(created.Domain.ID)
+	if w := handlertest.Request(t, router, ownerID, http.MethodPost, domainPath+"/verify", ""); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("verify without the record: got %d, want 422", w.Code)
+	}
+
+	resolver[record.Name] = []string{record.Value}
+	w = handlertest.Request(t, router, ownerID, http.MethodPost, domainPath+"/verify", "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"verified":true`) {
+		t.Fatalf("verify: got %d: %s", w.Code, w.Body)
+	}
+
+	w = handlertest.Request(t, router, ownerID, http.MethodGet, base, "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"count":1`) || strings.Contains(w.Body.String(), "verification_record") {
+		t.Errorf("list: got %d: %s", w.Code, w.Body)
+	}
+
+	// Domains serving links cannot be deleted
+	link := repotest.CreateLink(t, store, ownerID, func(l *models.Link) {
+		l.DomainID = sql.NullInt32{Int32: int32(created.Domain.ID), Valid: true}
+	})
+	if w := handlertest.Request(t, router, ownerID, http.MethodDelete, domainPath, ""); w.Code != http.StatusConflict {
+		t.Errorf("delete with links: got %d, want 409", w.Code)
+	}
+	if err := store.SoftDeleteLink(created.Domain.ID, link.Slug, ownerID); err != nil {
+		t.Fatal(err)
+	}
+	if w := handlertest.Request(t, router, ownerID, http.MethodDelete, domainPath, ""); w.Code != http.StatusOK {
+		t.Errorf("delete: got %d: %s", w.Code, w.Body)
+	}
+	if w := handlertest.Request(t, router, ownerID, http.MethodDelete, domainPath, ""); w.Code != http.StatusNotFound {
+		t.Errorf("second delete: got %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,27 @@
+package domains
+
+import (
+	"link-guardian/internal/services/domains"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// domainServiceFrom reads the domain service injected by DomainsMiddleware
+func domainServiceFrom(c *gin.Context) (*domains.Service, bool) {
+	service, exists := c.Get("domains")
+	if !exists {
+		log.Printf("Domain service not found in context for request from IP %s", c.ClientIP())
+		respondServiceUnavailable(c)
+		return nil, false
+	}
+	return service.(*domains.Service), true
+}
+
+func respondServiceUnavailable(c *gin.Context) {
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error":   "Service unavailable",
+		"message": "Please try again later",
+	})
+}
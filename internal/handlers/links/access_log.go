@@ -0,0 +1,50 @@
+package links
+
+import (
+	"errors"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"link-guardian/internal/services/clicklog"
+	"link-guardian/internal/services/useragent"
+	"log"
+
+	"github.com/gin-gonic/gin"
+)
+
+// recordAccess queues an access log entry on the click log pipeline. Once the
+// pipeline is closed the entry is written synchronously, and without a
+// pipeline it goes straight to the access log repository.
+func recordAccess(c *gin.Context, linkID int, eventType string) {
+	entry := models.AccessLog{
+		LinkID:    int64(linkID),
+		EventType: eventType,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Referer:   c.Request.Referer(),
+	}
+
+	if pipelineValue, exists := c.Get("clickLog"); exists {
+		pipeline := pipelineValue.(*clicklog.Pipeline)
+		err := pipeline.Enqueue(c.Request.Context(), entry)
+		if errors.Is(err, clicklog.ErrClosed) {
+			err = pipeline.WriteNow(entry)
+		}
+		if err != nil {
+			// Never hold up the response for analytics
+			log.Printf("Dropped %s event for link %d: %v", eventType, linkID, err)
+		}
+		return
+	}
+
+	repo, exists := c.Get("accessLogRepository")
+	if !exists {
+		log.Printf("Dropped %s event for link %d: no access log repository", eventType, linkID)
+		return
+	}
+
+	entry.DeviceType, entry.Browser, entry.OS = useragent.Parse(entry.UserAgent)
+	if err := repo.(repositories.AccessLogRepository).InsertAccessLogs([]models.AccessLog{entry}); err != nil {
+		// Do not block the response on logging failures
+		c.Error(err)
+	}
+}
@@ -3,10 +3,17 @@ package links
 import (
 	"crypto/rand"
 	"database/sql"
+	"errors"
 	"fmt"
+	"link-guardian/internal/handlers/middleware"
 	"link-guardian/internal/models"
-	"link-guardian/internal/repositories/db"
+	"link-guardian/internal/repositories"
+	authService "link-guardian/internal/services/auth"
+	"link-guardian/internal/services/domains"
+	"link-guardian/internal/services/slugs"
+	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -28,18 +35,52 @@ func CreateLinkHandler(c *gin.Context) {
 	}
 
 	// Get the user ID from JWT context
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(401, gin.H{"error": "User ID not found in token"})
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
-	slug, err := generateUniqueSlug(8)
-	if err != nil {
-		c.JSON(500, gin.H{"error": "Failed to generate unique slug"})
+	workspace, ok := middleware.CurrentWorkspace(c)
+	if !ok {
+		return
+	}
+
+	repo, ok := middleware.LinkRepositoryFrom(c)
+	if !ok {
 		return
 	}
 
+	var domain models.Domain
+	if req.Domain != "" {
+		domain, ok = workspaceDomain(c, workspace, req.Domain)
+		if !ok {
+			return
+		}
+	}
+
+	var slug string
+	var policy *slugs.Policy
+	var err error
+	if req.Slug != "" {
+		var ok bool
+		policy, ok = slugPolicyFrom(c)
+		if !ok {
+			return
+		}
+
+		if err := policy.Validate(req.Slug); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid slug: " + err.Error()})
+			return
+		}
+		slug = req.Slug
+	} else {
+		slug, err = generateUniqueSlug(repo, domain.ID, 8)
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Failed to generate unique slug"})
+			return
+		}
+	}
+
 	now := time.Now()
 	link := models.Link{
 		Slug:       slug,
@@ -47,9 +88,18 @@ func CreateLinkHandler(c *gin.Context) {
 		CreatedAt:  now,
 		ClickCount: 0,
 		UserID: sql.NullInt32{
-			Int32: int32(userID.(float64)), // Convert interface{} to int32
+			Int32: int32(userID),
 			Valid: true,
 		},
+		WorkspaceID: sql.NullInt32{Int32: int32(workspace.ID), Valid: true},
+		Tags:        models.NormalizeTags(req.Tags),
+	}
+	if folder := strings.TrimSpace(req.Folder); folder != "" {
+		link.Folder = sql.NullString{String: folder, Valid: true}
+	}
+	if domain.ID != 0 {
+		link.DomainID = sql.NullInt32{Int32: int32(domain.ID), Valid: true}
+		link.Domain = sql.NullString{String: domain.Hostname, Valid: true}
 	}
 
 	if req.ExpiresAt != nil {
@@ -66,7 +116,37 @@ func CreateLinkHandler(c *gin.Context) {
 		}
 	}
 
-	err = db.InsertLinktoDB(link.Slug, link.TargetURL, link.CreatedAt, link.ExpiresAt, link.ClickLimit, link.ClickCount, link.UserID)
+	if req.Password != nil {
+		authSvc, exists := c.Get("authService")
+		if !exists {
+			c.JSON(500, gin.H{"error": "Service unavailable"})
+			return
+		}
+		authService := authSvc.(*authService.AuthService)
+
+		if err := authService.ValidatePassword(*req.Password); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid password: " + err.Error()})
+			return
+		}
+
+		passwordHash, err := authService.HashPassword(*req.Password)
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Failed to secure link password"})
+			return
+		}
+		link.PasswordHash = sql.NullString{String: passwordHash, Valid: true}
+	}
+
+	link, err = repo.CreateLink(link)
+
+	if errors.Is(err, repositories.ErrSlugTaken) && policy != nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":       "Slug is already taken",
+			"slug":        slug,
+			"suggestions": suggestSlugs(repo, policy, domain.ID, slug),
+		})
+		return
+	}
 
 	if err != nil {
 		fmt.Println("Error inserting link into database:", err)
@@ -74,22 +154,56 @@ func CreateLinkHandler(c *gin.Context) {
 		return
 	}
 
-	scheme := "http"
-	if c.Request.TLS != nil {
-		scheme = "https"
-	}
-	host := c.Request.Host
-	shortURL := fmt.Sprintf("%s://%s/l/%s", scheme, host, slug)
+	// Drop any cached "not found" entry for the new slug
+	invalidateCachedLink(c, domain.ID, slug)
 
 	c.JSON(http.StatusCreated, gin.H{
 		"message":   "Link generated successfully",
-		"short_url": shortURL,
+		"short_url": shortURL(c, link),
 		"link":      link.ToResponse(),
 	})
 }
 
-func generateUniqueSlug(length int) (string, error) {
-	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_"
+// workspaceDomain looks up the verified custom domain hostname of workspace.
+// When it returns false a response has already been written.
+func workspaceDomain(c *gin.Context, workspace models.Workspace, hostname string) (models.Domain, bool) {
+	domainRepo, ok := middleware.DomainRepositoryFrom(c)
+	if !ok {
+		return models.Domain{}, false
+	}
+
+	domain, err := domainRepo.GetVerifiedDomainByHostname(domains.NormalizeHostname(hostname))
+	if errors.Is(err, repositories.ErrDomainNotFound) || (err == nil && domain.WorkspaceID != workspace.ID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Domain is not a verified domain of this workspace"})
+		return models.Domain{}, false
+	}
+	if err != nil {
+		log.Printf("Failed to look up domain %s: %v", hostname, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create link"})
+		return models.Domain{}, false
+	}
+	return domain, true
+}
+
+// shortURL builds the public redirect URL of link. Without a configured
+// public base URL, links on the default domain use the current request's host.
+func shortURL(c *gin.Context, link models.Link) string {
+	if service, exists := c.Get("domains"); exists {
+		if url := service.(*domains.Service).ShortURL(link); url != "" {
+			return url
+		}
+	}
+
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	host := c.Request.Host
+	return fmt.Sprintf("%s://%s/l/%s", scheme, host, link.Slug)
+}
+
+func generateUniqueSlug(repo repositories.LinkRepository, domainID int, length int) (string, error) {
+	const charset = slugs.Charset
 	const maxAttempts = 10
 	for attempt := 0; attempt < maxAttempts; attempt++ {
 		b := make([]byte, length)
@@ -99,10 +213,9 @@ func generateUniqueSlug(length int) (string, error) {
 		for i := 0; i < length; i++ {
 			b[i] = charset[int(b[i])%len(charset)]
 		}
-		slug := string(b)
-		uniqueSlug, err := db.CheckIfSlugUnique(slug)
-		if err == nil {
-			return uniqueSlug, nil
+		available, err := repo.FilterAvailableSlugs(domainID, []string{string(b)})
+		if err == nil && len(available) == 1 {
+			return available[0], nil
 		}
 	}
 	return "", fmt.Errorf("failed to generate unique slug after %d attempts", maxAttempts)
@@ -1,7 +1,9 @@
 package links
 
 import (
-	"link-guardian/internal/repositories/db"
+	"errors"
+	"link-guardian/internal/handlers/middleware"
+	"link-guardian/internal/repositories"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -14,21 +16,23 @@ func DeleteLinkHandler(c *gin.Context) {
 		return
 	}
 
-	userIDInterface, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
-	// Convert the user ID to int
-	userID := int(userIDInterface.(float64))
+	repo, ok := middleware.LinkRepositoryFrom(c)
+	if !ok {
+		return
+	}
 
-	err := db.SoftDeleteLink(slug, userID)
+	domainID := middleware.LinkDomainFrom(c).ID
+	err := repo.SoftDeleteLink(domainID, slug, userID)
 	if err != nil {
-		if err.Error() == "link not found or already deleted" {
+		if errors.Is(err, repositories.ErrLinkNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Link not found or already deleted"})
 			return
-		} else if err.Error() == "unauthorized: link belongs to a different user" {
+		} else if errors.Is(err, repositories.ErrLinkForbidden) {
 			c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to delete this link"})
 			return
 		}
@@ -36,6 +40,8 @@ func DeleteLinkHandler(c *gin.Context) {
 		return
 	}
 
+	invalidateCachedLink(c, domainID, slug)
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Link deleted successfully",
 		"slug":    slug,
@@ -1,7 +1,12 @@
 package links
 
 import (
-	"link-guardian/internal/repositories/db"
+	"errors"
+	"link-guardian/internal/handlers/middleware"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"link-guardian/internal/repositories/redis"
+	"log"
 	"net/http"
 	"time"
 
@@ -15,39 +20,136 @@ func GetLinkHandler(c *gin.Context) {
 		return
 	}
 
-	link, err := db.GetLinkBySlug(slug)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Link not found"})
+	repo, ok := middleware.LinkRepositoryFrom(c)
+	if !ok {
 		return
 	}
 
-	if link.ExpiresAt.Valid && link.ExpiresAt.Time.Before(time.Now()) {
-		c.JSON(http.StatusGone, gin.H{"error": "Link has expired"})
+	link, ok := resolveRedirect(c, repo, middleware.LinkDomainFrom(c), slug)
+	if !ok {
 		return
 	}
 
-	if link.ClickLimit.Valid && int(link.ClickLimit.Int32) <= link.ClickCount {
-		c.JSON(http.StatusGone, gin.H{"error": "Link has reached its maximum number of clicks"})
+	// Log the access for analytics off the redirect path
+	recordAccess(c, link.ID, models.EventClick)
+
+	// Perform the redirect
+	c.Redirect(http.StatusFound, link.TargetURL)
+}
+
+// CustomDomainLinkHandler serves links at the root of custom domains, so
+// https://go.example.com/abc redirects like /l/abc does. The default domain
+// only serves links under /l/.
+func CustomDomainLinkHandler(c *gin.Context) {
+	if middleware.LinkDomainFrom(c).ID == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
 		return
 	}
+	GetLinkHandler(c)
+}
 
-	err = db.IncrementClickCount(slug)
+// resolveRedirect decides whether slug may be followed on domain and counts
+// the click. Unknown, expired and locked links are answered from the slug
+// cache; links with a click limit or password always go through the atomic
+// ConsumeClick. When it returns false a response has already been written.
+func resolveRedirect(c *gin.Context, repo repositories.LinkRepository, domain models.Domain, slug string) (models.Link, bool) {
+	cached, found, err := redis.ResolveLink(c.Request.Context(), domain.ID, slug, cacheLoader(repo))
 	if err != nil {
-		c.Error(err)
+		log.Printf("Failed to resolve link %s: %v", slug, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve link"})
+		return models.Link{}, false
 	}
 
-	// Log the access for analytics
-	ipAddress := c.ClientIP()
-	userAgent := c.Request.UserAgent()
-	referer := c.Request.Referer()
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Link not found"})
+		return models.Link{}, false
+	}
+
+	if cached.TakenDownAt.Valid {
+		respondTakenDown(c)
+		return models.Link{}, false
+	}
+
+	if cached.ExpiresAt.Valid && !cached.ExpiresAt.Time.After(time.Now()) {
+		c.JSON(http.StatusGone, gin.H{"error": "Link has expired"})
+		return models.Link{}, false
+	}
+
+	unlocked := false
+	if cached.PasswordHash.Valid {
+		if unlocked = isUnlocked(c, domain, slug); !unlocked {
+			renderUnlockPage(c, http.StatusOK, unlockPage{Slug: cached.Slug})
+			return models.Link{}, false
+		}
+	}
 
-	// Log the access event to the database using the db package
-	err = db.LogAccessWithDetails(int64(link.ID), ipAddress, userAgent, referer)
+	if !cached.ClickLimit.Valid && !cached.PasswordHash.Valid {
+		counted, err := repo.IncrementClickCount(cached.ID)
+		if err != nil {
+			log.Printf("Failed to count click on link %s: %v", slug, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve link"})
+			return models.Link{}, false
+		}
+		if counted {
+			return cached, true
+		}
+
+		// The cached entry is stale; drop it and use the authoritative path
+		invalidateCachedLink(c, domain.ID, slug)
+	}
+
+	// Check expiry, click limit and password protection and count the click in one atomic step
+	link, outcome, err := repo.ConsumeClick(domain.ID, slug, unlocked || isUnlocked(c, domain, slug))
 	if err != nil {
-		// Optionally log this error, but do not block redirect
-		c.Error(err)
+		log.Printf("Failed to consume click on link %s: %v", slug, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve link"})
+		return models.Link{}, false
 	}
 
-	// Perform the redirect
-	c.Redirect(http.StatusFound, link.TargetURL)
+	switch outcome {
+	case models.ClickNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Link not found"})
+		return models.Link{}, false
+	case models.ClickTakenDown:
+		respondTakenDown(c)
+		return models.Link{}, false
+	case models.ClickExpired:
+		c.JSON(http.StatusGone, gin.H{"error": "Link has expired"})
+		return models.Link{}, false
+	case models.ClickExhausted:
+		c.JSON(http.StatusGone, gin.H{"error": "Link has reached its maximum number of clicks"})
+		return models.Link{}, false
+	case models.ClickLocked:
+		renderUnlockPage(c, http.StatusOK, unlockPage{Slug: link.Slug})
+		return models.Link{}, false
+	}
+
+	return link, true
+}
+
+// respondTakenDown answers requests for links an admin has taken down. The
+// reason is only shown to the owner and admins.
+func respondTakenDown(c *gin.Context) {
+	c.JSON(http.StatusGone, gin.H{"error": "Link has been taken down"})
+}
+
+// cacheLoader loads active links from repo for the slug cache
+func cacheLoader(repo repositories.LinkRepository) redis.LinkLoader {
+	return func(domainID int, slug string) (models.Link, bool, error) {
+		link, err := repo.GetLinkBySlug(domainID, slug)
+		if errors.Is(err, repositories.ErrLinkNotFound) {
+			return models.Link{}, false, nil
+		}
+		if err != nil {
+			return models.Link{}, false, err
+		}
+		return link, true, nil
+	}
+}
+
+// invalidateCachedLink drops slug on the domain from the slug cache, logging failures
+func invalidateCachedLink(c *gin.Context, domainID int, slug string) {
+	if err := redis.InvalidateLink(c.Request.Context(), domainID, slug); err != nil {
+		log.Printf("Failed to invalidate cached link %s: %v", slug, err)
+	}
 }
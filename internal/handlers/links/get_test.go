@@ -0,0 +1,117 @@
+package links
+
+import (
+	"database/sql"
+	"link-guardian/internal/handlers/middleware"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories/db"
+	"link-guardian/internal/repositories/memory"
+	"link-guardian/internal/repositories/repotest"
+	"link-guardian/internal/testutil/pgtest"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetLinkHandlerRespectsClickLimitUnderConcurrency(t *testing.T) {
+	store := db.NewStore(pgtest.Open(t))
+	userID := repotest.CreateUser(t, store)
+
+	const limit = 5
+	const requests = 30
+
+	link := repotest.CreateLink(t, store, userID, func(link *models.Link) {
+		link.TargetURL = "https://example.com/target"
+		link.ClickLimit = sql.NullInt32{Int32: limit, Valid: true}
+	})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.RepositoriesMiddleware(store))
+	router.GET("/l/:slug", GetLinkHandler)
+
+	var mu sync.Mutex
+	statuses := map[int]int{}
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/l/"+link.Slug, nil))
+			mu.Lock()
+			statuses[w.Code]++
+			mu.Unlock()
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if statuses[http.StatusFound] != limit {
+		t.Errorf("got %d redirects, want exactly %d (statuses: %v)", statuses[http.StatusFound], limit, statuses)
+	}
+	if statuses[http.StatusGone] != requests-limit {
+		t.Errorf("got %d gone responses, want %d (statuses: %v)", statuses[http.StatusGone], requests-limit, statuses)
+	}
+}
+
+func TestGetLinkHandlerRedirectsAndLogsClicks(t *testing.T) {
+	store := memory.NewStore()
+	router := newTestRouter(store)
+	userID := repotest.CreateUser(t, store)
+	link := repotest.CreateLink(t, store, userID, func(link *models.Link) {
+		link.TargetURL = "https://example.com/target"
+		link.ClickLimit = sql.NullInt32{Int32: 1, Valid: true}
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/l/"+link.Slug, nil)
+	req.Header.Set("User-Agent", "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) Mobile Safari")
+	w := serve(router, req)
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "https://example.com/target" {
+		t.Fatalf("first click: got %d to %q", w.Code, w.Header().Get("Location"))
+	}
+
+	if w := serve(router, httptest.NewRequest(http.MethodGet, "/l/"+link.Slug, nil)); w.Code != http.StatusGone {
+		t.Errorf("click past the limit: got %d, want 410", w.Code)
+	}
+	if w := serve(router, httptest.NewRequest(http.MethodGet, "/l/missing", nil)); w.Code != http.StatusNotFound {
+		t.Errorf("unknown slug: got %d, want 404", w.Code)
+	}
+
+	// Without a click log pipeline the access is written straight to the repository
+	logs, err := store.GetAccessLogsByLink(link.ID, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 1 || logs[0].EventType != models.EventClick || logs[0].DeviceType != "mobile" {
+		t.Errorf("access logs = %+v, want one mobile click", logs)
+	}
+}
+
+func TestGetLinkHandlerRejectsTakenDownLinks(t *testing.T) {
+	store := memory.NewStore()
+	router := newTestRouter(store)
+	userID := repotest.CreateUser(t, store)
+	link := repotest.CreateLink(t, store, userID, nil)
+
+	if _, err := store.TakeDownLink(0, link.Slug, userID, "phishing"); err != nil {
+		t.Fatal(err)
+	}
+	w := serve(router, httptest.NewRequest(http.MethodGet, "/l/"+link.Slug, nil))
+	if w.Code != http.StatusGone || strings.Contains(w.Body.String(), "phishing") {
+		t.Errorf("taken down link: got %d: %s, want 410 without the reason", w.Code, w.Body)
+	}
+
+	if _, err := store.RestoreLink(0, link.Slug); err != nil {
+		t.Fatal(err)
+	}
+	if w := serve(router, httptest.NewRequest(http.MethodGet, "/l/"+link.Slug, nil)); w.Code != http.StatusFound {
+		t.Errorf("restored link: got %d, want 302", w.Code)
+	}
+}
@@ -0,0 +1,103 @@
+package links
+
+import (
+	"link-guardian/internal/handlers/middleware"
+	"link-guardian/internal/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LinkHistoryHandler lists the recorded changes of a link, newest first
+func LinkHistoryHandler(c *gin.Context) {
+	slug := c.Param("slug")
+	if slug == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Slug is required"})
+		return
+	}
+
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	repo, ok := middleware.LinkRepositoryFrom(c)
+	if !ok {
+		return
+	}
+
+	link, err := repo.GetMemberLinkBySlug(middleware.LinkDomainFrom(c).ID, slug, userID, models.WorkspaceRoleViewer)
+	if err != nil {
+		respondLinkError(c, err, "You do not have permission to view this link's history", "Failed to fetch link history")
+		return
+	}
+
+	revisions, err := repo.GetLinkRevisions(link.ID)
+	if err != nil {
+		respondLinkError(c, err, "", "Failed to fetch link history")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"slug":      link.Slug,
+		"revisions": revisions,
+		"count":     len(revisions),
+	})
+}
+
+// RollbackLinkHandler restores a link to the values it had before the given
+// revision. The rollback is itself recorded as a new revision.
+func RollbackLinkHandler(c *gin.Context) {
+	slug := c.Param("slug")
+	if slug == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Slug is required"})
+		return
+	}
+
+	revisionID, err := strconv.ParseInt(c.Param("revision_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision ID"})
+		return
+	}
+
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	repo, ok := middleware.LinkRepositoryFrom(c)
+	if !ok {
+		return
+	}
+
+	domainID := middleware.LinkDomainFrom(c).ID
+	link, err := repo.GetMemberLinkBySlug(domainID, slug, userID, models.WorkspaceRoleEditor)
+	if err != nil {
+		respondLinkError(c, err, "You do not have permission to roll back this link", "Failed to roll back link")
+		return
+	}
+
+	revision, err := repo.GetLinkRevision(link.ID, revisionID)
+	if err != nil {
+		respondLinkError(c, err, "", "Failed to roll back link")
+		return
+	}
+
+	restore := func(models.LinkState) models.LinkState { return revision.OldValues }
+	link, rollback, err := repo.UpdateLink(domainID, slug, userID, models.RevisionActionRollback, &revision.ID, restore, models.LinkOrganization{})
+	if err != nil {
+		respondLinkError(c, err, "You do not have permission to roll back this link", "Failed to roll back link")
+		return
+	}
+
+	if rollback != nil {
+		invalidateCachedLink(c, domainID, slug)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Link rolled back successfully",
+		"link":     link.ToResponse(),
+		"revision": rollback,
+	})
+}
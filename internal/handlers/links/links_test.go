@@ -0,0 +1,445 @@
+package links
+
+import (
+	"link-guardian/internal/handlers/middleware"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"link-guardian/internal/repositories/memory"
+	"link-guardian/internal/repositories/repotest"
+	"link-guardian/internal/services/domains"
+	"link-guardian/internal/services/slugs"
+	"link-guardian/internal/services/verification"
+	"link-guardian/internal/testutil/handlertest"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newTestRouter serves the link routes from store with the same middleware as the server
+func newTestRouter(store repositories.Store) *gin.Engine {
+	router := handlertest.NewRouter(store)
+	slugPolicy, err := slugs.NewPolicy(3, 64, []string{"admin"})
+	if err != nil {
+		panic(err)
+	}
+	router.Use(middleware.SlugPolicyMiddleware(slugPolicy))
+	domainService, err := domains.NewService(domains.StaticResolver{}, domains.Options{})
+	if err != nil {
+		panic(err)
+	}
+	router.Use(middleware.DomainsMiddleware(domainService))
+
+	router.GET("/l/:slug", middleware.LinkDomainFromHost(), GetLinkHandler)
+	router.GET("/:slug", middleware.LinkDomainFromHost(), CustomDomainLinkHandler)
+	linkDomain := middleware.LinkDomainFromQuery()
+
+	protected := router.Group("")
+	protected.Use(middleware.JWTAuthMiddleware())
+	protected.POST("/links", middleware.RequireWorkspaceRole(models.WorkspaceRoleEditor), CreateLinkHandler)
+	protected.GET("/links", middleware.RequireWorkspaceRole(models.WorkspaceRoleViewer), ListLinksHandler)
+	protected.GET("/links/slug-availability", linkDomain, SlugAvailabilityHandler)
+	protected.PATCH("/links/:slug", linkDomain, UpdateLinkHandler)
+	protected.DELETE("/links/:slug", linkDomain, DeleteLinkHandler)
+	protected.GET("/links/:slug/history", linkDomain, LinkHistoryHandler)
+	protected.POST("/links/:slug/history/:revision_id/rollback", linkDomain, RollbackLinkHandler)
+	protected.GET("/tags", middleware.RequireWorkspaceRole(models.WorkspaceRoleViewer), ListTagsHandler)
+	protected.PATCH("/tags/:tag_id", middleware.RequireWorkspaceRole(models.WorkspaceRoleEditor), RenameTagHandler)
+	protected.POST("/tags/:tag_id/merge", middleware.RequireWorkspaceRole(models.WorkspaceRoleEditor), MergeTagHandler)
+	protected.GET("/folders", middleware.RequireWorkspaceRole(models.WorkspaceRoleViewer), ListFoldersHandler)
+	return router
+}
+
+func serve(router http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// authorized builds a request carrying a token for userID
+func authorized(t *testing.T, userID int, method, path, body string) *http.Request {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+handlertest.Token(t, userID))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestCreateLinkHandler(t *testing.T) {
+	store := memory.NewStore()
+	router := newTestRouter(store)
+	userID := repotest.CreateUser(t, store)
+
+	w := serve(router, authorized(t, userID, http.MethodPost, "/links", `{"target_url":"https://example.com","slug":"my-link","click_limit":3}`))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: got %d: %s", w.Code, w.Body)
+	}
+	var created struct {
+		ShortURL string `json:"short_url"`
+		Link     struct {
+			ID         int  `json:"id"`
+			ClickLimit *int `json:"click_limit"`
+		} `json:"link"`
+	}
+	handlertest.Decode(t, w, &created)
+	if created.Link.ID == 0 || created.ShortURL != "http://example.com/l/my-link" || *created.Link.ClickLimit != 3 {
+		t.Errorf("create response = %s", w.Body)
+	}
+
+	link, err := store.GetMemberLinkBySlug(0, "my-link", userID, models.WorkspaceRoleOwner)
+	if err != nil || link.TargetURL != "https://example.com" {
+		t.Errorf("stored link = %+v, %v", link, err)
+	}
+
+	w = serve(router, authorized(t, userID, http.MethodPost, "/links", `{"target_url":"https://example.org","slug":"my-link"}`))
+	if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), "suggestions") {
+		t.Errorf("duplicate slug: got %d: %s", w.Code, w.Body)
+	}
+
+	w = serve(router, authorized(t, userID, http.MethodPost, "/links", `{"target_url":"https://example.org"}`))
+	if w.Code != http.StatusCreated {
+		t.Errorf("generated slug: got %d: %s", w.Code, w.Body)
+	}
+
+	for name, body := range map[string]string{
+		"invalid URL":   `{"target_url":"not a url"}`,
+		"reserved slug": `{"target_url":"https://example.com","slug":"admin"}`,
+	} {
+		if w := serve(router, authorized(t, userID, http.MethodPost, "/links", body)); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: got %d, want 400", name, w.Code)
+		}
+	}
+
+	if w := serve(router, httptest.NewRequest(http.MethodPost, "/links", strings.NewReader(`{}`))); w.Code != http.StatusUnauthorized {
+		t.Errorf("without token: got %d, want 401", w.Code)
+	}
+}
+
+func TestListLinksHandlerOnlyReturnsOwnLinks(t *testing.T) {
+	store := memory.NewStore()
+	router := newTestRouter(store)
+	userID := repotest.CreateUser(t, store)
+	own := repotest.CreateLink(t, store, userID, nil)
+	repotest.CreateLink(t, store, repotest.CreateUser(t, store), nil)
+
+	w := serve(router, authorized(t, userID, http.MethodGet, "/links", ""))
+	var body struct {
+		Links []struct {
+			Slug string `json:"slug"`
+		} `json:"links"`
+	}
+	handlertest.Decode(t, w, &body)
+	if w.Code != http.StatusOK || len(body.Links) != 1 || body.Links[0].Slug != own.Slug {
+		t.Errorf("list: got %d: %s", w.Code, w.Body)
+	}
+}
+
+func TestListLinksHandlerPages(t *testing.T) {
+	store := memory.NewStore()
+	router := newTestRouter(store)
+	userID := repotest.CreateUser(t, store)
+	for i := 0; i < 3; i++ {
+		repotest.CreateLink(t, store, userID, func(l *models.Link) { l.TargetURL = "https://example.com/page" })
+	}
+	repotest.CreateLink(t, store, userID, func(l *models.Link) { l.TargetURL = "https://example.org" })
+
+	type listBody struct {
+		Links      []models.LinkResponse `json:"links"`
+		Total      int                   `json:"total"`
+		NextCursor *string               `json:"next_cursor"`
+	}
+	var seen []int
+	path := "/links?q=PAGE&sort=click_count&limit=2"
+	for pages := 0; pages < 3; pages++ {
+		w := serve(router, authorized(t, userID, http.MethodGet, path, ""))
+		var body listBody
+		handlertest.Decode(t, w, &body)
+		if w.Code != http.StatusOK || body.Total != 3 {
+			t.Fatalf("list: got %d: %s", w.Code, w.Body)
+		}
+		for _, link := range body.Links {
+			seen = append(seen, link.ID)
+		}
+		if body.NextCursor == nil {
+			break
+		}
+		path = "/links?q=PAGE&sort=click_count&limit=2&cursor=" + *body.NextCursor
+	}
+	if len(seen) != 3 || seen[0] == seen[1] || seen[1] == seen[2] {
+		t.Errorf("paged through links %v, want the three matches once each", seen)
+	}
+
+	for name, query := range map[string]string{
+		"unknown status":     "status=broken",
+		"unknown sort":       "sort=slug",
+		"unknown order":      "order=up",
+		"invalid date":       "created_from=yesterday",
+		"invalid flag":       "has_click_limit=maybe",
+		"invalid cursor":     "cursor=abc",
+		"cursor of a sort":   "sort=expires_at&cursor=" + models.LinkSort{Field: models.LinkSortCreatedAt}.CursorAt(models.Link{ID: 1}).Encode(),
+		"cursor of an order": "order=asc&cursor=" + models.LinkSort{Field: models.LinkSortCreatedAt, Desc: true}.CursorAt(models.Link{ID: 1}).Encode(),
+	} {
+		if w := serve(router, authorized(t, userID, http.MethodGet, "/links?"+query, "")); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: got %d, want 400", name, w.Code)
+		}
+	}
+
+	w := serve(router, authorized(t, userID, http.MethodGet, "/links?status=expired&created_to=2000-01-01", ""))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"total":0`) || !strings.Contains(w.Body.String(), `"links":[]`) {
+		t.Errorf("empty list: got %d: %s", w.Code, w.Body)
+	}
+}
+
+func TestSharedWorkspaceLinks(t *testing.T) {
+	store := memory.NewStore()
+	router := newTestRouter(store)
+	ownerID := repotest.CreateUser(t, store)
+	editorID := repotest.CreateUser(t, store)
+	viewerID := repotest.CreateUser(t, store)
+	team, err := store.CreateWorkspace("Team", ownerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repotest.AddWorkspaceMember(t, store, team.ID, editorID, models.WorkspaceRoleEditor)
+	repotest.AddWorkspaceMember(t, store, team.ID, viewerID, models.WorkspaceRoleViewer)
+
+	inTeam := func(req *http.Request) *http.Request {
+		req.Header.Set(middleware.WorkspaceHeader, strconv.Itoa(team.ID))
+		return req
+	}
+
+	w := serve(router, inTeam(authorized(t, editorID, http.MethodPost, "/links", `{"target_url":"https://example.com","slug":"team-link"}`)))
+	if w.Code != http.StatusCreated || !strings.Contains(w.Body.String(), `"workspace_id":`+strconv.Itoa(team.ID)) {
+		t.Fatalf("create by an editor: got %d: %s", w.Code, w.Body)
+	}
+	if w := serve(router, inTeam(authorized(t, viewerID, http.MethodPost, "/links", `{"target_url":"https://example.com"}`))); w.Code != http.StatusForbidden {
+		t.Errorf("create by a viewer: got %d, want 403", w.Code)
+	}
+	if w := serve(router, inTeam(authorized(t, repotest.CreateUser(t, store), http.MethodGet, "/links", ""))); w.Code != http.StatusNotFound {
+		t.Errorf("list by a non-member: got %d, want 404", w.Code)
+	}
+
+	// Every member sees the link in the team, and nobody in their personal workspace
+	for _, userID := range []int{ownerID, editorID, viewerID} {
+		w := serve(router, inTeam(authorized(t, userID, http.MethodGet, "/links", "")))
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "team-link") {
+			t.Errorf("team list for user %d: got %d: %s", userID, w.Code, w.Body)
+		}
+		w = serve(router, authorized(t, userID, http.MethodGet, "/links", ""))
+		if w.Code != http.StatusOK || strings.Contains(w.Body.String(), "team-link") {
+			t.Errorf("personal list for user %d: got %d: %s", userID, w.Code, w.Body)
+		}
+	}
+
+	if w := serve(router, authorized(t, viewerID, http.MethodPatch, "/links/team-link", `{"target_url":"https://example.org"}`)); w.Code != http.StatusForbidden {
+		t.Errorf("update by a viewer: got %d, want 403", w.Code)
+	}
+	if w := serve(router, authorized(t, viewerID, http.MethodGet, "/links/team-link/history", "")); w.Code != http.StatusOK {
+		t.Errorf("history for a viewer: got %d: %s", w.Code, w.Body)
+	}
+	if w := serve(router, authorized(t, ownerID, http.MethodPatch, "/links/team-link", `{"target_url":"https://example.org"}`)); w.Code != http.StatusOK {
+		t.Errorf("update by the owner: got %d: %s", w.Code, w.Body)
+	}
+}
+
+func TestSlugAvailabilityHandler(t *testing.T) {
+	store := memory.NewStore()
+	router := newTestRouter(store)
+	userID := repotest.CreateUser(t, store)
+	taken := repotest.CreateLink(t, store, userID, nil)
+
+	tests := map[string]bool{taken.Slug: false, "free-slug": true}
+	for slug, want := range tests {
+		w := serve(router, authorized(t, userID, http.MethodGet, "/links/slug-availability?slug="+slug, ""))
+		var body struct {
+			Available bool `json:"available"`
+		}
+		handlertest.Decode(t, w, &body)
+		if w.Code != http.StatusOK || body.Available != want {
+			t.Errorf("%s: got %d: %s", slug, w.Code, w.Body)
+		}
+	}
+}
+
+func TestUpdateHistoryAndRollbackHandlers(t *testing.T) {
+	store := memory.NewStore()
+	router := newTestRouter(store)
+	userID := repotest.CreateUser(t, store)
+	link := repotest.CreateLink(t, store, userID, nil)
+	path := "/links/" + link.Slug
+
+	if w := serve(router, authorized(t, repotest.CreateUser(t, store), http.MethodPatch, path, `{"target_url":"https://evil.example"}`)); w.Code != http.StatusForbidden {
+		t.Errorf("update by another user: got %d, want 403", w.Code)
+	}
+	if w := serve(router, authorized(t, userID, http.MethodPatch, path, `{}`)); w.Code != http.StatusBadRequest {
+		t.Errorf("empty update: got %d, want 400", w.Code)
+	}
+
+	w := serve(router, authorized(t, userID, http.MethodPatch, path, `{"target_url":"https://example.com/new"}`))
+	var updated struct {
+		Revision struct {
+			ID int64 `json:"id"`
+		} `json:"revision"`
+	}
+	handlertest.Decode(t, w, &updated)
+	if w.Code != http.StatusOK || updated.Revision.ID == 0 {
+		t.Fatalf("update: got %d: %s", w.Code, w.Body)
+	}
+
+	w = serve(router, authorized(t, userID, http.MethodGet, path+"/history", ""))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"count":1`) {
+		t.Errorf("history: got %d: %s", w.Code, w.Body)
+	}
+
+	w = serve(router, authorized(t, userID, http.MethodPost, path+"/history/999/rollback", ""))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("rollback of unknown revision: got %d, want 404", w.Code)
+	}
+
+	rollbackPath := path + "/history/" + strconv.FormatInt(updated.Revision.ID, 10) + "/rollback"
+	if w := serve(router, authorized(t, userID, http.MethodPost, rollbackPath, "")); w.Code != http.StatusOK {
+		t.Fatalf("rollback: got %d: %s", w.Code, w.Body)
+	}
+	if restored, _ := store.GetLinkBySlug(0, link.Slug); restored.TargetURL != link.TargetURL {
+		t.Errorf("target after rollback = %q, want %q", restored.TargetURL, link.TargetURL)
+	}
+}
+
+func TestDeleteLinkHandler(t *testing.T) {
+	store := memory.NewStore()
+	router := newTestRouter(store)
+	userID := repotest.CreateUser(t, store)
+	link := repotest.CreateLink(t, store, userID, nil)
+	path := "/links/" + link.Slug
+
+	if w := serve(router, authorized(t, repotest.CreateUser(t, store), http.MethodDelete, path, "")); w.Code != http.StatusForbidden {
+		t.Errorf("delete by another user: got %d, want 403", w.Code)
+	}
+	if w := serve(router, authorized(t, userID, http.MethodDelete, path, "")); w.Code != http.StatusOK {
+		t.Errorf("delete: got %d: %s", w.Code, w.Body)
+	}
+	if w := serve(router, authorized(t, userID, http.MethodDelete, path, "")); w.Code != http.StatusNotFound {
+		t.Errorf("second delete: got %d, want 404", w.Code)
+	}
+	if w := serve(router, httptest.NewRequest(http.MethodGet, "/l/"+link.Slug, nil)); w.Code != http.StatusNotFound {
+		t.Errorf("redirect of deleted link: got %d, want 404", w.Code)
+	}
+}
+
+func TestCustomDomainLinks(t *testing.T) {
+	store := memory.NewStore()
+	router := newTestRouter(store)
+	userID := repotest.CreateUser(t, store)
+	workspace, err := store.GetPersonalWorkspace(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	domain := repotest.CreateVerifiedDomain(t, store, workspace.ID, userID)
+
+	otherID := repotest.CreateUser(t, store)
+	other, err := store.GetPersonalWorkspace(otherID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	foreign := repotest.CreateVerifiedDomain(t, store, other.ID, otherID)
+
+	if w := serve(router, authorized(t, userID, http.MethodPost, "/links", `{"target_url":"https://example.com/default","slug":"promo"}`)); w.Code != http.StatusCreated {
+		t.Fatalf("create on the default domain: got %d: %s", w.Code, w.Body)
+	}
+	w := serve(router, authorized(t, userID, http.MethodPost, "/links",
+		`{"target_url":"https://example.com/custom","slug":"promo","domain":"`+strings.ToUpper(domain.Hostname)+`"}`))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create on the custom domain: got %d: %s", w.Code, w.Body)
+	}
+	var created struct {
+		ShortURL string `json:"short_url"`
+		Link     struct {
+			Domain string `json:"domain"`
+		} `json:"link"`
+	}
+	handlertest.Decode(t, w, &created)
+	if created.ShortURL != "https://"+domain.Hostname+"/promo" || created.Link.Domain != domain.Hostname {
+		t.Errorf("create response = %s", w.Body)
+	}
+
+	w = serve(router, authorized(t, userID, http.MethodPost, "/links", `{"target_url":"https://example.com","domain":"`+foreign.Hostname+`"}`))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("create on another workspace's domain: got %d, want 400", w.Code)
+	}
+
+	redirect := func(host, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Host = host
+		return serve(router, req)
+	}
+	for _, tc := range []struct {
+		host, path, location string
+	}{
+		{domain.Hostname, "/promo", "https://example.com/custom"},
+		{domain.Hostname + ":443", "/l/promo", "https://example.com/custom"},
+		{"example.com", "/l/promo", "https://example.com/default"},
+	} {
+		if w := redirect(tc.host, tc.path); w.Code != http.StatusFound || w.Header().Get("Location") != tc.location {
+			t.Errorf("GET %s%s: got %d to %q, want %s", tc.host, tc.path, w.Code, w.Header().Get("Location"), tc.location)
+		}
+	}
+	if w := redirect("example.com", "/promo"); w.Code != http.StatusNotFound {
+		t.Errorf("root path on the default domain: got %d, want 404", w.Code)
+	}
+
+	w = serve(router, authorized(t, userID, http.MethodPatch, "/links/promo?domain="+domain.Hostname, `{"target_url":"https://example.com/updated"}`))
+	if w.Code != http.StatusOK {
+		t.Fatalf("update on the custom domain: got %d: %s", w.Code, w.Body)
+	}
+	if w := redirect(domain.Hostname, "/promo"); w.Header().Get("Location") != "https://example.com/updated" {
+		t.Errorf("custom domain redirect after update = %q", w.Header().Get("Location"))
+	}
+	if w := redirect("example.com", "/l/promo"); w.Header().Get("Location") != "https://example.com/default" {
+		t.Errorf("default domain redirect after update = %q", w.Header().Get("Location"))
+	}
+
+	if w := serve(router, authorized(t, userID, http.MethodDelete, "/links/promo?domain=unknown.example.com", "")); w.Code != http.StatusNotFound {
+		t.Errorf("delete on an unknown domain: got %d, want 404", w.Code)
+	}
+}
+
+func TestHandlersRequireRepositories(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/l/:slug", GetLinkHandler)
+
+	if w := serve(router, httptest.NewRequest(http.MethodGet, "/l/abc", nil)); w.Code != http.StatusInternalServerError {
+		t.Errorf("without repositories: got %d, want 500", w.Code)
+	}
+}
+
+func TestUnverifiedLinkLimit(t *testing.T) {
+	store := memory.NewStore()
+	router := newTestRouter(store)
+	router.POST("/limited/links", middleware.JWTAuthMiddleware(),
+		middleware.RequireWorkspaceRole(models.WorkspaceRoleEditor),
+		middleware.UnverifiedLinkLimit(verification.Policy{MaxLinks: 1}), CreateLinkHandler)
+	userID := repotest.CreateUser(t, store)
+
+	create := func() int {
+		return serve(router, authorized(t, userID, http.MethodPost, "/limited/links", `{"target_url":"https://example.com"}`)).Code
+	}
+
+	if code := create(); code != http.StatusCreated {
+		t.Fatalf("first link: got %d, want 201", code)
+	}
+	if code := create(); code != http.StatusForbidden {
+		t.Errorf("link over the unverified limit: got %d, want 403", code)
+	}
+
+	if err := store.MarkEmailVerified(userID); err != nil {
+		t.Fatal(err)
+	}
+	if code := create(); code != http.StatusCreated {
+		t.Errorf("link after verification: got %d, want 201", code)
+	}
+}
@@ -1,46 +1,165 @@
 package links
 
 import (
+	"errors"
+	"fmt"
+	"link-guardian/internal/handlers/middleware"
 	"link-guardian/internal/models"
-	"link-guardian/internal/repositories/db"
+	"log"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// Page sizes of ListLinksHandler
+const (
+	defaultLinkPageSize = 50
+	maxLinkPageSize     = 200
+)
+
+// ListLinksHandler lists a page of the links of the workspace selected by
+// RequireWorkspaceRole with the total number of matches. Pass next_cursor
+// back as cursor, with the same sort and order, to get the following page.
 func ListLinksHandler(c *gin.Context) {
-	// Get the user ID from JWT context
-	userIDInterface, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+	workspace, ok := middleware.CurrentWorkspace(c)
+	if !ok {
 		return
 	}
 
-	// Convert the user ID to int
-	userID := int(userIDInterface.(float64))
-	links, err := db.GetAllLinks(userID)
+	filter, page, err := parseLinkListQuery(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch links"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	if len(links) == 0 {
-		c.JSON(http.StatusOK, gin.H{
-			"message": "No links found",
-			"links":   []interface{}{},
-		})
+	repo, ok := middleware.LinkRepositoryFrom(c)
+	if !ok {
 		return
 	}
 
+	// Ask for one more link to learn whether another page follows
+	limit := page.Limit
+	page.Limit++
+	links, total, err := repo.ListLinksByWorkspace(workspace.ID, filter, page)
+	if err != nil {
+		log.Printf("Failed to list links of workspace %d: %v", workspace.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch links"})
+		return
+	}
+
+	var nextCursor *string
+	if len(links) > limit {
+		links = links[:limit]
+		cursor := page.Sort.CursorAt(links[limit-1]).Encode()
+		nextCursor = &cursor
+	}
+
 	// Convert links to response format with proper null handling
-	var linkResponses []models.LinkResponse
+	linkResponses := make([]models.LinkResponse, 0, len(links))
 	for _, link := range links {
 		linkResponses = append(linkResponses, link.ToResponse())
 	}
 
+	message := "Links retrieved successfully"
+	if total == 0 {
+		message = "No links found"
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Links retrieved successfully",
-		"links":   linkResponses,
-		"count":   len(linkResponses),
+		"message":     message,
+		"links":       linkResponses,
+		"count":       len(linkResponses),
+		"total":       total,
+		"next_cursor": nextCursor,
 	})
 }
+
+// parseLinkListQuery reads the filters q, tag (repeatable), folder, status,
+// created_from, created_to and has_click_limit, and the page from sort,
+// order, limit and cursor. Times are RFC 3339 or YYYY-MM-DD dates in UTC; a
+// created_to date includes that whole day. Links are sorted newest first by
+// default, and expires_at sorts soonest first.
+func parseLinkListQuery(c *gin.Context) (models.LinkFilter, models.LinkPage, error) {
+	filter := models.LinkFilter{
+		Query:  strings.TrimSpace(c.Query("q")),
+		Tags:   models.NormalizeTags(c.QueryArray("tag")),
+		Folder: strings.TrimSpace(c.Query("folder")),
+		Status: c.Query("status"),
+	}
+	if filter.Status != "" && !models.ValidLinkStatus(filter.Status) {
+		return models.LinkFilter{}, models.LinkPage{}, errors.New("status must be one of active, expired, exhausted, deleted or taken_down")
+	}
+
+	var err error
+	if from := c.Query("created_from"); from != "" {
+		if filter.CreatedFrom, _, err = parseListTime(from); err != nil {
+			return models.LinkFilter{}, models.LinkPage{}, fmt.Errorf("invalid created_from: %w", err)
+		}
+	}
+	if to := c.Query("created_to"); to != "" {
+		var isDate bool
+		if filter.CreatedTo, isDate, err = parseListTime(to); err != nil {
+			return models.LinkFilter{}, models.LinkPage{}, fmt.Errorf("invalid created_to: %w", err)
+		}
+		if isDate {
+			filter.CreatedTo = filter.CreatedTo.AddDate(0, 0, 1)
+		}
+	}
+
+	if value := c.Query("has_click_limit"); value != "" {
+		hasClickLimit, err := strconv.ParseBool(value)
+		if err != nil {
+			return models.LinkFilter{}, models.LinkPage{}, errors.New("has_click_limit must be true or false")
+		}
+		filter.HasClickLimit = &hasClickLimit
+	}
+
+	page := models.LinkPage{
+		Sort:  models.LinkSort{Field: c.DefaultQuery("sort", models.LinkSortCreatedAt)},
+		Limit: defaultLinkPageSize,
+	}
+	if !models.ValidLinkSortField(page.Sort.Field) {
+		return models.LinkFilter{}, models.LinkPage{}, errors.New("sort must be one of created_at, click_count or expires_at")
+	}
+	switch c.Query("order") {
+	case "":
+		page.Sort.Desc = page.Sort.Field != models.LinkSortExpiresAt
+	case "desc":
+		page.Sort.Desc = true
+	case "asc":
+	default:
+		return models.LinkFilter{}, models.LinkPage{}, errors.New("order must be asc or desc")
+	}
+
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= maxLinkPageSize {
+		page.Limit = l
+	}
+
+	if value := c.Query("cursor"); value != "" {
+		cursor, err := models.ParseLinkCursor(value)
+		if err != nil {
+			return models.LinkFilter{}, models.LinkPage{}, err
+		}
+		if cursor.Sort != page.Sort.Field || cursor.Desc != page.Sort.Desc {
+			return models.LinkFilter{}, models.LinkPage{}, errors.New("cursor belongs to a different sort or order")
+		}
+		page.After = &cursor
+	}
+
+	return filter, page, nil
+}
+
+// parseListTime parses an RFC 3339 time or a YYYY-MM-DD date in UTC and
+// reports whether value was a date
+func parseListTime(value string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, false, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, true, nil
+	}
+	return time.Time{}, false, errors.New("expected an RFC 3339 time or a YYYY-MM-DD date")
+}
@@ -0,0 +1,108 @@
+package links
+
+import (
+	"link-guardian/internal/handlers/middleware"
+	"link-guardian/internal/models"
+	"link-guardian/internal/services/qrcode"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// QRCodeHandler renders the short URL of a link as a PNG or SVG QR code.
+// Query parameters: format (png|svg), size, margin, level (L|M|Q|H), fg, bg and logo.
+func QRCodeHandler(c *gin.Context) {
+	slug := c.Param("slug")
+	if slug == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Slug is required"})
+		return
+	}
+
+	generator, ok := middleware.QRGeneratorFrom(c)
+	if !ok {
+		return
+	}
+
+	opts, err := parseQROptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid QR options: " + err.Error()})
+		return
+	}
+
+	if opts.WithLogo && !generator.HasLogo() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No QR logo is configured on this server"})
+		return
+	}
+
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	repo, ok := middleware.LinkRepositoryFrom(c)
+	if !ok {
+		return
+	}
+
+	link, err := repo.GetMemberLinkBySlug(middleware.LinkDomainFrom(c).ID, slug, userID, models.WorkspaceRoleViewer)
+	if err != nil {
+		respondLinkError(c, err, "You do not have permission to access this link", "Failed to generate QR code")
+		return
+	}
+
+	data, contentType, err := generator.Render(shortURL(c, link), opts)
+	if err != nil {
+		log.Printf("QR generation failed for link %s: %v", link.Slug, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to generate QR code: " + err.Error()})
+		return
+	}
+
+	if c.Query("download") == "true" {
+		c.Header("Content-Disposition", "attachment; filename=\""+link.Slug+"-qr."+opts.Format+"\"")
+	}
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Data(http.StatusOK, contentType, data)
+}
+
+func parseQROptions(c *gin.Context) (qrcode.Options, error) {
+	opts := qrcode.DefaultOptions()
+
+	if format := c.Query("format"); format != "" {
+		opts.Format = strings.ToLower(format)
+	}
+	if level := c.Query("level"); level != "" {
+		opts.Level = strings.ToUpper(level)
+	}
+
+	var err error
+	if size := c.Query("size"); size != "" {
+		if opts.Size, err = strconv.Atoi(size); err != nil {
+			return opts, err
+		}
+	}
+	if margin := c.Query("margin"); margin != "" {
+		if opts.Margin, err = strconv.Atoi(margin); err != nil {
+			return opts, err
+		}
+	}
+	if fg := c.Query("fg"); fg != "" {
+		if opts.Foreground, err = qrcode.ParseHexColor(fg); err != nil {
+			return opts, err
+		}
+	}
+	if bg := c.Query("bg"); bg != "" {
+		if opts.Background, err = qrcode.ParseHexColor(bg); err != nil {
+			return opts, err
+		}
+	}
+	if logo := c.Query("logo"); logo != "" {
+		if opts.WithLogo, err = strconv.ParseBool(logo); err != nil {
+			return opts, err
+		}
+	}
+
+	return opts, opts.Validate()
+}
@@ -0,0 +1,23 @@
+package links
+
+import (
+	"link-guardian/internal/repositories"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// tagRepositoryFrom reads the tag repository injected by RepositoriesMiddleware
+func tagRepositoryFrom(c *gin.Context) (repositories.TagRepository, bool) {
+	repo, exists := c.Get("tagRepository")
+	if !exists {
+		log.Printf("Tag repository not found in context for request from IP %s", c.ClientIP())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Service unavailable",
+			"message": "Please try again later",
+		})
+		return nil, false
+	}
+	return repo.(repositories.TagRepository), true
+}
@@ -0,0 +1,100 @@
+package links
+
+import (
+	"errors"
+	"link-guardian/internal/handlers/middleware"
+	"link-guardian/internal/repositories"
+	"link-guardian/internal/services/slugs"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxSlugSuggestions caps the alternatives offered for a taken slug
+const maxSlugSuggestions = 5
+
+// SlugAvailabilityHandler reports whether a custom slug can be used and
+// suggests alternatives when it cannot
+func SlugAvailabilityHandler(c *gin.Context) {
+	slug := c.Query("slug")
+	if slug == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Query parameter 'slug' is required"})
+		return
+	}
+
+	policy, ok := slugPolicyFrom(c)
+	if !ok {
+		return
+	}
+
+	repo, ok := middleware.LinkRepositoryFrom(c)
+	if !ok {
+		return
+	}
+	domainID := middleware.LinkDomainFrom(c).ID
+
+	if err := policy.Validate(slug); err != nil {
+		reason := "invalid"
+		if errors.Is(err, slugs.ErrReservedSlug) {
+			reason = "reserved"
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"slug":        slug,
+			"available":   false,
+			"reason":      reason,
+			"message":     err.Error(),
+			"suggestions": suggestSlugs(repo, policy, domainID, slug),
+		})
+		return
+	}
+
+	available, err := repo.FilterAvailableSlugs(domainID, []string{slug})
+	if err != nil {
+		log.Printf("Slug availability check failed for %s: %v", slug, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check slug availability"})
+		return
+	}
+
+	if len(available) == 1 {
+		c.JSON(http.StatusOK, gin.H{
+			"slug":      slug,
+			"available": true,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"slug":        slug,
+		"available":   false,
+		"reason":      "taken",
+		"suggestions": suggestSlugs(repo, policy, domainID, slug),
+	})
+}
+
+// suggestSlugs returns alternatives for slug available on the domain; lookup failures yield no suggestions
+func suggestSlugs(repo repositories.LinkRepository, policy *slugs.Policy, domainID int, slug string) []string {
+	available, err := repo.FilterAvailableSlugs(domainID, policy.Candidates(slug, maxSlugSuggestions*2))
+	if err != nil {
+		log.Printf("Failed to build slug suggestions for %s: %v", slug, err)
+		return []string{}
+	}
+	if len(available) > maxSlugSuggestions {
+		available = available[:maxSlugSuggestions]
+	}
+	return available
+}
+
+// slugPolicyFrom reads the slug policy injected by SlugPolicyMiddleware
+func slugPolicyFrom(c *gin.Context) (*slugs.Policy, bool) {
+	policy, exists := c.Get("slugPolicy")
+	if !exists {
+		log.Printf("Slug policy not found in context for request from IP %s", c.ClientIP())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Service unavailable",
+			"message": "Please try again later",
+		})
+		return nil, false
+	}
+	return policy.(*slugs.Policy), true
+}
@@ -0,0 +1,172 @@
+package links
+
+import (
+	"errors"
+	"link-guardian/internal/handlers/middleware"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListTagsHandler lists the tags of the workspace selected by
+// RequireWorkspaceRole with the number of links and clicks of each
+func ListTagsHandler(c *gin.Context) {
+	workspace, ok := middleware.CurrentWorkspace(c)
+	if !ok {
+		return
+	}
+
+	repo, ok := tagRepositoryFrom(c)
+	if !ok {
+		return
+	}
+
+	tags, err := repo.ListTags(workspace.ID)
+	if err != nil {
+		log.Printf("Failed to list tags of workspace %d: %v", workspace.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tags"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tags":  tags,
+		"count": len(tags),
+	})
+}
+
+// ListFoldersHandler lists the folders of the workspace selected by
+// RequireWorkspaceRole with the number of links and clicks of each
+func ListFoldersHandler(c *gin.Context) {
+	workspace, ok := middleware.CurrentWorkspace(c)
+	if !ok {
+		return
+	}
+
+	repo, ok := tagRepositoryFrom(c)
+	if !ok {
+		return
+	}
+
+	folders, err := repo.ListFolders(workspace.ID)
+	if err != nil {
+		log.Printf("Failed to list folders of workspace %d: %v", workspace.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch folders"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"folders": folders,
+		"count":   len(folders),
+	})
+}
+
+// RenameTagHandler renames a tag of the workspace, keeping its links. It is
+// served behind RequireWorkspaceRole(editor).
+func RenameTagHandler(c *gin.Context) {
+	tagID, err := strconv.Atoi(c.Param("tag_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tag ID"})
+		return
+	}
+
+	var req models.RenameTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request or payload"})
+		return
+	}
+
+	req.Name = models.NormalizeTag(req.Name)
+	if err := linkValidator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+		return
+	}
+
+	workspace, ok := middleware.CurrentWorkspace(c)
+	if !ok {
+		return
+	}
+
+	repo, ok := tagRepositoryFrom(c)
+	if !ok {
+		return
+	}
+
+	tag, err := repo.RenameTag(workspace.ID, tagID, req.Name)
+	switch {
+	case errors.Is(err, repositories.ErrTagTaken):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Tag name is already used",
+			"message": "Merge the tags instead",
+		})
+		return
+	case err != nil:
+		respondTagError(c, err, "Failed to rename tag")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Tag renamed successfully",
+		"tag":     tag,
+	})
+}
+
+// MergeTagHandler moves the links of a tag to the target tag and deletes it.
+// It is served behind RequireWorkspaceRole(editor).
+func MergeTagHandler(c *gin.Context) {
+	tagID, err := strconv.Atoi(c.Param("tag_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tag ID"})
+		return
+	}
+
+	var req models.MergeTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request or payload"})
+		return
+	}
+
+	if err := linkValidator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+		return
+	}
+
+	if req.TargetID == tagID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A tag cannot be merged into itself"})
+		return
+	}
+
+	workspace, ok := middleware.CurrentWorkspace(c)
+	if !ok {
+		return
+	}
+
+	repo, ok := tagRepositoryFrom(c)
+	if !ok {
+		return
+	}
+
+	tag, err := repo.MergeTags(workspace.ID, tagID, req.TargetID)
+	if err != nil {
+		respondTagError(c, err, "Failed to merge tags")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Tags merged successfully",
+		"tag":     tag,
+	})
+}
+
+// respondTagError maps tag repository errors to HTTP responses
+func respondTagError(c *gin.Context, err error, failureMessage string) {
+	if errors.Is(err, repositories.ErrTagNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
+		return
+	}
+	log.Printf("%s for request from IP %s: %v", failureMessage, c.ClientIP(), err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": failureMessage})
+}
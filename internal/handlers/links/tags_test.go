@@ -0,0 +1,87 @@
+package links
+
+import (
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories/memory"
+	"link-guardian/internal/repositories/repotest"
+	"link-guardian/internal/testutil/handlertest"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestTagsAndFoldersHandlers(t *testing.T) {
+	store := memory.NewStore()
+	router := newTestRouter(store)
+	userID := repotest.CreateUser(t, store)
+
+	w := serve(router, authorized(t, userID, http.MethodPost, "/links",
+		`{"target_url":"https://example.com","slug":"launch","tags":[" Sale","docs","sale",""],"folder":" Campaigns "}`))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: got %d: %s", w.Code, w.Body)
+	}
+	var created struct {
+		Link models.LinkResponse `json:"link"`
+	}
+	handlertest.Decode(t, w, &created)
+	if !reflect.DeepEqual(created.Link.Tags, []string{"docs", "sale"}) || created.Link.Folder == nil || *created.Link.Folder != "Campaigns" {
+		t.Errorf("create response = %s", w.Body)
+	}
+	other := repotest.CreateLink(t, store, userID, nil)
+
+	w = serve(router, authorized(t, userID, http.MethodGet, "/links?tag=SALE&folder=Campaigns", ""))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"count":1`) || strings.Contains(w.Body.String(), other.Slug) {
+		t.Errorf("filtered list: got %d: %s", w.Code, w.Body)
+	}
+
+	// Tags and folder changes are not recorded in the link's history
+	w = serve(router, authorized(t, userID, http.MethodPatch, "/links/"+other.Slug, `{"tags":["promo"],"folder":"Campaigns"}`))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"tags":["promo"]`) || !strings.Contains(w.Body.String(), `"revision":null`) {
+		t.Fatalf("tag update: got %d: %s", w.Code, w.Body)
+	}
+	if w := serve(router, authorized(t, repotest.CreateUser(t, store), http.MethodPatch, "/links/"+other.Slug, `{"tags":[]}`)); w.Code != http.StatusForbidden {
+		t.Errorf("tag update by another user: got %d, want 403", w.Code)
+	}
+
+	w = serve(router, authorized(t, userID, http.MethodGet, "/folders", ""))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"name":"Campaigns","link_count":2`) {
+		t.Errorf("folders: got %d: %s", w.Code, w.Body)
+	}
+
+	w = serve(router, authorized(t, userID, http.MethodGet, "/tags", ""))
+	var listed struct {
+		Tags []models.Tag `json:"tags"`
+	}
+	handlertest.Decode(t, w, &listed)
+	if w.Code != http.StatusOK || len(listed.Tags) != 3 {
+		t.Fatalf("tags: got %d: %s", w.Code, w.Body)
+	}
+	ids := make(map[string]string)
+	for _, tag := range listed.Tags {
+		ids[tag.Name] = strconv.Itoa(tag.ID)
+	}
+
+	if w := serve(router, authorized(t, userID, http.MethodPatch, "/tags/"+ids["promo"], `{"name":"Sale"}`)); w.Code != http.StatusConflict {
+		t.Errorf("rename to a used name: got %d, want 409", w.Code)
+	}
+	if w := serve(router, authorized(t, userID, http.MethodPatch, "/tags/999", `{"name":"new"}`)); w.Code != http.StatusNotFound {
+		t.Errorf("rename of an unknown tag: got %d, want 404", w.Code)
+	}
+	w = serve(router, authorized(t, userID, http.MethodPatch, "/tags/"+ids["docs"], `{"name":" Guides "}`))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"name":"guides"`) {
+		t.Errorf("rename: got %d: %s", w.Code, w.Body)
+	}
+
+	if w := serve(router, authorized(t, userID, http.MethodPost, "/tags/"+ids["promo"]+"/merge", `{"target_id":`+ids["promo"]+`}`)); w.Code != http.StatusBadRequest {
+		t.Errorf("merge into itself: got %d, want 400", w.Code)
+	}
+	w = serve(router, authorized(t, userID, http.MethodPost, "/tags/"+ids["promo"]+"/merge", `{"target_id":`+ids["sale"]+`}`))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"link_count":2`) {
+		t.Errorf("merge: got %d: %s", w.Code, w.Body)
+	}
+	if link, err := store.GetLinkBySlug(0, other.Slug); err != nil || !reflect.DeepEqual(link.Tags, []string{"sale"}) {
+		t.Errorf("merged link = %+v, %v", link, err)
+	}
+}
@@ -0,0 +1,175 @@
+package links
+
+import (
+	"embed"
+	"html/template"
+	"link-guardian/internal/handlers/middleware"
+	"link-guardian/internal/models"
+	"link-guardian/internal/services/unlock"
+	"log"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed templates/unlock.html
+var templateFS embed.FS
+
+var unlockTemplate = template.Must(template.ParseFS(templateFS, "templates/unlock.html"))
+
+type unlockPage struct {
+	Slug    string
+	Error   string
+	Blocked bool
+}
+
+// UnlockLinkHandler checks the password submitted from the unlock page and,
+// when it is correct, sets a short-lived cookie scoped to the link
+func UnlockLinkHandler(c *gin.Context) {
+	slug := c.Param("slug")
+	if slug == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Slug is required"})
+		return
+	}
+
+	unlockService, ok := unlockServiceFrom(c)
+	if !ok {
+		return
+	}
+
+	domain := middleware.LinkDomainFrom(c)
+	key := unlockKey(domain, slug)
+	ctx := c.Request.Context()
+	ip := c.ClientIP()
+
+	repo, ok := middleware.LinkRepositoryFrom(c)
+	if !ok {
+		return
+	}
+
+	link, err := repo.GetLinkBySlug(domain.ID, slug)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Link not found"})
+		return
+	}
+
+	if link.TakenDownAt.Valid {
+		respondTakenDown(c)
+		return
+	}
+
+	if !link.PasswordHash.Valid {
+		c.Redirect(http.StatusSeeOther, linkPath(domain, slug))
+		return
+	}
+
+	// The attempt is counted before the password is checked
+	allowed, last, err := unlockService.Attempt(ctx, key, ip)
+	if err != nil {
+		log.Printf("Unlock attempt check failed for link %s from IP %s: %v", slug, ip, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock link"})
+		return
+	}
+	if !allowed {
+		renderUnlockPage(c, http.StatusTooManyRequests, unlockPage{
+			Slug:    slug,
+			Error:   "Too many incorrect attempts. Please try again later.",
+			Blocked: true,
+		})
+		return
+	}
+
+	if !unlockService.VerifyPassword(c.PostForm("password"), link.PasswordHash.String) {
+		log.Printf("Failed unlock attempt for link %s from IP %s", slug, ip)
+
+		page := unlockPage{Slug: slug, Error: "Incorrect password.", Blocked: last}
+		if last {
+			page.Error = "Too many incorrect attempts. Please try again later."
+		}
+		renderUnlockPage(c, http.StatusUnauthorized, page)
+		return
+	}
+
+	if err := unlockService.Reset(ctx, key, ip); err != nil {
+		log.Printf("Failed to reset unlock failures for link %s from IP %s: %v", slug, ip, err)
+	}
+
+	token, err := unlockService.IssueToken(key)
+	if err != nil {
+		log.Printf("Failed to issue unlock token for link %s: %v", slug, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock link"})
+		return
+	}
+
+	// Record the unlock for analytics
+	recordAccess(c, link.ID, models.EventUnlock)
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(unlock.CookieName(slug), token, int(unlockService.TTL().Seconds()), cookiePath(domain, slug), "", c.Request.TLS != nil, true)
+	c.Redirect(http.StatusSeeOther, linkPath(domain, slug))
+}
+
+// unlockKey identifies the link with slug on domain to the unlock service.
+// Links on the default domain keep their bare slug so existing cookies stay valid.
+func unlockKey(domain models.Domain, slug string) string {
+	if domain.ID == 0 {
+		return slug
+	}
+	return domain.Hostname + "/" + slug
+}
+
+// linkPath returns the path visitors follow slug at on domain
+func linkPath(domain models.Domain, slug string) string {
+	if domain.ID == 0 {
+		return "/l/" + url.PathEscape(slug)
+	}
+	return "/" + url.PathEscape(slug)
+}
+
+// cookiePath scopes unlock cookies to the link. Custom domains serve links at
+// both /<slug> and /l/<slug>, so their cookies cover the whole host.
+func cookiePath(domain models.Domain, slug string) string {
+	if domain.ID == 0 {
+		return "/l/" + slug
+	}
+	return "/"
+}
+
+// isUnlocked reports whether the request carries a valid unlock cookie for slug on domain
+func isUnlocked(c *gin.Context, domain models.Domain, slug string) bool {
+	unlockSvc, exists := c.Get("linkUnlock")
+	if !exists {
+		return false
+	}
+
+	token, err := c.Cookie(unlock.CookieName(slug))
+	if err != nil {
+		return false
+	}
+
+	return unlockSvc.(*unlock.Service).IsUnlocked(unlockKey(domain, slug), token)
+}
+
+func renderUnlockPage(c *gin.Context, status int, page unlockPage) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(status)
+	if err := unlockTemplate.Execute(c.Writer, page); err != nil {
+		log.Printf("Failed to render unlock page for link %s: %v", page.Slug, err)
+	}
+}
+
+// unlockServiceFrom reads the unlock service injected by LinkUnlockMiddleware
+func unlockServiceFrom(c *gin.Context) (*unlock.Service, bool) {
+	unlockSvc, exists := c.Get("linkUnlock")
+	if !exists {
+		log.Printf("Link unlock service not found in context for request from IP %s", c.ClientIP())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Service unavailable",
+			"message": "Please try again later",
+		})
+		return nil, false
+	}
+	return unlockSvc.(*unlock.Service), true
+}
@@ -0,0 +1,81 @@
+package links
+
+import (
+	"errors"
+	"link-guardian/internal/handlers/middleware"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UpdateLinkHandler changes the target URL, expiry or click limit of a link
+// and records the change in the link's history. It also sets the link's tags
+// and folder, which are not recorded.
+func UpdateLinkHandler(c *gin.Context) {
+	slug := c.Param("slug")
+	if slug == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Slug is required"})
+		return
+	}
+
+	var req models.UpdateLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request or payload"})
+		return
+	}
+
+	if err := linkValidator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+		return
+	}
+
+	if !req.EditsState() && req.Tags == nil && req.Folder == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No changes provided"})
+		return
+	}
+
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	repo, ok := middleware.LinkRepositoryFrom(c)
+	if !ok {
+		return
+	}
+
+	domainID := middleware.LinkDomainFrom(c).ID
+	link, revision, err := repo.UpdateLink(domainID, slug, userID, models.RevisionActionUpdate, nil, req.Apply, req.Organization())
+	if err != nil {
+		respondLinkError(c, err, "You do not have permission to edit this link", "Failed to update link")
+		return
+	}
+
+	if revision != nil {
+		invalidateCachedLink(c, domainID, slug)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Link updated successfully",
+		"link":     link.ToResponse(),
+		"revision": revision,
+	})
+}
+
+// respondLinkError maps link repository errors to HTTP responses
+func respondLinkError(c *gin.Context, err error, forbiddenMessage, failureMessage string) {
+	switch {
+	case errors.Is(err, repositories.ErrLinkNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Link not found"})
+	case errors.Is(err, repositories.ErrLinkForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": forbiddenMessage})
+	case errors.Is(err, repositories.ErrRevisionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Revision not found"})
+	default:
+		log.Printf("%s for request from IP %s: %v", failureMessage, c.ClientIP(), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": failureMessage})
+	}
+}
@@ -1,104 +1,116 @@
 package logs
 
 import (
-	"encoding/json"
-	"fmt"
+	"errors"
+	"link-guardian/internal/handlers/middleware"
 	"link-guardian/internal/models"
-	"link-guardian/internal/repositories/db"
+	"link-guardian/internal/repositories"
 	"net/http"
 	"strconv"
-	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-// geoIPResult is the structure for ip-api.com response
-type geoIPResult struct {
-	Country string `json:"countryCode"`
-	City    string `json:"city"`
-}
+// ListAccessLogsHandler returns access logs for the links of the workspace
+// selected by RequireWorkspaceRole, optionally filtered by link_id and limited
+func ListAccessLogsHandler(c *gin.Context) {
+	workspace, ok := middleware.CurrentWorkspace(c)
+	if !ok {
+		return
+	}
 
-// getGeoIPInfo fetches country and city from ip-api.com
-func getGeoIPInfo(ip string) (country, city string) {
-	url := fmt.Sprintf("http://ip-api.com/json/%s?fields=countryCode,city", ip)
-	client := http.Client{Timeout: 2 * time.Second}
-	resp, err := client.Get(url)
-	if err != nil {
-		return "", ""
+	repo, ok := accessLogRepositoryFrom(c)
+	if !ok {
+		return
 	}
-	defer resp.Body.Close()
-	var result geoIPResult
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", ""
+
+	logs, err := repo.GetAccessLogsByWorkspace(workspace.ID, c.Query("link_id"), parseLimit(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch access logs"})
+		return
 	}
-	return result.Country, result.City
-}
 
-// LogLinkAccess logs a link access event to the database
-func LogLinkAccess(linkID int, ipAddress, userAgent, referer string, accessedAt time.Time) error {
-	// Parse deviceType, browser, os from userAgent
-	deviceType, browser, os := db.ParseUserAgent(userAgent)
-	country, city := getGeoIPInfo(ipAddress)
-	fmt.Println("Country:", country, "City:", city)
-	return db.InsertAccessLogToDB(linkID, ipAddress, userAgent, referer, country, city, deviceType, browser, os, accessedAt)
+	c.JSON(http.StatusOK, gin.H{"logs": logs, "count": len(logs)})
 }
 
-// ListAccessLogsHandler returns access logs, optionally filtered by link_id and limited
-func ListAccessLogsHandler(c *gin.Context) {
-	linkID := c.Query("link_id")
-	limitStr := c.Query("limit")
-	limit := 50
-	if limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
-			limit = l
-		}
+// ListAccessLogsByUserHandler returns access logs for all links of the
+// workspace selected by RequireWorkspaceRole, the user's personal one by default
+func ListAccessLogsByUserHandler(c *gin.Context) {
+	workspace, ok := middleware.CurrentWorkspace(c)
+	if !ok {
+		return
 	}
 
-	logs, err := db.GetAccessLogs(linkID, limit)
+	repo, ok := accessLogRepositoryFrom(c)
+	if !ok {
+		return
+	}
+
+	logs, err := repo.GetAccessLogsByWorkspace(workspace.ID, "", parseLimit(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch access logs"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"logs": logs})
+	c.JSON(http.StatusOK, gin.H{"logs": logs, "count": len(logs)})
 }
 
-// ListAccessLogsByUserHandler returns access logs for a specific user
-func ListAccessLogsByUserHandler(c *gin.Context) {
-	userID := c.Query("user_id")
-	username := c.Query("username")
-	limitStr := c.Query("limit")
-	limit := 50
-	if limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
-			limit = l
-		}
+// ListLinkAccessLogsHandler returns access logs for a single link in a
+// workspace the authenticated user is a member of
+func ListLinkAccessLogsHandler(c *gin.Context) {
+	slug := c.Param("slug")
+	if slug == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Slug is required"})
+		return
 	}
 
-	var logs []models.AccessLog
-	var err error
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	linkRepo, ok := middleware.LinkRepositoryFrom(c)
+	if !ok {
+		return
+	}
+	logRepo, ok := accessLogRepositoryFrom(c)
+	if !ok {
+		return
+	}
 
-	// Check if we have a user identifier
-	if userID != "" {
-		// Convert user ID to int
-		uid, err := strconv.Atoi(userID)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+	link, err := linkRepo.GetMemberLinkBySlug(middleware.LinkDomainFrom(c).ID, slug, userID, models.WorkspaceRoleViewer)
+	if err != nil {
+		if errors.Is(err, repositories.ErrLinkNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Link not found"})
+			return
+		} else if errors.Is(err, repositories.ErrLinkForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to view logs for this link"})
 			return
 		}
-		logs, err = db.GetAccessLogsByUser(uid, limit)
-	} else if username != "" {
-		logs, err = db.GetAccessLogsByUser(username, limit)
-	} else {
-		// Fallback to regular access logs if no user identifier is provided
-		linkID := c.Query("link_id")
-		logs, err = db.GetAccessLogs(linkID, limit)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch access logs"})
+		return
 	}
 
+	logs, err := logRepo.GetAccessLogsByLink(link.ID, parseLimit(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch access logs"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"logs": logs})
+	c.JSON(http.StatusOK, gin.H{
+		"slug":  link.Slug,
+		"logs":  logs,
+		"count": len(logs),
+	})
+}
+
+// parseLimit reads the limit query parameter, defaulting to 50 and capping at 100
+func parseLimit(c *gin.Context) int {
+	limit := 50
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+	}
+	return limit
 }
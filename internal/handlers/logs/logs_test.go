@@ -0,0 +1,82 @@
+package logs
+
+import (
+	"encoding/json"
+	"link-guardian/internal/handlers/middleware"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories/memory"
+	"link-guardian/internal/repositories/repotest"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newTestRouter serves the log routes from store as the user with userID
+func newTestRouter(store *memory.Store, userID int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.RepositoriesMiddleware(store))
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", float64(userID))
+	})
+	router.GET("/logs", middleware.RequireWorkspaceRole(models.WorkspaceRoleViewer), ListAccessLogsHandler)
+	router.GET("/links/:slug/logs", ListLinkAccessLogsHandler)
+	router.GET("/links/:slug/stats", LinkStatsHandler)
+	return router
+}
+
+func get(router http.Handler, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+func TestAccessLogAndStatsHandlers(t *testing.T) {
+	store := memory.NewStore()
+	userID := repotest.CreateUser(t, store)
+	link := repotest.CreateLink(t, store, userID, nil)
+	foreign := repotest.CreateLink(t, store, repotest.CreateUser(t, store), nil)
+
+	now := time.Now()
+	err := store.InsertAccessLogs([]models.AccessLog{
+		{LinkID: int64(link.ID), AccessedAt: now.Add(-2 * time.Hour), IPAddress: "10.0.0.1", Country: "GB"},
+		{LinkID: int64(link.ID), AccessedAt: now.Add(-time.Hour), IPAddress: "10.0.0.2", Country: "GB"},
+		{LinkID: int64(foreign.ID), AccessedAt: now, IPAddress: "10.0.0.3"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := newTestRouter(store, userID)
+
+	var list struct {
+		Count int `json:"count"`
+	}
+	w := get(router, "/logs")
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil || w.Code != http.StatusOK || list.Count != 2 {
+		t.Errorf("/logs: got %d: %s", w.Code, w.Body)
+	}
+
+	w = get(router, "/links/"+link.Slug+"/logs?limit=1")
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil || w.Code != http.StatusOK || list.Count != 1 {
+		t.Errorf("link logs: got %d: %s", w.Code, w.Body)
+	}
+	if w := get(router, "/links/"+foreign.Slug+"/logs"); w.Code != http.StatusForbidden {
+		t.Errorf("logs of another user's link: got %d, want 403", w.Code)
+	}
+
+	var stats models.LinkStats
+	w = get(router, "/links/"+link.Slug+"/stats?bucket=hour")
+	if err := json.Unmarshal(w.Body.Bytes(), &stats); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("stats: got %d: %s", w.Code, w.Body)
+	}
+	if stats.TotalClicks != 2 || stats.UniqueVisitors != 2 || stats.Status != models.LinkStatusActive || len(stats.Series) < 24 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if w := get(router, "/links/missing/stats"); w.Code != http.StatusNotFound {
+		t.Errorf("stats of unknown link: got %d, want 404", w.Code)
+	}
+}
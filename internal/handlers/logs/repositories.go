@@ -0,0 +1,23 @@
+package logs
+
+import (
+	"link-guardian/internal/repositories"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// accessLogRepositoryFrom reads the access log repository injected by RepositoriesMiddleware
+func accessLogRepositoryFrom(c *gin.Context) (repositories.AccessLogRepository, bool) {
+	repo, exists := c.Get("accessLogRepository")
+	if !exists {
+		log.Printf("Access log repository not found in context for request from IP %s", c.ClientIP())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Service unavailable",
+			"message": "Please try again later",
+		})
+		return nil, false
+	}
+	return repo.(repositories.AccessLogRepository), true
+}
@@ -0,0 +1,149 @@
+package logs
+
+import (
+	"errors"
+	"fmt"
+	"link-guardian/internal/handlers/middleware"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// maxStatsBuckets bounds the length of the click series
+	maxStatsBuckets = 1000
+	// statsBreakdownLimit is the number of top values returned per breakdown
+	statsBreakdownLimit = 10
+)
+
+// statsBucketSizes maps each bucket name to its length and the default range
+var statsBucketSizes = map[string]struct {
+	size         time.Duration
+	defaultRange time.Duration
+}{
+	models.StatsBucketHour: {time.Hour, 24 * time.Hour},
+	models.StatsBucketDay:  {24 * time.Hour, 30 * 24 * time.Hour},
+	models.StatsBucketWeek: {7 * 24 * time.Hour, 12 * 7 * 24 * time.Hour},
+}
+
+// LinkStatsHandler returns the click time series, visitor breakdowns and
+// current status of a link in a workspace the authenticated user is a member of
+func LinkStatsHandler(c *gin.Context) {
+	slug := c.Param("slug")
+	if slug == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Slug is required"})
+		return
+	}
+
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	query, err := parseStatsQuery(c, time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	linkRepo, ok := middleware.LinkRepositoryFrom(c)
+	if !ok {
+		return
+	}
+	logRepo, ok := accessLogRepositoryFrom(c)
+	if !ok {
+		return
+	}
+
+	link, err := linkRepo.GetMemberLinkBySlug(middleware.LinkDomainFrom(c).ID, slug, userID, models.WorkspaceRoleViewer)
+	if err != nil {
+		if errors.Is(err, repositories.ErrLinkNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Link not found"})
+			return
+		} else if errors.Is(err, repositories.ErrLinkForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to view stats for this link"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch link stats"})
+		return
+	}
+
+	stats, err := logRepo.GetLinkClickStats(link.ID, query.from, query.to, query.bucket, query.location.String(), statsBreakdownLimit)
+	if err != nil {
+		log.Printf("Failed to fetch stats for link %s: %v", slug, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch link stats"})
+		return
+	}
+
+	stats.Link = link.ToResponse()
+	stats.Status = link.Status(time.Now())
+	for i := range stats.Series {
+		stats.Series[i].Bucket = stats.Series[i].Bucket.In(query.location)
+	}
+	stats.From = stats.From.In(query.location)
+	stats.To = stats.To.In(query.location)
+
+	c.JSON(http.StatusOK, stats)
+}
+
+type statsQuery struct {
+	from     time.Time
+	to       time.Time
+	bucket   string
+	location *time.Location
+}
+
+// parseStatsQuery reads from, to, bucket and tz. Times are RFC 3339 or
+// YYYY-MM-DD dates in tz; by default the range ends now.
+func parseStatsQuery(c *gin.Context, now time.Time) (statsQuery, error) {
+	query := statsQuery{bucket: c.DefaultQuery("bucket", models.StatsBucketDay)}
+
+	bucketSize, ok := statsBucketSizes[query.bucket]
+	if !ok {
+		return statsQuery{}, errors.New("bucket must be one of hour, day or week")
+	}
+
+	timezone := c.DefaultQuery("tz", "UTC")
+	location, err := time.LoadLocation(timezone)
+	if err != nil || timezone == "" || timezone == "Local" {
+		return statsQuery{}, fmt.Errorf("unknown time zone %q", timezone)
+	}
+	query.location = location
+
+	query.to = now
+	if toStr := c.Query("to"); toStr != "" {
+		if query.to, err = parseStatsTime(toStr, location); err != nil {
+			return statsQuery{}, fmt.Errorf("invalid to: %w", err)
+		}
+	}
+
+	query.from = query.to.Add(-bucketSize.defaultRange)
+	if fromStr := c.Query("from"); fromStr != "" {
+		if query.from, err = parseStatsTime(fromStr, location); err != nil {
+			return statsQuery{}, fmt.Errorf("invalid from: %w", err)
+		}
+	}
+
+	if !query.from.Before(query.to) {
+		return statsQuery{}, errors.New("from must be before to")
+	}
+	if query.to.Sub(query.from)/bucketSize.size >= maxStatsBuckets {
+		return statsQuery{}, fmt.Errorf("range is too long for %s buckets", query.bucket)
+	}
+
+	return query, nil
+}
+
+func parseStatsTime(value string, location *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, location); err == nil {
+		return t, nil
+	}
+	return time.Time{}, errors.New("expected an RFC 3339 time or a YYYY-MM-DD date")
+}
@@ -0,0 +1,51 @@
+package logs
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func statsContext(rawQuery string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/links/abc/stats?"+rawQuery, nil)
+	return c
+}
+
+func TestParseStatsQuery(t *testing.T) {
+	now := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	query, err := parseStatsQuery(statsContext(""), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query.bucket != "day" || query.location.String() != "UTC" || !query.to.Equal(now) ||
+		!query.from.Equal(now.Add(-30*24*time.Hour)) {
+		t.Errorf("defaults = %+v", query)
+	}
+
+	query, err = parseStatsQuery(statsContext("bucket=hour&tz=Europe/Berlin&from=2026-05-01&to=2026-05-02"), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	if !query.from.Equal(time.Date(2026, 5, 1, 0, 0, 0, 0, berlin)) || !query.to.Equal(time.Date(2026, 5, 2, 0, 0, 0, 0, berlin)) {
+		t.Errorf("dates were not parsed in the requested zone: %v - %v", query.from, query.to)
+	}
+
+	invalid := []string{
+		"bucket=month",
+		"tz=Mars/Olympus",
+		"tz=Local",
+		"from=yesterday",
+		"from=2026-05-02&to=2026-05-01",
+		"bucket=hour&from=2025-01-01&to=2026-01-01",
+	}
+	for _, rawQuery := range invalid {
+		if _, err := parseStatsQuery(statsContext(rawQuery), now); err == nil {
+			t.Errorf("parseStatsQuery(%q) succeeded, want error", rawQuery)
+		}
+	}
+}
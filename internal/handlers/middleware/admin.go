@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"errors"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requireActiveAccount loads the authenticated user, rejecting deleted and
+// disabled accounts, and sets their role in the context as "user_role" for
+// RequireAdmin. It reports false after aborting the request.
+func requireActiveAccount(c *gin.Context) bool {
+	userRepo, ok := UserRepositoryFrom(c)
+	if !ok {
+		return false
+	}
+
+	userID := int(c.GetFloat64("user_id"))
+	user, err := userRepo.GetUserByID(userID)
+	if errors.Is(err, repositories.ErrUserNotFound) {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Invalid token",
+			"message": "Please login again",
+		})
+		c.Abort()
+		return false
+	}
+	if err != nil {
+		log.Printf("Failed to load user ID %d from IP %s: %v", userID, c.ClientIP(), err)
+		respondServiceUnavailable(c, "")
+		return false
+	}
+
+	if user.DisabledAt.Valid {
+		log.Printf("Request from disabled user ID %d from IP %s rejected", userID, c.ClientIP())
+		respondAccountDisabled(c)
+		return false
+	}
+
+	c.Set("user_role", user.Role)
+	return true
+}
+
+// respondAccountDisabled aborts with the 403 returned to disabled accounts
+func respondAccountDisabled(c *gin.Context) {
+	c.JSON(http.StatusForbidden, gin.H{
+		"error":   "Account disabled",
+		"message": "This account has been disabled. Contact an administrator.",
+	})
+	c.Abort()
+}
+
+// RequireAdmin rejects users who are not instance admins. It must run after
+// JWTAuthMiddleware.
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("user_role") != models.UserRoleAdmin {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "Admin access required",
+				"message": "Only administrators can use this endpoint",
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -1,15 +1,20 @@
 package middleware
 
 import (
+	"link-guardian/internal/repositories/redis"
 	"link-guardian/internal/services/auth"
 	"log"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-// JWTAuthMiddleware creates a JWT authentication middleware that uses the auth service
+// JWTAuthMiddleware creates a JWT authentication middleware that uses the auth
+// service. Access tokens revoked through the Redis denylist are rejected, and
+// personal API keys are accepted in place of a token. Requests from disabled
+// accounts are rejected either way.
 func JWTAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get auth service from context
@@ -49,9 +54,15 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 		}
 
 		tokenString := headerParts[1]
+		if authService.IsAPIKey(tokenString) {
+			if authenticateAPIKey(c, authService, tokenString) && requireActiveAccount(c) {
+				c.Next()
+			}
+			return
+		}
 
 		// Validate token using auth service
-		claims, err := authService.ValidateJWTToken(tokenString)
+		claims, err := authService.ValidateAccessToken(tokenString)
 		if err != nil {
 			log.Printf("Token validation failed from IP %s: %v", c.ClientIP(), err)
 			c.JSON(http.StatusUnauthorized, gin.H{
@@ -83,9 +94,41 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// Set user information in context for handlers to use
+		// Reject tokens revoked by logout or a password reset before they expire
+		tokenID := claims["jti"].(string)
+		numericUserID, _ := userID.(float64)
+		denied, err := redis.IsAccessTokenDenied(c.Request.Context(), tokenID, int(numericUserID), auth.AccessTokenIssuedAt(claims))
+		if err != nil {
+			log.Printf("Token denylist check failed from IP %s: %v", c.ClientIP(), err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Service unavailable",
+				"message": "Please try again later",
+			})
+			c.Abort()
+			return
+		}
+		if denied {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error":   "Invalid token",
+				"message": "Please login again",
+			})
+			c.Abort()
+			return
+		}
+
+		// Set user and token information in context for handlers to use
 		c.Set("user_id", userID)
 		c.Set("username", username)
+		c.Set("token_id", tokenID)
+		if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+			c.Set("token_expires_at", exp.Time)
+		} else {
+			c.Set("token_expires_at", time.Now().Add(authService.AccessTokenTTL()))
+		}
+
+		if !requireActiveAccount(c) {
+			return
+		}
 
 		c.Next()
 	}
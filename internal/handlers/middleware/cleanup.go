@@ -0,0 +1,15 @@
+package middleware
+
+import (
+	"link-guardian/internal/services/cleanup"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CleanupServiceMiddleware injects the expired-link cleanup service into the Gin context
+func CleanupServiceMiddleware(cleanupService *cleanup.ExpiredLinkCleanupService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("cleanupService", cleanupService)
+		c.Next()
+	}
+}
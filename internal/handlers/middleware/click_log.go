@@ -0,0 +1,15 @@
+package middleware
+
+import (
+	"link-guardian/internal/services/clicklog"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClickLogMiddleware injects the click log pipeline into the Gin context
+func ClickLogMiddleware(pipeline *clicklog.Pipeline) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("clickLog", pipeline)
+		c.Next()
+	}
+}
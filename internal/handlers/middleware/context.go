@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"link-guardian/internal/services/loginguard"
+	"link-guardian/internal/services/qrcode"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CurrentUserID reads the ID of the user JWTAuthMiddleware authenticated
+// with a token or API key. It reports false after aborting the request when
+// the ID is missing.
+func CurrentUserID(c *gin.Context) (int, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		c.Abort()
+		return 0, false
+	}
+	return int(userID.(float64)), true
+}
+
+// CurrentWorkspace reads the workspace loaded by RequireWorkspaceRole. It
+// reports false after aborting the request when no workspace was loaded.
+func CurrentWorkspace(c *gin.Context) (models.Workspace, bool) {
+	workspace, exists := c.Get("workspace")
+	if !exists {
+		respondServiceUnavailable(c, "Workspace")
+		return models.Workspace{}, false
+	}
+	return workspace.(models.Workspace), true
+}
+
+// LinkDomainFrom reads the custom domain selected by a LinkDomain middleware.
+// The zero Domain, whose ID is 0, stands for the default domain.
+func LinkDomainFrom(c *gin.Context) models.Domain {
+	domain, exists := c.Get("linkDomain")
+	if !exists {
+		return models.Domain{}
+	}
+	return domain.(models.Domain)
+}
+
+// UserRepositoryFrom reads the user repository injected by
+// RepositoriesMiddleware. It reports false after aborting the request when
+// the repository is missing.
+func UserRepositoryFrom(c *gin.Context) (repositories.UserRepository, bool) {
+	repo, exists := c.Get("userRepository")
+	if !exists {
+		respondServiceUnavailable(c, "User repository")
+		return nil, false
+	}
+	return repo.(repositories.UserRepository), true
+}
+
+// LinkRepositoryFrom reads the link repository injected by
+// RepositoriesMiddleware, like UserRepositoryFrom.
+func LinkRepositoryFrom(c *gin.Context) (repositories.LinkRepository, bool) {
+	repo, exists := c.Get("linkRepository")
+	if !exists {
+		respondServiceUnavailable(c, "Link repository")
+		return nil, false
+	}
+	return repo.(repositories.LinkRepository), true
+}
+
+// DomainRepositoryFrom reads the domain repository injected by
+// RepositoriesMiddleware, like UserRepositoryFrom.
+func DomainRepositoryFrom(c *gin.Context) (repositories.DomainRepository, bool) {
+	repo, exists := c.Get("domainRepository")
+	if !exists {
+		respondServiceUnavailable(c, "Domain repository")
+		return nil, false
+	}
+	return repo.(repositories.DomainRepository), true
+}
+
+// LoginGuardFrom reads the login guard injected by LoginGuardMiddleware,
+// like UserRepositoryFrom.
+func LoginGuardFrom(c *gin.Context) (*loginguard.Guard, bool) {
+	guard, exists := c.Get("loginGuard")
+	if !exists {
+		respondServiceUnavailable(c, "Login guard")
+		return nil, false
+	}
+	return guard.(*loginguard.Guard), true
+}
+
+// QRGeneratorFrom reads the QR generator injected by QRGeneratorMiddleware,
+// like UserRepositoryFrom.
+func QRGeneratorFrom(c *gin.Context) (*qrcode.Generator, bool) {
+	generator, exists := c.Get("qrGenerator")
+	if !exists {
+		respondServiceUnavailable(c, "QR generator")
+		return nil, false
+	}
+	return generator.(*qrcode.Generator), true
+}
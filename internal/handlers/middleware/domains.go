@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"errors"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"link-guardian/internal/repositories/redis"
+	"link-guardian/internal/services/domains"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DomainsMiddleware injects the custom domain service into the Gin context
+func DomainsMiddleware(domainService *domains.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("domains", domainService)
+		c.Next()
+	}
+}
+
+// LinkDomainFromHost selects the domain redirect routes serve by the
+// request's Host header. Hosts that are not a verified custom domain, such as
+// the public base URL or the API host, serve the default domain. A custom
+// domain is set in the context as "linkDomain".
+func LinkDomainFromHost() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		hostname := domains.NormalizeHostname(c.Request.Host)
+		if service, exists := c.Get("domains"); exists && hostname == service.(*domains.Service).DefaultHost() {
+			c.Next()
+			return
+		}
+
+		domain, found, ok := resolveLinkDomain(c, hostname)
+		if !ok {
+			return
+		}
+		if found {
+			c.Set("linkDomain", domain)
+		}
+		c.Next()
+	}
+}
+
+// LinkDomainFromQuery selects the domain link management routes act on by
+// the domain query parameter, the default domain when it is absent. Hostnames
+// that are not a verified custom domain are answered with 404. A custom
+// domain is set in the context as "linkDomain".
+func LinkDomainFromQuery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		hostname := c.Query("domain")
+		if hostname == "" {
+			c.Next()
+			return
+		}
+
+		domain, found, ok := resolveLinkDomain(c, domains.NormalizeHostname(hostname))
+		if !ok {
+			return
+		}
+		if !found {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+			c.Abort()
+			return
+		}
+		c.Set("linkDomain", domain)
+		c.Next()
+	}
+}
+
+// resolveLinkDomain looks up the verified domain with hostname through the
+// domain cache. When it returns false a response has already been written.
+func resolveLinkDomain(c *gin.Context, hostname string) (models.Domain, bool, bool) {
+	domainRepo, ok := DomainRepositoryFrom(c)
+	if !ok {
+		return models.Domain{}, false, false
+	}
+
+	domain, found, err := redis.ResolveDomain(c.Request.Context(), hostname, func(hostname string) (models.Domain, bool, error) {
+		domain, err := domainRepo.GetVerifiedDomainByHostname(hostname)
+		if errors.Is(err, repositories.ErrDomainNotFound) {
+			return models.Domain{}, false, nil
+		}
+		return domain, err == nil, err
+	})
+	if err != nil {
+		log.Printf("Failed to resolve domain %s for request from IP %s: %v", hostname, c.ClientIP(), err)
+		respondServiceUnavailable(c, "")
+		return models.Domain{}, false, false
+	}
+	return domain, found, true
+}
@@ -0,0 +1,15 @@
+package middleware
+
+import (
+	"link-guardian/internal/services/unlock"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LinkUnlockMiddleware injects the link unlock service into the Gin context
+func LinkUnlockMiddleware(unlockService *unlock.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("linkUnlock", unlockService)
+		c.Next()
+	}
+}
@@ -0,0 +1,15 @@
+package middleware
+
+import (
+	"link-guardian/internal/services/loginguard"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LoginGuardMiddleware injects the login brute-force guard into the Gin context
+func LoginGuardMiddleware(guard *loginguard.Guard) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("loginGuard", guard)
+		c.Next()
+	}
+}
@@ -0,0 +1,15 @@
+package middleware
+
+import (
+	"link-guardian/internal/services/passwordreset"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PasswordResetMiddleware injects the password reset service into the Gin context
+func PasswordResetMiddleware(resetService *passwordreset.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("passwordReset", resetService)
+		c.Next()
+	}
+}
@@ -0,0 +1,15 @@
+package middleware
+
+import (
+	"link-guardian/internal/services/qrcode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// QRGeneratorMiddleware injects the QR code generator into the Gin context
+func QRGeneratorMiddleware(generator *qrcode.Generator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("qrGenerator", generator)
+		c.Next()
+	}
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"link-guardian/internal/repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RepositoriesMiddleware injects the link, user, access log, refresh token,
+// API key, password reset, two-factor, workspace, admin and domain
+// repositories into the Gin context
+func RepositoriesMiddleware(store repositories.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("linkRepository", repositories.LinkRepository(store))
+		c.Set("userRepository", repositories.UserRepository(store))
+		c.Set("accessLogRepository", repositories.AccessLogRepository(store))
+		c.Set("refreshTokenRepository", repositories.RefreshTokenRepository(store))
+		c.Set("apiKeyRepository", repositories.APIKeyRepository(store))
+		c.Set("passwordResetRepository", repositories.PasswordResetRepository(store))
+		c.Set("twoFactorRepository", repositories.TwoFactorRepository(store))
+		c.Set("workspaceRepository", repositories.WorkspaceRepository(store))
+		c.Set("adminRepository", repositories.AdminRepository(store))
+		c.Set("domainRepository", repositories.DomainRepository(store))
+		c.Set("tagRepository", repositories.TagRepository(store))
+		c.Next()
+	}
+}
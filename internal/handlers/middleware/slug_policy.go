@@ -0,0 +1,15 @@
+package middleware
+
+import (
+	"link-guardian/internal/services/slugs"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SlugPolicyMiddleware injects the custom slug policy into the Gin context
+func SlugPolicyMiddleware(policy *slugs.Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("slugPolicy", policy)
+		c.Next()
+	}
+}
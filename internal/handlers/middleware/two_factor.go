@@ -0,0 +1,15 @@
+package middleware
+
+import (
+	"link-guardian/internal/services/twofactor"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TwoFactorMiddleware injects the two-factor authentication service into the Gin context
+func TwoFactorMiddleware(twoFactorService *twofactor.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("twoFactor", twoFactorService)
+		c.Next()
+	}
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"errors"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"link-guardian/internal/services/invitations"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WorkspaceHeader is the request header that selects the workspace a request acts on
+const WorkspaceHeader = "X-Workspace-ID"
+
+// InvitationsMiddleware injects the workspace invitation service into the Gin context
+func InvitationsMiddleware(invitationService *invitations.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("invitations", invitationService)
+		c.Next()
+	}
+}
+
+// RequireWorkspaceRole loads the workspace a request acts on and rejects
+// users whose role in it is below role. The workspace is named by the
+// workspace_id route parameter, the workspace_id query parameter or the
+// X-Workspace-ID header, in that order, and defaults to the user's personal
+// workspace. It is set in the context as "workspace", with the user's role.
+func RequireWorkspaceRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := CurrentUserID(c)
+		if !ok {
+			return
+		}
+
+		repo, exists := c.Get("workspaceRepository")
+		if !exists {
+			respondServiceUnavailable(c, "Workspace repository")
+			return
+		}
+		workspaces := repo.(repositories.WorkspaceRepository)
+
+		var workspace models.Workspace
+		var err error
+		if requested := requestedWorkspace(c); requested != "" {
+			workspaceID, convErr := strconv.Atoi(requested)
+			if convErr != nil || workspaceID <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+				c.Abort()
+				return
+			}
+			workspace, err = workspaces.GetWorkspace(workspaceID, userID)
+		} else {
+			workspace, err = workspaces.GetPersonalWorkspace(userID)
+		}
+
+		if errors.Is(err, repositories.ErrWorkspaceNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Workspace not found"})
+			c.Abort()
+			return
+		}
+		if err != nil {
+			log.Printf("Failed to load workspace for user ID %d from IP %s: %v", userID, c.ClientIP(), err)
+			respondServiceUnavailable(c, "")
+			return
+		}
+
+		if !models.WorkspaceRoleAtLeast(workspace.Role, role) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "Insufficient workspace role",
+				"message": "This requires the " + role + " role in the workspace",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Set("workspace", workspace)
+		c.Next()
+	}
+}
+
+// requestedWorkspace returns the workspace ID named by the request, if any
+func requestedWorkspace(c *gin.Context) string {
+	if workspaceID := c.Param("workspace_id"); workspaceID != "" {
+		return workspaceID
+	}
+	if workspaceID := c.Query("workspace_id"); workspaceID != "" {
+		return workspaceID
+	}
+	return c.GetHeader(WorkspaceHeader)
+}
@@ -0,0 +1,32 @@
+package system
+
+import (
+	"link-guardian/internal/services/cleanup"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CleanupStatusHandler reports whether the expired-link cleanup service is
+// running, the results of its last run and totals since startup
+func CleanupStatusHandler(c *gin.Context) {
+	cleanupSvc, exists := c.Get("cleanupService")
+	if !exists {
+		log.Printf("Cleanup service not found in context for request from IP %s", c.ClientIP())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Service unavailable",
+			"message": "Please try again later",
+		})
+		return
+	}
+
+	// The service is nil when cleanup is disabled in the configuration
+	service := cleanupSvc.(*cleanup.ExpiredLinkCleanupService)
+	if service == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Expired link cleanup is disabled"})
+		return
+	}
+
+	c.JSON(http.StatusOK, service.Status())
+}
@@ -0,0 +1,195 @@
+package workspaces
+
+import (
+	"errors"
+	"link-guardian/internal/handlers/middleware"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"link-guardian/internal/services/invitations"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateWorkspaceInvitationHandler invites an email address to the workspace
+// selected by RequireWorkspaceRole(owner) and emails it a link to accept
+func CreateWorkspaceInvitationHandler(c *gin.Context) {
+	var req models.CreateWorkspaceInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request or payload"})
+		return
+	}
+	if err := workspaceValidator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+		return
+	}
+
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	workspace, ok := middleware.CurrentWorkspace(c)
+	if !ok {
+		return
+	}
+
+	users, ok := middleware.UserRepositoryFrom(c)
+	if !ok {
+		return
+	}
+	repo, ok := workspaceRepositoryFrom(c)
+	if !ok {
+		return
+	}
+	invitationService, ok := invitationsFrom(c)
+	if !ok {
+		return
+	}
+
+	inviter, err := users.GetUserByID(userID)
+	if err != nil {
+		log.Printf("User lookup failed for workspace invitation, user ID %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send invitation"})
+		return
+	}
+
+	invitation, err := invitationService.Invite(c.Request.Context(), repo, workspace, *inviter, req.Email, req.Role)
+	if errors.Is(err, invitations.ErrPersonalWorkspace) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Personal workspaces cannot be shared",
+			"message": "Create a workspace to invite others",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to invite to workspace %d by user ID %d: %v", workspace.ID, userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send invitation"})
+		return
+	}
+
+	log.Printf("Invitation %d to workspace %d sent by user ID %d", invitation.ID, workspace.ID, userID)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":    "Invitation sent",
+		"invitation": invitation,
+	})
+}
+
+// ListWorkspaceInvitationsHandler lists the workspace's pending invitations
+func ListWorkspaceInvitationsHandler(c *gin.Context) {
+	workspace, ok := middleware.CurrentWorkspace(c)
+	if !ok {
+		return
+	}
+
+	repo, ok := workspaceRepositoryFrom(c)
+	if !ok {
+		return
+	}
+
+	pending, err := repo.ListWorkspaceInvitations(workspace.ID)
+	if err != nil {
+		log.Printf("Failed to list invitations of workspace %d: %v", workspace.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch invitations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"invitations": pending,
+		"count":       len(pending),
+	})
+}
+
+// RevokeWorkspaceInvitationHandler deletes a pending invitation so its link stops working
+func RevokeWorkspaceInvitationHandler(c *gin.Context) {
+	invitationID, err := strconv.ParseInt(c.Param("invitation_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invitation ID"})
+		return
+	}
+
+	workspace, ok := middleware.CurrentWorkspace(c)
+	if !ok {
+		return
+	}
+
+	repo, ok := workspaceRepositoryFrom(c)
+	if !ok {
+		return
+	}
+
+	if err := repo.RevokeWorkspaceInvitation(workspace.ID, invitationID); err != nil {
+		if errors.Is(err, repositories.ErrInvitationNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found or already accepted"})
+			return
+		}
+		log.Printf("Failed to revoke invitation %d of workspace %d: %v", invitationID, workspace.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke invitation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Invitation revoked successfully",
+		"id":      invitationID,
+	})
+}
+
+// AcceptWorkspaceInvitationHandler adds the caller to the workspace an
+// invitation token was sent for. The invitation must be addressed to the
+// caller's email.
+func AcceptWorkspaceInvitationHandler(c *gin.Context) {
+	var req models.AcceptWorkspaceInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil || workspaceValidator.Struct(req) != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request or payload"})
+		return
+	}
+
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	users, ok := middleware.UserRepositoryFrom(c)
+	if !ok {
+		return
+	}
+	repo, ok := workspaceRepositoryFrom(c)
+	if !ok {
+		return
+	}
+	invitationService, ok := invitationsFrom(c)
+	if !ok {
+		return
+	}
+
+	user, err := users.GetUserByID(userID)
+	if err != nil {
+		log.Printf("User lookup failed for invitation acceptance, user ID %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept invitation"})
+		return
+	}
+
+	workspace, err := invitationService.Accept(repo, *user, req.Token)
+	if errors.Is(err, repositories.ErrInvitationInvalid) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid invitation",
+			"message": "This invitation is invalid, expired or was sent to a different email address",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to accept invitation for user ID %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept invitation"})
+		return
+	}
+
+	log.Printf("User ID %d joined workspace %d as %s", userID, workspace.ID, workspace.Role)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "You joined " + workspace.Name,
+		"workspace": workspace,
+	})
+}
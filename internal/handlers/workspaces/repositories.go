@@ -0,0 +1,39 @@
+package workspaces
+
+import (
+	"link-guardian/internal/repositories"
+	"link-guardian/internal/services/invitations"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// workspaceRepositoryFrom reads the workspace repository injected by RepositoriesMiddleware
+func workspaceRepositoryFrom(c *gin.Context) (repositories.WorkspaceRepository, bool) {
+	repo, exists := c.Get("workspaceRepository")
+	if !exists {
+		log.Printf("Workspace repository not found in context for request from IP %s", c.ClientIP())
+		respondServiceUnavailable(c)
+		return nil, false
+	}
+	return repo.(repositories.WorkspaceRepository), true
+}
+
+// invitationsFrom reads the invitation service injected by InvitationsMiddleware
+func invitationsFrom(c *gin.Context) (*invitations.Service, bool) {
+	service, exists := c.Get("invitations")
+	if !exists {
+		log.Printf("Invitation service not found in context for request from IP %s", c.ClientIP())
+		respondServiceUnavailable(c)
+		return nil, false
+	}
+	return service.(*invitations.Service), true
+}
+
+func respondServiceUnavailable(c *gin.Context) {
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error":   "Service unavailable",
+		"message": "Please try again later",
+	})
+}
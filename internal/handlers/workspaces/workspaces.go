@@ -0,0 +1,214 @@
+// Package workspaces serves the endpoints users manage shared workspaces,
+// their members and invitations with
+package workspaces
+
+import (
+	"errors"
+	"link-guardian/internal/handlers/middleware"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+var workspaceValidator = validator.New()
+
+// ListWorkspacesHandler lists the workspaces the caller is a member of with
+// their role in each, the personal workspace first
+func ListWorkspacesHandler(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	repo, ok := workspaceRepositoryFrom(c)
+	if !ok {
+		return
+	}
+
+	workspaces, err := repo.ListWorkspacesByUser(userID)
+	if err != nil {
+		log.Printf("Failed to list workspaces for user ID %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch workspaces"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"workspaces": workspaces,
+		"count":      len(workspaces),
+	})
+}
+
+// CreateWorkspaceHandler creates a shared workspace owned by the caller
+func CreateWorkspaceHandler(c *gin.Context) {
+	var req models.CreateWorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request or payload"})
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if err := workspaceValidator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+		return
+	}
+
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	repo, ok := workspaceRepositoryFrom(c)
+	if !ok {
+		return
+	}
+
+	workspace, err := repo.CreateWorkspace(req.Name, userID)
+	if err != nil {
+		log.Printf("Workspace creation failed for user ID %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create workspace"})
+		return
+	}
+
+	log.Printf("Workspace %d (%s) created by user ID %d", workspace.ID, workspace.Name, userID)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":   "Workspace created successfully",
+		"workspace": workspace,
+	})
+}
+
+// ListWorkspaceMembersHandler lists the members of the workspace selected by RequireWorkspaceRole
+func ListWorkspaceMembersHandler(c *gin.Context) {
+	workspace, ok := middleware.CurrentWorkspace(c)
+	if !ok {
+		return
+	}
+
+	repo, ok := workspaceRepositoryFrom(c)
+	if !ok {
+		return
+	}
+
+	members, err := repo.ListWorkspaceMembers(workspace.ID)
+	if err != nil {
+		log.Printf("Failed to list members of workspace %d: %v", workspace.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch workspace members"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"workspace": workspace,
+		"members":   members,
+		"count":     len(members),
+	})
+}
+
+// UpdateWorkspaceMemberHandler changes a member's role. It is served behind
+// RequireWorkspaceRole(owner).
+func UpdateWorkspaceMemberHandler(c *gin.Context) {
+	memberID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req models.UpdateWorkspaceMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request or payload"})
+		return
+	}
+	if err := workspaceValidator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+		return
+	}
+
+	workspace, ok := middleware.CurrentWorkspace(c)
+	if !ok {
+		return
+	}
+
+	repo, ok := workspaceRepositoryFrom(c)
+	if !ok {
+		return
+	}
+
+	if err := repo.SetWorkspaceMemberRole(workspace.ID, memberID, req.Role); err != nil {
+		respondMemberError(c, err, "Failed to update workspace member")
+		return
+	}
+
+	log.Printf("User ID %d is now %s in workspace %d", memberID, req.Role, workspace.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Workspace member updated successfully",
+		"user_id": memberID,
+		"role":    req.Role,
+	})
+}
+
+// RemoveWorkspaceMemberHandler removes a member from the workspace. Owners
+// may remove anyone; other members may only remove themselves, to leave.
+func RemoveWorkspaceMemberHandler(c *gin.Context) {
+	memberID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	workspace, ok := middleware.CurrentWorkspace(c)
+	if !ok {
+		return
+	}
+
+	if memberID != userID && workspace.Role != models.WorkspaceRoleOwner {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "Insufficient workspace role",
+			"message": "Only owners can remove other members",
+		})
+		return
+	}
+
+	repo, ok := workspaceRepositoryFrom(c)
+	if !ok {
+		return
+	}
+
+	if err := repo.RemoveWorkspaceMember(workspace.ID, memberID); err != nil {
+		respondMemberError(c, err, "Failed to remove workspace member")
+		return
+	}
+
+	log.Printf("User ID %d removed from workspace %d by user ID %d", memberID, workspace.ID, userID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Workspace member removed successfully",
+		"user_id": memberID,
+	})
+}
+
+// respondMemberError maps membership change errors to responses
+func respondMemberError(c *gin.Context, err error, failureMessage string) {
+	switch {
+	case errors.Is(err, repositories.ErrWorkspaceMemberNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Workspace member not found"})
+	case errors.Is(err, repositories.ErrLastWorkspaceOwner):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Workspace must keep an owner",
+			"message": "Make another member an owner first",
+		})
+	default:
+		log.Printf("%s: %v", failureMessage, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": failureMessage})
+	}
+}
@@ -0,0 +1,131 @@
+package workspaces
+
+import (
+	"link-guardian/internal/handlers/middleware"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories/memory"
+	"link-guardian/internal/repositories/repotest"
+	"link-guardian/internal/services/invitations"
+	"link-guardian/internal/services/mailer"
+	"link-guardian/internal/testutil/handlertest"
+	"link-guardian/internal/testutil/mailtest"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newTestRouter serves the workspace routes with the same middleware as the server
+func newTestRouter(store *memory.Store, mail mailer.Mailer) *gin.Engine {
+	router := handlertest.NewRouter(store)
+	router.Use(middleware.InvitationsMiddleware(invitations.NewService(handlertest.AuthService, mail, invitations.Options{
+		TokenTTL:  time.Hour,
+		AcceptURL: "http://app.test/accept-invitation",
+	})))
+
+	viewer := middleware.RequireWorkspaceRole(models.WorkspaceRoleViewer)
+	owner := middleware.RequireWorkspaceRole(models.WorkspaceRoleOwner)
+
+	protected := router.Group("")
+	protected.Use(middleware.JWTAuthMiddleware())
+	protected.GET("/workspaces", ListWorkspacesHandler)
+	protected.POST("/workspaces", CreateWorkspaceHandler)
+	protected.GET("/workspaces/:workspace_id/members", viewer, ListWorkspaceMembersHandler)
+	protected.PATCH("/workspaces/:workspace_id/members/:user_id", owner, UpdateWorkspaceMemberHandler)
+	protected.DELETE("/workspaces/:workspace_id/members/:user_id", viewer, RemoveWorkspaceMemberHandler)
+	protected.POST("/workspaces/:workspace_id/invitations", owner, CreateWorkspaceInvitationHandler)
+	protected.GET("/workspaces/:workspace_id/invitations", owner, ListWorkspaceInvitationsHandler)
+	protected.DELETE("/workspaces/:workspace_id/invitations/:invitation_id", owner, RevokeWorkspaceInvitationHandler)
+	protected.POST("/invitations/accept", AcceptWorkspaceInvitationHandler)
+	return router
+}
+
+var tokenPattern = regexp.MustCompile(`token=([^\s]+)`)
+
+func TestWorkspaceInvitationFlow(t *testing.T) {
+	store := memory.NewStore()
+	mail := mailtest.NewRecordingMailer()
+	router := newTestRouter(store, mail)
+	ownerID := repotest.CreateUser(t, store)
+	inviteeID := repotest.CreateUser(t, store)
+	invitee, err := store.GetUserByID(inviteeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := handlertest.Request(t, router, ownerID, http.MethodPost, "/workspaces", `{"name":"  Marketing  "}`)
+	var created struct {
+		Workspace models.Workspace `json:"workspace"`
+	}
+	handlertest.Decode(t, w, &created)
+	if w.Code != http.StatusCreated || created.Workspace.Name != "Marketing" || created.Workspace.Role != models.WorkspaceRoleOwner {
+		t.Fatalf("create: got %d: %s", w.Code, w.Body)
+	}
+	base := "/workspaces/" + strconv.Itoa(created.Workspace.ID)
+
+	personal, err := store.GetPersonalWorkspace(ownerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w = handlertest.Request(t, router, ownerID, http.MethodPost, "/workspaces/"+strconv.Itoa(personal.ID)+"/invitations", `{"email":"a@example.com","role":"viewer"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("invite to the personal workspace: got %d, want 400", w.Code)
+	}
+	if w := handlertest.Request(t, router, inviteeID, http.MethodGet, base+"/members", ""); w.Code != http.StatusNotFound {
+		t.Errorf("members for a non-member: got %d, want 404", w.Code)
+	}
+
+	w = handlertest.Request(t, router, ownerID, http.MethodPost, base+"/invitations", `{"email":"`+invitee.Email+`","role":"editor"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("invite: got %d: %s", w.Code, w.Body)
+	}
+	invitation := mail.Next(t, "")
+	if w := handlertest.Request(t, router, ownerID, http.MethodGet, base+"/invitations", ""); !strings.Contains(w.Body.String(), `"count":1`) {
+		t.Errorf("pending invitations: got %d: %s", w.Code, w.Body)
+	}
+
+	match := tokenPattern.FindStringSubmatch(invitation.Body)
+	if match == nil {
+		t.Fatalf("email body has no invitation link:\n%s", invitation.Body)
+	}
+	token, _ := url.QueryUnescape(match[1])
+	if w := handlertest.Request(t, router, ownerID, http.MethodPost, "/invitations/accept", `{"token":"`+token+`"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("accept by another account: got %d, want 400", w.Code)
+	}
+	if w := handlertest.Request(t, router, inviteeID, http.MethodPost, "/invitations/accept", `{"token":"`+token+`"}`); w.Code != http.StatusOK {
+		t.Fatalf("accept: got %d: %s", w.Code, w.Body)
+	}
+
+	w = handlertest.Request(t, router, inviteeID, http.MethodGet, "/workspaces", "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"count":2`) || !strings.Contains(w.Body.String(), "Marketing") {
+		t.Errorf("invitee workspaces: got %d: %s", w.Code, w.Body)
+	}
+
+	// Editors cannot manage members, but owners can and must leave an owner behind
+	memberPath := base + "/members/" + strconv.Itoa(ownerID)
+	if w := handlertest.Request(t, router, inviteeID, http.MethodPatch, memberPath, `{"role":"viewer"}`); w.Code != http.StatusForbidden {
+		t.Errorf("role change by an editor: got %d, want 403", w.Code)
+	}
+	if w := handlertest.Request(t, router, inviteeID, http.MethodDelete, memberPath, ""); w.Code != http.StatusForbidden {
+		t.Errorf("removal by an editor: got %d, want 403", w.Code)
+	}
+	if w := handlertest.Request(t, router, ownerID, http.MethodPatch, memberPath, `{"role":"viewer"}`); w.Code != http.StatusConflict {
+		t.Errorf("demoting the last owner: got %d, want 409", w.Code)
+	}
+	if w := handlertest.Request(t, router, ownerID, http.MethodPatch, base+"/members/"+strconv.Itoa(inviteeID), `{"role":"viewer"}`); w.Code != http.StatusOK {
+		t.Errorf("role change by the owner: got %d: %s", w.Code, w.Body)
+	}
+
+	// Any member may leave
+	if w := handlertest.Request(t, router, inviteeID, http.MethodDelete, base+"/members/"+strconv.Itoa(inviteeID), ""); w.Code != http.StatusOK {
+		t.Errorf("leaving: got %d: %s", w.Code, w.Body)
+	}
+	if w := handlertest.Request(t, router, inviteeID, http.MethodGet, base+"/members", ""); w.Code != http.StatusNotFound {
+		t.Errorf("members after leaving: got %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,90 @@
+// Package lifecycle starts long-running components in order and stops them
+// in reverse order on shutdown.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+)
+
+// Component is a named part of the application with optional start and stop
+// hooks. Start must not block; Stop should return once the component has
+// released its resources or ctx is done.
+type Component struct {
+	Name  string
+	Start func(ctx context.Context) error
+	Stop  func(ctx context.Context) error
+}
+
+// Manager runs the registered components. Components are started in the
+// order they were added and stopped in reverse, so each component can rely
+// on everything registered before it for its whole lifetime.
+type Manager struct {
+	mu         sync.Mutex
+	components []Component
+	started    int
+}
+
+// New creates an empty manager
+func New() *Manager {
+	return &Manager{}
+}
+
+// Add registers a component. Components added after Start are not started.
+func (m *Manager) Add(component Component) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.components = append(m.components, component)
+}
+
+// Start starts every component in order. If one fails, the components that
+// already started are stopped again and the error is returned.
+func (m *Manager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for m.started < len(m.components) {
+		component := m.components[m.started]
+		if component.Start != nil {
+			if err := component.Start(ctx); err != nil {
+				startErr := fmt.Errorf("failed to start %s: %w", component.Name, err)
+				return errors.Join(startErr, m.stopLocked(ctx))
+			}
+		}
+		m.started++
+		log.Printf("✅ Started %s", component.Name)
+	}
+
+	return nil
+}
+
+// Stop stops the started components in reverse order. Every component is
+// given the chance to stop even if an earlier one fails.
+func (m *Manager) Stop(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stopLocked(ctx)
+}
+
+func (m *Manager) stopLocked(ctx context.Context) error {
+	var errs []error
+	for m.started > 0 {
+		m.started--
+		component := m.components[m.started]
+		if component.Stop == nil {
+			continue
+		}
+
+		if err := component.Stop(ctx); err != nil {
+			log.Printf("Failed to stop %s: %v", component.Name, err)
+			errs = append(errs, fmt.Errorf("failed to stop %s: %w", component.Name, err))
+			continue
+		}
+		log.Printf("Stopped %s", component.Name)
+	}
+
+	return errors.Join(errs...)
+}
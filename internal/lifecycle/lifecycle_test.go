@@ -0,0 +1,90 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func recordingComponent(name string, events *[]string, startErr error) Component {
+	return Component{
+		Name: name,
+		Start: func(ctx context.Context) error {
+			*events = append(*events, "start "+name)
+			return startErr
+		},
+		Stop: func(ctx context.Context) error {
+			*events = append(*events, "stop "+name)
+			return nil
+		},
+	}
+}
+
+func TestManagerStartsInOrderAndStopsInReverse(t *testing.T) {
+	var events []string
+	manager := New()
+	manager.Add(recordingComponent("database", &events, nil))
+	manager.Add(recordingComponent("pipeline", &events, nil))
+	manager.Add(recordingComponent("server", &events, nil))
+
+	if err := manager.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"start database", "start pipeline", "start server", "stop server", "stop pipeline", "stop database"}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+
+	// A second Stop has nothing left to stop
+	events = nil
+	if err := manager.Stop(context.Background()); err != nil || len(events) != 0 {
+		t.Errorf("second Stop = %v, events %v", err, events)
+	}
+}
+
+func TestManagerRollsBackOnStartFailure(t *testing.T) {
+	var events []string
+	boom := errors.New("boom")
+	manager := New()
+	manager.Add(recordingComponent("database", &events, nil))
+	manager.Add(recordingComponent("pipeline", &events, boom))
+	manager.Add(recordingComponent("server", &events, nil))
+
+	err := manager.Start(context.Background())
+	if !errors.Is(err, boom) {
+		t.Fatalf("Start error = %v, want %v", err, boom)
+	}
+
+	want := []string{"start database", "start pipeline", "stop database"}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+}
+
+func TestManagerStopContinuesAfterErrors(t *testing.T) {
+	var stopped []string
+	manager := New()
+	manager.Add(Component{Name: "a", Stop: func(ctx context.Context) error {
+		stopped = append(stopped, "a")
+		return nil
+	}})
+	manager.Add(Component{Name: "b", Stop: func(ctx context.Context) error {
+		stopped = append(stopped, "b")
+		return errors.New("b failed")
+	}})
+
+	if err := manager.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.Stop(context.Background()); err == nil {
+		t.Error("expected Stop to report the failure")
+	}
+	if !reflect.DeepEqual(stopped, []string{"b", "a"}) {
+		t.Errorf("stopped = %v", stopped)
+	}
+}
@@ -0,0 +1,351 @@
+// Package migrations applies the versioned database schema embedded in the
+// binary and records each applied version in the schema_migrations table.
+//
+// Migrations live in sql/ as pairs of NNN_name.up.sql and NNN_name.down.sql
+// files. Each one runs in its own transaction together with its bookkeeping
+// row, so a failed migration leaves no trace.
+package migrations
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//go:embed sql/*.sql
+var embedded embed.FS
+
+// lockName identifies the advisory lock that serialises migration runs across replicas
+const lockName = "link-guardian:schema-migrations"
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// ErrChecksumMismatch is returned when an applied migration file was edited afterwards
+var ErrChecksumMismatch = errors.New("applied migration has been modified")
+
+// Migration is one schema version with its up and down scripts
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string // SHA-256 of the up script
+}
+
+// MigrationStatus describes whether a migration has been applied
+type MigrationStatus struct {
+	Migration
+	Applied         bool
+	AppliedAt       time.Time
+	ChecksumMatches bool
+}
+
+// Load reads the migrations in fsys, ordered by version. Every version must
+// have both an up and a down script.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("unexpected migration file name %s", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		migration, exists := byVersion[version]
+		if !exists {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names %s and %s", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(content)
+			sum := sha256.Sum256(content)
+			migration.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %s needs both an up and a down script", migration.fileName())
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// Runner applies and rolls back migrations against a database
+type Runner struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// NewRunner creates a runner for the migrations embedded in the binary
+func NewRunner(db *sql.DB) (*Runner, error) {
+	sqlFiles, err := fs.Sub(embedded, "sql")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations, err := Load(sqlFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Runner{db: db, migrations: migrations}, nil
+}
+
+// Latest returns the highest known version, or 0 when there are no migrations
+func (r *Runner) Latest() int {
+	if len(r.migrations) == 0 {
+		return 0
+	}
+	return r.migrations[len(r.migrations)-1].Version
+}
+
+// Status reports every known migration and whether it has been applied
+func (r *Runner) Status(ctx context.Context) ([]MigrationStatus, error) {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database connection: %w", err)
+	}
+	defer conn.Close()
+
+	if err := ensureTable(ctx, conn); err != nil {
+		return nil, err
+	}
+
+	applied, err := appliedMigrations(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(r.migrations))
+	for _, migration := range r.migrations {
+		status := MigrationStatus{Migration: migration}
+		if record, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = record.appliedAt
+			status.ChecksumMatches = record.checksum == migration.Checksum
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// Up applies every pending migration and returns the versions it applied
+func (r *Runner) Up(ctx context.Context) ([]int, error) {
+	return r.To(ctx, r.Latest())
+}
+
+// Down rolls back the most recently applied migrations, at most steps of them
+func (r *Runner) Down(ctx context.Context, steps int) ([]int, error) {
+	if steps < 1 {
+		return nil, errors.New("steps must be at least 1")
+	}
+
+	var rolledBack []int
+	err := r.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(r.migrations) - 1; i >= 0 && len(rolledBack) < steps; i-- {
+			migration := r.migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			if err := revert(ctx, conn, migration); err != nil {
+				return err
+			}
+			rolledBack = append(rolledBack, migration.Version)
+		}
+		return nil
+	})
+
+	return rolledBack, err
+}
+
+// To migrates up or down until exactly the migrations up to version are applied.
+// It returns the versions it applied or rolled back, in the order it did so.
+func (r *Runner) To(ctx context.Context, version int) ([]int, error) {
+	if version < 0 || version > r.Latest() {
+		return nil, fmt.Errorf("unknown migration version %d (latest is %d)", version, r.Latest())
+	}
+
+	var changed []int
+	err := r.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range r.migrations {
+			if record, ok := applied[migration.Version]; ok && record.checksum != migration.Checksum {
+				return fmt.Errorf("%w: %s", ErrChecksumMismatch, migration.fileName())
+			}
+		}
+
+		// Roll back newer migrations first, then apply older pending ones
+		for i := len(r.migrations) - 1; i >= 0; i-- {
+			migration := r.migrations[i]
+			if _, ok := applied[migration.Version]; ok && migration.Version > version {
+				if err := revert(ctx, conn, migration); err != nil {
+					return err
+				}
+				changed = append(changed, migration.Version)
+			}
+		}
+		for _, migration := range r.migrations {
+			if _, ok := applied[migration.Version]; !ok && migration.Version <= version {
+				if err := apply(ctx, conn, migration); err != nil {
+					return err
+				}
+				changed = append(changed, migration.Version)
+			}
+		}
+		return nil
+	})
+
+	return changed, err
+}
+
+// withLock runs fn on a dedicated connection holding the migration advisory
+// lock, so concurrent replicas apply migrations one at a time
+func (r *Runner) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get database connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock(hashtext($1))", lockName); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock(hashtext($1))", lockName)
+
+	if err := ensureTable(ctx, conn); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+func ensureTable(ctx context.Context, conn *sql.Conn) error {
+	query := `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		checksum CHAR(64) NOT NULL,
+		applied_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL
+	)`
+	if _, err := conn.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+	return nil
+}
+
+type appliedMigration struct {
+	checksum  string
+	appliedAt time.Time
+}
+
+func appliedMigrations(ctx context.Context, conn *sql.Conn) (map[int]appliedMigration, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, checksum, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := map[int]appliedMigration{}
+	for rows.Next() {
+		var version int
+		var record appliedMigration
+		if err := rows.Scan(&version, &record.checksum, &record.appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan schema_migrations row: %w", err)
+		}
+		applied[version] = record
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating schema_migrations rows: %w", err)
+	}
+
+	return applied, nil
+}
+
+func apply(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	err := inTx(ctx, conn, migration.Up,
+		"INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)",
+		migration.Version, migration.Name, migration.Checksum)
+	if err != nil {
+		return fmt.Errorf("failed to apply migration %s: %w", migration.fileName(), err)
+	}
+
+	log.Printf("✅ Applied migration: %s", migration.fileName())
+	return nil
+}
+
+func revert(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	err := inTx(ctx, conn, migration.Down,
+		"DELETE FROM schema_migrations WHERE version = $1", migration.Version)
+	if err != nil {
+		return fmt.Errorf("failed to roll back migration %s: %w", migration.fileName(), err)
+	}
+
+	log.Printf("✅ Rolled back migration: %s", migration.fileName())
+	return nil
+}
+
+// inTx runs a migration script and its bookkeeping statement in one transaction
+func inTx(ctx context.Context, conn *sql.Conn, script, bookkeeping string, args ...interface{}) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, bookkeeping, args...); err != nil {
+		return fmt.Errorf("failed to update schema_migrations: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (m Migration) fileName() string {
+	return fmt.Sprintf("%03d_%s", m.Version, m.Name)
+}
@@ -0,0 +1,155 @@
+package migrations_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"link-guardian/internal/migrations"
+	"link-guardian/internal/testutil/pgtest"
+	"os"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadPairsAndOrdersMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"010_second.up.sql":   {Data: []byte("SELECT 2")},
+		"010_second.down.sql": {Data: []byte("SELECT -2")},
+		"002_first.up.sql":    {Data: []byte("SELECT 1")},
+		"002_first.down.sql":  {Data: []byte("SELECT -1")},
+	}
+
+	loaded, err := migrations.Load(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 2 || loaded[0].Version != 2 || loaded[1].Version != 10 {
+		t.Fatalf("Load = %+v", loaded)
+	}
+	if loaded[0].Name != "first" || loaded[0].Up != "SELECT 1" || loaded[0].Down != "SELECT -1" {
+		t.Errorf("first migration = %+v", loaded[0])
+	}
+	if len(loaded[0].Checksum) != 64 || loaded[0].Checksum == loaded[1].Checksum {
+		t.Errorf("unexpected checksums %q and %q", loaded[0].Checksum, loaded[1].Checksum)
+	}
+}
+
+func TestLoadRejectsIncompleteMigrations(t *testing.T) {
+	tests := map[string]fstest.MapFS{
+		"missing down": {"001_users.up.sql": {Data: []byte("SELECT 1")}},
+		"bad name":     {"users.sql": {Data: []byte("SELECT 1")}},
+		"name clash": {
+			"001_users.up.sql":    {Data: []byte("SELECT 1")},
+			"001_people.down.sql": {Data: []byte("SELECT 1")},
+		},
+	}
+
+	for name, fsys := range tests {
+		if _, err := migrations.Load(fsys); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestEmbeddedMigrationsLoad(t *testing.T) {
+	runner, err := migrations.NewRunner(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runner.Latest() < 1 {
+		t.Error("no embedded migrations found")
+	}
+}
+
+// openEmptySchema connects to the test database with search_path set to a
+// fresh schema, so migrations can be rolled back without affecting the
+// tables other packages' tests are using
+func openEmptySchema(t *testing.T) *sql.DB {
+	t.Helper()
+
+	shared := pgtest.Open(t)
+	schema := "migrations_" + pgtest.RandomString(t, 8)
+	if _, err := shared.Exec("CREATE SCHEMA " + schema); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { shared.Exec("DROP SCHEMA " + schema + " CASCADE") })
+
+	conn, err := sql.Open("postgres", os.Getenv("TEST_DATABASE_DSN")+" search_path="+schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestRunnerUpDownAndTo(t *testing.T) {
+	conn := openEmptySchema(t)
+	ctx := context.Background()
+
+	runner, err := migrations.NewRunner(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest := runner.Latest()
+
+	applied, err := runner.Up(ctx)
+	if err != nil || len(applied) != latest {
+		t.Fatalf("Up = %v, %v", applied, err)
+	}
+	if again, err := runner.Up(ctx); err != nil || len(again) != 0 {
+		t.Fatalf("second Up = %v, %v", again, err)
+	}
+
+	rolledBack, err := runner.Down(ctx, 2)
+	if err != nil || len(rolledBack) != 2 || rolledBack[0] != latest {
+		t.Fatalf("Down(2) = %v, %v", rolledBack, err)
+	}
+
+	statuses, err := runner.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range statuses {
+		wantApplied := status.Version <= latest-2
+		if status.Applied != wantApplied {
+			t.Errorf("migration %d applied = %v, want %v", status.Version, status.Applied, wantApplied)
+		}
+		if status.Applied && !status.ChecksumMatches {
+			t.Errorf("migration %d checksum does not match", status.Version)
+		}
+	}
+
+	if changed, err := runner.To(ctx, latest); err != nil || len(changed) != 2 {
+		t.Fatalf("To(latest) = %v, %v", changed, err)
+	}
+	if changed, err := runner.To(ctx, 0); err != nil || len(changed) != latest {
+		t.Fatalf("To(0) = %v, %v", changed, err)
+	}
+	if _, err := runner.To(ctx, latest+1); err == nil {
+		t.Error("expected an error for an unknown version")
+	}
+}
+
+func TestRunnerRefusesModifiedMigrations(t *testing.T) {
+	conn := openEmptySchema(t)
+	ctx := context.Background()
+
+	runner, err := migrations.NewRunner(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runner.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := conn.Exec("UPDATE schema_migrations SET checksum = repeat('0', 64) WHERE version = 1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := runner.Up(ctx); !errors.Is(err, migrations.ErrChecksumMismatch) {
+		t.Errorf("Up with a modified migration = %v, want ErrChecksumMismatch", err)
+	}
+	if statuses, err := runner.Status(ctx); err != nil || statuses[0].ChecksumMatches {
+		t.Errorf("Status reports checksum match for modified migration: %v", err)
+	}
+}
@@ -0,0 +1,5 @@
+-- Associate links with the user who created them
+ALTER TABLE links ADD COLUMN IF NOT EXISTS user_id INTEGER REFERENCES users(id);
+
+-- Create index on user_id for efficient per-user queries
+CREATE INDEX IF NOT EXISTS idx_links_user_id ON links (user_id);
@@ -0,0 +1,14 @@
+-- Create link_revisions table to record every change made to a link
+CREATE TABLE IF NOT EXISTS link_revisions (
+    id BIGSERIAL PRIMARY KEY,
+    link_id BIGINT NOT NULL REFERENCES links(id) ON DELETE CASCADE,
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,  -- Who made the change
+    action VARCHAR(20) NOT NULL,                               -- update or rollback
+    old_values JSONB NOT NULL,                                 -- Editable fields before the change
+    new_values JSONB NOT NULL,                                 -- Editable fields after the change
+    reverted_revision_id BIGINT REFERENCES link_revisions(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- Create index for listing a link's history newest first
+CREATE INDEX IF NOT EXISTS idx_link_revisions_link_id ON link_revisions (link_id, created_at DESC);
@@ -0,0 +1,2 @@
+ALTER TABLE access_logs DROP COLUMN IF EXISTS event_type;
+ALTER TABLE links DROP COLUMN IF EXISTS password_hash;
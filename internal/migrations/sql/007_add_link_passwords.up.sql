@@ -0,0 +1,5 @@
+-- Add optional password protection to links (bcrypt hash, NULL when unprotected)
+ALTER TABLE links ADD COLUMN IF NOT EXISTS password_hash VARCHAR(255);
+
+-- Distinguish redirects from other recorded events such as password unlocks
+ALTER TABLE access_logs ADD COLUMN IF NOT EXISTS event_type VARCHAR(20) DEFAULT 'click' NOT NULL;
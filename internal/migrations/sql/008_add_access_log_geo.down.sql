@@ -0,0 +1,2 @@
+ALTER TABLE access_logs DROP COLUMN IF EXISTS asn;
+ALTER TABLE access_logs DROP COLUMN IF EXISTS region;
@@ -0,0 +1,3 @@
+-- Region and autonomous system resolved from the local GeoIP databases
+ALTER TABLE access_logs ADD COLUMN IF NOT EXISTS region VARCHAR(100);
+ALTER TABLE access_logs ADD COLUMN IF NOT EXISTS asn BIGINT;
@@ -0,0 +1,5 @@
+-- Record when a user proved ownership of their email address (NULL until verified)
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMPTZ;
+
+-- Accounts created before verification existed keep their current permissions
+UPDATE users SET email_verified_at = created_at WHERE email_verified_at IS NULL;
@@ -0,0 +1,21 @@
+-- Create user_totp table; a row is a pending enrolment until enabled_at is set.
+-- The secret is kept in clear because codes are computed from it.
+CREATE TABLE IF NOT EXISTS user_totp (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret VARCHAR(64) NOT NULL,
+    enabled_at TIMESTAMPTZ,
+    last_used_step BIGINT DEFAULT 0 NOT NULL,                   -- Newest accepted time step, so codes cannot be replayed
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- Create recovery_codes table; only a SHA-256 hash of each code is stored
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash CHAR(64) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    used_at TIMESTAMPTZ
+);
+
+-- Create index for looking up and replacing a user's codes
+CREATE INDEX IF NOT EXISTS idx_recovery_codes_user_id ON recovery_codes (user_id);
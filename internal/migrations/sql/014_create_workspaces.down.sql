@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_links_workspace_id;
+ALTER TABLE links DROP COLUMN IF EXISTS workspace_id;
+DROP TABLE IF EXISTS workspace_invitations;
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
@@ -0,0 +1,53 @@
+-- Create workspaces table; personal_user_id is set on the workspace created
+-- with each account, which only that user belongs to
+CREATE TABLE IF NOT EXISTS workspaces (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    personal_user_id INTEGER UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- Create workspace_members table
+CREATE TABLE IF NOT EXISTS workspace_members (
+    workspace_id INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(10) NOT NULL CHECK (role IN ('owner', 'editor', 'viewer')),
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (workspace_id, user_id)
+);
+
+-- Create index for listing a user's workspaces
+CREATE INDEX IF NOT EXISTS idx_workspace_members_user_id ON workspace_members (user_id);
+
+-- Create workspace_invitations table; only a SHA-256 hash of each token is stored
+CREATE TABLE IF NOT EXISTS workspace_invitations (
+    id BIGSERIAL PRIMARY KEY,
+    workspace_id INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(10) NOT NULL CHECK (role IN ('owner', 'editor', 'viewer')),
+    token_hash CHAR(64) UNIQUE NOT NULL,
+    invited_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    accepted_at TIMESTAMPTZ
+);
+
+-- Create index for listing a workspace's pending invitations
+CREATE INDEX IF NOT EXISTS idx_workspace_invitations_workspace_id ON workspace_invitations (workspace_id);
+
+-- Links belong to a workspace; user_id keeps recording who created them
+ALTER TABLE links ADD COLUMN IF NOT EXISTS workspace_id INTEGER REFERENCES workspaces(id);
+CREATE INDEX IF NOT EXISTS idx_links_workspace_id ON links (workspace_id);
+
+-- Give every existing user a personal workspace holding the links they created
+INSERT INTO workspaces (name, personal_user_id)
+SELECT 'Personal', id FROM users
+ON CONFLICT (personal_user_id) DO NOTHING;
+
+INSERT INTO workspace_members (workspace_id, user_id, role)
+SELECT id, personal_user_id, 'owner' FROM workspaces WHERE personal_user_id IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+UPDATE links SET workspace_id = workspaces.id
+FROM workspaces
+WHERE workspaces.personal_user_id = links.user_id AND links.workspace_id IS NULL;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_links_taken_down_at;
+ALTER TABLE links DROP COLUMN IF EXISTS taken_down_by;
+ALTER TABLE links DROP COLUMN IF EXISTS takedown_reason;
+ALTER TABLE links DROP COLUMN IF EXISTS taken_down_at;
+ALTER TABLE users DROP COLUMN IF EXISTS disabled_at;
+ALTER TABLE users DROP COLUMN IF EXISTS role;
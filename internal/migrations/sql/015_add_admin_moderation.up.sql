@@ -0,0 +1,13 @@
+-- Administrators moderate the instance; everyone else is a regular user
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'user'
+    CHECK (role IN ('user', 'admin'));
+
+-- Disabled accounts cannot sign in and their tokens and API keys are rejected
+ALTER TABLE users ADD COLUMN IF NOT EXISTS disabled_at TIMESTAMPTZ;
+
+-- Links taken down by an administrator stop redirecting but stay visible to their workspace
+ALTER TABLE links ADD COLUMN IF NOT EXISTS taken_down_at TIMESTAMPTZ;
+ALTER TABLE links ADD COLUMN IF NOT EXISTS takedown_reason TEXT;
+ALTER TABLE links ADD COLUMN IF NOT EXISTS taken_down_by INTEGER REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_links_taken_down_at ON links(taken_down_at) WHERE taken_down_at IS NOT NULL;
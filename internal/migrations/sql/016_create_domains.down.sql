@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_links_domain_slug;
+-- Links on custom domains are removed so slugs are globally unique again
+DELETE FROM links WHERE domain_id IS NOT NULL;
+ALTER TABLE links ADD CONSTRAINT links_slug_key UNIQUE (slug);
+ALTER TABLE links DROP COLUMN IF EXISTS domain_id;
+DROP TABLE IF EXISTS domains;
@@ -0,0 +1,22 @@
+-- Create domains table; workspaces register custom short domains and prove
+-- they control them with a DNS TXT record holding verification_token
+CREATE TABLE IF NOT EXISTS domains (
+    id SERIAL PRIMARY KEY,
+    workspace_id INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    hostname VARCHAR(253) NOT NULL,
+    verification_token VARCHAR(64) NOT NULL,
+    verified_at TIMESTAMPTZ,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    UNIQUE (workspace_id, hostname)
+);
+
+-- Any workspace may register a hostname, but only one can verify it
+CREATE UNIQUE INDEX IF NOT EXISTS idx_domains_verified_hostname ON domains (hostname) WHERE verified_at IS NOT NULL;
+
+-- Links without a domain use the default short domain
+ALTER TABLE links ADD COLUMN IF NOT EXISTS domain_id INTEGER REFERENCES domains(id) ON DELETE CASCADE;
+
+-- Slugs are unique per domain rather than globally
+ALTER TABLE links DROP CONSTRAINT IF EXISTS links_slug_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_links_domain_slug ON links (COALESCE(domain_id, 0), slug);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS link_tags;
+DROP TABLE IF EXISTS tags;
+DROP INDEX IF EXISTS idx_links_folder_id;
+ALTER TABLE links DROP COLUMN IF EXISTS folder_id;
+DROP TABLE IF EXISTS folders;
@@ -0,0 +1,30 @@
+-- Create folders table; each link is in at most one folder of its workspace
+CREATE TABLE IF NOT EXISTS folders (
+    id SERIAL PRIMARY KEY,
+    workspace_id INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    UNIQUE (workspace_id, name)
+);
+
+ALTER TABLE links ADD COLUMN IF NOT EXISTS folder_id INTEGER REFERENCES folders(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_links_folder_id ON links (folder_id);
+
+-- Create tags table; tag names are stored lowercased
+CREATE TABLE IF NOT EXISTS tags (
+    id SERIAL PRIMARY KEY,
+    workspace_id INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    UNIQUE (workspace_id, name)
+);
+
+-- Create link_tags table joining links to any number of tags
+CREATE TABLE IF NOT EXISTS link_tags (
+    link_id BIGINT NOT NULL REFERENCES links(id) ON DELETE CASCADE,
+    tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (link_id, tag_id)
+);
+
+-- Create index for listing and counting the links of a tag
+CREATE INDEX IF NOT EXISTS idx_link_tags_tag_id ON link_tags (tag_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_links_workspace_created_at;
+DROP INDEX IF EXISTS idx_links_target_url_trgm;
+DROP INDEX IF EXISTS idx_links_slug_trgm;
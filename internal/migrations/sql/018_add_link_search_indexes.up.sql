@@ -0,0 +1,8 @@
+-- Trigram indexes let searches for any part of a slug or target URL use ILIKE '%...%'.
+-- The down migration leaves pg_trgm installed, as it may have been before.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS idx_links_slug_trgm ON links USING GIN (slug gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_links_target_url_trgm ON links USING GIN (target_url gin_trgm_ops);
+
+-- Serve the default newest-first page of a workspace's links from an index
+CREATE INDEX IF NOT EXISTS idx_links_workspace_created_at ON links (workspace_id, created_at DESC, id DESC);
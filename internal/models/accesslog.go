@@ -4,15 +4,24 @@ import (
 	"time"
 )
 
+// Access log event types
+const (
+	EventClick  = "click"
+	EventUnlock = "unlock"
+)
+
 type AccessLog struct {
 	ID         int64     `json:"id"`
 	LinkID     int64     `json:"link_id"`
+	EventType  string    `json:"event_type"`
 	AccessedAt time.Time `json:"accessed_at"`
 	IPAddress  string    `json:"ip_address"`
 	UserAgent  string    `json:"user_agent"`
 	Referer    string    `json:"referer,omitempty"`
 	Country    string    `json:"country,omitempty"`
+	Region     string    `json:"region,omitempty"`
 	City       string    `json:"city,omitempty"`
+	ASN        int64     `json:"asn,omitempty"`
 	DeviceType string    `json:"device_type,omitempty"`
 	Browser    string    `json:"browser,omitempty"`
 	OS         string    `json:"os,omitempty"`
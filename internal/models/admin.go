@@ -0,0 +1,20 @@
+package models
+
+// TakedownLinkRequest is the body of POST /admin/links/:slug/takedown
+type TakedownLinkRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+// InstanceStats are instance-wide totals shown to admins
+type InstanceStats struct {
+	Users             int   `json:"users"`
+	AdminUsers        int   `json:"admin_users"`
+	DisabledUsers     int   `json:"disabled_users"`
+	UnverifiedUsers   int   `json:"unverified_users"`
+	SharedWorkspaces  int   `json:"shared_workspaces"`
+	Links             int   `json:"links"` // Links that are not deleted, including taken down ones
+	TakenDownLinks    int   `json:"taken_down_links"`
+	DeletedLinks      int   `json:"deleted_links"`
+	TotalClicks       int64 `json:"total_clicks"`
+	ClicksLast24Hours int64 `json:"clicks_last_24h"`
+}
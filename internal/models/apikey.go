@@ -0,0 +1,70 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// API key scopes
+const (
+	ScopeLinksRead     = "links:read"
+	ScopeLinksWrite    = "links:write"
+	ScopeAnalyticsRead = "analytics:read"
+)
+
+// APIKey is a personal API key. Requests made with it may only use the
+// routes its scopes allow.
+type APIKey struct {
+	ID         int64
+	UserID     int
+	Name       string
+	Prefix     string // Leading characters of the key, shown to tell keys apart
+	KeyHash    string // SHA-256 hex of the key; the key itself is never stored
+	Scopes     []string
+	CreatedAt  time.Time
+	LastUsedAt sql.NullTime
+	RevokedAt  sql.NullTime
+}
+
+// APIKeyResponse is the JSON representation of an API key
+type APIKeyResponse struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+// CreateAPIKeyRequest is the body of POST /api-keys
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name" validate:"required,max=100"`
+	Scopes []string `json:"scopes" validate:"required,min=1,dive,oneof=links:read links:write analytics:read"`
+}
+
+// ToResponse converts APIKey to APIKeyResponse with proper null handling
+func (k *APIKey) ToResponse() APIKeyResponse {
+	response := APIKeyResponse{
+		ID:        k.ID,
+		Name:      k.Name,
+		Prefix:    k.Prefix,
+		Scopes:    k.Scopes,
+		CreatedAt: k.CreatedAt,
+	}
+
+	if k.LastUsedAt.Valid {
+		response.LastUsedAt = &k.LastUsedAt.Time
+	}
+
+	return response
+}
+
+// HasScope reports whether the key grants scope
+func (k *APIKey) HasScope(scope string) bool {
+	for _, granted := range k.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
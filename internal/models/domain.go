@@ -0,0 +1,60 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Domain is a custom short domain registered by a workspace. Links can use
+// it once the workspace has proven it controls the hostname with a DNS TXT
+// record holding VerificationToken.
+type Domain struct {
+	ID                int           `json:"id"`
+	WorkspaceID       int           `json:"workspace_id"`
+	Hostname          string        `json:"hostname"`
+	VerificationToken string        `json:"-"`
+	VerifiedAt        sql.NullTime  `json:"-"`
+	CreatedBy         sql.NullInt32 `json:"-"`
+	CreatedAt         time.Time     `json:"created_at"`
+}
+
+// DNSRecord is a DNS record a workspace must publish
+type DNSRecord struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// DomainResponse is used for JSON serialization of a domain. The
+// verification record is only included until the domain is verified.
+type DomainResponse struct {
+	ID          int        `json:"id"`
+	WorkspaceID int        `json:"workspace_id"`
+	Hostname    string     `json:"hostname"`
+	Verified    bool       `json:"verified"`
+	VerifiedAt  *time.Time `json:"verified_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+
+	VerificationRecord *DNSRecord `json:"verification_record,omitempty"`
+}
+
+// ToResponse converts Domain to DomainResponse with proper null handling
+func (d *Domain) ToResponse() DomainResponse {
+	response := DomainResponse{
+		ID:          d.ID,
+		WorkspaceID: d.WorkspaceID,
+		Hostname:    d.Hostname,
+		Verified:    d.VerifiedAt.Valid,
+		CreatedAt:   d.CreatedAt,
+	}
+
+	if d.VerifiedAt.Valid {
+		response.VerifiedAt = &d.VerifiedAt.Time
+	}
+
+	return response
+}
+
+type CreateDomainRequest struct {
+	Hostname string `json:"hostname" validate:"required,fqdn,max=253"`
+}
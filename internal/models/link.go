@@ -2,6 +2,7 @@ package models
 
 import (
 	"database/sql"
+	"strings"
 	"time"
 )
 
@@ -14,7 +15,22 @@ type Link struct {
 	ClickLimit sql.NullInt32 `json:"click_limit"`
 	ClickCount int           `json:"click_count"`
 	DeletedAt  sql.NullTime  `json:"deleted_at,omitempty"`
-	UserID     sql.NullInt32 `json:"user_id,omitempty"`
+	UserID     sql.NullInt32 `json:"user_id,omitempty"` // The user who created the link
+	// WorkspaceID is the workspace whose members may manage the link
+	WorkspaceID sql.NullInt32 `json:"workspace_id,omitempty"`
+	// DomainID is the custom domain serving the link, with hostname Domain;
+	// links without one use the default short domain
+	DomainID sql.NullInt32  `json:"domain_id,omitempty"`
+	Domain   sql.NullString `json:"domain,omitempty"`
+	// Tags are the names of the link's tags, sorted, and Folder the name of
+	// its folder, both in the link's workspace
+	Tags   []string       `json:"tags"`
+	Folder sql.NullString `json:"folder,omitempty"`
+	// PasswordHash is set when the link is password protected
+	PasswordHash sql.NullString `json:"-"`
+	// TakenDownAt is set while an admin has taken the link down, for TakedownReason
+	TakenDownAt    sql.NullTime   `json:"taken_down_at,omitempty"`
+	TakedownReason sql.NullString `json:"takedown_reason,omitempty"`
 }
 
 // LinkResponse is used for JSON serialization with proper null handling
@@ -28,6 +44,16 @@ type LinkResponse struct {
 	ClickCount int        `json:"click_count"`
 	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
 	UserID     *int       `json:"user_id,omitempty"`
+
+	WorkspaceID       *int     `json:"workspace_id,omitempty"`
+	DomainID          *int     `json:"domain_id,omitempty"`
+	Domain            *string  `json:"domain,omitempty"`
+	Tags              []string `json:"tags"`
+	Folder            *string  `json:"folder,omitempty"`
+	PasswordProtected bool     `json:"password_protected"`
+
+	TakenDownAt    *time.Time `json:"taken_down_at,omitempty"`
+	TakedownReason *string    `json:"takedown_reason,omitempty"`
 }
 
 // ToResponse converts Link to LinkResponse with proper null handling
@@ -38,6 +64,13 @@ func (l *Link) ToResponse() LinkResponse {
 		TargetURL:  l.TargetURL,
 		CreatedAt:  l.CreatedAt,
 		ClickCount: l.ClickCount,
+		Tags:       l.Tags,
+
+		PasswordProtected: l.PasswordHash.Valid,
+	}
+
+	if response.Tags == nil {
+		response.Tags = []string{}
 	}
 
 	if l.ExpiresAt.Valid {
@@ -58,11 +91,188 @@ func (l *Link) ToResponse() LinkResponse {
 		response.UserID = &userID
 	}
 
+	if l.WorkspaceID.Valid {
+		workspaceID := int(l.WorkspaceID.Int32)
+		response.WorkspaceID = &workspaceID
+	}
+
+	if l.DomainID.Valid {
+		domainID := int(l.DomainID.Int32)
+		response.DomainID = &domainID
+		domain := l.Domain.String
+		response.Domain = &domain
+	}
+
+	if l.Folder.Valid {
+		folder := l.Folder.String
+		response.Folder = &folder
+	}
+
+	if l.TakenDownAt.Valid {
+		response.TakenDownAt = &l.TakenDownAt.Time
+		reason := l.TakedownReason.String
+		response.TakedownReason = &reason
+	}
+
 	return response
 }
 
+// Link statuses reported to owners
+const (
+	LinkStatusActive    = "active"
+	LinkStatusExpired   = "expired"
+	LinkStatusExhausted = "exhausted"
+	LinkStatusDeleted   = "deleted"
+	LinkStatusTakenDown = "taken_down"
+)
+
+// Status reports whether the link is active, expired, exhausted, deleted or
+// taken down at now
+func (l *Link) Status(now time.Time) string {
+	switch {
+	case l.DeletedAt.Valid:
+		return LinkStatusDeleted
+	case l.TakenDownAt.Valid:
+		return LinkStatusTakenDown
+	case l.ExpiresAt.Valid && !l.ExpiresAt.Time.After(now):
+		return LinkStatusExpired
+	case l.ClickLimit.Valid && l.ClickCount >= int(l.ClickLimit.Int32):
+		return LinkStatusExhausted
+	default:
+		return LinkStatusActive
+	}
+}
+
+// ClickOutcome describes the result of trying to consume a click on a link
+type ClickOutcome int
+
+const (
+	// ClickAllowed means the click was counted and the visitor may be redirected
+	ClickAllowed ClickOutcome = iota
+	// ClickExpired means the link is past its expiration time
+	ClickExpired
+	// ClickExhausted means the link has reached its click limit
+	ClickExhausted
+	// ClickNotFound means no active link matches the slug
+	ClickNotFound
+	// ClickLocked means the link is password protected and has not been unlocked
+	ClickLocked
+	// ClickTakenDown means an admin has taken the link down
+	ClickTakenDown
+)
+
 type CreateLinkRequest struct {
 	TargetURL  string     `json:"target_url" validate:"required,url"`
+	Slug       string     `json:"slug,omitempty" validate:"omitempty"`
+	Domain     string     `json:"domain,omitempty" validate:"omitempty,fqdn,max=253"` // Verified custom domain of the workspace; empty for the default
 	ExpiresAt  *time.Time `json:"expires_at,omitempty" validate:"omitempty"`
 	ClickLimit *int       `json:"click_limit,omitempty" validate:"omitempty,gt=0"`
+	Password   *string    `json:"password,omitempty" validate:"omitempty,min=8,max=128"`
+	Tags       []string   `json:"tags,omitempty" validate:"omitempty,max=20,dive,max=50"`
+	Folder     string     `json:"folder,omitempty" validate:"omitempty,max=100"`
+}
+
+type UpdateLinkRequest struct {
+	TargetURL       *string    `json:"target_url,omitempty" validate:"omitempty,url"`
+	ExpiresAt       *time.Time `json:"expires_at,omitempty" validate:"omitempty"`
+	ClickLimit      *int       `json:"click_limit,omitempty" validate:"omitempty,gt=0"`
+	ClearExpiresAt  bool       `json:"clear_expires_at,omitempty"`
+	ClearClickLimit bool       `json:"clear_click_limit,omitempty"`
+	// Tags and Folder are not part of the link's history; tags replace the
+	// current ones and an empty folder takes the link out of its folder
+	Tags   *[]string `json:"tags,omitempty" validate:"omitempty,max=20,dive,max=50"`
+	Folder *string   `json:"folder,omitempty" validate:"omitempty,max=100"`
+}
+
+// EditsState reports whether the request changes fields recorded in the link's history
+func (r UpdateLinkRequest) EditsState() bool {
+	return r.TargetURL != nil || r.ExpiresAt != nil || r.ClickLimit != nil || r.ClearExpiresAt || r.ClearClickLimit
+}
+
+// Organization returns the normalized tag and folder changes of the request
+func (r UpdateLinkRequest) Organization() LinkOrganization {
+	var organization LinkOrganization
+	if r.Tags != nil {
+		tags := NormalizeTags(*r.Tags)
+		organization.Tags = &tags
+	}
+	if r.Folder != nil {
+		folder := strings.TrimSpace(*r.Folder)
+		organization.Folder = &folder
+	}
+	return organization
+}
+
+// LinkState holds the editable fields of a link as recorded in its revisions
+type LinkState struct {
+	TargetURL  string     `json:"target_url"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	ClickLimit *int       `json:"click_limit"`
+}
+
+// State returns the editable fields of the link
+func (l *Link) State() LinkState {
+	state := LinkState{TargetURL: l.TargetURL}
+	if l.ExpiresAt.Valid {
+		expiresAt := l.ExpiresAt.Time
+		state.ExpiresAt = &expiresAt
+	}
+	if l.ClickLimit.Valid {
+		clickLimit := int(l.ClickLimit.Int32)
+		state.ClickLimit = &clickLimit
+	}
+	return state
+}
+
+// Equal reports whether two states hold the same values
+func (s LinkState) Equal(other LinkState) bool {
+	if s.TargetURL != other.TargetURL {
+		return false
+	}
+	if (s.ExpiresAt == nil) != (other.ExpiresAt == nil) ||
+		(s.ExpiresAt != nil && !s.ExpiresAt.Equal(*other.ExpiresAt)) {
+		return false
+	}
+	if (s.ClickLimit == nil) != (other.ClickLimit == nil) ||
+		(s.ClickLimit != nil && *s.ClickLimit != *other.ClickLimit) {
+		return false
+	}
+	return true
+}
+
+// Apply returns the state that results from applying the update request
+func (r UpdateLinkRequest) Apply(current LinkState) LinkState {
+	next := current
+	if r.TargetURL != nil {
+		next.TargetURL = *r.TargetURL
+	}
+	if r.ClearExpiresAt {
+		next.ExpiresAt = nil
+	} else if r.ExpiresAt != nil {
+		next.ExpiresAt = r.ExpiresAt
+	}
+	if r.ClearClickLimit {
+		next.ClickLimit = nil
+	} else if r.ClickLimit != nil {
+		next.ClickLimit = r.ClickLimit
+	}
+	return next
+}
+
+// Revision actions recorded in link_revisions
+const (
+	RevisionActionUpdate   = "update"
+	RevisionActionRollback = "rollback"
+)
+
+// LinkRevision is a recorded change to a link's editable fields
+type LinkRevision struct {
+	ID                 int64     `json:"id"`
+	LinkID             int       `json:"link_id"`
+	UserID             *int      `json:"user_id"`
+	Action             string    `json:"action"`
+	OldValues          LinkState `json:"old_values"`
+	NewValues          LinkState `json:"new_values"`
+	RevertedRevisionID *int64    `json:"reverted_revision_id,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
 }
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// LinkFilter narrows a listing of links
+type LinkFilter struct {
+	Query  string   // Slug or target URL contains this, ignoring case
+	Tags   []string // Links must have every one of these normalized, distinct tags
+	Folder string   // Links must be in the folder with this name; empty matches any
+	// Status is one of the link statuses; empty matches every link that is not deleted
+	Status string
+	// CreatedFrom (inclusive) and CreatedTo (exclusive) bound the creation
+	// time; zero times leave it unbounded
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	// HasClickLimit, when set, keeps the links with or without a click limit
+	HasClickLimit *bool
+}
+
+// ValidLinkStatus reports whether status is one of the link statuses
+func ValidLinkStatus(status string) bool {
+	switch status {
+	case LinkStatusActive, LinkStatusExpired, LinkStatusExhausted, LinkStatusDeleted, LinkStatusTakenDown:
+		return true
+	}
+	return false
+}
+
+// Fields links can be sorted by
+const (
+	LinkSortCreatedAt  = "created_at"
+	LinkSortClickCount = "click_count"
+	LinkSortExpiresAt  = "expires_at"
+)
+
+// ValidLinkSortField reports whether links can be sorted by field
+func ValidLinkSortField(field string) bool {
+	return field == LinkSortCreatedAt || field == LinkSortClickCount || field == LinkSortExpiresAt
+}
+
+// LinkSort orders a listing of links. Ties are broken by ID in the same
+// direction, and links without an expiry sort after every expiry time.
+type LinkSort struct {
+	Field string
+	Desc  bool
+}
+
+// CursorAt returns the cursor pointing at link in this order
+func (s LinkSort) CursorAt(link Link) LinkCursor {
+	cursor := LinkCursor{Sort: s.Field, Desc: s.Desc, ID: link.ID}
+	switch s.Field {
+	case LinkSortCreatedAt:
+		createdAt := link.CreatedAt
+		cursor.Time = &createdAt
+	case LinkSortClickCount:
+		cursor.ClickCount = link.ClickCount
+	case LinkSortExpiresAt:
+		if link.ExpiresAt.Valid {
+			expiresAt := link.ExpiresAt.Time
+			cursor.Time = &expiresAt
+		}
+	}
+	return cursor
+}
+
+// LinkPage selects part of a sorted listing of links
+type LinkPage struct {
+	Sort  LinkSort
+	After *LinkCursor // Start after the link the cursor points at; nil starts at the beginning
+	Limit int         // Most links returned
+}
+
+// ErrInvalidCursor is returned when a cursor cannot be decoded
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// LinkCursor points at a link in a sorted listing by the link's sort key
+// and ID, so pages stay stable while links are added or removed
+type LinkCursor struct {
+	Sort       string     `json:"s"`
+	Desc       bool       `json:"d,omitempty"`
+	ID         int        `json:"id"`
+	Time       *time.Time `json:"t,omitempty"` // CreatedAt or ExpiresAt; nil for links without an expiry
+	ClickCount int        `json:"c,omitempty"`
+}
+
+// Encode returns the cursor as an opaque URL-safe string
+func (c LinkCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParseLinkCursor decodes a cursor returned by Encode or returns ErrInvalidCursor
+func ParseLinkCursor(value string) (LinkCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return LinkCursor{}, ErrInvalidCursor
+	}
+
+	var cursor LinkCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID <= 0 || !ValidLinkSortField(cursor.Sort) ||
+		(cursor.Sort == LinkSortCreatedAt && cursor.Time == nil) {
+		return LinkCursor{}, ErrInvalidCursor
+	}
+	return cursor, nil
+}
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// Stats bucket sizes accepted by the link stats endpoint
+const (
+	StatsBucketHour = "hour"
+	StatsBucketDay  = "day"
+	StatsBucketWeek = "week"
+)
+
+// StatsPoint is the number of clicks in one time bucket
+type StatsPoint struct {
+	Bucket time.Time `json:"bucket"`
+	Clicks int64     `json:"clicks"`
+}
+
+// StatsBreakdownEntry is the number of clicks for one value of a dimension
+type StatsBreakdownEntry struct {
+	Value  string `json:"value"`
+	Clicks int64  `json:"clicks"`
+}
+
+// StatsBreakdowns groups clicks by visitor attributes
+type StatsBreakdowns struct {
+	Countries        []StatsBreakdownEntry `json:"countries"`
+	DeviceTypes      []StatsBreakdownEntry `json:"device_types"`
+	Browsers         []StatsBreakdownEntry `json:"browsers"`
+	OperatingSystems []StatsBreakdownEntry `json:"operating_systems"`
+	Referrers        []StatsBreakdownEntry `json:"referrers"`
+}
+
+// LinkStats is the aggregated click activity of a link over a time range
+type LinkStats struct {
+	Link           LinkResponse    `json:"link"`
+	Status         string          `json:"status"`
+	From           time.Time       `json:"from"`
+	To             time.Time       `json:"to"`
+	Bucket         string          `json:"bucket"`
+	Timezone       string          `json:"timezone"`
+	TotalClicks    int64           `json:"total_clicks"`
+	UniqueVisitors int64           `json:"unique_visitors"`
+	Series         []StatsPoint    `json:"series"`
+	Breakdowns     StatsBreakdowns `json:"breakdowns"`
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Tag labels links of a workspace; a link may have any number of tags.
+// Tags are created when first assigned to a link.
+type Tag struct {
+	ID          int       `json:"id"`
+	WorkspaceID int       `json:"workspace_id"`
+	Name        string    `json:"name"`
+	LinkCount   int       `json:"link_count"`  // Links with the tag that are not deleted
+	ClickCount  int       `json:"click_count"` // Total clicks on those links
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Folder groups links of a workspace; a link is in at most one folder.
+// Folders are created when a link is first moved into them.
+type Folder struct {
+	ID          int       `json:"id"`
+	WorkspaceID int       `json:"workspace_id"`
+	Name        string    `json:"name"`
+	LinkCount   int       `json:"link_count"`  // Links in the folder that are not deleted
+	ClickCount  int       `json:"click_count"` // Total clicks on those links
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// LinkOrganization changes the tags and folder of a link, which are not part
+// of its history. Nil fields are left unchanged.
+type LinkOrganization struct {
+	Tags   *[]string // Normalized names replacing the current tags
+	Folder *string   // Folder name; empty takes the link out of its folder
+}
+
+// IsZero reports whether the organization changes nothing
+func (o LinkOrganization) IsZero() bool {
+	return o.Tags == nil && o.Folder == nil
+}
+
+type RenameTagRequest struct {
+	Name string `json:"name" validate:"required,max=50"`
+}
+
+type MergeTagRequest struct {
+	TargetID int `json:"target_id" validate:"required"` // Tag that takes over the links
+}
+
+// NormalizeTag trims and lowercases a tag name
+func NormalizeTag(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// NormalizeTags normalizes tag names with NormalizeTag, dropping blank and
+// duplicate names and keeping the order of the rest
+func NormalizeTags(names []string) []string {
+	tags := []string{}
+	seen := make(map[string]bool)
+	for _, name := range names {
+		name = NormalizeTag(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		tags = append(tags, name)
+	}
+	return tags
+}
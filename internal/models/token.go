@@ -0,0 +1,45 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// RefreshToken is a stored refresh token. Tokens rotated from the same login
+// share a FamilyID, so reuse of a rotated token can revoke the whole chain.
+type RefreshToken struct {
+	ID         int64
+	UserID     int
+	FamilyID   string
+	TokenHash  string // SHA-256 hex of the token; the token itself is never stored
+	ExpiresAt  time.Time
+	CreatedAt  time.Time
+	RevokedAt  sql.NullTime
+	ReplacedBy sql.NullInt64
+}
+
+// RefreshRequest is the body of POST /auth/refresh and POST /auth/logout
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// PasswordResetToken is a stored single-use password reset token
+type PasswordResetToken struct {
+	ID        int64
+	UserID    int
+	TokenHash string // SHA-256 hex of the token; the token itself is never stored
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UsedAt    sql.NullTime
+}
+
+// ForgotPasswordRequest is the body of POST /auth/forgot
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ResetPasswordRequest is the body of POST /auth/reset
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
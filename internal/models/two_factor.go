@@ -0,0 +1,41 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// TwoFactor is a user's TOTP enrolment. It is pending until the user confirms
+// it with a first code, which sets EnabledAt.
+type TwoFactor struct {
+	UserID            int
+	Secret            string // Base32 TOTP secret
+	EnabledAt         sql.NullTime
+	LastUsedStep      int64 // Time step of the newest accepted code
+	CreatedAt         time.Time
+	RecoveryCodesLeft int
+}
+
+// Enabled reports whether logins require a second factor
+func (t TwoFactor) Enabled() bool {
+	return t.EnabledAt.Valid
+}
+
+// TwoFactorCodeRequest is the body of POST /auth/2fa/enable
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// DisableTwoFactorRequest is the body of POST /auth/2fa/disable. Code may be
+// an authenticator code or a recovery code.
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+// TwoFactorLoginRequest is the body of POST /login/2fa. Code may be an
+// authenticator code or a recovery code.
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required"`
+}
@@ -1,12 +1,59 @@
 package models
 
+import (
+	"database/sql"
+	"time"
+)
+
+// User roles. Admins can use the /admin API to moderate users and links.
+const (
+	UserRoleUser  = "user"
+	UserRoleAdmin = "admin"
+)
+
 type User struct {
-	ID        int    `json:"id"`
-	Username  string `json:"username" binding:"required,min=3,max=50"`
-	Email     string `json:"email" binding:"required,email"`
-	Password  string `json:"password" binding:"required,min=6,max=100"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	ID               int    `json:"id"`
+	Username         string `json:"username" binding:"required,min=3,max=50"`
+	Email            string `json:"email" binding:"required,email"`
+	Password         string `json:"password" binding:"required,min=6,max=100"`
+	EmailVerified    bool   `json:"email_verified"`
+	TwoFactorEnabled bool   `json:"two_factor_enabled"`
+	Role             string `json:"role"`
+	// DisabledAt is set while an admin has disabled the account
+	DisabledAt sql.NullTime `json:"-"`
+	CreatedAt  string       `json:"created_at"`
+	UpdatedAt  string       `json:"updated_at"`
+}
+
+// AdminUserResponse is a user as listed in the admin API, without the password hash
+type AdminUserResponse struct {
+	ID               int        `json:"id"`
+	Username         string     `json:"username"`
+	Email            string     `json:"email"`
+	Role             string     `json:"role"`
+	EmailVerified    bool       `json:"email_verified"`
+	TwoFactorEnabled bool       `json:"two_factor_enabled"`
+	Disabled         bool       `json:"disabled"`
+	DisabledAt       *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt        string     `json:"created_at"`
+}
+
+// ToAdminResponse converts the user for the admin API
+func (u *User) ToAdminResponse() AdminUserResponse {
+	response := AdminUserResponse{
+		ID:               u.ID,
+		Username:         u.Username,
+		Email:            u.Email,
+		Role:             u.Role,
+		EmailVerified:    u.EmailVerified,
+		TwoFactorEnabled: u.TwoFactorEnabled,
+		Disabled:         u.DisabledAt.Valid,
+		CreatedAt:        u.CreatedAt,
+	}
+	if u.DisabledAt.Valid {
+		response.DisabledAt = &u.DisabledAt.Time
+	}
+	return response
 }
 
 type SignupRequest struct {
@@ -19,3 +66,8 @@ type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required"`
 }
+
+// VerifyEmailRequest is the body of POST /auth/verify
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
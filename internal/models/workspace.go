@@ -0,0 +1,101 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Workspace roles, from most to least privileged. Viewers can read links and
+// their analytics, editors can also create, change and delete links, and
+// owners can also manage members and invitations.
+const (
+	WorkspaceRoleOwner  = "owner"
+	WorkspaceRoleEditor = "editor"
+	WorkspaceRoleViewer = "viewer"
+)
+
+// workspaceRoleRanks orders the roles; a higher rank grants everything a lower one does
+var workspaceRoleRanks = map[string]int{
+	WorkspaceRoleViewer: 1,
+	WorkspaceRoleEditor: 2,
+	WorkspaceRoleOwner:  3,
+}
+
+// ValidWorkspaceRole reports whether role is owner, editor or viewer
+func ValidWorkspaceRole(role string) bool {
+	return workspaceRoleRanks[role] > 0
+}
+
+// WorkspaceRoleAtLeast reports whether role grants everything min does
+func WorkspaceRoleAtLeast(role, min string) bool {
+	return ValidWorkspaceRole(role) && workspaceRoleRanks[role] >= workspaceRoleRanks[min]
+}
+
+// WorkspaceRolesAtLeast lists the roles that grant everything min does
+func WorkspaceRolesAtLeast(min string) []string {
+	roles := []string{}
+	for _, role := range []string{WorkspaceRoleOwner, WorkspaceRoleEditor, WorkspaceRoleViewer} {
+		if WorkspaceRoleAtLeast(role, min) {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// PersonalWorkspaceName is the name of the workspace created with each account
+const PersonalWorkspaceName = "Personal"
+
+// Workspace groups links shared by its members. Every user has a personal
+// workspace, created with their account, that only they belong to.
+type Workspace struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Personal  bool      `json:"personal"`
+	CreatedAt time.Time `json:"created_at"`
+	// Role is the role of the user the workspace was loaded for
+	Role string `json:"role"`
+}
+
+// WorkspaceMember is a user's membership of a workspace
+type WorkspaceMember struct {
+	UserID    int       `json:"user_id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"joined_at"`
+}
+
+// WorkspaceInvitation is a stored invitation to join a workspace, accepted
+// with a single-use token emailed to the invited address
+type WorkspaceInvitation struct {
+	ID          int64        `json:"id"`
+	WorkspaceID int          `json:"workspace_id"`
+	Email       string       `json:"email"`
+	Role        string       `json:"role"`
+	TokenHash   string       `json:"-"` // SHA-256 hex of the token; the token itself is never stored
+	InvitedBy   int          `json:"invited_by"`
+	ExpiresAt   time.Time    `json:"expires_at"`
+	CreatedAt   time.Time    `json:"created_at"`
+	AcceptedAt  sql.NullTime `json:"-"`
+}
+
+// CreateWorkspaceRequest is the body of POST /workspaces
+type CreateWorkspaceRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+// UpdateWorkspaceMemberRequest is the body of PATCH /workspaces/:workspace_id/members/:user_id
+type UpdateWorkspaceMemberRequest struct {
+	Role string `json:"role" validate:"required,oneof=owner editor viewer"`
+}
+
+// CreateWorkspaceInvitationRequest is the body of POST /workspaces/:workspace_id/invitations
+type CreateWorkspaceInvitationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+	Role  string `json:"role" validate:"required,oneof=owner editor viewer"`
+}
+
+// AcceptWorkspaceInvitationRequest is the body of POST /invitations/accept
+type AcceptWorkspaceInvitationRequest struct {
+	Token string `json:"token" validate:"required"`
+}
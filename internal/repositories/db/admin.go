@@ -0,0 +1,187 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"strings"
+)
+
+// likeEscaper escapes the LIKE wildcards in user input
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// containsPattern returns an ILIKE pattern matching values that contain query
+func containsPattern(query string) string {
+	return "%" + likeEscaper.Replace(query) + "%"
+}
+
+// SearchUsers implements repositories.AdminRepository
+func (s *Store) SearchUsers(query string, limit, offset int) ([]models.User, int, error) {
+	condition := "username ILIKE $1 OR email ILIKE $1"
+	pattern := containsPattern(query)
+
+	var total int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM users WHERE "+condition, pattern).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	rows, err := s.db.Query("SELECT "+userColumns+" FROM users WHERE "+condition+
+		" ORDER BY created_at DESC, id DESC LIMIT $2 OFFSET $3", pattern, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, *user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating user rows: %w", err)
+	}
+
+	return users, total, nil
+}
+
+// SetUserRole implements repositories.AdminRepository
+func (s *Store) SetUserRole(userID int, role string) error {
+	result, err := s.db.Exec("UPDATE users SET role = $2 WHERE id = $1", userID, role)
+	if err != nil {
+		return fmt.Errorf("failed to set user role: %w", err)
+	}
+	return requireUserRow(result)
+}
+
+// SetUserDisabled implements repositories.AdminRepository
+func (s *Store) SetUserDisabled(userID int, disabled bool) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start updating user: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := "UPDATE users SET disabled_at = NULL WHERE id = $1"
+	if disabled {
+		query = "UPDATE users SET disabled_at = COALESCE(disabled_at, NOW()) WHERE id = $1"
+	}
+	result, err := tx.Exec(query, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+	if err := requireUserRow(result); err != nil {
+		return err
+	}
+
+	if disabled {
+		if _, err := tx.Exec("UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL", userID); err != nil {
+			return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit user update: %w", err)
+	}
+	return nil
+}
+
+// requireUserRow returns ErrUserNotFound unless the statement changed a row
+func requireUserRow(result sql.Result) error {
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+	if rows == 0 {
+		return repositories.ErrUserNotFound
+	}
+	return nil
+}
+
+// SearchLinks implements repositories.AdminRepository
+func (s *Store) SearchLinks(query string, limit, offset int) ([]models.Link, int, error) {
+	condition := "deleted_at IS NULL AND (slug ILIKE $1 OR target_url ILIKE $1)"
+	pattern := containsPattern(query)
+
+	var total int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM links WHERE "+condition, pattern).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count links: %w", err)
+	}
+
+	rows, err := s.db.Query("SELECT "+linkColumns+" FROM links WHERE "+condition+
+		" ORDER BY created_at DESC, id DESC LIMIT $2 OFFSET $3", pattern, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search links: %w", err)
+	}
+	defer rows.Close()
+
+	links := []models.Link{}
+	for rows.Next() {
+		link, err := scanLink(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan link row: %w", err)
+		}
+		links = append(links, link)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating links rows: %w", err)
+	}
+
+	return links, total, nil
+}
+
+// TakeDownLink implements repositories.AdminRepository. Taking down a link
+// again only replaces the reason.
+func (s *Store) TakeDownLink(domainID int, slug string, adminID int, reason string) (models.Link, error) {
+	query := `UPDATE links SET taken_down_at = COALESCE(taken_down_at, NOW()), takedown_reason = $3, taken_down_by = $4
+		WHERE ` + linkSlugCondition + ` AND deleted_at IS NULL RETURNING ` + linkColumns
+	return scanModeratedLink(s.db.QueryRow(query, domainID, slug, reason, adminID))
+}
+
+// RestoreLink implements repositories.AdminRepository
+func (s *Store) RestoreLink(domainID int, slug string) (models.Link, error) {
+	query := `UPDATE links SET taken_down_at = NULL, takedown_reason = NULL, taken_down_by = NULL
+		WHERE ` + linkSlugCondition + ` AND deleted_at IS NULL RETURNING ` + linkColumns
+	return scanModeratedLink(s.db.QueryRow(query, domainID, slug))
+}
+
+// scanModeratedLink scans the link returned by a takedown change
+func scanModeratedLink(row *sql.Row) (models.Link, error) {
+	link, err := scanLink(row)
+	if err == sql.ErrNoRows {
+		return models.Link{}, repositories.ErrLinkNotFound
+	}
+	if err != nil {
+		return models.Link{}, fmt.Errorf("failed to update link takedown: %w", err)
+	}
+	return link, nil
+}
+
+// GetInstanceStats implements repositories.AdminRepository
+func (s *Store) GetInstanceStats() (models.InstanceStats, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM users),
+			(SELECT COUNT(*) FROM users WHERE role = $1),
+			(SELECT COUNT(*) FROM users WHERE disabled_at IS NOT NULL),
+			(SELECT COUNT(*) FROM users WHERE email_verified_at IS NULL),
+			(SELECT COUNT(*) FROM workspaces WHERE personal_user_id IS NULL),
+			(SELECT COUNT(*) FROM links WHERE deleted_at IS NULL),
+			(SELECT COUNT(*) FROM links WHERE deleted_at IS NULL AND taken_down_at IS NOT NULL),
+			(SELECT COUNT(*) FROM links WHERE deleted_at IS NOT NULL),
+			(SELECT COALESCE(SUM(click_count), 0) FROM links),
+			(SELECT COUNT(*) FROM access_logs WHERE event_type = $2 AND accessed_at >= NOW() - INTERVAL '24 hours')
+	`
+
+	var stats models.InstanceStats
+	err := s.db.QueryRow(query, models.UserRoleAdmin, models.EventClick).Scan(&stats.Users, &stats.AdminUsers,
+		&stats.DisabledUsers, &stats.UnverifiedUsers, &stats.SharedWorkspaces, &stats.Links, &stats.TakenDownLinks,
+		&stats.DeletedLinks, &stats.TotalClicks, &stats.ClicksLast24Hours)
+	if err != nil {
+		return models.InstanceStats{}, fmt.Errorf("failed to count instance stats: %w", err)
+	}
+	return stats, nil
+}
@@ -0,0 +1,96 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// apiKeyColumns lists the api_keys columns scanned by scanAPIKey
+const apiKeyColumns = "id, user_id, name, prefix, key_hash, scopes, created_at, last_used_at, revoked_at"
+
+func scanAPIKey(row rowScanner) (models.APIKey, error) {
+	var key models.APIKey
+	err := row.Scan(&key.ID, &key.UserID, &key.Name, &key.Prefix, &key.KeyHash, pq.Array(&key.Scopes),
+		&key.CreatedAt, &key.LastUsedAt, &key.RevokedAt)
+	return key, err
+}
+
+// CreateAPIKey implements repositories.APIKeyRepository
+func (s *Store) CreateAPIKey(key models.APIKey) (models.APIKey, error) {
+	query := `INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes)
+			  VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`
+	err := s.db.QueryRow(query, key.UserID, key.Name, key.Prefix, key.KeyHash, pq.Array(key.Scopes)).Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		return models.APIKey{}, fmt.Errorf("failed to insert API key: %w", err)
+	}
+	return key, nil
+}
+
+// ListAPIKeysByUser implements repositories.APIKeyRepository
+func (s *Store) ListAPIKeysByUser(userID int) ([]models.APIKey, error) {
+	query := "SELECT " + apiKeyColumns + " FROM api_keys WHERE user_id = $1 AND revoked_at IS NULL ORDER BY created_at DESC, id DESC"
+	rows, err := s.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API key row: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating API key rows: %w", err)
+	}
+
+	return keys, nil
+}
+
+// GetAPIKeyByHash implements repositories.APIKeyRepository
+func (s *Store) GetAPIKeyByHash(keyHash string) (models.APIKey, error) {
+	query := "SELECT " + apiKeyColumns + " FROM api_keys WHERE key_hash = $1 AND revoked_at IS NULL"
+	key, err := scanAPIKey(s.db.QueryRow(query, keyHash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.APIKey{}, repositories.ErrAPIKeyNotFound
+		}
+		return models.APIKey{}, fmt.Errorf("failed to get API key: %w", err)
+	}
+	return key, nil
+}
+
+// TouchAPIKey implements repositories.APIKeyRepository
+func (s *Store) TouchAPIKey(keyID int64, usedAt time.Time) error {
+	if _, err := s.db.Exec("UPDATE api_keys SET last_used_at = $1 WHERE id = $2", usedAt, keyID); err != nil {
+		return fmt.Errorf("failed to record API key use: %w", err)
+	}
+	return nil
+}
+
+// RevokeAPIKey implements repositories.APIKeyRepository
+func (s *Store) RevokeAPIKey(keyID int64, userID int) error {
+	result, err := s.db.Exec("UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL", keyID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	if rows == 0 {
+		return repositories.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+)
+
+// domainColumns lists the domains columns scanned by scanDomain
+const domainColumns = "id, workspace_id, hostname, verification_token, verified_at, created_by, created_at"
+
+func scanDomain(row rowScanner) (models.Domain, error) {
+	var domain models.Domain
+	err := row.Scan(&domain.ID, &domain.WorkspaceID, &domain.Hostname, &domain.VerificationToken, &domain.VerifiedAt,
+		&domain.CreatedBy, &domain.CreatedAt)
+	return domain, err
+}
+
+// CreateDomain implements repositories.DomainRepository. The insert is
+// skipped when another workspace has verified the hostname.
+func (s *Store) CreateDomain(domain models.Domain) (models.Domain, error) {
+	query := `INSERT INTO domains (workspace_id, hostname, verification_token, created_by)
+			  SELECT $1, $2, $3, $4
+			  WHERE NOT EXISTS (SELECT 1 FROM domains WHERE hostname = $2 AND verified_at IS NOT NULL)
+			  RETURNING id, created_at`
+
+	err := s.db.QueryRow(query, domain.WorkspaceID, domain.Hostname, domain.VerificationToken, domain.CreatedBy).
+		Scan(&domain.ID, &domain.CreatedAt)
+	if err == sql.ErrNoRows || isUniqueViolation(err, "") {
+		return models.Domain{}, repositories.ErrDomainTaken
+	}
+	if err != nil {
+		return models.Domain{}, fmt.Errorf("failed to insert domain: %w", err)
+	}
+
+	return domain, nil
+}
+
+// ListDomainsByWorkspace implements repositories.DomainRepository
+func (s *Store) ListDomainsByWorkspace(workspaceID int) ([]models.Domain, error) {
+	rows, err := s.db.Query("SELECT "+domainColumns+" FROM domains WHERE workspace_id = $1 ORDER BY id", workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list domains: %w", err)
+	}
+	defer rows.Close()
+
+	domains := []models.Domain{}
+	for rows.Next() {
+		domain, err := scanDomain(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan domain row: %w", err)
+		}
+		domains = append(domains, domain)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating domain rows: %w", err)
+	}
+
+	return domains, nil
+}
+
+// GetDomain implements repositories.DomainRepository
+func (s *Store) GetDomain(workspaceID, domainID int) (models.Domain, error) {
+	query := "SELECT " + domainColumns + " FROM domains WHERE workspace_id = $1 AND id = $2"
+	return s.getDomain(s.db.QueryRow(query, workspaceID, domainID))
+}
+
+// GetVerifiedDomainByHostname implements repositories.DomainRepository
+func (s *Store) GetVerifiedDomainByHostname(hostname string) (models.Domain, error) {
+	query := "SELECT " + domainColumns + " FROM domains WHERE hostname = $1 AND verified_at IS NOT NULL"
+	return s.getDomain(s.db.QueryRow(query, hostname))
+}
+
+// MarkDomainVerified implements repositories.DomainRepository. The partial
+// unique index on verified hostnames decides between concurrent verifications.
+func (s *Store) MarkDomainVerified(workspaceID, domainID int) (models.Domain, error) {
+	query := `UPDATE domains SET verified_at = COALESCE(verified_at, NOW())
+			  WHERE workspace_id = $1 AND id = $2 RETURNING ` + domainColumns
+
+	domain, err := scanDomain(s.db.QueryRow(query, workspaceID, domainID))
+	if isUniqueViolation(err, "idx_domains_verified_hostname") {
+		return models.Domain{}, repositories.ErrDomainTaken
+	}
+	if err == sql.ErrNoRows {
+		return models.Domain{}, repositories.ErrDomainNotFound
+	}
+	if err != nil {
+		return models.Domain{}, fmt.Errorf("failed to verify domain: %w", err)
+	}
+	return domain, nil
+}
+
+// DeleteDomain implements repositories.DomainRepository. The domain row is
+// locked first, so links cannot be created on it while the check runs.
+func (s *Store) DeleteDomain(workspaceID, domainID int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start deleting domain: %w", err)
+	}
+	defer tx.Rollback()
+
+	var id int
+	err = tx.QueryRow("SELECT id FROM domains WHERE workspace_id = $1 AND id = $2 FOR UPDATE", workspaceID, domainID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return repositories.ErrDomainNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get domain: %w", err)
+	}
+
+	var inUse bool
+	if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM links WHERE domain_id = $1 AND deleted_at IS NULL)", id).Scan(&inUse); err != nil {
+		return fmt.Errorf("failed to check domain links: %w", err)
+	}
+	if inUse {
+		return repositories.ErrDomainInUse
+	}
+
+	// Deleted links on the domain go with it
+	if _, err := tx.Exec("DELETE FROM domains WHERE id = $1", id); err != nil {
+		return fmt.Errorf("failed to delete domain: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit domain deletion: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) getDomain(row *sql.Row) (models.Domain, error) {
+	domain, err := scanDomain(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Domain{}, repositories.ErrDomainNotFound
+		}
+		return models.Domain{}, fmt.Errorf("failed to get domain: %w", err)
+	}
+	return domain, nil
+}
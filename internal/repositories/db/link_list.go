@@ -0,0 +1,151 @@
+package db
+
+import (
+	"fmt"
+	"link-guardian/internal/models"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// linkQuery collects the conditions of a links query and numbers their arguments
+type linkQuery struct {
+	conditions []string
+	args       []interface{}
+}
+
+// arg adds an argument and returns its placeholder
+func (q *linkQuery) arg(value interface{}) string {
+	q.args = append(q.args, value)
+	return fmt.Sprintf("$%d", len(q.args))
+}
+
+func (q *linkQuery) where(condition string) {
+	q.conditions = append(q.conditions, condition)
+}
+
+func (q *linkQuery) String() string {
+	return strings.Join(q.conditions, " AND ")
+}
+
+// liveLinkCondition matches links that are neither deleted nor taken down
+const liveLinkCondition = "deleted_at IS NULL AND taken_down_at IS NULL"
+
+// linkStatusConditions match the links whose Status is the key
+var linkStatusConditions = map[string]string{
+	"":                         "deleted_at IS NULL",
+	models.LinkStatusDeleted:   "deleted_at IS NOT NULL",
+	models.LinkStatusTakenDown: "deleted_at IS NULL AND taken_down_at IS NOT NULL",
+	models.LinkStatusExpired:   liveLinkCondition + " AND expires_at <= NOW()",
+	models.LinkStatusExhausted: liveLinkCondition + " AND (expires_at IS NULL OR expires_at > NOW()) AND click_count >= click_limit",
+	models.LinkStatusActive: liveLinkCondition + " AND (expires_at IS NULL OR expires_at > NOW())" +
+		" AND (click_limit IS NULL OR click_count < click_limit)",
+}
+
+// ListLinksByWorkspace implements repositories.LinkRepository. Searches use
+// the trigram indexes on slug and target_url.
+func (s *Store) ListLinksByWorkspace(workspaceID int, filter models.LinkFilter, page models.LinkPage) ([]models.Link, int, error) {
+	statusCondition, ok := linkStatusConditions[filter.Status]
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown link status %q", filter.Status)
+	}
+	order, err := linkOrder(page.Sort)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var q linkQuery
+	q.where("workspace_id = " + q.arg(workspaceID))
+	q.where(statusCondition)
+	if filter.Query != "" {
+		pattern := q.arg(containsPattern(filter.Query))
+		q.where(fmt.Sprintf("(slug ILIKE %s OR target_url ILIKE %s)", pattern, pattern))
+	}
+	if filter.Folder != "" {
+		q.where(fmt.Sprintf("folder_id = (SELECT id FROM folders WHERE workspace_id = $1 AND name = %s)", q.arg(filter.Folder)))
+	}
+	if len(filter.Tags) > 0 {
+		q.where(fmt.Sprintf(`(SELECT COUNT(*) FROM link_tags JOIN tags ON tags.id = link_tags.tag_id
+			WHERE link_tags.link_id = links.id AND tags.name = ANY(%s)) = %s`, q.arg(pq.Array(filter.Tags)), q.arg(len(filter.Tags))))
+	}
+	if !filter.CreatedFrom.IsZero() {
+		q.where("created_at >= " + q.arg(filter.CreatedFrom))
+	}
+	if !filter.CreatedTo.IsZero() {
+		q.where("created_at < " + q.arg(filter.CreatedTo))
+	}
+	if filter.HasClickLimit != nil {
+		if *filter.HasClickLimit {
+			q.where("click_limit IS NOT NULL")
+		} else {
+			q.where("click_limit IS NULL")
+		}
+	}
+
+	var total int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM links WHERE "+q.String(), q.args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count links: %w", err)
+	}
+
+	if page.After != nil {
+		q.where(afterLinkCondition(&q, page.Sort, *page.After))
+	}
+
+	query := "SELECT " + linkColumns + " FROM links WHERE " + q.String() + " ORDER BY " + order + " LIMIT " + q.arg(page.Limit)
+	links, err := s.queryLinks(query, q.args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return links, total, nil
+}
+
+// linkOrder returns the ORDER BY clause of sort
+func linkOrder(sort models.LinkSort) (string, error) {
+	direction := "ASC"
+	if sort.Desc {
+		direction = "DESC"
+	}
+
+	switch sort.Field {
+	case models.LinkSortCreatedAt, models.LinkSortClickCount:
+		return fmt.Sprintf("%s %s, id %s", sort.Field, direction, direction), nil
+	case models.LinkSortExpiresAt:
+		// Links without an expiry never expire, so they come last
+		if sort.Desc {
+			return "expires_at DESC NULLS FIRST, id DESC", nil
+		}
+		return "expires_at ASC NULLS LAST, id ASC", nil
+	}
+	return "", fmt.Errorf("unknown link sort field %q", sort.Field)
+}
+
+// afterLinkCondition matches the links that come after cursor in sort order,
+// adding its arguments to q
+func afterLinkCondition(q *linkQuery, sort models.LinkSort, cursor models.LinkCursor) string {
+	op := ">"
+	if sort.Desc {
+		op = "<"
+	}
+
+	switch sort.Field {
+	case models.LinkSortCreatedAt:
+		return fmt.Sprintf("(created_at, id) %s (%s, %s)", op, q.arg(*cursor.Time), q.arg(cursor.ID))
+	case models.LinkSortClickCount:
+		return fmt.Sprintf("(click_count, id) %s (%s, %s)", op, q.arg(cursor.ClickCount), q.arg(cursor.ID))
+	}
+
+	// expires_at, where NULL sorts after every time
+	id := q.arg(cursor.ID)
+	if cursor.Time == nil {
+		if sort.Desc {
+			return fmt.Sprintf("(expires_at IS NOT NULL OR id < %s)", id)
+		}
+		return fmt.Sprintf("(expires_at IS NULL AND id > %s)", id)
+	}
+	expiresAt := q.arg(*cursor.Time)
+	condition := fmt.Sprintf("(expires_at %s %s OR (expires_at = %s AND id %s %s)", op, expiresAt, expiresAt, op, id)
+	if !sort.Desc {
+		condition += " OR expires_at IS NULL"
+	}
+	return condition + ")"
+}
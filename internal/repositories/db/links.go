@@ -2,88 +2,279 @@ package db
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"link-guardian/internal/models"
-	"time"
+	"link-guardian/internal/repositories"
+	"sort"
+
+	"github.com/lib/pq"
 )
 
-var db *sql.DB
+// Store implements the repositories on PostgreSQL
+type Store struct {
+	db *sql.DB
+}
+
+var _ repositories.Store = (*Store)(nil)
+
+// NewStore creates a store using the given connection pool
+func NewStore(database *sql.DB) *Store {
+	return &Store{db: database}
+}
 
-func InitDB(database *sql.DB) {
-	db = database
+// isUniqueViolation reports whether err is a PostgreSQL unique_violation,
+// optionally on a specific constraint
+func isUniqueViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505" && (constraint == "" || pqErr.Constraint == constraint)
 }
 
-func InsertLinktoDB(Slug string, TargetURL string, CreatedAt time.Time, ExpiresAt sql.NullTime, ClickLimit sql.NullInt32, ClickCount int, UserID sql.NullInt32) error {
-	query := `INSERT INTO links (slug, target_url, created_at, expires_at, click_limit, click_count, user_id) 
-			  VALUES ($1, $2, $3, $4, $5, $6, $7)`
-	_, err := db.Exec(query, Slug, TargetURL, CreatedAt, ExpiresAt, ClickLimit, ClickCount, UserID)
+// linkColumns lists the links columns scanned by scanLink, with the hostname
+// of the link's domain, the name of its folder and its sorted tag names
+const linkColumns = "id, slug, target_url, created_at, expires_at, click_limit, click_count, deleted_at, user_id, workspace_id, password_hash, " +
+	"taken_down_at, takedown_reason, domain_id, (SELECT hostname FROM domains WHERE domains.id = links.domain_id), " +
+	"(SELECT name FROM folders WHERE folders.id = links.folder_id), " +
+	"ARRAY(SELECT tags.name FROM link_tags JOIN tags ON tags.id = link_tags.tag_id WHERE link_tags.link_id = links.id ORDER BY tags.name)"
+
+// linkSlugCondition matches the link with slug $2 on domain $1, 0 being the default domain
+const linkSlugCondition = "COALESCE(domain_id, 0) = $1 AND slug = $2"
+
+// scanLink scans a row selected with linkColumns
+func scanLink(row rowScanner) (models.Link, error) {
+	var link models.Link
+	err := row.Scan(&link.ID, &link.Slug, &link.TargetURL, &link.CreatedAt, &link.ExpiresAt, &link.ClickLimit,
+		&link.ClickCount, &link.DeletedAt, &link.UserID, &link.WorkspaceID, &link.PasswordHash, &link.TakenDownAt, &link.TakedownReason,
+		&link.DomainID, &link.Domain, &link.Folder, pq.Array(&link.Tags))
+	return link, err
+}
 
-	return err
+// queryRower is implemented by *sql.DB and *sql.Tx
+type queryRower interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
 }
 
-func CheckIfSlugUnique(slug string) (string, error) {
-	var exists bool
-	query := "SELECT EXISTS(SELECT 1 FROM links WHERE slug = $1)"
-	err := db.QueryRow(query, slug).Scan(&exists)
+// checkLinkAccess returns ErrLinkForbidden unless userID is a member of the
+// link's workspace with at least role
+func checkLinkAccess(q queryRower, link models.Link, userID int, role string) error {
+	if !link.WorkspaceID.Valid {
+		return repositories.ErrLinkForbidden
+	}
+
+	query := "SELECT EXISTS(SELECT 1 FROM workspace_members WHERE workspace_id = $1 AND user_id = $2 AND role = ANY($3))"
+	var allowed bool
+	if err := q.QueryRow(query, link.WorkspaceID, userID, pq.Array(models.WorkspaceRolesAtLeast(role))).Scan(&allowed); err != nil {
+		return fmt.Errorf("failed to check workspace role: %w", err)
+	}
+	if !allowed {
+		return repositories.ErrLinkForbidden
+	}
+	return nil
+}
+
+// CreateLink implements repositories.LinkRepository. The link, its tags and
+// its folder are stored in one transaction.
+func (s *Store) CreateLink(link models.Link) (models.Link, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return models.Link{}, fmt.Errorf("failed to start link insert: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO links (slug, target_url, created_at, expires_at, click_limit, click_count, user_id, workspace_id, password_hash, domain_id) 
+			  VALUES ($1, $2, COALESCE($3, NOW()), $4, $5, $6, $7, $8, $9, $10) RETURNING id, created_at`
 
+	var createdAt sql.NullTime
+	if !link.CreatedAt.IsZero() {
+		createdAt = sql.NullTime{Time: link.CreatedAt, Valid: true}
+	}
+
+	err = tx.QueryRow(query, link.Slug, link.TargetURL, createdAt, link.ExpiresAt, link.ClickLimit, link.ClickCount,
+		link.UserID, link.WorkspaceID, link.PasswordHash, link.DomainID).Scan(&link.ID, &link.CreatedAt)
+	if isUniqueViolation(err, "") {
+		return models.Link{}, repositories.ErrSlugTaken
+	}
 	if err != nil {
-		return "", fmt.Errorf("Database check for slug uniqueness failed: %w", err)
+		return models.Link{}, fmt.Errorf("failed to insert link: %w", err)
+	}
+
+	if link.WorkspaceID.Valid {
+		workspaceID := int(link.WorkspaceID.Int32)
+		if len(link.Tags) > 0 {
+			if err := setLinkTags(tx, link.ID, workspaceID, link.Tags); err != nil {
+				return models.Link{}, err
+			}
+			link.Tags = append([]string(nil), link.Tags...)
+			sort.Strings(link.Tags)
+		}
+		if link.Folder.Valid {
+			if err := setLinkFolder(tx, link.ID, workspaceID, link.Folder.String); err != nil {
+				return models.Link{}, err
+			}
+		}
+	} else {
+		// Tags and folders belong to workspaces
+		link.Tags, link.Folder = nil, sql.NullString{}
 	}
 
-	if !exists {
-		return slug, nil
+	if err := tx.Commit(); err != nil {
+		return models.Link{}, fmt.Errorf("failed to commit link insert: %w", err)
 	}
-	return "", fmt.Errorf("Found slug already in database")
+
+	return link, nil
 }
 
-func GetLinkBySlug(slug string) (models.Link, error) {
-	var link models.Link
+// FilterAvailableSlugs implements repositories.LinkRepository
+func (s *Store) FilterAvailableSlugs(domainID int, candidates []string) ([]string, error) {
+	rows, err := s.db.Query("SELECT slug FROM links WHERE COALESCE(domain_id, 0) = $1 AND slug = ANY($2)", domainID, pq.Array(candidates))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check slug availability: %w", err)
+	}
+	defer rows.Close()
+
+	taken := make(map[string]bool)
+	for rows.Next() {
+		var slug string
+		if err := rows.Scan(&slug); err != nil {
+			return nil, fmt.Errorf("failed to scan slug row: %w", err)
+		}
+		taken[slug] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating slug rows: %w", err)
+	}
 
-	query := "SELECT id, slug, target_url, created_at, expires_at, click_limit, click_count, deleted_at, user_id FROM links WHERE slug = $1 AND deleted_at IS NULL"
+	available := []string{}
+	for _, candidate := range candidates {
+		if !taken[candidate] {
+			available = append(available, candidate)
+		}
+	}
+	return available, nil
+}
 
-	err := db.QueryRow(query, slug).Scan(&link.ID, &link.Slug, &link.TargetURL, &link.CreatedAt, &link.ExpiresAt, &link.ClickLimit, &link.ClickCount, &link.DeletedAt, &link.UserID)
+// GetLinkBySlug implements repositories.LinkRepository
+func (s *Store) GetLinkBySlug(domainID int, slug string) (models.Link, error) {
+	query := "SELECT " + linkColumns + " FROM links WHERE " + linkSlugCondition + " AND deleted_at IS NULL"
+
+	link, err := scanLink(s.db.QueryRow(query, domainID, slug))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return models.Link{}, fmt.Errorf("link not found")
+			return models.Link{}, repositories.ErrLinkNotFound
 		}
 		return models.Link{}, fmt.Errorf("failed to get link: %w", err)
 	}
 	return link, nil
 }
 
-func IncrementClickCount(slug string) error {
-	query := "UPDATE links SET click_count = click_count + 1 WHERE slug = $1"
-	result, err := db.Exec(query, slug)
+// ConsumeClick implements repositories.LinkRepository. The check and the
+// increment happen in one statement, so concurrent callers can never push
+// click_count past click_limit.
+func (s *Store) ConsumeClick(domainID int, slug string, unlocked bool) (models.Link, models.ClickOutcome, error) {
+	query := `
+		WITH claimed AS (
+			UPDATE links SET click_count = click_count + 1
+			WHERE COALESCE(domain_id, 0) = $1 AND slug = $2 AND deleted_at IS NULL AND taken_down_at IS NULL
+				AND (expires_at IS NULL OR expires_at > NOW())
+				AND (click_limit IS NULL OR click_count < click_limit)
+				AND (password_hash IS NULL OR $3)
+			RETURNING id, click_count
+		)
+		SELECT l.id, l.slug, l.target_url, l.created_at, l.expires_at, l.click_limit,
+			COALESCE(c.click_count, l.click_count), l.deleted_at, l.user_id, l.workspace_id, l.password_hash,
+			l.taken_down_at, l.takedown_reason, l.domain_id, d.hostname,
+			CASE
+				WHEN c.id IS NOT NULL THEN 'allowed'
+				WHEN l.taken_down_at IS NOT NULL THEN 'taken_down'
+				WHEN l.expires_at IS NOT NULL AND l.expires_at <= NOW() THEN 'expired'
+				WHEN l.click_limit IS NOT NULL AND l.click_count >= l.click_limit THEN 'exhausted'
+				WHEN l.password_hash IS NOT NULL AND NOT $3 THEN 'locked'
+				ELSE 'exhausted'
+			END
+		FROM links l
+		LEFT JOIN claimed c ON c.id = l.id
+		LEFT JOIN domains d ON d.id = l.domain_id
+		WHERE COALESCE(l.domain_id, 0) = $1 AND l.slug = $2 AND l.deleted_at IS NULL
+	`
+
+	var link models.Link
+	var outcome string
+	err := s.db.QueryRow(query, domainID, slug, unlocked).Scan(&link.ID, &link.Slug, &link.TargetURL, &link.CreatedAt, &link.ExpiresAt,
+		&link.ClickLimit, &link.ClickCount, &link.DeletedAt, &link.UserID, &link.WorkspaceID, &link.PasswordHash,
+		&link.TakenDownAt, &link.TakedownReason, &link.DomainID, &link.Domain, &outcome)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Link{}, models.ClickNotFound, nil
+		}
+		return models.Link{}, models.ClickNotFound, fmt.Errorf("failed to consume click: %w", err)
+	}
+
+	switch outcome {
+	case "allowed":
+		return link, models.ClickAllowed, nil
+	case "expired":
+		return link, models.ClickExpired, nil
+	case "locked":
+		return link, models.ClickLocked, nil
+	case "taken_down":
+		return link, models.ClickTakenDown, nil
+	default:
+		return link, models.ClickExhausted, nil
+	}
+}
+
+// IncrementClickCount implements repositories.LinkRepository
+func (s *Store) IncrementClickCount(linkID int) (bool, error) {
+	query := `UPDATE links SET click_count = click_count + 1
+		WHERE id = $1 AND deleted_at IS NULL AND taken_down_at IS NULL
+			AND (expires_at IS NULL OR expires_at > NOW())
+			AND click_limit IS NULL AND password_hash IS NULL`
+	result, err := s.db.Exec(query, linkID)
 	if err != nil {
-		return fmt.Errorf("failed to increment click count: %w", err)
+		return false, fmt.Errorf("failed to increment click count: %w", err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("error getting rows affected: %w", err)
+		return false, fmt.Errorf("error getting rows affected: %w", err)
 	}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("link not found")
+	return rowsAffected == 1, nil
+}
+
+// ListLinksByUser implements repositories.LinkRepository
+func (s *Store) ListLinksByUser(userID int) ([]models.Link, error) {
+	return s.listLinks("user_id = $1", userID)
+}
+
+// CountLinksByUser implements repositories.LinkRepository
+func (s *Store) CountLinksByUser(userID int) (int, error) {
+	var count int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM links WHERE deleted_at IS NULL AND user_id = $1", userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count links: %w", err)
 	}
+	return count, nil
+}
 
-	return nil
+// listLinks returns the active links matching condition, newest first
+func (s *Store) listLinks(condition string, args ...interface{}) ([]models.Link, error) {
+	query := "SELECT " + linkColumns + " FROM links WHERE deleted_at IS NULL AND " + condition + " ORDER BY created_at DESC, id DESC"
+	return s.queryLinks(query, args...)
 }
 
-func GetAllLinks(userID int) ([]models.Link, error) {
+// queryLinks returns the links selected with linkColumns by query
+func (s *Store) queryLinks(query string, args ...interface{}) ([]models.Link, error) {
 	var links []models.Link
 
-	query := "SELECT id, slug, target_url, created_at, expires_at, click_limit, click_count, deleted_at, user_id FROM links WHERE deleted_at IS NULL AND user_id = $1 ORDER BY created_at DESC"
-
-	rows, err := db.Query(query, userID)
+	rows, err := s.db.Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get links: %w", err)
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		var link models.Link
-		if err := rows.Scan(&link.ID, &link.Slug, &link.TargetURL, &link.CreatedAt, &link.ExpiresAt, &link.ClickLimit, &link.ClickCount, &link.DeletedAt, &link.UserID); err != nil {
+		link, err := scanLink(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan link row: %w", err)
 		}
 		links = append(links, link)
@@ -96,26 +287,30 @@ func GetAllLinks(userID int) ([]models.Link, error) {
 	return links, nil
 }
 
-func SoftDeleteLink(slug string, userID int) error {
-	// First check if link belongs to the user
-	var linkUserID int
-	checkQuery := "SELECT user_id FROM links WHERE slug = $1 AND deleted_at IS NULL"
-	err := db.QueryRow(checkQuery, slug).Scan(&linkUserID)
+// GetMemberLinkBySlug implements repositories.LinkRepository
+func (s *Store) GetMemberLinkBySlug(domainID int, slug string, userID int, role string) (models.Link, error) {
+	link, err := s.GetLinkBySlug(domainID, slug)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return fmt.Errorf("link not found")
-		}
-		return fmt.Errorf("failed to check link ownership: %w", err)
+		return models.Link{}, err
+	}
+
+	if err := checkLinkAccess(s.db, link, userID, role); err != nil {
+		return models.Link{}, err
 	}
 
-	// Verify ownership
-	if linkUserID != userID {
-		return fmt.Errorf("unauthorized: link belongs to a different user")
+	return link, nil
+}
+
+// SoftDeleteLink implements repositories.LinkRepository
+func (s *Store) SoftDeleteLink(domainID int, slug string, userID int) error {
+	// First check that the user may edit links in the link's workspace
+	if _, err := s.GetMemberLinkBySlug(domainID, slug, userID, models.WorkspaceRoleEditor); err != nil {
+		return err
 	}
 
 	// Proceed with deletion
-	query := "UPDATE links SET deleted_at = NOW() WHERE slug = $1 AND deleted_at IS NULL"
-	result, err := db.Exec(query, slug)
+	query := "UPDATE links SET deleted_at = NOW() WHERE " + linkSlugCondition + " AND deleted_at IS NULL"
+	result, err := s.db.Exec(query, domainID, slug)
 	if err != nil {
 		return fmt.Errorf("failed to delete link: %w", err)
 	}
@@ -126,7 +321,7 @@ func SoftDeleteLink(slug string, userID int) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("link not found or already deleted")
+		return repositories.ErrLinkNotFound
 	}
 
 	return nil
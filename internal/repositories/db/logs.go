@@ -4,157 +4,129 @@ import (
 	"database/sql"
 	"fmt"
 	"link-guardian/internal/models"
-	"strings"
 	"time"
-)
-
-// InsertAccessLog inserts a new access log entry into the database
-func InsertAccessLogToDB(linkID int, ipAddress, userAgent, referer, country, city, deviceType, browser, os string, accessedAt time.Time) error {
-	query := `INSERT INTO access_logs 
-		(link_id, ip_address, user_agent, referer, country, city, device_type, browser, os, accessed_at) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
 
-	_, err := db.Exec(query, linkID, ipAddress, userAgent, referer, country, city, deviceType, browser, os, accessedAt)
-	if err != nil {
-		return fmt.Errorf("failed to log access: %w", err)
-	}
-
-	return nil
-}
+	"github.com/lib/pq"
+)
 
-// ParseUserAgent extracts device, browser and OS information from user agent string
-func ParseUserAgent(userAgent string) (deviceType, browser, os string) {
-	userAgent = strings.ToLower(userAgent)
-
-	// Determine device type
-	if strings.Contains(userAgent, "mobile") || strings.Contains(userAgent, "android") || strings.Contains(userAgent, "iphone") {
-		deviceType = "mobile"
-	} else if strings.Contains(userAgent, "tablet") || strings.Contains(userAgent, "ipad") {
-		deviceType = "tablet"
-	} else {
-		deviceType = "desktop"
+// InsertAccessLogs implements repositories.AccessLogRepository with a single
+// statement for the whole batch
+func (s *Store) InsertAccessLogs(logs []models.AccessLog) error {
+	if len(logs) == 0 {
+		return nil
 	}
 
-	// Determine browser
-	if strings.Contains(userAgent, "firefox") {
-		browser = "Firefox"
-	} else if strings.Contains(userAgent, "chrome") && !strings.Contains(userAgent, "edg") {
-		browser = "Chrome"
-	} else if strings.Contains(userAgent, "safari") && !strings.Contains(userAgent, "chrome") {
-		browser = "Safari"
-	} else if strings.Contains(userAgent, "edg") {
-		browser = "Edge"
-	} else if strings.Contains(userAgent, "opera") {
-		browser = "Opera"
-	} else {
-		browser = "Other"
-	}
+	linkIDs := make([]int64, len(logs))
+	eventTypes := make([]string, len(logs))
+	accessedAt := make([]string, len(logs))
+	ipAddresses := make([]string, len(logs))
+	userAgents := make([]string, len(logs))
+	referers := make([]string, len(logs))
+	countries := make([]string, len(logs))
+	regions := make([]string, len(logs))
+	cities := make([]string, len(logs))
+	asns := make([]int64, len(logs))
+	deviceTypes := make([]string, len(logs))
+	browsers := make([]string, len(logs))
+	oses := make([]string, len(logs))
+
+	for i, entry := range logs {
+		eventType := entry.EventType
+		if eventType == "" {
+			eventType = models.EventClick
+		}
+		if entry.AccessedAt.IsZero() {
+			entry.AccessedAt = time.Now()
+		}
 
-	// Determine OS
-	if strings.Contains(userAgent, "windows") {
-		os = "Windows"
-	} else if strings.Contains(userAgent, "mac os") {
-		os = "macOS"
-	} else if strings.Contains(userAgent, "linux") {
-		os = "Linux"
-	} else if strings.Contains(userAgent, "android") {
-		os = "Android"
-	} else if strings.Contains(userAgent, "iphone") || strings.Contains(userAgent, "ipad") {
-		os = "iOS"
-	} else {
-		os = "Other"
+		linkIDs[i] = entry.LinkID
+		eventTypes[i] = eventType
+		accessedAt[i] = entry.AccessedAt.UTC().Format(time.RFC3339Nano)
+		ipAddresses[i] = entry.IPAddress
+		userAgents[i] = entry.UserAgent
+		referers[i] = entry.Referer
+		countries[i] = entry.Country
+		regions[i] = entry.Region
+		cities[i] = entry.City
+		asns[i] = entry.ASN
+		deviceTypes[i] = entry.DeviceType
+		browsers[i] = entry.Browser
+		oses[i] = entry.OS
 	}
 
-	return
-}
-
-// LogAccessWithDetails records a new access log entry with additional details
-func LogAccessWithDetails(linkID int64, ipAddress, userAgent, referer string) error {
-	// Parse user agent for additional details
-	deviceType, browser, os := ParseUserAgent(userAgent)
-
-	// Full query with all fields
-	query := `INSERT INTO access_logs 
-		(link_id, ip_address, user_agent, referer, device_type, browser, os) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7)`
-
-	// Execute the query
-	_, err := db.Exec(query, linkID, ipAddress, userAgent, referer, deviceType, browser, os)
+	query := `INSERT INTO access_logs
+		(link_id, event_type, accessed_at, ip_address, user_agent, referer,
+			country, region, city, asn, device_type, browser, os)
+		SELECT v.link_id, v.event_type, v.accessed_at, v.ip_address, v.user_agent, v.referer,
+			NULLIF(left(v.country, 2), ''), NULLIF(left(v.region, 100), ''), NULLIF(left(v.city, 100), ''),
+			NULLIF(v.asn, 0), v.device_type, v.browser, v.os
+		FROM unnest($1::bigint[], $2::text[], $3::timestamptz[], $4::text[], $5::text[], $6::text[],
+			$7::text[], $8::text[], $9::text[], $10::bigint[], $11::text[], $12::text[], $13::text[])
+			AS v(link_id, event_type, accessed_at, ip_address, user_agent, referer,
+				country, region, city, asn, device_type, browser, os)
+		WHERE EXISTS (SELECT 1 FROM links l WHERE l.id = v.link_id)`
+
+	_, err := s.db.Exec(query, pq.Array(linkIDs), pq.Array(eventTypes), pq.Array(accessedAt),
+		pq.Array(ipAddresses), pq.Array(userAgents), pq.Array(referers), pq.Array(countries),
+		pq.Array(regions), pq.Array(cities), pq.Array(asns), pq.Array(deviceTypes),
+		pq.Array(browsers), pq.Array(oses))
 	if err != nil {
-		return fmt.Errorf("failed to log access with details: %w", err)
+		return fmt.Errorf("failed to insert access log batch: %w", err)
 	}
 
 	return nil
 }
 
-// GetAccessLogs fetches access logs, optionally filtered by linkID and limited
-func GetAccessLogs(linkID string, limit int) ([]models.AccessLog, error) {
-	var rows *sql.Rows
-	var err error
-	if linkID != "" {
-		rows, err = db.Query(`SELECT id, link_id, accessed_at, ip_address, user_agent FROM access_logs WHERE link_id = $1 ORDER BY accessed_at DESC LIMIT $2`, linkID, limit)
-	} else {
-		rows, err = db.Query(`SELECT id, link_id, accessed_at, ip_address, user_agent FROM access_logs ORDER BY accessed_at DESC LIMIT $1`, limit)
-	}
+// accessLogColumns lists the access_logs columns scanned by scanAccessLogs
+const accessLogColumns = `al.id, al.link_id, al.event_type, al.accessed_at, al.ip_address, COALESCE(al.user_agent, ''),
+	COALESCE(al.referer, ''), COALESCE(al.country, ''), COALESCE(al.region, ''), COALESCE(al.city, ''),
+	COALESCE(al.asn, 0), COALESCE(al.device_type, ''), COALESCE(al.browser, ''), COALESCE(al.os, '')`
+
+// GetAccessLogsByWorkspace implements repositories.AccessLogRepository
+func (s *Store) GetAccessLogsByWorkspace(workspaceID int, linkID string, limit int) ([]models.AccessLog, error) {
+	query := `
+		SELECT ` + accessLogColumns + `
+		FROM access_logs al
+		JOIN links l ON al.link_id = l.id
+		WHERE l.workspace_id = $1 AND ($2 = '' OR al.link_id::text = $2)
+		ORDER BY al.accessed_at DESC
+		LIMIT $3
+	`
+
+	rows, err := s.db.Query(query, workspaceID, linkID, limit)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to fetch workspace access logs: %w", err)
 	}
 	defer rows.Close()
-	var logs []models.AccessLog
-	for rows.Next() {
-		var log models.AccessLog
-		if err := rows.Scan(&log.ID, &log.LinkID, &log.AccessedAt, &log.IPAddress, &log.UserAgent); err != nil {
-			return nil, err
-		}
-		logs = append(logs, log)
-	}
-	return logs, nil
-}
 
-// GetAccessLogsByUser fetches access logs for a specific user by userID or username
-// Returns logs for all links owned by the specified user
-func GetAccessLogsByUser(userIdentifier interface{}, limit int) ([]models.AccessLog, error) {
-	var query string
-	var args []interface{}
-
-	switch v := userIdentifier.(type) {
-	case int, int64:
-		// If user ID is provided
-		query = `
-			SELECT al.id, al.link_id, al.accessed_at, al.ip_address, al.user_agent
-			FROM access_logs al
-			JOIN links l ON al.link_id = l.id
-			WHERE l.user_id = $1
-			ORDER BY al.accessed_at DESC
-			LIMIT $2
-		`
-		args = []interface{}{v, limit}
-	case string:
-		// If username is provided
-		query = `
-			SELECT al.id, al.link_id, al.accessed_at, al.ip_address, al.user_agent
-			FROM access_logs al
-			JOIN links l ON al.link_id = l.id
-			JOIN users u ON l.user_id = u.id
-			WHERE u.username = $1
-			ORDER BY al.accessed_at DESC
-			LIMIT $2
-		`
-		args = []interface{}{v, limit}
-	default:
-		return nil, fmt.Errorf("invalid user identifier type: must be int/int64 for user ID or string for username")
-	}
+	return scanAccessLogs(rows)
+}
 
-	rows, err := db.Query(query, args...)
+// GetAccessLogsByLink implements repositories.AccessLogRepository
+func (s *Store) GetAccessLogsByLink(linkID int, limit int) ([]models.AccessLog, error) {
+	query := `
+		SELECT ` + accessLogColumns + `
+		FROM access_logs al
+		WHERE al.link_id = $1
+		ORDER BY al.accessed_at DESC
+		LIMIT $2
+	`
+
+	rows, err := s.db.Query(query, linkID, limit)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch user access logs: %w", err)
+		return nil, fmt.Errorf("failed to fetch link access logs: %w", err)
 	}
 	defer rows.Close()
 
-	var logs []models.AccessLog
+	return scanAccessLogs(rows)
+}
+
+func scanAccessLogs(rows *sql.Rows) ([]models.AccessLog, error) {
+	logs := []models.AccessLog{}
 	for rows.Next() {
 		var log models.AccessLog
-		if err := rows.Scan(&log.ID, &log.LinkID, &log.AccessedAt, &log.IPAddress, &log.UserAgent); err != nil {
+		if err := rows.Scan(&log.ID, &log.LinkID, &log.EventType, &log.AccessedAt, &log.IPAddress, &log.UserAgent,
+			&log.Referer, &log.Country, &log.Region, &log.City, &log.ASN, &log.DeviceType, &log.Browser, &log.OS); err != nil {
 			return nil, fmt.Errorf("failed to scan access log row: %w", err)
 		}
 		logs = append(logs, log)
@@ -0,0 +1,60 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+)
+
+// CreatePasswordResetToken implements repositories.PasswordResetRepository
+func (s *Store) CreatePasswordResetToken(token models.PasswordResetToken) (models.PasswordResetToken, error) {
+	query := `INSERT INTO password_reset_tokens (user_id, token_hash, expires_at)
+			  VALUES ($1, $2, $3) RETURNING id, created_at`
+	err := s.db.QueryRow(query, token.UserID, token.TokenHash, token.ExpiresAt).Scan(&token.ID, &token.CreatedAt)
+	if err != nil {
+		return models.PasswordResetToken{}, fmt.Errorf("failed to insert password reset token: %w", err)
+	}
+	return token, nil
+}
+
+// ResetPassword implements repositories.PasswordResetRepository. Marking the
+// token used is the first statement, so of two concurrent redemptions only
+// one finds it unused.
+func (s *Store) ResetPassword(tokenHash, passwordHash string) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to start password reset: %w", err)
+	}
+	defer tx.Rollback()
+
+	var userID int
+	err = tx.QueryRow(`UPDATE password_reset_tokens SET used_at = NOW()
+			  WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+			  RETURNING user_id`, tokenHash).Scan(&userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, repositories.ErrPasswordResetTokenInvalid
+		}
+		return 0, fmt.Errorf("failed to redeem password reset token: %w", err)
+	}
+
+	query := "UPDATE users SET password = $1, email_verified_at = COALESCE(email_verified_at, NOW()) WHERE id = $2"
+	if _, err := tx.Exec(query, passwordHash, userID); err != nil {
+		return 0, fmt.Errorf("failed to update password: %w", err)
+	}
+
+	if _, err := tx.Exec("UPDATE password_reset_tokens SET used_at = NOW() WHERE user_id = $1 AND used_at IS NULL", userID); err != nil {
+		return 0, fmt.Errorf("failed to invalidate password reset tokens: %w", err)
+	}
+
+	if _, err := tx.Exec("UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL", userID); err != nil {
+		return 0, fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit password reset: %w", err)
+	}
+
+	return userID, nil
+}
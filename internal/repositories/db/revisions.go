@@ -0,0 +1,193 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+)
+
+// UpdateLink implements repositories.LinkRepository. The link row is locked
+// and the revision, tags and folder written in the same transaction as the
+// update.
+func (s *Store) UpdateLink(domainID int, slug string, userID int, action string, revertedRevisionID *int64,
+	change repositories.LinkChange, organization models.LinkOrganization) (models.Link, *models.LinkRevision, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return models.Link{}, nil, fmt.Errorf("failed to start link update: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := "SELECT " + linkColumns + " FROM links WHERE " + linkSlugCondition + " AND deleted_at IS NULL FOR UPDATE OF links"
+	link, err := scanLink(tx.QueryRow(query, domainID, slug))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Link{}, nil, repositories.ErrLinkNotFound
+		}
+		return models.Link{}, nil, fmt.Errorf("failed to get link: %w", err)
+	}
+
+	if err := checkLinkAccess(tx, link, userID, models.WorkspaceRoleEditor); err != nil {
+		return models.Link{}, nil, err
+	}
+
+	oldState := link.State()
+	newState := change(oldState)
+	if newState.Equal(oldState) && organization.IsZero() {
+		return link, nil, nil
+	}
+
+	var revision *models.LinkRevision
+	if !newState.Equal(oldState) {
+		if revision, err = updateLinkState(tx, &link, userID, action, revertedRevisionID, oldState, newState); err != nil {
+			return models.Link{}, nil, err
+		}
+	}
+
+	if !organization.IsZero() {
+		workspaceID := int(link.WorkspaceID.Int32)
+		if organization.Tags != nil {
+			if err := setLinkTags(tx, link.ID, workspaceID, *organization.Tags); err != nil {
+				return models.Link{}, nil, err
+			}
+		}
+		if organization.Folder != nil {
+			if err := setLinkFolder(tx, link.ID, workspaceID, *organization.Folder); err != nil {
+				return models.Link{}, nil, err
+			}
+		}
+		if link, err = scanLink(tx.QueryRow("SELECT "+linkColumns+" FROM links WHERE id = $1", link.ID)); err != nil {
+			return models.Link{}, nil, fmt.Errorf("failed to get updated link: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Link{}, nil, fmt.Errorf("failed to commit link update: %w", err)
+	}
+
+	return link, revision, nil
+}
+
+// updateLinkState writes newState to link and records the revision
+func updateLinkState(tx *sql.Tx, link *models.Link, userID int, action string, revertedRevisionID *int64,
+	oldState, newState models.LinkState) (*models.LinkRevision, error) {
+	link.TargetURL = newState.TargetURL
+	link.ExpiresAt = sql.NullTime{}
+	if newState.ExpiresAt != nil {
+		link.ExpiresAt = sql.NullTime{Time: *newState.ExpiresAt, Valid: true}
+	}
+	link.ClickLimit = sql.NullInt32{}
+	if newState.ClickLimit != nil {
+		link.ClickLimit = sql.NullInt32{Int32: int32(*newState.ClickLimit), Valid: true}
+	}
+
+	updateQuery := "UPDATE links SET target_url = $1, expires_at = $2, click_limit = $3 WHERE id = $4"
+	if _, err := tx.Exec(updateQuery, link.TargetURL, link.ExpiresAt, link.ClickLimit, link.ID); err != nil {
+		return nil, fmt.Errorf("failed to update link: %w", err)
+	}
+
+	oldValues, err := json.Marshal(oldState)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode old link values: %w", err)
+	}
+	newValues, err := json.Marshal(newState)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode new link values: %w", err)
+	}
+
+	revision := models.LinkRevision{
+		LinkID:             link.ID,
+		UserID:             &userID,
+		Action:             action,
+		OldValues:          oldState,
+		NewValues:          newState,
+		RevertedRevisionID: revertedRevisionID,
+	}
+	insertQuery := `INSERT INTO link_revisions (link_id, user_id, action, old_values, new_values, reverted_revision_id)
+			  VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`
+	err = tx.QueryRow(insertQuery, link.ID, userID, action, oldValues, newValues, revertedRevisionID).Scan(&revision.ID, &revision.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record link revision: %w", err)
+	}
+
+	return &revision, nil
+}
+
+// GetLinkRevisions implements repositories.LinkRepository
+func (s *Store) GetLinkRevisions(linkID int) ([]models.LinkRevision, error) {
+	query := `SELECT id, link_id, user_id, action, old_values, new_values, reverted_revision_id, created_at
+			  FROM link_revisions WHERE link_id = $1 ORDER BY created_at DESC, id DESC`
+
+	rows, err := s.db.Query(query, linkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get link revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := []models.LinkRevision{}
+	for rows.Next() {
+		revision, err := scanLinkRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating link revision rows: %w", err)
+	}
+
+	return revisions, nil
+}
+
+// GetLinkRevision implements repositories.LinkRepository
+func (s *Store) GetLinkRevision(linkID int, revisionID int64) (models.LinkRevision, error) {
+	query := `SELECT id, link_id, user_id, action, old_values, new_values, reverted_revision_id, created_at
+			  FROM link_revisions WHERE link_id = $1 AND id = $2`
+
+	revision, err := scanLinkRevision(s.db.QueryRow(query, linkID, revisionID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.LinkRevision{}, repositories.ErrRevisionNotFound
+		}
+		return models.LinkRevision{}, err
+	}
+	return revision, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanLinkRevision(row rowScanner) (models.LinkRevision, error) {
+	var revision models.LinkRevision
+	var userID sql.NullInt32
+	var revertedRevisionID sql.NullInt64
+	var oldValues, newValues []byte
+
+	err := row.Scan(&revision.ID, &revision.LinkID, &userID, &revision.Action, &oldValues, &newValues, &revertedRevisionID, &revision.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.LinkRevision{}, err
+		}
+		return models.LinkRevision{}, fmt.Errorf("failed to scan link revision row: %w", err)
+	}
+
+	if userID.Valid {
+		id := int(userID.Int32)
+		revision.UserID = &id
+	}
+	if revertedRevisionID.Valid {
+		revision.RevertedRevisionID = &revertedRevisionID.Int64
+	}
+	if err := json.Unmarshal(oldValues, &revision.OldValues); err != nil {
+		return models.LinkRevision{}, fmt.Errorf("failed to decode old link values: %w", err)
+	}
+	if err := json.Unmarshal(newValues, &revision.NewValues); err != nil {
+		return models.LinkRevision{}, fmt.Errorf("failed to decode new link values: %w", err)
+	}
+
+	return revision, nil
+}
@@ -0,0 +1,165 @@
+package db
+
+import (
+	"fmt"
+	"link-guardian/internal/models"
+	"time"
+)
+
+// Breakdown dimensions returned by getClickBreakdowns
+const (
+	dimensionCountry  = "country"
+	dimensionDevice   = "device_type"
+	dimensionBrowser  = "browser"
+	dimensionOS       = "os"
+	dimensionReferrer = "referrer"
+)
+
+// GetLinkClickStats implements repositories.AccessLogRepository
+func (s *Store) GetLinkClickStats(linkID int, from, to time.Time, bucket, timezone string, breakdownLimit int) (models.LinkStats, error) {
+	stats := models.LinkStats{
+		From:     from,
+		To:       to,
+		Bucket:   bucket,
+		Timezone: timezone,
+	}
+
+	totalsQuery := `
+		SELECT COUNT(*), COUNT(DISTINCT ip_address)
+		FROM access_logs
+		WHERE link_id = $1 AND event_type = 'click' AND accessed_at >= $2 AND accessed_at < $3
+	`
+	if err := s.db.QueryRow(totalsQuery, linkID, from, to).Scan(&stats.TotalClicks, &stats.UniqueVisitors); err != nil {
+		return models.LinkStats{}, fmt.Errorf("failed to count link clicks: %w", err)
+	}
+
+	series, err := s.getClickSeries(linkID, from, to, bucket, timezone)
+	if err != nil {
+		return models.LinkStats{}, err
+	}
+	stats.Series = series
+
+	breakdowns, err := s.getClickBreakdowns(linkID, from, to, breakdownLimit)
+	if err != nil {
+		return models.LinkStats{}, err
+	}
+	stats.Breakdowns = breakdowns
+
+	return stats, nil
+}
+
+// getClickSeries returns one point per bucket in the range, including empty buckets
+func (s *Store) getClickSeries(linkID int, from, to time.Time, bucket, timezone string) ([]models.StatsPoint, error) {
+	query := `
+		WITH clicks AS (
+			SELECT date_trunc($4, accessed_at AT TIME ZONE $5) AS bucket, COUNT(*) AS clicks
+			FROM access_logs
+			WHERE link_id = $1 AND event_type = 'click' AND accessed_at >= $2 AND accessed_at < $3
+			GROUP BY 1
+		)
+		SELECT s.bucket AT TIME ZONE $5, COALESCE(c.clicks, 0)
+		FROM generate_series(
+			date_trunc($4, $2::timestamptz AT TIME ZONE $5),
+			date_trunc($4, ($3::timestamptz - interval '1 microsecond') AT TIME ZONE $5),
+			('1 ' || $4)::interval
+		) AS s(bucket)
+		LEFT JOIN clicks c ON c.bucket = s.bucket
+		ORDER BY s.bucket
+	`
+
+	rows, err := s.db.Query(query, linkID, from, to, bucket, timezone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch click series: %w", err)
+	}
+	defer rows.Close()
+
+	series := []models.StatsPoint{}
+	for rows.Next() {
+		var point models.StatsPoint
+		if err := rows.Scan(&point.Bucket, &point.Clicks); err != nil {
+			return nil, fmt.Errorf("failed to scan click series row: %w", err)
+		}
+		series = append(series, point)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating click series rows: %w", err)
+	}
+
+	return series, nil
+}
+
+// getClickBreakdowns returns the top values of each breakdown dimension.
+// Referrers are reduced to their host name without a leading "www.".
+func (s *Store) getClickBreakdowns(linkID int, from, to time.Time, limit int) (models.StatsBreakdowns, error) {
+	query := `
+		WITH clicks AS (
+			SELECT country, device_type, browser, os,
+				lower(regexp_replace(
+					substring(referer from '^[a-zA-Z][a-zA-Z0-9+.-]*://(?:[^@/?#]*@)?([^:/?#]+)'),
+					'^www\.', '')) AS referrer
+			FROM access_logs
+			WHERE link_id = $1 AND event_type = 'click' AND accessed_at >= $2 AND accessed_at < $3
+		),
+		breakdowns AS (
+			SELECT 'country' AS dimension, COALESCE(NULLIF(country, ''), 'unknown') AS value, COUNT(*) AS clicks
+			FROM clicks GROUP BY 2
+			UNION ALL
+			SELECT 'device_type', COALESCE(NULLIF(device_type, ''), 'unknown'), COUNT(*) FROM clicks GROUP BY 2
+			UNION ALL
+			SELECT 'browser', COALESCE(NULLIF(browser, ''), 'unknown'), COUNT(*) FROM clicks GROUP BY 2
+			UNION ALL
+			SELECT 'os', COALESCE(NULLIF(os, ''), 'unknown'), COUNT(*) FROM clicks GROUP BY 2
+			UNION ALL
+			SELECT 'referrer', COALESCE(NULLIF(referrer, ''), 'direct'), COUNT(*) FROM clicks GROUP BY 2
+		)
+		SELECT dimension, value, clicks
+		FROM (
+			SELECT dimension, value, clicks,
+				row_number() OVER (PARTITION BY dimension ORDER BY clicks DESC, value) AS rank
+			FROM breakdowns
+		) ranked
+		WHERE rank <= $4
+		ORDER BY dimension, clicks DESC, value
+	`
+
+	rows, err := s.db.Query(query, linkID, from, to, limit)
+	if err != nil {
+		return models.StatsBreakdowns{}, fmt.Errorf("failed to fetch click breakdowns: %w", err)
+	}
+	defer rows.Close()
+
+	breakdowns := models.StatsBreakdowns{
+		Countries:        []models.StatsBreakdownEntry{},
+		DeviceTypes:      []models.StatsBreakdownEntry{},
+		Browsers:         []models.StatsBreakdownEntry{},
+		OperatingSystems: []models.StatsBreakdownEntry{},
+		Referrers:        []models.StatsBreakdownEntry{},
+	}
+	for rows.Next() {
+		var dimension string
+		var entry models.StatsBreakdownEntry
+		if err := rows.Scan(&dimension, &entry.Value, &entry.Clicks); err != nil {
+			return models.StatsBreakdowns{}, fmt.Errorf("failed to scan click breakdown row: %w", err)
+		}
+
+		switch dimension {
+		case dimensionCountry:
+			breakdowns.Countries = append(breakdowns.Countries, entry)
+		case dimensionDevice:
+			breakdowns.DeviceTypes = append(breakdowns.DeviceTypes, entry)
+		case dimensionBrowser:
+			breakdowns.Browsers = append(breakdowns.Browsers, entry)
+		case dimensionOS:
+			breakdowns.OperatingSystems = append(breakdowns.OperatingSystems, entry)
+		case dimensionReferrer:
+			breakdowns.Referrers = append(breakdowns.Referrers, entry)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return models.StatsBreakdowns{}, fmt.Errorf("error iterating click breakdown rows: %w", err)
+	}
+
+	return breakdowns, nil
+}
@@ -0,0 +1,15 @@
+package db
+
+import (
+	"link-guardian/internal/repositories"
+	"link-guardian/internal/repositories/repotest"
+	"link-guardian/internal/testutil/pgtest"
+	"testing"
+)
+
+func TestStoreContract(t *testing.T) {
+	conn := pgtest.Open(t)
+	repotest.Run(t, func(t *testing.T) repositories.Store {
+		return NewStore(conn)
+	})
+}
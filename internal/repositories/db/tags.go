@@ -0,0 +1,238 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+
+	"github.com/lib/pq"
+)
+
+// setLinkTags replaces the tags of a link with names, creating missing tags in the workspace
+func setLinkTags(tx execer, linkID, workspaceID int, names []string) error {
+	if _, err := tx.Exec("DELETE FROM link_tags WHERE link_id = $1", linkID); err != nil {
+		return fmt.Errorf("failed to clear link tags: %w", err)
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	query := `INSERT INTO tags (workspace_id, name) SELECT $1, unnest($2::text[])
+			  ON CONFLICT (workspace_id, name) DO NOTHING`
+	if _, err := tx.Exec(query, workspaceID, pq.Array(names)); err != nil {
+		return fmt.Errorf("failed to create tags: %w", err)
+	}
+
+	query = `INSERT INTO link_tags (link_id, tag_id) SELECT $1, id FROM tags WHERE workspace_id = $2 AND name = ANY($3)`
+	if _, err := tx.Exec(query, linkID, workspaceID, pq.Array(names)); err != nil {
+		return fmt.Errorf("failed to tag link: %w", err)
+	}
+	return nil
+}
+
+// setLinkFolder moves a link into the named folder of the workspace, creating
+// it, or out of any folder when name is empty
+func setLinkFolder(tx execer, linkID, workspaceID int, name string) error {
+	if name == "" {
+		if _, err := tx.Exec("UPDATE links SET folder_id = NULL WHERE id = $1", linkID); err != nil {
+			return fmt.Errorf("failed to clear link folder: %w", err)
+		}
+		return nil
+	}
+
+	query := "INSERT INTO folders (workspace_id, name) VALUES ($1, $2) ON CONFLICT (workspace_id, name) DO NOTHING"
+	if _, err := tx.Exec(query, workspaceID, name); err != nil {
+		return fmt.Errorf("failed to create folder: %w", err)
+	}
+
+	query = "UPDATE links SET folder_id = (SELECT id FROM folders WHERE workspace_id = $2 AND name = $3) WHERE id = $1"
+	if _, err := tx.Exec(query, linkID, workspaceID, name); err != nil {
+		return fmt.Errorf("failed to move link to folder: %w", err)
+	}
+	return nil
+}
+
+// SetLinkTags implements repositories.TagRepository
+func (s *Store) SetLinkTags(linkID int, names []string) error {
+	return s.organizeLink(linkID, func(tx *sql.Tx, workspaceID int) error {
+		return setLinkTags(tx, linkID, workspaceID, names)
+	})
+}
+
+// SetLinkFolder implements repositories.TagRepository
+func (s *Store) SetLinkFolder(linkID int, name string) error {
+	return s.organizeLink(linkID, func(tx *sql.Tx, workspaceID int) error {
+		return setLinkFolder(tx, linkID, workspaceID, name)
+	})
+}
+
+// organizeLink locks an active link and runs change with its workspace in one transaction
+func (s *Store) organizeLink(linkID int, change func(tx *sql.Tx, workspaceID int) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start link change: %w", err)
+	}
+	defer tx.Rollback()
+
+	var workspaceID int
+	query := "SELECT workspace_id FROM links WHERE id = $1 AND deleted_at IS NULL AND workspace_id IS NOT NULL FOR UPDATE"
+	if err := tx.QueryRow(query, linkID).Scan(&workspaceID); err != nil {
+		if err == sql.ErrNoRows {
+			return repositories.ErrLinkNotFound
+		}
+		return fmt.Errorf("failed to get link: %w", err)
+	}
+
+	if err := change(tx, workspaceID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit link change: %w", err)
+	}
+	return nil
+}
+
+// tagQuery selects tags with the number of links using them that are not
+// deleted and their total clicks; callers append conditions on t
+const tagQuery = `SELECT t.id, t.workspace_id, t.name, t.created_at, COUNT(l.id), COALESCE(SUM(l.click_count), 0)
+	FROM tags t
+	LEFT JOIN link_tags lt ON lt.tag_id = t.id
+	LEFT JOIN links l ON l.id = lt.link_id AND l.deleted_at IS NULL
+	WHERE t.workspace_id = $1`
+
+func scanTag(row rowScanner) (models.Tag, error) {
+	var tag models.Tag
+	err := row.Scan(&tag.ID, &tag.WorkspaceID, &tag.Name, &tag.CreatedAt, &tag.LinkCount, &tag.ClickCount)
+	return tag, err
+}
+
+// ListTags implements repositories.TagRepository
+func (s *Store) ListTags(workspaceID int) ([]models.Tag, error) {
+	rows, err := s.db.Query(tagQuery+" GROUP BY t.id ORDER BY t.name", workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []models.Tag{}
+	for rows.Next() {
+		tag, err := scanTag(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tag row: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tag rows: %w", err)
+	}
+
+	return tags, nil
+}
+
+// ListFolders implements repositories.TagRepository
+func (s *Store) ListFolders(workspaceID int) ([]models.Folder, error) {
+	query := `SELECT f.id, f.workspace_id, f.name, f.created_at, COUNT(l.id), COALESCE(SUM(l.click_count), 0)
+			  FROM folders f
+			  LEFT JOIN links l ON l.folder_id = f.id AND l.deleted_at IS NULL
+			  WHERE f.workspace_id = $1
+			  GROUP BY f.id ORDER BY f.name`
+	rows, err := s.db.Query(query, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list folders: %w", err)
+	}
+	defer rows.Close()
+
+	folders := []models.Folder{}
+	for rows.Next() {
+		var folder models.Folder
+		if err := rows.Scan(&folder.ID, &folder.WorkspaceID, &folder.Name, &folder.CreatedAt, &folder.LinkCount, &folder.ClickCount); err != nil {
+			return nil, fmt.Errorf("failed to scan folder row: %w", err)
+		}
+		folders = append(folders, folder)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating folder rows: %w", err)
+	}
+
+	return folders, nil
+}
+
+// RenameTag implements repositories.TagRepository
+func (s *Store) RenameTag(workspaceID, tagID int, name string) (models.Tag, error) {
+	result, err := s.db.Exec("UPDATE tags SET name = $3 WHERE workspace_id = $1 AND id = $2", workspaceID, tagID, name)
+	if isUniqueViolation(err, "") {
+		return models.Tag{}, repositories.ErrTagTaken
+	}
+	if err != nil {
+		return models.Tag{}, fmt.Errorf("failed to rename tag: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return models.Tag{}, fmt.Errorf("error getting rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return models.Tag{}, repositories.ErrTagNotFound
+	}
+
+	return s.getTag(s.db, workspaceID, tagID)
+}
+
+// MergeTags implements repositories.TagRepository. Both tags are locked so a
+// concurrent rename or merge cannot interleave.
+func (s *Store) MergeTags(workspaceID, tagID, targetID int) (models.Tag, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return models.Tag{}, fmt.Errorf("failed to start tag merge: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query("SELECT id FROM tags WHERE workspace_id = $1 AND id IN ($2, $3) FOR UPDATE", workspaceID, tagID, targetID)
+	if err != nil {
+		return models.Tag{}, fmt.Errorf("failed to lock tags: %w", err)
+	}
+	found := 0
+	for rows.Next() {
+		found++
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return models.Tag{}, fmt.Errorf("error iterating tag rows: %w", err)
+	}
+	if found != 2 {
+		return models.Tag{}, repositories.ErrTagNotFound
+	}
+
+	query := `INSERT INTO link_tags (link_id, tag_id) SELECT link_id, $2 FROM link_tags WHERE tag_id = $1
+			  ON CONFLICT DO NOTHING`
+	if _, err := tx.Exec(query, tagID, targetID); err != nil {
+		return models.Tag{}, fmt.Errorf("failed to move tagged links: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM tags WHERE id = $1", tagID); err != nil {
+		return models.Tag{}, fmt.Errorf("failed to delete merged tag: %w", err)
+	}
+
+	tag, err := s.getTag(tx, workspaceID, targetID)
+	if err != nil {
+		return models.Tag{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Tag{}, fmt.Errorf("failed to commit tag merge: %w", err)
+	}
+	return tag, nil
+}
+
+// getTag returns a tag of the workspace with its counts or ErrTagNotFound
+func (s *Store) getTag(q queryRower, workspaceID, tagID int) (models.Tag, error) {
+	tag, err := scanTag(q.QueryRow(tagQuery+" AND t.id = $2 GROUP BY t.id", workspaceID, tagID))
+	if err == sql.ErrNoRows {
+		return models.Tag{}, repositories.ErrTagNotFound
+	}
+	if err != nil {
+		return models.Tag{}, fmt.Errorf("failed to get tag: %w", err)
+	}
+	return tag, nil
+}
@@ -0,0 +1,114 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"time"
+)
+
+// refreshTokenColumns lists the refresh_tokens columns scanned by scanRefreshToken
+const refreshTokenColumns = "id, user_id, family_id, token_hash, expires_at, created_at, revoked_at, replaced_by"
+
+func scanRefreshToken(row rowScanner) (models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := row.Scan(&token.ID, &token.UserID, &token.FamilyID, &token.TokenHash,
+		&token.ExpiresAt, &token.CreatedAt, &token.RevokedAt, &token.ReplacedBy)
+	return token, err
+}
+
+// CreateRefreshToken implements repositories.RefreshTokenRepository
+func (s *Store) CreateRefreshToken(token models.RefreshToken) (models.RefreshToken, error) {
+	return insertRefreshToken(s.db, token)
+}
+
+// RotateRefreshToken implements repositories.RefreshTokenRepository. The
+// presented token is locked so concurrent refreshes cannot both rotate it.
+func (s *Store) RotateRefreshToken(tokenHash string, next models.RefreshToken) (models.RefreshToken, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return models.RefreshToken{}, fmt.Errorf("failed to start token rotation: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := "SELECT " + refreshTokenColumns + " FROM refresh_tokens WHERE token_hash = $1 FOR UPDATE"
+	current, err := scanRefreshToken(tx.QueryRow(query, tokenHash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.RefreshToken{}, repositories.ErrRefreshTokenNotFound
+		}
+		return models.RefreshToken{}, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	if current.RevokedAt.Valid {
+		if err := revokeFamily(tx, current.FamilyID); err != nil {
+			return models.RefreshToken{}, err
+		}
+		if err := tx.Commit(); err != nil {
+			return models.RefreshToken{}, fmt.Errorf("failed to commit token family revocation: %w", err)
+		}
+		return current, repositories.ErrRefreshTokenReused
+	}
+
+	if !current.ExpiresAt.After(time.Now()) {
+		return models.RefreshToken{}, repositories.ErrRefreshTokenNotFound
+	}
+
+	next.UserID = current.UserID
+	next.FamilyID = current.FamilyID
+	created, err := insertRefreshToken(tx, next)
+	if err != nil {
+		return models.RefreshToken{}, err
+	}
+
+	updateQuery := "UPDATE refresh_tokens SET revoked_at = NOW(), replaced_by = $1 WHERE id = $2"
+	if _, err := tx.Exec(updateQuery, created.ID, current.ID); err != nil {
+		return models.RefreshToken{}, fmt.Errorf("failed to revoke rotated refresh token: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.RefreshToken{}, fmt.Errorf("failed to commit token rotation: %w", err)
+	}
+
+	return created, nil
+}
+
+// RevokeRefreshTokenFamily implements repositories.RefreshTokenRepository
+func (s *Store) RevokeRefreshTokenFamily(tokenHash string, userID int) error {
+	var familyID string
+	err := s.db.QueryRow("SELECT family_id FROM refresh_tokens WHERE token_hash = $1 AND user_id = $2",
+		tokenHash, userID).Scan(&familyID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return repositories.ErrRefreshTokenNotFound
+		}
+		return fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	return revokeFamily(s.db, familyID)
+}
+
+// execer is satisfied by both *sql.DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+func insertRefreshToken(db execer, token models.RefreshToken) (models.RefreshToken, error) {
+	query := `INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at)
+			  VALUES ($1, $2, $3, $4) RETURNING id, created_at`
+	err := db.QueryRow(query, token.UserID, token.FamilyID, token.TokenHash, token.ExpiresAt).Scan(&token.ID, &token.CreatedAt)
+	if err != nil {
+		return models.RefreshToken{}, fmt.Errorf("failed to insert refresh token: %w", err)
+	}
+	return token, nil
+}
+
+func revokeFamily(db execer, familyID string) error {
+	query := "UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL"
+	if _, err := db.Exec(query, familyID); err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+	return nil
+}
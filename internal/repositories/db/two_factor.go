@@ -0,0 +1,139 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+)
+
+// GetTwoFactor implements repositories.TwoFactorRepository
+func (s *Store) GetTwoFactor(userID int) (models.TwoFactor, error) {
+	query := `SELECT t.user_id, t.secret, t.enabled_at, t.last_used_step, t.created_at,
+			  (SELECT COUNT(*) FROM recovery_codes r WHERE r.user_id = t.user_id AND r.used_at IS NULL)
+			  FROM user_totp t WHERE t.user_id = $1`
+
+	var enrolment models.TwoFactor
+	err := s.db.QueryRow(query, userID).Scan(&enrolment.UserID, &enrolment.Secret, &enrolment.EnabledAt,
+		&enrolment.LastUsedStep, &enrolment.CreatedAt, &enrolment.RecoveryCodesLeft)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.TwoFactor{}, repositories.ErrTwoFactorNotFound
+		}
+		return models.TwoFactor{}, fmt.Errorf("failed to get two-factor enrolment: %w", err)
+	}
+	return enrolment, nil
+}
+
+// StartTwoFactorEnrolment implements repositories.TwoFactorRepository. The
+// conditional upsert leaves an enabled enrolment untouched.
+func (s *Store) StartTwoFactorEnrolment(userID int, secret string) error {
+	query := `INSERT INTO user_totp (user_id, secret) VALUES ($1, $2)
+			  ON CONFLICT (user_id) DO UPDATE SET secret = EXCLUDED.secret, last_used_step = 0, created_at = NOW()
+			  WHERE user_totp.enabled_at IS NULL`
+	result, err := s.db.Exec(query, userID, secret)
+	if err != nil {
+		return fmt.Errorf("failed to store TOTP secret: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to store TOTP secret: %w", err)
+	}
+	if rows == 0 {
+		return repositories.ErrTwoFactorEnabled
+	}
+	return nil
+}
+
+// EnableTwoFactor implements repositories.TwoFactorRepository
+func (s *Store) EnableTwoFactor(userID int, step int64, codeHashes []string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start enabling two-factor authentication: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE user_totp SET enabled_at = NOW(), last_used_step = $2
+			  WHERE user_id = $1 AND enabled_at IS NULL`, userID, step)
+	if err != nil {
+		return fmt.Errorf("failed to enable two-factor authentication: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to enable two-factor authentication: %w", err)
+	}
+	if rows == 0 {
+		return repositories.ErrTwoFactorNotFound
+	}
+
+	if _, err := tx.Exec("DELETE FROM recovery_codes WHERE user_id = $1", userID); err != nil {
+		return fmt.Errorf("failed to replace recovery codes: %w", err)
+	}
+	for _, hash := range codeHashes {
+		if _, err := tx.Exec("INSERT INTO recovery_codes (user_id, code_hash) VALUES ($1, $2)", userID, hash); err != nil {
+			return fmt.Errorf("failed to insert recovery code: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit two-factor enrolment: %w", err)
+	}
+	return nil
+}
+
+// DisableTwoFactor implements repositories.TwoFactorRepository
+func (s *Store) DisableTwoFactor(userID int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start disabling two-factor authentication: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM user_totp WHERE user_id = $1", userID); err != nil {
+		return fmt.Errorf("failed to delete TOTP secret: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM recovery_codes WHERE user_id = $1", userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit disabling two-factor authentication: %w", err)
+	}
+	return nil
+}
+
+// UseTOTPStep implements repositories.TwoFactorRepository. The comparison in
+// the UPDATE makes concurrent uses of one code accept it only once.
+func (s *Store) UseTOTPStep(userID int, step int64) (bool, error) {
+	result, err := s.db.Exec(`UPDATE user_totp SET last_used_step = $2
+			  WHERE user_id = $1 AND enabled_at IS NOT NULL AND last_used_step < $2`, userID, step)
+	if err != nil {
+		return false, fmt.Errorf("failed to record TOTP step: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to record TOTP step: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// UseRecoveryCode implements repositories.TwoFactorRepository
+func (s *Store) UseRecoveryCode(userID int, codeHash string) error {
+	result, err := s.db.Exec(`UPDATE recovery_codes SET used_at = NOW()
+			  WHERE id = (SELECT id FROM recovery_codes WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL LIMIT 1)
+			  AND used_at IS NULL`, userID, codeHash)
+	if err != nil {
+		return fmt.Errorf("failed to redeem recovery code: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to redeem recovery code: %w", err)
+	}
+	if rows == 0 {
+		return repositories.ErrRecoveryCodeInvalid
+	}
+	return nil
+}
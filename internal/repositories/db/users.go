@@ -3,102 +3,109 @@ package db
 import (
 	"database/sql"
 	"fmt"
-	"log"
-
 	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
 )
 
-func IsEmailUnique(email string) (error error) {
-	query := "SELECT COUNT(*) FROM users WHERE email = $1"
-	var count int
+// Unique constraints PostgreSQL names after the users columns
+const (
+	usersEmailConstraint    = "users_email_key"
+	usersUsernameConstraint = "users_username_key"
+)
 
-	err := db.QueryRow(query, email).Scan(&count)
-	if err != nil {
-		log.Fatalf("Error checking email uniqueness: %v", err)
+// EmailExists implements repositories.UserRepository
+func (s *Store) EmailExists(email string) (bool, error) {
+	var exists bool
+	if err := s.db.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)", email).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check email uniqueness: %w", err)
 	}
+	return exists, nil
+}
 
-	if count > 0 {
-		return fmt.Errorf("email %s is already in use", email)
+// UsernameExists implements repositories.UserRepository
+func (s *Store) UsernameExists(username string) (bool, error) {
+	var exists bool
+	if err := s.db.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE username = $1)", username).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check username uniqueness: %w", err)
 	}
-	return nil
+	return exists, nil
 }
 
-func IsUsernameUnique(username string) (error error) {
-	query := "SELECT COUNT(*) FROM users WHERE username = $1"
-	var count int
-
-	err := db.QueryRow(query, username).Scan(&count)
+// CreateUser implements repositories.UserRepository. The user and their
+// personal workspace are stored in one transaction.
+func (s *Store) CreateUser(username, email, passwordHash string) (int, error) {
+	tx, err := s.db.Begin()
 	if err != nil {
-		log.Fatalf("Error checking username uniqueness: %v", err)
+		return 0, fmt.Errorf("failed to start creating user: %w", err)
 	}
+	defer tx.Rollback()
 
-	if count > 0 {
-		return fmt.Errorf("username %s is already in use", username)
-	}
-	return nil
-}
-
-func InsertUserToDB(username, email, password string) (int, error) {
 	query := `INSERT INTO users (username, email, password, created_at) 
 			  VALUES ($1, $2, $3, NOW()) RETURNING id`
 	var userID int
 
-	err := db.QueryRow(query, username, email, password).Scan(&userID)
-	if err != nil {
-		fmt.Println("Error inserting user:", err)
+	err = tx.QueryRow(query, username, email, passwordHash).Scan(&userID)
+	switch {
+	case isUniqueViolation(err, usersEmailConstraint):
+		return 0, repositories.ErrEmailTaken
+	case isUniqueViolation(err, usersUsernameConstraint):
+		return 0, repositories.ErrUsernameTaken
+	case err != nil:
 		return 0, fmt.Errorf("failed to insert user: %w", err)
-
 	}
-	return userID, nil
-}
 
-func CheckLoginCredentials(email, password string) (int, error) {
-	query := "SELECT id FROM users WHERE email = $1 AND password = $2"
-	var userID int
+	if _, err := createWorkspace(tx, models.PersonalWorkspaceName, userID, true); err != nil {
+		return 0, err
+	}
 
-	err := db.QueryRow(query, email, password).Scan(&userID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return 0, fmt.Errorf("invalid email or password")
-		}
-		return 0, fmt.Errorf("failed to check login credentials: %w", err)
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit user: %w", err)
 	}
 
 	return userID, nil
 }
 
-func GetUsernameByID(userID int) (string, error) {
-	query := "SELECT username FROM users WHERE id = $1"
-	var username string
+// userColumns lists the users columns scanned by scanUser
+const userColumns = "id, username, email, password, email_verified_at IS NOT NULL, " +
+	"EXISTS(SELECT 1 FROM user_totp WHERE user_totp.user_id = users.id AND user_totp.enabled_at IS NOT NULL), " +
+	"role, disabled_at, created_at"
 
-	err := db.QueryRow(query, userID).Scan(&username)
+func scanUser(row rowScanner) (*models.User, error) {
+	var user models.User
+	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.EmailVerified, &user.TwoFactorEnabled,
+		&user.Role, &user.DisabledAt, &user.CreatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return "", fmt.Errorf("user not found")
+			return nil, repositories.ErrUserNotFound
 		}
-		return "", fmt.Errorf("failed to get username: %w", err)
+		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
+	return &user, nil
+}
 
-	return username, nil
+// GetUserByEmail implements repositories.UserRepository
+func (s *Store) GetUserByEmail(email string) (*models.User, error) {
+	return scanUser(s.db.QueryRow("SELECT "+userColumns+" FROM users WHERE LOWER(email) = LOWER($1)", email))
 }
 
-func GetUserByEmail(email string) (*models.User, error) {
-	query := `SELECT id, username, email, password, created_at FROM users WHERE LOWER(email) = LOWER($1)`
-	var user models.User
+// GetUserByID implements repositories.UserRepository
+func (s *Store) GetUserByID(userID int) (*models.User, error) {
+	return scanUser(s.db.QueryRow("SELECT "+userColumns+" FROM users WHERE id = $1", userID))
+}
 
-	err := db.QueryRow(query, email).Scan(
-		&user.ID,
-		&user.Username,
-		&user.Email,
-		&user.Password,
-		&user.CreatedAt,
-	)
+// MarkEmailVerified implements repositories.UserRepository
+func (s *Store) MarkEmailVerified(userID int) error {
+	result, err := s.db.Exec("UPDATE users SET email_verified_at = COALESCE(email_verified_at, NOW()) WHERE id = $1", userID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("user not found")
-		}
-		return nil, fmt.Errorf("failed to get user: %w", err)
+		return fmt.Errorf("failed to mark email verified: %w", err)
 	}
 
-	return &user, nil
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to mark email verified: %w", err)
+	}
+	if rows == 0 {
+		return repositories.ErrUserNotFound
+	}
+	return nil
 }
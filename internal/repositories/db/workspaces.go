@@ -0,0 +1,325 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+
+	"github.com/lib/pq"
+)
+
+// workspaceColumns lists the columns scanned by scanWorkspace from workspaces
+// w joined with the membership m of the user the workspace is loaded for
+const workspaceColumns = "w.id, w.name, w.personal_user_id IS NOT NULL, w.created_at, m.role"
+
+func scanWorkspace(row rowScanner) (models.Workspace, error) {
+	var workspace models.Workspace
+	err := row.Scan(&workspace.ID, &workspace.Name, &workspace.Personal, &workspace.CreatedAt, &workspace.Role)
+	return workspace, err
+}
+
+// createWorkspace stores a workspace owned by ownerID in tx, personal to them when personal is set
+func createWorkspace(tx *sql.Tx, name string, ownerID int, personal bool) (models.Workspace, error) {
+	workspace := models.Workspace{Name: name, Personal: personal, Role: models.WorkspaceRoleOwner}
+
+	var personalUserID sql.NullInt32
+	if personal {
+		personalUserID = sql.NullInt32{Int32: int32(ownerID), Valid: true}
+	}
+	err := tx.QueryRow("INSERT INTO workspaces (name, personal_user_id) VALUES ($1, $2) RETURNING id, created_at",
+		name, personalUserID).Scan(&workspace.ID, &workspace.CreatedAt)
+	if err != nil {
+		return models.Workspace{}, fmt.Errorf("failed to insert workspace: %w", err)
+	}
+
+	_, err = tx.Exec("INSERT INTO workspace_members (workspace_id, user_id, role) VALUES ($1, $2, $3)",
+		workspace.ID, ownerID, models.WorkspaceRoleOwner)
+	if err != nil {
+		return models.Workspace{}, fmt.Errorf("failed to insert workspace owner: %w", err)
+	}
+
+	return workspace, nil
+}
+
+// CreateWorkspace implements repositories.WorkspaceRepository
+func (s *Store) CreateWorkspace(name string, ownerID int) (models.Workspace, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return models.Workspace{}, fmt.Errorf("failed to start creating workspace: %w", err)
+	}
+	defer tx.Rollback()
+
+	workspace, err := createWorkspace(tx, name, ownerID, false)
+	if err != nil {
+		return models.Workspace{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Workspace{}, fmt.Errorf("failed to commit workspace: %w", err)
+	}
+	return workspace, nil
+}
+
+// GetWorkspace implements repositories.WorkspaceRepository
+func (s *Store) GetWorkspace(workspaceID, userID int) (models.Workspace, error) {
+	query := "SELECT " + workspaceColumns + ` FROM workspaces w
+			  JOIN workspace_members m ON m.workspace_id = w.id AND m.user_id = $2
+			  WHERE w.id = $1`
+	return s.getWorkspace(query, workspaceID, userID)
+}
+
+// GetPersonalWorkspace implements repositories.WorkspaceRepository
+func (s *Store) GetPersonalWorkspace(userID int) (models.Workspace, error) {
+	query := "SELECT " + workspaceColumns + ` FROM workspaces w
+			  JOIN workspace_members m ON m.workspace_id = w.id AND m.user_id = w.personal_user_id
+			  WHERE w.personal_user_id = $1`
+	return s.getWorkspace(query, userID)
+}
+
+func (s *Store) getWorkspace(query string, args ...interface{}) (models.Workspace, error) {
+	workspace, err := scanWorkspace(s.db.QueryRow(query, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Workspace{}, repositories.ErrWorkspaceNotFound
+		}
+		return models.Workspace{}, fmt.Errorf("failed to get workspace: %w", err)
+	}
+	return workspace, nil
+}
+
+// ListWorkspacesByUser implements repositories.WorkspaceRepository
+func (s *Store) ListWorkspacesByUser(userID int) ([]models.Workspace, error) {
+	query := "SELECT " + workspaceColumns + ` FROM workspaces w
+			  JOIN workspace_members m ON m.workspace_id = w.id
+			  WHERE m.user_id = $1
+			  ORDER BY w.personal_user_id IS NULL, w.id`
+
+	rows, err := s.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspaces: %w", err)
+	}
+	defer rows.Close()
+
+	workspaces := []models.Workspace{}
+	for rows.Next() {
+		workspace, err := scanWorkspace(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan workspace row: %w", err)
+		}
+		workspaces = append(workspaces, workspace)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating workspace rows: %w", err)
+	}
+
+	return workspaces, nil
+}
+
+// ListWorkspaceMembers implements repositories.WorkspaceRepository
+func (s *Store) ListWorkspaceMembers(workspaceID int) ([]models.WorkspaceMember, error) {
+	query := `SELECT u.id, u.username, u.email, m.role, m.created_at
+			  FROM workspace_members m JOIN users u ON u.id = m.user_id
+			  WHERE m.workspace_id = $1
+			  ORDER BY m.created_at, u.id`
+
+	rows, err := s.db.Query(query, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspace members: %w", err)
+	}
+	defer rows.Close()
+
+	members := []models.WorkspaceMember{}
+	for rows.Next() {
+		var member models.WorkspaceMember
+		if err := rows.Scan(&member.UserID, &member.Username, &member.Email, &member.Role, &member.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan workspace member row: %w", err)
+		}
+		members = append(members, member)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating workspace member rows: %w", err)
+	}
+
+	return members, nil
+}
+
+// SetWorkspaceMemberRole implements repositories.WorkspaceRepository
+func (s *Store) SetWorkspaceMemberRole(workspaceID, userID int, role string) error {
+	return s.changeWorkspaceMember(workspaceID, userID, role, func(tx *sql.Tx) error {
+		_, err := tx.Exec("UPDATE workspace_members SET role = $3 WHERE workspace_id = $1 AND user_id = $2",
+			workspaceID, userID, role)
+		return err
+	})
+}
+
+// RemoveWorkspaceMember implements repositories.WorkspaceRepository
+func (s *Store) RemoveWorkspaceMember(workspaceID, userID int) error {
+	return s.changeWorkspaceMember(workspaceID, userID, "", func(tx *sql.Tx) error {
+		_, err := tx.Exec("DELETE FROM workspace_members WHERE workspace_id = $1 AND user_id = $2", workspaceID, userID)
+		return err
+	})
+}
+
+// changeWorkspaceMember applies change to a membership that may be changed
+// to role, or removed when role is empty, without leaving the workspace
+// without an owner. The workspace's owners are locked, so two owners cannot
+// demote each other at the same time.
+func (s *Store) changeWorkspaceMember(workspaceID, userID int, role string, change func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start changing workspace member: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query("SELECT user_id FROM workspace_members WHERE workspace_id = $1 AND role = $2 FOR UPDATE",
+		workspaceID, models.WorkspaceRoleOwner)
+	if err != nil {
+		return fmt.Errorf("failed to lock workspace owners: %w", err)
+	}
+	owners := map[int]bool{}
+	for rows.Next() {
+		var ownerID int
+		if err := rows.Scan(&ownerID); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan workspace owner row: %w", err)
+		}
+		owners[ownerID] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating workspace owner rows: %w", err)
+	}
+
+	var isMember bool
+	err = tx.QueryRow("SELECT EXISTS(SELECT 1 FROM workspace_members WHERE workspace_id = $1 AND user_id = $2)",
+		workspaceID, userID).Scan(&isMember)
+	if err != nil {
+		return fmt.Errorf("failed to get workspace member: %w", err)
+	}
+	if !isMember {
+		return repositories.ErrWorkspaceMemberNotFound
+	}
+	if owners[userID] && role != models.WorkspaceRoleOwner && len(owners) == 1 {
+		return repositories.ErrLastWorkspaceOwner
+	}
+
+	if err := change(tx); err != nil {
+		return fmt.Errorf("failed to change workspace member: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit workspace member change: %w", err)
+	}
+	return nil
+}
+
+// invitationColumns lists the workspace_invitations columns scanned by scanInvitation
+const invitationColumns = "id, workspace_id, email, role, token_hash, COALESCE(invited_by, 0), expires_at, created_at, accepted_at"
+
+func scanInvitation(row rowScanner) (models.WorkspaceInvitation, error) {
+	var invitation models.WorkspaceInvitation
+	err := row.Scan(&invitation.ID, &invitation.WorkspaceID, &invitation.Email, &invitation.Role, &invitation.TokenHash,
+		&invitation.InvitedBy, &invitation.ExpiresAt, &invitation.CreatedAt, &invitation.AcceptedAt)
+	return invitation, err
+}
+
+// CreateWorkspaceInvitation implements repositories.WorkspaceRepository
+func (s *Store) CreateWorkspaceInvitation(invitation models.WorkspaceInvitation) (models.WorkspaceInvitation, error) {
+	query := `INSERT INTO workspace_invitations (workspace_id, email, role, token_hash, invited_by, expires_at)
+			  VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`
+	err := s.db.QueryRow(query, invitation.WorkspaceID, invitation.Email, invitation.Role, invitation.TokenHash,
+		invitation.InvitedBy, invitation.ExpiresAt).Scan(&invitation.ID, &invitation.CreatedAt)
+	if err != nil {
+		return models.WorkspaceInvitation{}, fmt.Errorf("failed to insert workspace invitation: %w", err)
+	}
+	return invitation, nil
+}
+
+// ListWorkspaceInvitations implements repositories.WorkspaceRepository
+func (s *Store) ListWorkspaceInvitations(workspaceID int) ([]models.WorkspaceInvitation, error) {
+	query := "SELECT " + invitationColumns + ` FROM workspace_invitations
+			  WHERE workspace_id = $1 AND accepted_at IS NULL AND expires_at > NOW()
+			  ORDER BY created_at DESC, id DESC`
+
+	rows, err := s.db.Query(query, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspace invitations: %w", err)
+	}
+	defer rows.Close()
+
+	invitations := []models.WorkspaceInvitation{}
+	for rows.Next() {
+		invitation, err := scanInvitation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan workspace invitation row: %w", err)
+		}
+		invitations = append(invitations, invitation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating workspace invitation rows: %w", err)
+	}
+
+	return invitations, nil
+}
+
+// RevokeWorkspaceInvitation implements repositories.WorkspaceRepository
+func (s *Store) RevokeWorkspaceInvitation(workspaceID int, invitationID int64) error {
+	result, err := s.db.Exec("DELETE FROM workspace_invitations WHERE id = $1 AND workspace_id = $2 AND accepted_at IS NULL",
+		invitationID, workspaceID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke workspace invitation: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to revoke workspace invitation: %w", err)
+	}
+	if rows == 0 {
+		return repositories.ErrInvitationNotFound
+	}
+	return nil
+}
+
+// AcceptWorkspaceInvitation implements repositories.WorkspaceRepository.
+// Marking the invitation accepted is the first statement, so of two
+// concurrent redemptions only one finds it pending.
+func (s *Store) AcceptWorkspaceInvitation(tokenHash string, userID int, email string) (models.Workspace, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return models.Workspace{}, fmt.Errorf("failed to start accepting invitation: %w", err)
+	}
+	defer tx.Rollback()
+
+	var workspaceID int
+	var role string
+	err = tx.QueryRow(`UPDATE workspace_invitations SET accepted_at = NOW()
+			  WHERE token_hash = $1 AND accepted_at IS NULL AND expires_at > NOW() AND LOWER(email) = LOWER($2)
+			  RETURNING workspace_id, role`, tokenHash, email).Scan(&workspaceID, &role)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Workspace{}, repositories.ErrInvitationInvalid
+		}
+		return models.Workspace{}, fmt.Errorf("failed to redeem workspace invitation: %w", err)
+	}
+
+	// Existing members are only ever promoted by an invitation
+	query := `INSERT INTO workspace_members (workspace_id, user_id, role) VALUES ($1, $2, $3)
+			  ON CONFLICT (workspace_id, user_id) DO UPDATE SET role = EXCLUDED.role
+			  WHERE NOT (workspace_members.role = ANY($4))`
+	if _, err := tx.Exec(query, workspaceID, userID, role, pq.Array(models.WorkspaceRolesAtLeast(role))); err != nil {
+		return models.Workspace{}, fmt.Errorf("failed to add workspace member: %w", err)
+	}
+
+	workspace, err := scanWorkspace(tx.QueryRow("SELECT "+workspaceColumns+` FROM workspaces w
+			  JOIN workspace_members m ON m.workspace_id = w.id AND m.user_id = $2
+			  WHERE w.id = $1`, workspaceID, userID))
+	if err != nil {
+		return models.Workspace{}, fmt.Errorf("failed to get workspace: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Workspace{}, fmt.Errorf("failed to commit invitation: %w", err)
+	}
+	return workspace, nil
+}
@@ -0,0 +1,172 @@
+package memory
+
+import (
+	"database/sql"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"strings"
+	"time"
+)
+
+// SearchUsers implements repositories.AdminRepository
+func (s *Store) SearchUsers(query string, limit, offset int) ([]models.User, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Users are appended in ID order, so walking backwards is newest first
+	var users []models.User
+	for i := len(s.users) - 1; i >= 0; i-- {
+		user := s.users[i]
+		if containsFold(user.Username, query) || containsFold(user.Email, query) {
+			users = append(users, user)
+		}
+	}
+	return page(users, limit, offset), len(users), nil
+}
+
+// SetUserRole implements repositories.AdminRepository
+func (s *Store) SetUserRole(userID int, role string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.users {
+		if s.users[i].ID == userID {
+			s.users[i].Role = role
+			return nil
+		}
+	}
+	return repositories.ErrUserNotFound
+}
+
+// SetUserDisabled implements repositories.AdminRepository
+func (s *Store) SetUserDisabled(userID int, disabled bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := sql.NullTime{Time: time.Now(), Valid: true}
+	for i := range s.users {
+		user := &s.users[i]
+		if user.ID != userID {
+			continue
+		}
+		if !disabled {
+			user.DisabledAt = sql.NullTime{}
+			return nil
+		}
+		if !user.DisabledAt.Valid {
+			user.DisabledAt = now
+		}
+		for j := range s.tokens {
+			if s.tokens[j].UserID == userID && !s.tokens[j].RevokedAt.Valid {
+				s.tokens[j].RevokedAt = now
+			}
+		}
+		return nil
+	}
+	return repositories.ErrUserNotFound
+}
+
+// SearchLinks implements repositories.AdminRepository
+func (s *Store) SearchLinks(query string, limit, offset int) ([]models.Link, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	links := s.newestLinks(func(link models.Link) bool {
+		return containsFold(link.Slug, query) || containsFold(link.TargetURL, query)
+	})
+	return page(links, limit, offset), len(links), nil
+}
+
+// TakeDownLink implements repositories.AdminRepository. The memory store
+// does not keep which admin took the link down.
+func (s *Store) TakeDownLink(domainID int, slug string, adminID int, reason string) (models.Link, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.linkIndex(domainID, slug, false)
+	if i < 0 {
+		return models.Link{}, repositories.ErrLinkNotFound
+	}
+	link := &s.links[i]
+	if !link.TakenDownAt.Valid {
+		link.TakenDownAt = sql.NullTime{Time: time.Now(), Valid: true}
+	}
+	link.TakedownReason = sql.NullString{String: reason, Valid: true}
+	return *link, nil
+}
+
+// RestoreLink implements repositories.AdminRepository
+func (s *Store) RestoreLink(domainID int, slug string) (models.Link, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.linkIndex(domainID, slug, false)
+	if i < 0 {
+		return models.Link{}, repositories.ErrLinkNotFound
+	}
+	link := &s.links[i]
+	link.TakenDownAt = sql.NullTime{}
+	link.TakedownReason = sql.NullString{}
+	return *link, nil
+}
+
+// GetInstanceStats implements repositories.AdminRepository
+func (s *Store) GetInstanceStats() (models.InstanceStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var stats models.InstanceStats
+	for _, user := range s.users {
+		stats.Users++
+		if user.Role == models.UserRoleAdmin {
+			stats.AdminUsers++
+		}
+		if user.DisabledAt.Valid {
+			stats.DisabledUsers++
+		}
+		if !user.EmailVerified {
+			stats.UnverifiedUsers++
+		}
+	}
+	for _, ws := range s.workspaces {
+		if ws.personalUserID == 0 {
+			stats.SharedWorkspaces++
+		}
+	}
+	for _, link := range s.links {
+		stats.TotalClicks += int64(link.ClickCount)
+		switch {
+		case link.DeletedAt.Valid:
+			stats.DeletedLinks++
+		case link.TakenDownAt.Valid:
+			stats.Links++
+			stats.TakenDownLinks++
+		default:
+			stats.Links++
+		}
+	}
+	since := time.Now().Add(-24 * time.Hour)
+	for _, entry := range s.logs {
+		if entry.EventType == models.EventClick && !entry.AccessedAt.Before(since) {
+			stats.ClicksLast24Hours++
+		}
+	}
+	return stats, nil
+}
+
+// containsFold reports whether value contains query, ignoring case
+func containsFold(value, query string) bool {
+	return strings.Contains(strings.ToLower(value), strings.ToLower(query))
+}
+
+// page returns the items from offset, at most limit of them
+func page[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return []T{}
+	}
+	items = items[offset:]
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return items
+}
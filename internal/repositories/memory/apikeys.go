@@ -0,0 +1,85 @@
+package memory
+
+import (
+	"database/sql"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"sort"
+	"time"
+)
+
+// CreateAPIKey implements repositories.APIKeyRepository
+func (s *Store) CreateAPIKey(key models.APIKey) (models.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextAPIKeyID++
+	key.ID = s.nextAPIKeyID
+	key.CreatedAt = time.Now()
+	key.Scopes = append([]string(nil), key.Scopes...)
+	s.apiKeys = append(s.apiKeys, key)
+
+	return key, nil
+}
+
+// ListAPIKeysByUser implements repositories.APIKeyRepository
+func (s *Store) ListAPIKeysByUser(userID int) ([]models.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := []models.APIKey{}
+	for _, key := range s.apiKeys {
+		if key.UserID == userID && !key.RevokedAt.Valid {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool {
+		if !keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].CreatedAt.After(keys[j].CreatedAt)
+		}
+		return keys[i].ID > keys[j].ID
+	})
+	return keys, nil
+}
+
+// GetAPIKeyByHash implements repositories.APIKeyRepository
+func (s *Store) GetAPIKeyByHash(keyHash string) (models.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range s.apiKeys {
+		if key.KeyHash == keyHash && !key.RevokedAt.Valid {
+			return key, nil
+		}
+	}
+	return models.APIKey{}, repositories.ErrAPIKeyNotFound
+}
+
+// TouchAPIKey implements repositories.APIKeyRepository
+func (s *Store) TouchAPIKey(keyID int64, usedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.apiKeys {
+		if s.apiKeys[i].ID == keyID {
+			s.apiKeys[i].LastUsedAt = sql.NullTime{Time: usedAt, Valid: true}
+		}
+	}
+	return nil
+}
+
+// RevokeAPIKey implements repositories.APIKeyRepository
+func (s *Store) RevokeAPIKey(keyID int64, userID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.apiKeys {
+		key := &s.apiKeys[i]
+		if key.ID == keyID && key.UserID == userID && !key.RevokedAt.Valid {
+			key.RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+			return nil
+		}
+	}
+	return repositories.ErrAPIKeyNotFound
+}
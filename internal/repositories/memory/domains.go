@@ -0,0 +1,137 @@
+package memory
+
+import (
+	"database/sql"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"time"
+)
+
+// CreateDomain implements repositories.DomainRepository
+func (s *Store) CreateDomain(domain models.Domain) (models.Domain, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.domains {
+		if existing.Hostname == domain.Hostname && (existing.WorkspaceID == domain.WorkspaceID || existing.VerifiedAt.Valid) {
+			return models.Domain{}, repositories.ErrDomainTaken
+		}
+	}
+
+	s.nextDomainID++
+	domain.ID = s.nextDomainID
+	domain.VerifiedAt = sql.NullTime{}
+	domain.CreatedAt = time.Now()
+	s.domains = append(s.domains, domain)
+	return domain, nil
+}
+
+// ListDomainsByWorkspace implements repositories.DomainRepository
+func (s *Store) ListDomainsByWorkspace(workspaceID int) ([]models.Domain, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	domains := []models.Domain{}
+	for _, domain := range s.domains {
+		if domain.WorkspaceID == workspaceID {
+			domains = append(domains, domain)
+		}
+	}
+	return domains, nil
+}
+
+// GetDomain implements repositories.DomainRepository
+func (s *Store) GetDomain(workspaceID, domainID int) (models.Domain, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.workspaceDomainIndex(workspaceID, domainID)
+	if i < 0 {
+		return models.Domain{}, repositories.ErrDomainNotFound
+	}
+	return s.domains[i], nil
+}
+
+// GetVerifiedDomainByHostname implements repositories.DomainRepository
+func (s *Store) GetVerifiedDomainByHostname(hostname string) (models.Domain, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, domain := range s.domains {
+		if domain.Hostname == hostname && domain.VerifiedAt.Valid {
+			return domain, nil
+		}
+	}
+	return models.Domain{}, repositories.ErrDomainNotFound
+}
+
+// MarkDomainVerified implements repositories.DomainRepository
+func (s *Store) MarkDomainVerified(workspaceID, domainID int) (models.Domain, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.workspaceDomainIndex(workspaceID, domainID)
+	if i < 0 {
+		return models.Domain{}, repositories.ErrDomainNotFound
+	}
+
+	domain := &s.domains[i]
+	if domain.VerifiedAt.Valid {
+		return *domain, nil
+	}
+	for _, other := range s.domains {
+		if other.Hostname == domain.Hostname && other.VerifiedAt.Valid {
+			return models.Domain{}, repositories.ErrDomainTaken
+		}
+	}
+
+	domain.VerifiedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	return *domain, nil
+}
+
+// DeleteDomain implements repositories.DomainRepository
+func (s *Store) DeleteDomain(workspaceID, domainID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.workspaceDomainIndex(workspaceID, domainID)
+	if i < 0 {
+		return repositories.ErrDomainNotFound
+	}
+
+	for _, link := range s.links {
+		if linkDomainID(link) == domainID && !link.DeletedAt.Valid {
+			return repositories.ErrDomainInUse
+		}
+	}
+
+	// Deleted links on the domain go with it
+	links := []models.Link{}
+	for _, link := range s.links {
+		if linkDomainID(link) != domainID {
+			links = append(links, link)
+		}
+	}
+	s.links = links
+	s.domains = append(s.domains[:i], s.domains[i+1:]...)
+	return nil
+}
+
+// domainIndex returns the position of the domain with the ID, or -1. The caller must hold s.mu.
+func (s *Store) domainIndex(domainID int) int {
+	for i, domain := range s.domains {
+		if domain.ID == domainID {
+			return i
+		}
+	}
+	return -1
+}
+
+// workspaceDomainIndex is domainIndex for a domain of the workspace. The caller must hold s.mu.
+func (s *Store) workspaceDomainIndex(workspaceID, domainID int) int {
+	i := s.domainIndex(domainID)
+	if i < 0 || s.domains[i].WorkspaceID != workspaceID {
+		return -1
+	}
+	return i
+}
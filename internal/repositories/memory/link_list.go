@@ -0,0 +1,105 @@
+package memory
+
+import (
+	"fmt"
+	"link-guardian/internal/models"
+	"sort"
+	"time"
+)
+
+// ListLinksByWorkspace implements repositories.LinkRepository
+func (s *Store) ListLinksByWorkspace(workspaceID int, filter models.LinkFilter, page models.LinkPage) ([]models.Link, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if filter.Status != "" && !models.ValidLinkStatus(filter.Status) {
+		return nil, 0, fmt.Errorf("unknown link status %q", filter.Status)
+	}
+	if !models.ValidLinkSortField(page.Sort.Field) {
+		return nil, 0, fmt.Errorf("unknown link sort field %q", page.Sort.Field)
+	}
+
+	now := time.Now()
+	var matches []models.Link
+	for _, link := range s.links {
+		if link.WorkspaceID.Valid && int(link.WorkspaceID.Int32) == workspaceID && linkMatches(link, filter, now) {
+			matches = append(matches, link)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return compareLinkCursors(page.Sort.CursorAt(matches[i]), page.Sort.CursorAt(matches[j])) < 0
+	})
+
+	links := []models.Link{}
+	for _, link := range matches {
+		if len(links) == page.Limit {
+			break
+		}
+		if page.After == nil || compareLinkCursors(page.Sort.CursorAt(link), *page.After) > 0 {
+			links = append(links, link)
+		}
+	}
+	return links, len(matches), nil
+}
+
+// linkMatches reports whether link passes filter at now
+func linkMatches(link models.Link, filter models.LinkFilter, now time.Time) bool {
+	status := link.Status(now)
+	if filter.Status == "" && status == models.LinkStatusDeleted || filter.Status != "" && status != filter.Status {
+		return false
+	}
+	if filter.Query != "" && !containsFold(link.Slug, filter.Query) && !containsFold(link.TargetURL, filter.Query) {
+		return false
+	}
+	if filter.Folder != "" && link.Folder.String != filter.Folder {
+		return false
+	}
+	for _, tag := range filter.Tags {
+		if !containsString(link.Tags, tag) {
+			return false
+		}
+	}
+	if !filter.CreatedFrom.IsZero() && link.CreatedAt.Before(filter.CreatedFrom) {
+		return false
+	}
+	if !filter.CreatedTo.IsZero() && !link.CreatedAt.Before(filter.CreatedTo) {
+		return false
+	}
+	if filter.HasClickLimit != nil && link.ClickLimit.Valid != *filter.HasClickLimit {
+		return false
+	}
+	return true
+}
+
+// compareLinkCursors orders two cursors of the same sort like the database:
+// by sort key, with no expiry after every time, then by ID
+func compareLinkCursors(a, b models.LinkCursor) int {
+	result := 0
+	switch {
+	case a.Sort == models.LinkSortClickCount:
+		result = compareInts(a.ClickCount, b.ClickCount)
+	case a.Time == nil && b.Time != nil:
+		result = 1
+	case a.Time != nil && b.Time == nil:
+		result = -1
+	case a.Time != nil:
+		result = a.Time.Compare(*b.Time)
+	}
+	if result == 0 {
+		result = compareInts(a.ID, b.ID)
+	}
+	if a.Desc {
+		return -result
+	}
+	return result
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,498 @@
+// Package memory implements the repositories in process. It mirrors the
+// behaviour of the PostgreSQL store closely enough for handler tests and
+// local development without a database.
+package memory
+
+import (
+	"database/sql"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Store holds users, links, revisions, access logs, refresh tokens, API
+// keys, password reset tokens, two-factor enrolments, workspaces, domains,
+// tags and folders in memory. It is safe for concurrent use; every method runs under a single
+// lock, which also makes ConsumeClick, RotateRefreshToken, ResetPassword and
+// UseTOTPStep atomic.
+type Store struct {
+	mu sync.Mutex
+
+	users     []models.User
+	links     []models.Link // ordered by ID
+	revisions []models.LinkRevision
+	logs      []models.AccessLog
+	tokens    []models.RefreshToken
+	apiKeys   []models.APIKey
+	resets    []models.PasswordResetToken
+	totp      []models.TwoFactor
+	recovery  []recoveryCode
+
+	workspaces  []workspace // ordered by ID
+	members     []workspaceMember
+	invitations []models.WorkspaceInvitation
+	domains     []models.Domain // ordered by ID
+	tags        []models.Tag
+	folders     []models.Folder
+
+	nextUserID       int
+	nextLinkID       int
+	nextRevisionID   int64
+	nextLogID        int64
+	nextTokenID      int64
+	nextAPIKeyID     int64
+	nextResetID      int64
+	nextWorkspaceID  int
+	nextInvitationID int64
+	nextDomainID     int
+	nextTagID        int
+	nextFolderID     int
+}
+
+var _ repositories.Store = (*Store)(nil)
+
+// NewStore creates an empty store
+func NewStore() *Store {
+	return &Store{}
+}
+
+// CreateLink implements repositories.LinkRepository
+func (s *Store) CreateLink(link models.Link) (models.Link, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.linkIndex(linkDomainID(link), link.Slug, true) >= 0 {
+		return models.Link{}, repositories.ErrSlugTaken
+	}
+
+	link.Domain = sql.NullString{}
+	if link.DomainID.Valid {
+		if i := s.domainIndex(int(link.DomainID.Int32)); i >= 0 {
+			link.Domain = sql.NullString{String: s.domains[i].Hostname, Valid: true}
+		}
+	}
+
+	s.nextLinkID++
+	link.ID = s.nextLinkID
+	if link.CreatedAt.IsZero() {
+		link.CreatedAt = time.Now()
+	}
+	if link.WorkspaceID.Valid {
+		workspaceID := int(link.WorkspaceID.Int32)
+		link.Tags = s.linkTags(workspaceID, link.Tags)
+		link.Folder = s.linkFolder(workspaceID, link.Folder.String)
+	} else {
+		link.Tags, link.Folder = nil, sql.NullString{}
+	}
+	s.links = append(s.links, link)
+
+	return link, nil
+}
+
+// FilterAvailableSlugs implements repositories.LinkRepository
+func (s *Store) FilterAvailableSlugs(domainID int, candidates []string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	available := []string{}
+	for _, candidate := range candidates {
+		if s.linkIndex(domainID, candidate, true) < 0 {
+			available = append(available, candidate)
+		}
+	}
+	return available, nil
+}
+
+// GetLinkBySlug implements repositories.LinkRepository
+func (s *Store) GetLinkBySlug(domainID int, slug string) (models.Link, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.linkIndex(domainID, slug, false)
+	if i < 0 {
+		return models.Link{}, repositories.ErrLinkNotFound
+	}
+	return s.links[i], nil
+}
+
+// GetMemberLinkBySlug implements repositories.LinkRepository
+func (s *Store) GetMemberLinkBySlug(domainID int, slug string, userID int, role string) (models.Link, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, err := s.memberLinkIndex(domainID, slug, userID, role)
+	if err != nil {
+		return models.Link{}, err
+	}
+	return s.links[i], nil
+}
+
+// ListLinksByUser implements repositories.LinkRepository
+func (s *Store) ListLinksByUser(userID int) ([]models.Link, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.newestLinks(func(link models.Link) bool {
+		return link.UserID.Valid && int(link.UserID.Int32) == userID
+	}), nil
+}
+
+// CountLinksByUser implements repositories.LinkRepository
+func (s *Store) CountLinksByUser(userID int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for _, link := range s.links {
+		if !link.DeletedAt.Valid && link.UserID.Valid && int(link.UserID.Int32) == userID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// newestLinks returns the active links matching match, newest first. The caller must hold s.mu.
+func (s *Store) newestLinks(match func(models.Link) bool) []models.Link {
+	var links []models.Link
+	for _, link := range s.links {
+		if !link.DeletedAt.Valid && match(link) {
+			links = append(links, link)
+		}
+	}
+
+	sort.SliceStable(links, func(i, j int) bool {
+		if !links[i].CreatedAt.Equal(links[j].CreatedAt) {
+			return links[i].CreatedAt.After(links[j].CreatedAt)
+		}
+		return links[i].ID > links[j].ID
+	})
+	return links
+}
+
+// ConsumeClick implements repositories.LinkRepository
+func (s *Store) ConsumeClick(domainID int, slug string, unlocked bool) (models.Link, models.ClickOutcome, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.linkIndex(domainID, slug, false)
+	if i < 0 {
+		return models.Link{}, models.ClickNotFound, nil
+	}
+
+	link := &s.links[i]
+	switch {
+	case link.TakenDownAt.Valid:
+		return *link, models.ClickTakenDown, nil
+	case link.ExpiresAt.Valid && !link.ExpiresAt.Time.After(time.Now()):
+		return *link, models.ClickExpired, nil
+	case link.ClickLimit.Valid && link.ClickCount >= int(link.ClickLimit.Int32):
+		return *link, models.ClickExhausted, nil
+	case link.PasswordHash.Valid && !unlocked:
+		return *link, models.ClickLocked, nil
+	}
+
+	link.ClickCount++
+	return *link, models.ClickAllowed, nil
+}
+
+// IncrementClickCount implements repositories.LinkRepository
+func (s *Store) IncrementClickCount(linkID int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.links {
+		link := &s.links[i]
+		if link.ID != linkID {
+			continue
+		}
+		if link.DeletedAt.Valid || link.TakenDownAt.Valid || (link.ExpiresAt.Valid && !link.ExpiresAt.Time.After(time.Now())) ||
+			link.ClickLimit.Valid || link.PasswordHash.Valid {
+			return false, nil
+		}
+		link.ClickCount++
+		return true, nil
+	}
+	return false, nil
+}
+
+// UpdateLink implements repositories.LinkRepository
+func (s *Store) UpdateLink(domainID int, slug string, userID int, action string, revertedRevisionID *int64,
+	change repositories.LinkChange, organization models.LinkOrganization) (models.Link, *models.LinkRevision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, err := s.memberLinkIndex(domainID, slug, userID, models.WorkspaceRoleEditor)
+	if err != nil {
+		return models.Link{}, nil, err
+	}
+
+	link := &s.links[i]
+	if organization.Tags != nil {
+		link.Tags = s.linkTags(int(link.WorkspaceID.Int32), *organization.Tags)
+	}
+	if organization.Folder != nil {
+		link.Folder = s.linkFolder(int(link.WorkspaceID.Int32), *organization.Folder)
+	}
+
+	oldState := link.State()
+	newState := change(oldState)
+	if newState.Equal(oldState) {
+		return *link, nil, nil
+	}
+
+	link.TargetURL = newState.TargetURL
+	link.ExpiresAt = sql.NullTime{}
+	if newState.ExpiresAt != nil {
+		link.ExpiresAt = sql.NullTime{Time: *newState.ExpiresAt, Valid: true}
+	}
+	link.ClickLimit = sql.NullInt32{}
+	if newState.ClickLimit != nil {
+		link.ClickLimit = sql.NullInt32{Int32: int32(*newState.ClickLimit), Valid: true}
+	}
+
+	s.nextRevisionID++
+	revision := models.LinkRevision{
+		ID:                 s.nextRevisionID,
+		LinkID:             link.ID,
+		UserID:             &userID,
+		Action:             action,
+		OldValues:          oldState,
+		NewValues:          newState,
+		RevertedRevisionID: revertedRevisionID,
+		CreatedAt:          time.Now(),
+	}
+	s.revisions = append(s.revisions, revision)
+
+	return *link, &revision, nil
+}
+
+// SoftDeleteLink implements repositories.LinkRepository
+func (s *Store) SoftDeleteLink(domainID int, slug string, userID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, err := s.memberLinkIndex(domainID, slug, userID, models.WorkspaceRoleEditor)
+	if err != nil {
+		return err
+	}
+
+	s.links[i].DeletedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	return nil
+}
+
+// GetLinkRevisions implements repositories.LinkRepository
+func (s *Store) GetLinkRevisions(linkID int) ([]models.LinkRevision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Revisions are appended in ID order, so walking backwards is newest first
+	revisions := []models.LinkRevision{}
+	for i := len(s.revisions) - 1; i >= 0; i-- {
+		if s.revisions[i].LinkID == linkID {
+			revisions = append(revisions, s.revisions[i])
+		}
+	}
+	return revisions, nil
+}
+
+// GetLinkRevision implements repositories.LinkRepository
+func (s *Store) GetLinkRevision(linkID int, revisionID int64) (models.LinkRevision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, revision := range s.revisions {
+		if revision.LinkID == linkID && revision.ID == revisionID {
+			return revision, nil
+		}
+	}
+	return models.LinkRevision{}, repositories.ErrRevisionNotFound
+}
+
+// linkIndex returns the position of the link with slug on the domain, or -1.
+// Deleted links are only considered when includeDeleted is set. The caller
+// must hold s.mu.
+func (s *Store) linkIndex(domainID int, slug string, includeDeleted bool) int {
+	for i, link := range s.links {
+		if linkDomainID(link) == domainID && link.Slug == slug && (includeDeleted || !link.DeletedAt.Valid) {
+			return i
+		}
+	}
+	return -1
+}
+
+// linkDomainID returns the ID of the link's custom domain, or 0 for the default domain
+func linkDomainID(link models.Link) int {
+	if !link.DomainID.Valid {
+		return 0
+	}
+	return int(link.DomainID.Int32)
+}
+
+// memberLinkIndex is linkIndex for an active link in a workspace where
+// userID has at least role. The caller must hold s.mu.
+func (s *Store) memberLinkIndex(domainID int, slug string, userID int, role string) (int, error) {
+	i := s.linkIndex(domainID, slug, false)
+	if i < 0 {
+		return -1, repositories.ErrLinkNotFound
+	}
+	link := s.links[i]
+	if !link.WorkspaceID.Valid || !models.WorkspaceRoleAtLeast(s.memberRole(int(link.WorkspaceID.Int32), userID), role) {
+		return -1, repositories.ErrLinkForbidden
+	}
+	return i, nil
+}
+
+// CreateUser implements repositories.UserRepository
+func (s *Store) CreateUser(username, email, passwordHash string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, user := range s.users {
+		if user.Email == email {
+			return 0, repositories.ErrEmailTaken
+		}
+		if user.Username == username {
+			return 0, repositories.ErrUsernameTaken
+		}
+	}
+
+	s.nextUserID++
+	s.users = append(s.users, models.User{
+		ID:        s.nextUserID,
+		Username:  username,
+		Email:     email,
+		Password:  passwordHash,
+		Role:      models.UserRoleUser,
+		CreatedAt: time.Now().Format(time.RFC3339Nano),
+	})
+	s.createWorkspace(models.PersonalWorkspaceName, s.nextUserID, true)
+	return s.nextUserID, nil
+}
+
+// EmailExists implements repositories.UserRepository
+func (s *Store) EmailExists(email string) (bool, error) {
+	return s.findUser(func(user models.User) bool { return user.Email == email }) != nil, nil
+}
+
+// UsernameExists implements repositories.UserRepository
+func (s *Store) UsernameExists(username string) (bool, error) {
+	return s.findUser(func(user models.User) bool { return user.Username == username }) != nil, nil
+}
+
+// GetUserByEmail implements repositories.UserRepository
+func (s *Store) GetUserByEmail(email string) (*models.User, error) {
+	if user := s.findUser(func(user models.User) bool { return strings.EqualFold(user.Email, email) }); user != nil {
+		return user, nil
+	}
+	return nil, repositories.ErrUserNotFound
+}
+
+// GetUserByID implements repositories.UserRepository
+func (s *Store) GetUserByID(userID int) (*models.User, error) {
+	if user := s.findUser(func(user models.User) bool { return user.ID == userID }); user != nil {
+		return user, nil
+	}
+	return nil, repositories.ErrUserNotFound
+}
+
+// MarkEmailVerified implements repositories.UserRepository
+func (s *Store) MarkEmailVerified(userID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.users {
+		if s.users[i].ID == userID {
+			s.users[i].EmailVerified = true
+			return nil
+		}
+	}
+	return repositories.ErrUserNotFound
+}
+
+// findUser returns a copy of the first user matching match, or nil
+func (s *Store) findUser(match func(models.User) bool) *models.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, user := range s.users {
+		if match(user) {
+			return &user
+		}
+	}
+	return nil
+}
+
+// InsertAccessLogs implements repositories.AccessLogRepository
+func (s *Store) InsertAccessLogs(logs []models.AccessLog) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, entry := range logs {
+		if !s.linkExists(int(entry.LinkID)) {
+			continue
+		}
+		if entry.EventType == "" {
+			entry.EventType = models.EventClick
+		}
+		if entry.AccessedAt.IsZero() {
+			entry.AccessedAt = time.Now()
+		}
+		s.nextLogID++
+		entry.ID = s.nextLogID
+		s.logs = append(s.logs, entry)
+	}
+	return nil
+}
+
+// GetAccessLogsByWorkspace implements repositories.AccessLogRepository
+func (s *Store) GetAccessLogsByWorkspace(workspaceID int, linkID string, limit int) ([]models.AccessLog, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inWorkspace := map[int64]bool{}
+	for _, link := range s.links {
+		if link.WorkspaceID.Valid && int(link.WorkspaceID.Int32) == workspaceID && (linkID == "" || strconv.Itoa(link.ID) == linkID) {
+			inWorkspace[int64(link.ID)] = true
+		}
+	}
+
+	return s.newestLogs(func(entry models.AccessLog) bool { return inWorkspace[entry.LinkID] }, limit), nil
+}
+
+// GetAccessLogsByLink implements repositories.AccessLogRepository
+func (s *Store) GetAccessLogsByLink(linkID int, limit int) ([]models.AccessLog, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.newestLogs(func(entry models.AccessLog) bool { return entry.LinkID == int64(linkID) }, limit), nil
+}
+
+// newestLogs returns up to limit matching entries, newest first. The caller must hold s.mu.
+func (s *Store) newestLogs(match func(models.AccessLog) bool, limit int) []models.AccessLog {
+	logs := []models.AccessLog{}
+	for _, entry := range s.logs {
+		if match(entry) {
+			logs = append(logs, entry)
+		}
+	}
+
+	sort.SliceStable(logs, func(i, j int) bool { return logs[i].AccessedAt.After(logs[j].AccessedAt) })
+	if len(logs) > limit {
+		logs = logs[:limit]
+	}
+	return logs
+}
+
+// linkExists reports whether a link with the ID exists, deleted or not. The caller must hold s.mu.
+func (s *Store) linkExists(linkID int) bool {
+	for _, link := range s.links {
+		if link.ID == linkID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,13 @@
+package memory
+
+import (
+	"link-guardian/internal/repositories"
+	"link-guardian/internal/repositories/repotest"
+	"testing"
+)
+
+func TestStoreContract(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repositories.Store {
+		return NewStore()
+	})
+}
@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"database/sql"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"time"
+)
+
+// CreatePasswordResetToken implements repositories.PasswordResetRepository
+func (s *Store) CreatePasswordResetToken(token models.PasswordResetToken) (models.PasswordResetToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextResetID++
+	token.ID = s.nextResetID
+	token.CreatedAt = time.Now()
+	s.resets = append(s.resets, token)
+	return token, nil
+}
+
+// ResetPassword implements repositories.PasswordResetRepository
+func (s *Store) ResetPassword(tokenHash, passwordHash string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	userID := 0
+	for _, token := range s.resets {
+		if token.TokenHash == tokenHash && !token.UsedAt.Valid && token.ExpiresAt.After(now) {
+			userID = token.UserID
+			break
+		}
+	}
+	if userID == 0 {
+		return 0, repositories.ErrPasswordResetTokenInvalid
+	}
+
+	for i := range s.users {
+		if s.users[i].ID == userID {
+			s.users[i].Password = passwordHash
+			s.users[i].EmailVerified = true
+		}
+	}
+
+	used := sql.NullTime{Time: now, Valid: true}
+	for i := range s.resets {
+		if s.resets[i].UserID == userID && !s.resets[i].UsedAt.Valid {
+			s.resets[i].UsedAt = used
+		}
+	}
+	for i := range s.tokens {
+		if s.tokens[i].UserID == userID && !s.tokens[i].RevokedAt.Valid {
+			s.tokens[i].RevokedAt = used
+		}
+	}
+
+	return userID, nil
+}
@@ -0,0 +1,140 @@
+package memory
+
+import (
+	"fmt"
+	"link-guardian/internal/models"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// referrerHost matches the host of an absolute URL, like the PostgreSQL store
+var referrerHost = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*://(?:[^@/?#]*@)?([^:/?#]+)`)
+
+// GetLinkClickStats implements repositories.AccessLogRepository
+func (s *Store) GetLinkClickStats(linkID int, from, to time.Time, bucket, timezone string, breakdownLimit int) (models.LinkStats, error) {
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return models.LinkStats{}, fmt.Errorf("failed to load time zone: %w", err)
+	}
+	switch bucket {
+	case models.StatsBucketHour, models.StatsBucketDay, models.StatsBucketWeek:
+	default:
+		return models.LinkStats{}, fmt.Errorf("unknown stats bucket %q", bucket)
+	}
+
+	s.mu.Lock()
+	var clicks []models.AccessLog
+	for _, entry := range s.logs {
+		if entry.LinkID == int64(linkID) && entry.EventType == models.EventClick &&
+			!entry.AccessedAt.Before(from) && entry.AccessedAt.Before(to) {
+			clicks = append(clicks, entry)
+		}
+	}
+	s.mu.Unlock()
+
+	stats := models.LinkStats{
+		From:     from,
+		To:       to,
+		Bucket:   bucket,
+		Timezone: timezone,
+	}
+
+	visitors := map[string]bool{}
+	perBucket := map[time.Time]int64{}
+	counts := map[string]map[string]int64{}
+	count := func(dimension, value, fallback string) {
+		if value == "" {
+			value = fallback
+		}
+		if counts[dimension] == nil {
+			counts[dimension] = map[string]int64{}
+		}
+		counts[dimension][value]++
+	}
+
+	for _, entry := range clicks {
+		stats.TotalClicks++
+		visitors[entry.IPAddress] = true
+		perBucket[truncate(entry.AccessedAt.In(location), bucket)]++
+
+		count("country", entry.Country, "unknown")
+		count("device_type", entry.DeviceType, "unknown")
+		count("browser", entry.Browser, "unknown")
+		count("os", entry.OS, "unknown")
+		count("referrer", normalizeReferrer(entry.Referer), "direct")
+	}
+	stats.UniqueVisitors = int64(len(visitors))
+
+	stats.Series = []models.StatsPoint{}
+	last := truncate(to.Add(-time.Microsecond).In(location), bucket)
+	for point := truncate(from.In(location), bucket); !point.After(last); point = next(point, bucket) {
+		stats.Series = append(stats.Series, models.StatsPoint{Bucket: point, Clicks: perBucket[point]})
+	}
+
+	stats.Breakdowns = models.StatsBreakdowns{
+		Countries:        topValues(counts["country"], breakdownLimit),
+		DeviceTypes:      topValues(counts["device_type"], breakdownLimit),
+		Browsers:         topValues(counts["browser"], breakdownLimit),
+		OperatingSystems: topValues(counts["os"], breakdownLimit),
+		Referrers:        topValues(counts["referrer"], breakdownLimit),
+	}
+
+	return stats, nil
+}
+
+// truncate rounds t down to the start of its hour, day or ISO week in t's location
+func truncate(t time.Time, bucket string) time.Time {
+	year, month, day := t.Date()
+	switch bucket {
+	case models.StatsBucketHour:
+		return time.Date(year, month, day, t.Hour(), 0, 0, 0, t.Location())
+	case models.StatsBucketWeek:
+		daysSinceMonday := (int(t.Weekday()) + 6) % 7
+		return time.Date(year, month, day-daysSinceMonday, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+	}
+}
+
+// next returns the start of the bucket after the one starting at t
+func next(t time.Time, bucket string) time.Time {
+	year, month, day := t.Date()
+	switch bucket {
+	case models.StatsBucketHour:
+		return time.Date(year, month, day, t.Hour()+1, 0, 0, 0, t.Location())
+	case models.StatsBucketWeek:
+		return time.Date(year, month, day+7, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(year, month, day+1, 0, 0, 0, 0, t.Location())
+	}
+}
+
+// normalizeReferrer reduces a referrer URL to its host name without a leading "www."
+func normalizeReferrer(referer string) string {
+	match := referrerHost.FindStringSubmatch(referer)
+	if match == nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimPrefix(match[1], "www."))
+}
+
+// topValues returns the limit most clicked values, ties broken by value
+func topValues(counts map[string]int64, limit int) []models.StatsBreakdownEntry {
+	entries := []models.StatsBreakdownEntry{}
+	for value, clicks := range counts {
+		entries = append(entries, models.StatsBreakdownEntry{Value: value, Clicks: clicks})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Clicks != entries[j].Clicks {
+			return entries[i].Clicks > entries[j].Clicks
+		}
+		return entries[i].Value < entries[j].Value
+	})
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries
+}
@@ -0,0 +1,224 @@
+package memory
+
+import (
+	"database/sql"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"sort"
+	"time"
+)
+
+// SetLinkTags implements repositories.TagRepository
+func (s *Store) SetLinkTags(linkID int, names []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	link := s.workspaceLink(linkID)
+	if link == nil {
+		return repositories.ErrLinkNotFound
+	}
+	link.Tags = s.linkTags(int(link.WorkspaceID.Int32), names)
+	return nil
+}
+
+// SetLinkFolder implements repositories.TagRepository
+func (s *Store) SetLinkFolder(linkID int, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	link := s.workspaceLink(linkID)
+	if link == nil {
+		return repositories.ErrLinkNotFound
+	}
+	link.Folder = s.linkFolder(int(link.WorkspaceID.Int32), name)
+	return nil
+}
+
+// ListTags implements repositories.TagRepository
+func (s *Store) ListTags(workspaceID int) ([]models.Tag, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tags := []models.Tag{}
+	for _, tag := range s.tags {
+		if tag.WorkspaceID == workspaceID {
+			tags = append(tags, s.countTag(tag))
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	return tags, nil
+}
+
+// ListFolders implements repositories.TagRepository
+func (s *Store) ListFolders(workspaceID int) ([]models.Folder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	folders := []models.Folder{}
+	for _, folder := range s.folders {
+		if folder.WorkspaceID != workspaceID {
+			continue
+		}
+		folder.LinkCount, folder.ClickCount = s.countLinks(workspaceID, func(link models.Link) bool {
+			return link.Folder.Valid && link.Folder.String == folder.Name
+		})
+		folders = append(folders, folder)
+	}
+	sort.Slice(folders, func(i, j int) bool { return folders[i].Name < folders[j].Name })
+	return folders, nil
+}
+
+// RenameTag implements repositories.TagRepository
+func (s *Store) RenameTag(workspaceID, tagID int, name string) (models.Tag, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.tagIndex(workspaceID, tagID)
+	if i < 0 {
+		return models.Tag{}, repositories.ErrTagNotFound
+	}
+	oldName := s.tags[i].Name
+	if name == oldName {
+		return s.countTag(s.tags[i]), nil
+	}
+	if s.tagNameIndex(workspaceID, name) >= 0 {
+		return models.Tag{}, repositories.ErrTagTaken
+	}
+
+	s.tags[i].Name = name
+	s.retagLinks(workspaceID, oldName, name)
+	return s.countTag(s.tags[i]), nil
+}
+
+// MergeTags implements repositories.TagRepository
+func (s *Store) MergeTags(workspaceID, tagID, targetID int) (models.Tag, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, target := s.tagIndex(workspaceID, tagID), s.tagIndex(workspaceID, targetID)
+	if i < 0 || target < 0 {
+		return models.Tag{}, repositories.ErrTagNotFound
+	}
+	if i == target {
+		return s.countTag(s.tags[target]), nil
+	}
+
+	s.retagLinks(workspaceID, s.tags[i].Name, s.tags[target].Name)
+	targetTag := s.tags[target]
+	s.tags = append(s.tags[:i:i], s.tags[i+1:]...)
+	return s.countTag(targetTag), nil
+}
+
+// workspaceLink returns the active link with the ID if it belongs to a
+// workspace. The caller must hold s.mu.
+func (s *Store) workspaceLink(linkID int) *models.Link {
+	for i := range s.links {
+		link := &s.links[i]
+		if link.ID == linkID && !link.DeletedAt.Valid && link.WorkspaceID.Valid {
+			return link
+		}
+	}
+	return nil
+}
+
+// linkTags creates the missing tags of the workspace and returns a new
+// sorted slice of the names. The caller must hold s.mu.
+func (s *Store) linkTags(workspaceID int, names []string) []string {
+	if len(names) == 0 {
+		return nil
+	}
+	tags := append([]string(nil), names...)
+	for _, name := range tags {
+		if s.tagNameIndex(workspaceID, name) < 0 {
+			s.nextTagID++
+			s.tags = append(s.tags, models.Tag{ID: s.nextTagID, WorkspaceID: workspaceID, Name: name, CreatedAt: time.Now()})
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// linkFolder creates the named folder of the workspace if missing and
+// returns the link's folder. The caller must hold s.mu.
+func (s *Store) linkFolder(workspaceID int, name string) sql.NullString {
+	if name == "" {
+		return sql.NullString{}
+	}
+	for _, folder := range s.folders {
+		if folder.WorkspaceID == workspaceID && folder.Name == name {
+			return sql.NullString{String: name, Valid: true}
+		}
+	}
+	s.nextFolderID++
+	s.folders = append(s.folders, models.Folder{ID: s.nextFolderID, WorkspaceID: workspaceID, Name: name, CreatedAt: time.Now()})
+	return sql.NullString{String: name, Valid: true}
+}
+
+// retagLinks replaces tag from with tag to on the workspace's links, including
+// deleted ones. The caller must hold s.mu.
+func (s *Store) retagLinks(workspaceID int, from, to string) {
+	for i := range s.links {
+		link := &s.links[i]
+		if !link.WorkspaceID.Valid || int(link.WorkspaceID.Int32) != workspaceID || !containsString(link.Tags, from) {
+			continue
+		}
+		tags := []string{}
+		for _, tag := range link.Tags {
+			if tag != from && tag != to {
+				tags = append(tags, tag)
+			}
+		}
+		tags = append(tags, to)
+		sort.Strings(tags)
+		link.Tags = tags
+	}
+}
+
+// countTag returns the tag with its link and click counts. The caller must hold s.mu.
+func (s *Store) countTag(tag models.Tag) models.Tag {
+	tag.LinkCount, tag.ClickCount = s.countLinks(tag.WorkspaceID, func(link models.Link) bool {
+		return containsString(link.Tags, tag.Name)
+	})
+	return tag
+}
+
+// countLinks returns the number of the workspace's active links matching match
+// and their total clicks. The caller must hold s.mu.
+func (s *Store) countLinks(workspaceID int, match func(models.Link) bool) (links, clicks int) {
+	for _, link := range s.links {
+		if !link.DeletedAt.Valid && link.WorkspaceID.Valid && int(link.WorkspaceID.Int32) == workspaceID && match(link) {
+			links++
+			clicks += link.ClickCount
+		}
+	}
+	return links, clicks
+}
+
+// tagIndex returns the index of the workspace's tag with the ID, or -1. The caller must hold s.mu.
+func (s *Store) tagIndex(workspaceID, tagID int) int {
+	for i, tag := range s.tags {
+		if tag.ID == tagID && tag.WorkspaceID == workspaceID {
+			return i
+		}
+	}
+	return -1
+}
+
+// tagNameIndex returns the index of the workspace's tag with the name, or -1. The caller must hold s.mu.
+func (s *Store) tagNameIndex(workspaceID int, name string) int {
+	for i, tag := range s.tags {
+		if tag.Name == name && tag.WorkspaceID == workspaceID {
+			return i
+		}
+	}
+	return -1
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
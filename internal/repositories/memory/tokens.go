@@ -0,0 +1,85 @@
+package memory
+
+import (
+	"database/sql"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"time"
+)
+
+// CreateRefreshToken implements repositories.RefreshTokenRepository
+func (s *Store) CreateRefreshToken(token models.RefreshToken) (models.RefreshToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.insertRefreshToken(token), nil
+}
+
+// RotateRefreshToken implements repositories.RefreshTokenRepository
+func (s *Store) RotateRefreshToken(tokenHash string, next models.RefreshToken) (models.RefreshToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.refreshTokenIndex(tokenHash)
+	if i < 0 {
+		return models.RefreshToken{}, repositories.ErrRefreshTokenNotFound
+	}
+
+	current := s.tokens[i]
+	if current.RevokedAt.Valid {
+		s.revokeFamily(current.FamilyID)
+		return current, repositories.ErrRefreshTokenReused
+	}
+	if !current.ExpiresAt.After(time.Now()) {
+		return models.RefreshToken{}, repositories.ErrRefreshTokenNotFound
+	}
+
+	next.UserID = current.UserID
+	next.FamilyID = current.FamilyID
+	created := s.insertRefreshToken(next)
+
+	s.tokens[i].RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	s.tokens[i].ReplacedBy = sql.NullInt64{Int64: created.ID, Valid: true}
+
+	return created, nil
+}
+
+// RevokeRefreshTokenFamily implements repositories.RefreshTokenRepository
+func (s *Store) RevokeRefreshTokenFamily(tokenHash string, userID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.refreshTokenIndex(tokenHash)
+	if i < 0 || s.tokens[i].UserID != userID {
+		return repositories.ErrRefreshTokenNotFound
+	}
+
+	s.revokeFamily(s.tokens[i].FamilyID)
+	return nil
+}
+
+func (s *Store) insertRefreshToken(token models.RefreshToken) models.RefreshToken {
+	s.nextTokenID++
+	token.ID = s.nextTokenID
+	token.CreatedAt = time.Now()
+	s.tokens = append(s.tokens, token)
+	return token
+}
+
+func (s *Store) refreshTokenIndex(tokenHash string) int {
+	for i, token := range s.tokens {
+		if token.TokenHash == tokenHash {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Store) revokeFamily(familyID string) {
+	now := time.Now()
+	for i := range s.tokens {
+		if s.tokens[i].FamilyID == familyID && !s.tokens[i].RevokedAt.Valid {
+			s.tokens[i].RevokedAt = sql.NullTime{Time: now, Valid: true}
+		}
+	}
+}
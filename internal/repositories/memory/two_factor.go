@@ -0,0 +1,139 @@
+package memory
+
+import (
+	"database/sql"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"time"
+)
+
+// recoveryCode is a stored two-factor recovery code
+type recoveryCode struct {
+	userID   int
+	codeHash string
+	usedAt   sql.NullTime
+}
+
+// GetTwoFactor implements repositories.TwoFactorRepository
+func (s *Store) GetTwoFactor(userID int) (models.TwoFactor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.twoFactorIndex(userID)
+	if i < 0 {
+		return models.TwoFactor{}, repositories.ErrTwoFactorNotFound
+	}
+
+	enrolment := s.totp[i]
+	for _, code := range s.recovery {
+		if code.userID == userID && !code.usedAt.Valid {
+			enrolment.RecoveryCodesLeft++
+		}
+	}
+	return enrolment, nil
+}
+
+// StartTwoFactorEnrolment implements repositories.TwoFactorRepository
+func (s *Store) StartTwoFactorEnrolment(userID int, secret string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending := models.TwoFactor{UserID: userID, Secret: secret, CreatedAt: time.Now()}
+	i := s.twoFactorIndex(userID)
+	switch {
+	case i < 0:
+		s.totp = append(s.totp, pending)
+	case s.totp[i].Enabled():
+		return repositories.ErrTwoFactorEnabled
+	default:
+		s.totp[i] = pending
+	}
+	return nil
+}
+
+// EnableTwoFactor implements repositories.TwoFactorRepository
+func (s *Store) EnableTwoFactor(userID int, step int64, codeHashes []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.twoFactorIndex(userID)
+	if i < 0 || s.totp[i].Enabled() {
+		return repositories.ErrTwoFactorNotFound
+	}
+	s.totp[i].EnabledAt = sql.NullTime{Time: time.Now(), Valid: true}
+	s.totp[i].LastUsedStep = step
+
+	s.deleteRecoveryCodes(userID)
+	for _, hash := range codeHashes {
+		s.recovery = append(s.recovery, recoveryCode{userID: userID, codeHash: hash})
+	}
+	s.setTwoFactorEnabled(userID, true)
+	return nil
+}
+
+// DisableTwoFactor implements repositories.TwoFactorRepository
+func (s *Store) DisableTwoFactor(userID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if i := s.twoFactorIndex(userID); i >= 0 {
+		s.totp = append(s.totp[:i], s.totp[i+1:]...)
+	}
+	s.deleteRecoveryCodes(userID)
+	s.setTwoFactorEnabled(userID, false)
+	return nil
+}
+
+// UseTOTPStep implements repositories.TwoFactorRepository
+func (s *Store) UseTOTPStep(userID int, step int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.twoFactorIndex(userID)
+	if i < 0 || !s.totp[i].Enabled() || s.totp[i].LastUsedStep >= step {
+		return false, nil
+	}
+	s.totp[i].LastUsedStep = step
+	return true, nil
+}
+
+// UseRecoveryCode implements repositories.TwoFactorRepository
+func (s *Store) UseRecoveryCode(userID int, codeHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, code := range s.recovery {
+		if code.userID == userID && code.codeHash == codeHash && !code.usedAt.Valid {
+			s.recovery[i].usedAt = sql.NullTime{Time: time.Now(), Valid: true}
+			return nil
+		}
+	}
+	return repositories.ErrRecoveryCodeInvalid
+}
+
+func (s *Store) twoFactorIndex(userID int) int {
+	for i, enrolment := range s.totp {
+		if enrolment.UserID == userID {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Store) deleteRecoveryCodes(userID int) {
+	kept := s.recovery[:0]
+	for _, code := range s.recovery {
+		if code.userID != userID {
+			kept = append(kept, code)
+		}
+	}
+	s.recovery = kept
+}
+
+func (s *Store) setTwoFactorEnabled(userID int, enabled bool) {
+	for i := range s.users {
+		if s.users[i].ID == userID {
+			s.users[i].TwoFactorEnabled = enabled
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package memory
+
+import (
+	"database/sql"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"sort"
+	"strings"
+	"time"
+)
+
+// workspace is a stored workspace; personalUserID is set on personal ones
+type workspace struct {
+	models.Workspace
+	personalUserID int
+}
+
+// workspaceMember is a stored membership
+type workspaceMember struct {
+	workspaceID int
+	userID      int
+	role        string
+	createdAt   time.Time
+}
+
+// CreateWorkspace implements repositories.WorkspaceRepository
+func (s *Store) CreateWorkspace(name string, ownerID int) (models.Workspace, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.createWorkspace(name, ownerID, false), nil
+}
+
+// GetWorkspace implements repositories.WorkspaceRepository
+func (s *Store) GetWorkspace(workspaceID, userID int) (models.Workspace, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, ws := range s.workspaces {
+		if ws.ID != workspaceID {
+			continue
+		}
+		role := s.memberRole(workspaceID, userID)
+		if role == "" {
+			break
+		}
+		ws.Role = role
+		return ws.Workspace, nil
+	}
+	return models.Workspace{}, repositories.ErrWorkspaceNotFound
+}
+
+// GetPersonalWorkspace implements repositories.WorkspaceRepository
+func (s *Store) GetPersonalWorkspace(userID int) (models.Workspace, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, ws := range s.workspaces {
+		if ws.personalUserID == userID {
+			ws.Role = models.WorkspaceRoleOwner
+			return ws.Workspace, nil
+		}
+	}
+	return models.Workspace{}, repositories.ErrWorkspaceNotFound
+}
+
+// ListWorkspacesByUser implements repositories.WorkspaceRepository
+func (s *Store) ListWorkspacesByUser(userID int) ([]models.Workspace, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Workspaces are appended in ID order, so only the personal one moves
+	workspaces := []models.Workspace{}
+	for _, ws := range s.workspaces {
+		if role := s.memberRole(ws.ID, userID); role != "" {
+			ws.Role = role
+			workspaces = append(workspaces, ws.Workspace)
+		}
+	}
+	sort.SliceStable(workspaces, func(i, j int) bool { return workspaces[i].Personal && !workspaces[j].Personal })
+	return workspaces, nil
+}
+
+// ListWorkspaceMembers implements repositories.WorkspaceRepository
+func (s *Store) ListWorkspaceMembers(workspaceID int) ([]models.WorkspaceMember, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	members := []models.WorkspaceMember{}
+	for _, member := range s.members {
+		if member.workspaceID != workspaceID {
+			continue
+		}
+		for _, user := range s.users {
+			if user.ID == member.userID {
+				members = append(members, models.WorkspaceMember{
+					UserID:    user.ID,
+					Username:  user.Username,
+					Email:     user.Email,
+					Role:      member.role,
+					CreatedAt: member.createdAt,
+				})
+			}
+		}
+	}
+	return members, nil
+}
+
+// SetWorkspaceMemberRole implements repositories.WorkspaceRepository
+func (s *Store) SetWorkspaceMemberRole(workspaceID, userID int, role string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, err := s.changeableMemberIndex(workspaceID, userID, role)
+	if err != nil {
+		return err
+	}
+	s.members[i].role = role
+	return nil
+}
+
+// RemoveWorkspaceMember implements repositories.WorkspaceRepository
+func (s *Store) RemoveWorkspaceMember(workspaceID, userID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, err := s.changeableMemberIndex(workspaceID, userID, "")
+	if err != nil {
+		return err
+	}
+	s.members = append(s.members[:i], s.members[i+1:]...)
+	return nil
+}
+
+// CreateWorkspaceInvitation implements repositories.WorkspaceRepository
+func (s *Store) CreateWorkspaceInvitation(invitation models.WorkspaceInvitation) (models.WorkspaceInvitation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextInvitationID++
+	invitation.ID = s.nextInvitationID
+	invitation.CreatedAt = time.Now()
+	s.invitations = append(s.invitations, invitation)
+	return invitation, nil
+}
+
+// ListWorkspaceInvitations implements repositories.WorkspaceRepository
+func (s *Store) ListWorkspaceInvitations(workspaceID int) ([]models.WorkspaceInvitation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Invitations are appended in ID order, so walking backwards is newest first
+	now := time.Now()
+	invitations := []models.WorkspaceInvitation{}
+	for i := len(s.invitations) - 1; i >= 0; i-- {
+		invitation := s.invitations[i]
+		if invitation.WorkspaceID == workspaceID && !invitation.AcceptedAt.Valid && invitation.ExpiresAt.After(now) {
+			invitations = append(invitations, invitation)
+		}
+	}
+	return invitations, nil
+}
+
+// RevokeWorkspaceInvitation implements repositories.WorkspaceRepository
+func (s *Store) RevokeWorkspaceInvitation(workspaceID int, invitationID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, invitation := range s.invitations {
+		if invitation.ID == invitationID && invitation.WorkspaceID == workspaceID && !invitation.AcceptedAt.Valid {
+			s.invitations = append(s.invitations[:i], s.invitations[i+1:]...)
+			return nil
+		}
+	}
+	return repositories.ErrInvitationNotFound
+}
+
+// AcceptWorkspaceInvitation implements repositories.WorkspaceRepository
+func (s *Store) AcceptWorkspaceInvitation(tokenHash string, userID int, email string) (models.Workspace, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for i := range s.invitations {
+		invitation := &s.invitations[i]
+		if invitation.TokenHash != tokenHash || invitation.AcceptedAt.Valid || !invitation.ExpiresAt.After(now) ||
+			!strings.EqualFold(invitation.Email, email) {
+			continue
+		}
+
+		invitation.AcceptedAt = sql.NullTime{Time: now, Valid: true}
+		if j := s.memberIndex(invitation.WorkspaceID, userID); j < 0 {
+			s.members = append(s.members, workspaceMember{
+				workspaceID: invitation.WorkspaceID,
+				userID:      userID,
+				role:        invitation.Role,
+				createdAt:   now,
+			})
+		} else if !models.WorkspaceRoleAtLeast(s.members[j].role, invitation.Role) {
+			s.members[j].role = invitation.Role
+		}
+
+		for _, ws := range s.workspaces {
+			if ws.ID == invitation.WorkspaceID {
+				ws.Role = s.memberRole(ws.ID, userID)
+				return ws.Workspace, nil
+			}
+		}
+	}
+	return models.Workspace{}, repositories.ErrInvitationInvalid
+}
+
+// createWorkspace stores a workspace owned by ownerID, personal to them when
+// personal is set. The caller must hold s.mu.
+func (s *Store) createWorkspace(name string, ownerID int, personal bool) models.Workspace {
+	s.nextWorkspaceID++
+	ws := workspace{Workspace: models.Workspace{
+		ID:        s.nextWorkspaceID,
+		Name:      name,
+		Personal:  personal,
+		CreatedAt: time.Now(),
+	}}
+	if personal {
+		ws.personalUserID = ownerID
+	}
+	s.workspaces = append(s.workspaces, ws)
+	s.members = append(s.members, workspaceMember{
+		workspaceID: ws.ID,
+		userID:      ownerID,
+		role:        models.WorkspaceRoleOwner,
+		createdAt:   ws.CreatedAt,
+	})
+
+	ws.Role = models.WorkspaceRoleOwner
+	return ws.Workspace
+}
+
+// memberIndex returns the position of userID's membership of the workspace,
+// or -1. The caller must hold s.mu.
+func (s *Store) memberIndex(workspaceID, userID int) int {
+	for i, member := range s.members {
+		if member.workspaceID == workspaceID && member.userID == userID {
+			return i
+		}
+	}
+	return -1
+}
+
+// memberRole returns userID's role in the workspace, or "" for non-members.
+// The caller must hold s.mu.
+func (s *Store) memberRole(workspaceID, userID int) string {
+	if i := s.memberIndex(workspaceID, userID); i >= 0 {
+		return s.members[i].role
+	}
+	return ""
+}
+
+// changeableMemberIndex is memberIndex for a membership that may be changed
+// to role, or removed when role is empty, without leaving the workspace
+// without an owner. The caller must hold s.mu.
+func (s *Store) changeableMemberIndex(workspaceID, userID int, role string) (int, error) {
+	i := s.memberIndex(workspaceID, userID)
+	if i < 0 {
+		return -1, repositories.ErrWorkspaceMemberNotFound
+	}
+	if s.members[i].role != models.WorkspaceRoleOwner || role == models.WorkspaceRoleOwner {
+		return i, nil
+	}
+
+	for _, member := range s.members {
+		if member.workspaceID == workspaceID && member.userID != userID && member.role == models.WorkspaceRoleOwner {
+			return i, nil
+		}
+	}
+	return -1, repositories.ErrLastWorkspaceOwner
+}
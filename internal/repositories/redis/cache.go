@@ -1 +1,354 @@
 package redis
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"link-guardian/internal/models"
+	"log"
+	"strconv"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+)
+
+var (
+	linkCacheTTL         = 5 * time.Minute
+	linkCacheNegativeTTL = 30 * time.Second
+	linkLoads            singleflight.Group
+	domainLoads          singleflight.Group
+)
+
+// cachedLink is the resolved link data stored in the slug cache. Only the
+// fields needed to decide a redirect are kept; click counts are never cached.
+type cachedLink struct {
+	Found             bool       `json:"found"`
+	ID                int        `json:"id,omitempty"`
+	Slug              string     `json:"slug,omitempty"`
+	TargetURL         string     `json:"target_url,omitempty"`
+	ExpiresAt         *time.Time `json:"expires_at,omitempty"`
+	ClickLimit        *int       `json:"click_limit,omitempty"`
+	PasswordProtected bool       `json:"password_protected,omitempty"`
+	UserID            *int       `json:"user_id,omitempty"`
+	TakenDownAt       *time.Time `json:"taken_down_at,omitempty"`
+}
+
+// LinkLoader loads a link from the source of truth; found is false for unknown slugs
+type LinkLoader func(domainID int, slug string) (link models.Link, found bool, err error)
+
+// ConfigureLinkCache sets the TTL of cached links and domains and of cached
+// unknown slugs and hostnames
+func ConfigureLinkCache(ttl, negativeTTL time.Duration) {
+	linkCacheTTL = ttl
+	linkCacheNegativeTTL = negativeTTL
+}
+
+// linkCacheKey names the entry for slug on the domain; links on the default
+// domain, 0, keep the key they had before custom domains existed
+func linkCacheKey(domainID int, slug string) string {
+	if domainID == 0 {
+		return "link:slug:" + slug
+	}
+	return "link:domain:" + strconv.Itoa(domainID) + ":" + slug
+}
+
+// generationKey names the counter InvalidateLink and InvalidateDomain bump
+// for the entry at key, so loads that started before an invalidation do not
+// store their stale result after it
+func generationKey(key string) string {
+	return key + ":gen"
+}
+
+// generationTTL keeps generation counters well beyond any load
+const generationTTL = time.Hour
+
+// setIfGenerationScript stores an entry only if the generation counter still
+// has the value read before the entry was loaded
+var setIfGenerationScript = goredis.NewScript(`
+local generation = redis.call("GET", KEYS[2]) or ""
+if generation ~= ARGV[3] then
+	return 0
+end
+redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+return 1
+`)
+
+// ResolveLink returns the link for slug on the domain, 0 being the default
+// one, reading through the cache. Concurrent misses for the same slug share a
+// single call to load. Cache failures are logged and fall back to load so
+// Redis outages never break redirects.
+//
+// The returned link carries no click count and, for protected links, a
+// placeholder PasswordHash that only signals protection.
+func ResolveLink(ctx context.Context, domainID int, slug string, load LinkLoader) (models.Link, bool, error) {
+	if client == nil {
+		return load(domainID, slug)
+	}
+
+	key := linkCacheKey(domainID, slug)
+	if entry, ok := getCachedLink(ctx, key); ok {
+		link, found := entry.toLink()
+		return link, found, nil
+	}
+
+	value, err, _ := linkLoads.Do(key, func() (interface{}, error) {
+		generation, cacheable := readGeneration(ctx, key)
+		link, found, err := load(domainID, slug)
+		if err != nil {
+			return nil, err
+		}
+
+		entry := newCachedLink(link, found)
+		if cacheable {
+			setCachedLink(context.WithoutCancel(ctx), key, generation, entry)
+		}
+		return entry, nil
+	})
+	if err != nil {
+		return models.Link{}, false, err
+	}
+
+	link, found := value.(cachedLink).toLink()
+	return link, found, nil
+}
+
+// InvalidateLink removes slug on the domain from the cache after the link was
+// created, edited, deleted, taken down or restored. Loads already in flight
+// are not cached, and later callers do not wait for them.
+func InvalidateLink(ctx context.Context, domainID int, slug string) error {
+	if client == nil {
+		return nil
+	}
+
+	key := linkCacheKey(domainID, slug)
+	linkLoads.Forget(key)
+	if err := invalidate(ctx, key); err != nil {
+		return fmt.Errorf("failed to invalidate cached link: %w", err)
+	}
+
+	return nil
+}
+
+// readGeneration returns the generation of the entry at key before it is
+// loaded, and false when it cannot be read and the load must not be cached
+func readGeneration(ctx context.Context, key string) (string, bool) {
+	generation, err := client.Get(ctx, generationKey(key)).Result()
+	if err != nil && !errors.Is(err, goredis.Nil) {
+		log.Printf("Cache read failed for %s: %v", generationKey(key), err)
+		return "", false
+	}
+	return generation, true
+}
+
+// setIfGeneration stores data at key for ttl unless the entry was
+// invalidated since its generation was read
+func setIfGeneration(ctx context.Context, key, generation string, data []byte, ttl time.Duration) error {
+	keys := []string{key, generationKey(key)}
+	return setIfGenerationScript.Run(ctx, client, keys, data, ttl.Milliseconds(), generation).Err()
+}
+
+// invalidate deletes the entry at key and bumps its generation so loads in
+// flight do not store theirs
+func invalidate(ctx context.Context, key string) error {
+	_, err := client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.Incr(ctx, generationKey(key))
+		pipe.Expire(ctx, generationKey(key), generationTTL)
+		pipe.Del(ctx, key)
+		return nil
+	})
+	return err
+}
+
+func getCachedLink(ctx context.Context, key string) (cachedLink, bool) {
+	data, err := client.Get(ctx, key).Bytes()
+	if err != nil {
+		if !errors.Is(err, goredis.Nil) {
+			log.Printf("Link cache read failed for %s: %v", key, err)
+		}
+		return cachedLink{}, false
+	}
+
+	var entry cachedLink
+	if err := json.Unmarshal(data, &entry); err != nil {
+		log.Printf("Discarding corrupt link cache entry %s: %v", key, err)
+		return cachedLink{}, false
+	}
+
+	return entry, true
+}
+
+// setCachedLink stores entry unless the link was invalidated since its
+// generation was read
+func setCachedLink(ctx context.Context, key, generation string, entry cachedLink) {
+	ttl := entry.ttl(time.Now())
+	if ttl < time.Millisecond {
+		return
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		log.Printf("Failed to encode link cache entry %s: %v", key, err)
+		return
+	}
+
+	if err := setIfGeneration(ctx, key, generation, data, ttl); err != nil {
+		log.Printf("Link cache write failed for %s: %v", key, err)
+	}
+}
+
+func newCachedLink(link models.Link, found bool) cachedLink {
+	if !found {
+		return cachedLink{Found: false}
+	}
+
+	response := link.ToResponse()
+	return cachedLink{
+		Found:             true,
+		ID:                link.ID,
+		Slug:              link.Slug,
+		TargetURL:         link.TargetURL,
+		ExpiresAt:         response.ExpiresAt,
+		ClickLimit:        response.ClickLimit,
+		PasswordProtected: link.PasswordHash.Valid,
+		UserID:            response.UserID,
+		TakenDownAt:       response.TakenDownAt,
+	}
+}
+
+// ttl bounds the cache lifetime of an entry by the link's expiry. Links that
+// have already expired are kept only as briefly as unknown slugs.
+func (e cachedLink) ttl(now time.Time) time.Duration {
+	if !e.Found {
+		return linkCacheNegativeTTL
+	}
+
+	ttl := linkCacheTTL
+	if e.ExpiresAt != nil {
+		untilExpiry := e.ExpiresAt.Sub(now)
+		if untilExpiry <= 0 {
+			return linkCacheNegativeTTL
+		}
+		if untilExpiry < ttl {
+			ttl = untilExpiry
+		}
+	}
+	return ttl
+}
+
+func (e cachedLink) toLink() (models.Link, bool) {
+	if !e.Found {
+		return models.Link{}, false
+	}
+
+	link := models.Link{
+		ID:        e.ID,
+		Slug:      e.Slug,
+		TargetURL: e.TargetURL,
+	}
+	if e.ExpiresAt != nil {
+		link.ExpiresAt = sql.NullTime{Time: *e.ExpiresAt, Valid: true}
+	}
+	if e.ClickLimit != nil {
+		link.ClickLimit = sql.NullInt32{Int32: int32(*e.ClickLimit), Valid: true}
+	}
+	if e.UserID != nil {
+		link.UserID = sql.NullInt32{Int32: int32(*e.UserID), Valid: true}
+	}
+	if e.PasswordProtected {
+		link.PasswordHash = sql.NullString{String: "protected", Valid: true}
+	}
+	if e.TakenDownAt != nil {
+		link.TakenDownAt = sql.NullTime{Time: *e.TakenDownAt, Valid: true}
+	}
+	return link, true
+}
+
+// cachedDomain is the verified custom domain stored in the hostname cache
+type cachedDomain struct {
+	Found       bool   `json:"found"`
+	ID          int    `json:"id,omitempty"`
+	WorkspaceID int    `json:"workspace_id,omitempty"`
+	Hostname    string `json:"hostname,omitempty"`
+}
+
+// DomainLoader loads a verified domain from the source of truth; found is false for unknown hostnames
+type DomainLoader func(hostname string) (domain models.Domain, found bool, err error)
+
+func domainCacheKey(hostname string) string {
+	return "domain:host:" + hostname
+}
+
+// ResolveDomain returns the verified custom domain for hostname, reading
+// through the cache like ResolveLink. The returned domain carries no
+// verification token.
+func ResolveDomain(ctx context.Context, hostname string, load DomainLoader) (models.Domain, bool, error) {
+	if client == nil {
+		return load(hostname)
+	}
+
+	key := domainCacheKey(hostname)
+	if data, err := client.Get(ctx, key).Bytes(); err == nil {
+		var entry cachedDomain
+		if err := json.Unmarshal(data, &entry); err == nil {
+			domain, found := entry.toDomain()
+			return domain, found, nil
+		}
+		log.Printf("Discarding corrupt domain cache entry for %s", hostname)
+	} else if !errors.Is(err, goredis.Nil) {
+		log.Printf("Domain cache read failed for %s: %v", hostname, err)
+	}
+
+	value, err, _ := domainLoads.Do(key, func() (interface{}, error) {
+		generation, cacheable := readGeneration(ctx, key)
+		domain, found, err := load(hostname)
+		if err != nil {
+			return nil, err
+		}
+
+		entry := cachedDomain{Found: found}
+		ttl := linkCacheNegativeTTL
+		if found {
+			entry = cachedDomain{Found: true, ID: domain.ID, WorkspaceID: domain.WorkspaceID, Hostname: domain.Hostname}
+			ttl = linkCacheTTL
+		}
+		if data, err := json.Marshal(entry); err == nil && cacheable && ttl > 0 {
+			if err := setIfGeneration(context.WithoutCancel(ctx), key, generation, data, ttl); err != nil {
+				log.Printf("Domain cache write failed for %s: %v", hostname, err)
+			}
+		}
+		return entry, nil
+	})
+	if err != nil {
+		return models.Domain{}, false, err
+	}
+
+	domain, found := value.(cachedDomain).toDomain()
+	return domain, found, nil
+}
+
+// InvalidateDomain removes hostname from the cache after a domain was
+// verified or deleted. Like InvalidateLink, loads already in flight are not
+// cached.
+func InvalidateDomain(ctx context.Context, hostname string) error {
+	if client == nil {
+		return nil
+	}
+
+	key := domainCacheKey(hostname)
+	domainLoads.Forget(key)
+	if err := invalidate(ctx, key); err != nil {
+		return fmt.Errorf("failed to invalidate cached domain: %w", err)
+	}
+
+	return nil
+}
+
+func (e cachedDomain) toDomain() (models.Domain, bool) {
+	if !e.Found {
+		return models.Domain{}, false
+	}
+	return models.Domain{ID: e.ID, WorkspaceID: e.WorkspaceID, Hostname: e.Hostname}, true
+}
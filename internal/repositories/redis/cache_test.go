@@ -0,0 +1,102 @@
+package redis
+
+import (
+	"context"
+	"link-guardian/internal/models"
+	"link-guardian/internal/testutil/redistest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestInvalidateLinkDuringLoad(t *testing.T) {
+	InitRedis(redistest.Open(t))
+	t.Cleanup(func() { InitRedis(nil) })
+	ctx := context.Background()
+	slug := "race" + strconv.FormatInt(time.Now().UnixNano(), 36)
+	t.Cleanup(func() { client.Del(ctx, linkCacheKey(0, slug), generationKey(linkCacheKey(0, slug))) })
+
+	// The first load reads the link, then the link is updated and
+	// invalidated before the load stores its result
+	loading, updated := make(chan struct{}), make(chan struct{})
+	staleLoad := func(domainID int, slug string) (models.Link, bool, error) {
+		close(loading)
+		<-updated
+		return models.Link{ID: 1, Slug: slug, TargetURL: "https://old.example"}, true, nil
+	}
+	done := make(chan models.Link)
+	go func() {
+		link, _, err := ResolveLink(ctx, 0, slug, staleLoad)
+		if err != nil {
+			t.Error(err)
+		}
+		done <- link
+	}()
+
+	<-loading
+	if err := InvalidateLink(ctx, 0, slug); err != nil {
+		t.Fatal(err)
+	}
+	close(updated)
+	if link := <-done; link.TargetURL != "https://old.example" {
+		t.Fatalf("in-flight load returned %q", link.TargetURL)
+	}
+
+	freshLoad := func(domainID int, slug string) (models.Link, bool, error) {
+		return models.Link{ID: 1, Slug: slug, TargetURL: "https://new.example"}, true, nil
+	}
+	link, found, err := ResolveLink(ctx, 0, slug, freshLoad)
+	if err != nil || !found {
+		t.Fatalf("ResolveLink = %v, %v", found, err)
+	}
+	if link.TargetURL != "https://new.example" {
+		t.Errorf("ResolveLink after the update returned %q from a stale cache entry", link.TargetURL)
+	}
+
+	// Loads after the invalidation are cached again
+	link, _, err = ResolveLink(ctx, 0, slug, func(int, string) (models.Link, bool, error) {
+		t.Error("loaded a cached link")
+		return models.Link{}, false, nil
+	})
+	if err != nil || link.TargetURL != "https://new.example" {
+		t.Errorf("cached link = %q, %v, want the fresh entry", link.TargetURL, err)
+	}
+}
+
+func TestInvalidateDomainDuringLoad(t *testing.T) {
+	InitRedis(redistest.Open(t))
+	t.Cleanup(func() { InitRedis(nil) })
+	ctx := context.Background()
+	hostname := "race" + strconv.FormatInt(time.Now().UnixNano(), 36) + ".example"
+	t.Cleanup(func() { client.Del(ctx, domainCacheKey(hostname), generationKey(domainCacheKey(hostname))) })
+
+	// The first load finds the domain, which is deleted before the load
+	// stores its result
+	loading, deleted := make(chan struct{}), make(chan struct{})
+	staleLoad := func(hostname string) (models.Domain, bool, error) {
+		close(loading)
+		<-deleted
+		return models.Domain{ID: 1, WorkspaceID: 1, Hostname: hostname}, true, nil
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, _, err := ResolveDomain(ctx, hostname, staleLoad); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	<-loading
+	if err := InvalidateDomain(ctx, hostname); err != nil {
+		t.Fatal(err)
+	}
+	close(deleted)
+	<-done
+
+	_, found, err := ResolveDomain(ctx, hostname, func(string) (models.Domain, bool, error) {
+		return models.Domain{}, false, nil
+	})
+	if err != nil || found {
+		t.Errorf("ResolveDomain after the delete = %v, %v; want the domain gone", found, err)
+	}
+}
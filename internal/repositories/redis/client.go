@@ -0,0 +1,12 @@
+package redis
+
+import (
+	goredis "github.com/redis/go-redis/v9"
+)
+
+var client *goredis.Client
+
+// InitRedis sets the Redis client used by this package
+func InitRedis(redisClient *goredis.Client) {
+	client = redisClient
+}
@@ -0,0 +1,99 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+func loginFailuresKey(subject string) string {
+	return "login:fail:" + subject
+}
+
+func loginBlockKey(subject string) string {
+	return "login:block:" + subject
+}
+
+// IncrementLoginFailures records a failed login for subject, such as an email
+// address or IP, and returns the number of failures within the current window
+func IncrementLoginFailures(ctx context.Context, subject string, window time.Duration) (int64, error) {
+	count, err := incrementInWindow.Run(ctx, client, []string{loginFailuresKey(subject)}, window.Milliseconds()).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("failed to record login failure: %w", err)
+	}
+
+	return count, nil
+}
+
+// ReleaseLoginFailure takes back one failure of subject counted by
+// IncrementLoginFailures
+func ReleaseLoginFailure(ctx context.Context, subject string) error {
+	if err := decrementInWindow.Run(ctx, client, []string{loginFailuresKey(subject)}).Err(); err != nil {
+		return fmt.Errorf("failed to release login failure: %w", err)
+	}
+
+	return nil
+}
+
+// BlockLogin blocks logins for subject for the given duration
+func BlockLogin(ctx context.Context, subject string, duration time.Duration) error {
+	if err := client.Set(ctx, loginBlockKey(subject), 1, duration).Err(); err != nil {
+		return fmt.Errorf("failed to block login: %w", err)
+	}
+
+	return nil
+}
+
+// TryBlockLogin blocks logins for subject for the given duration unless they
+// are already blocked, and reports whether it did
+func TryBlockLogin(ctx context.Context, subject string, duration time.Duration) (bool, error) {
+	blocked, err := client.SetNX(ctx, loginBlockKey(subject), 1, duration).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to block login: %w", err)
+	}
+
+	return blocked, nil
+}
+
+// UnblockLogin lifts a block of logins for subject
+func UnblockLogin(ctx context.Context, subject string) error {
+	if err := client.Del(ctx, loginBlockKey(subject)).Err(); err != nil {
+		return fmt.Errorf("failed to unblock login: %w", err)
+	}
+
+	return nil
+}
+
+// LoginBlockedFor returns how long logins for subject remain blocked, or 0
+func LoginBlockedFor(ctx context.Context, subject string) (time.Duration, error) {
+	ttl, err := client.PTTL(ctx, loginBlockKey(subject)).Result()
+	if err != nil {
+		if errors.Is(err, goredis.Nil) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to check login block: %w", err)
+	}
+
+	// PTTL reports -2 for missing keys and -1 for keys without expiry
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+// ClearLoginFailures removes the failure count and block of each subject
+func ClearLoginFailures(ctx context.Context, subjects ...string) error {
+	keys := make([]string, 0, 2*len(subjects))
+	for _, subject := range subjects {
+		keys = append(keys, loginFailuresKey(subject), loginBlockKey(subject))
+	}
+
+	if err := client.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("failed to clear login failures: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+func emailThrottleKey(purpose, email string) string {
+	return "mail:throttle:" + purpose + ":" + strings.ToLower(email)
+}
+
+// AllowEmail reports whether an email for purpose, such as a password reset,
+// may be sent to email, allowing at most one per interval
+func AllowEmail(ctx context.Context, purpose, email string, interval time.Duration) (bool, error) {
+	if client == nil || interval <= 0 {
+		return true, nil
+	}
+
+	allowed, err := client.SetNX(ctx, emailThrottleKey(purpose, email), 1, interval).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to throttle %s email: %w", purpose, err)
+	}
+
+	return allowed, nil
+}
@@ -1 +1,54 @@
 package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// incrementInWindow increments a counter and starts its expiry with the
+// first increment, in one step so the counter cannot be left without one
+var incrementInWindow = goredis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if redis.call("PTTL", KEYS[1]) < 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return count
+`)
+
+// decrementInWindow takes back one increment of a counter that has not
+// expired, keeping its expiry
+var decrementInWindow = goredis.NewScript(`
+local count = tonumber(redis.call("GET", KEYS[1]))
+if count and count > 0 then
+	return redis.call("DECR", KEYS[1])
+end
+return 0
+`)
+
+func unlockFailuresKey(slug, ip string) string {
+	return fmt.Sprintf("unlock:fail:%s:%s", slug, ip)
+}
+
+// IncrementUnlockFailures counts a password attempt for a link from an IP and
+// returns the number of attempts since the last successful unlock within the
+// current window
+func IncrementUnlockFailures(ctx context.Context, slug, ip string, window time.Duration) (int64, error) {
+	count, err := incrementInWindow.Run(ctx, client, []string{unlockFailuresKey(slug, ip)}, window.Milliseconds()).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("failed to record unlock failure: %w", err)
+	}
+
+	return count, nil
+}
+
+// ResetUnlockFailures clears failed password attempts for a link from an IP
+func ResetUnlockFailures(ctx context.Context, slug, ip string) error {
+	if err := client.Del(ctx, unlockFailuresKey(slug, ip)).Err(); err != nil {
+		return fmt.Errorf("failed to reset unlock failures: %w", err)
+	}
+
+	return nil
+}
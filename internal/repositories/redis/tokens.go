@@ -0,0 +1,84 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+func deniedAccessTokenKey(tokenID string) string {
+	return "auth:denied:" + tokenID
+}
+
+func revokedSessionsKey(userID int) string {
+	return "auth:revoked-before:" + strconv.Itoa(userID)
+}
+
+// secondsCutoffLimit is above any revocation time stored in seconds and below
+// any stored in milliseconds
+const secondsCutoffLimit = 100_000_000_000
+
+// DenyAccessToken revokes the access token with ID tokenID. The entry only
+// needs to outlive the token, so ttl should be its remaining lifetime.
+func DenyAccessToken(ctx context.Context, tokenID string, ttl time.Duration) error {
+	if client == nil || ttl <= 0 {
+		return nil
+	}
+
+	if err := client.Set(ctx, deniedAccessTokenKey(tokenID), 1, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to deny access token: %w", err)
+	}
+
+	return nil
+}
+
+// DenyUserAccessTokens revokes every access token of the user issued up to
+// and including the millisecond of at. The entry only needs to outlive those
+// tokens, so ttl should be the access token lifetime.
+func DenyUserAccessTokens(ctx context.Context, userID int, at time.Time, ttl time.Duration) error {
+	if client == nil || ttl <= 0 {
+		return nil
+	}
+
+	if err := client.Set(ctx, revokedSessionsKey(userID), at.UnixMilli(), ttl).Err(); err != nil {
+		return fmt.Errorf("failed to deny user access tokens: %w", err)
+	}
+
+	return nil
+}
+
+// IsAccessTokenDenied reports whether the access token with ID tokenID, issued
+// to userID at issuedAt, was revoked on its own or together with every token
+// of the user
+func IsAccessTokenDenied(ctx context.Context, tokenID string, userID int, issuedAt time.Time) (bool, error) {
+	if client == nil {
+		return false, nil
+	}
+
+	values, err := client.MGet(ctx, deniedAccessTokenKey(tokenID), revokedSessionsKey(userID)).Result()
+	if err != nil && err != goredis.Nil {
+		return false, fmt.Errorf("failed to check access token denylist: %w", err)
+	}
+
+	if values[0] != nil {
+		return true, nil
+	}
+	if revokedAt, ok := values[1].(string); ok {
+		cutoff, err := strconv.ParseInt(revokedAt, 10, 64)
+		if err != nil {
+			return false, fmt.Errorf("invalid session revocation time for user %d: %w", userID, err)
+		}
+		// Revocations stored before cutoffs had milliseconds are in seconds
+		// and cover the whole second
+		if cutoff < secondsCutoffLimit {
+			cutoff = cutoff*1000 + 999
+		}
+		// Tokens issued in the same millisecond as the revocation are denied too
+		return issuedAt.UnixMilli() <= cutoff, nil
+	}
+
+	return false, nil
+}
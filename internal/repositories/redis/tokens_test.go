@@ -0,0 +1,38 @@
+package redis
+
+import (
+	"context"
+	"link-guardian/internal/testutil/redistest"
+	"testing"
+	"time"
+)
+
+func TestDenyUserAccessTokensCutoff(t *testing.T) {
+	InitRedis(redistest.Open(t))
+	t.Cleanup(func() { InitRedis(nil) })
+	ctx := context.Background()
+	userID := int(time.Now().UnixNano() % 1_000_000_000)
+	t.Cleanup(func() { client.Del(ctx, revokedSessionsKey(userID)) })
+
+	revokedAt := time.Now()
+	if err := DenyUserAccessTokens(ctx, userID, revokedAt, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	for issuedAt, want := range map[time.Time]bool{
+		revokedAt.Add(-time.Second):          true,
+		revokedAt:                            true,
+		revokedAt.Truncate(time.Millisecond): true,
+		revokedAt.Add(time.Millisecond):      false,
+		revokedAt.Add(time.Second):           false,
+		revokedAt.Truncate(time.Second):      true,
+	} {
+		denied, err := IsAccessTokenDenied(ctx, "token", userID, issuedAt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if denied != want {
+			t.Errorf("token issued %v after the revocation: denied = %v, want %v", issuedAt.Sub(revokedAt), denied, want)
+		}
+	}
+}
@@ -0,0 +1,341 @@
+// Package repositories defines the storage interfaces used by the HTTP
+// handlers. The db package implements them on PostgreSQL and the memory
+// package in process, for tests and local development.
+package repositories
+
+import (
+	"errors"
+	"link-guardian/internal/models"
+	"time"
+)
+
+var (
+	// ErrLinkNotFound is returned when no active link matches the slug
+	ErrLinkNotFound = errors.New("link not found")
+	// ErrLinkForbidden is returned when the user's role in the link's
+	// workspace does not allow the operation, or they are not a member
+	ErrLinkForbidden = errors.New("unauthorized: link belongs to a workspace the user may not access")
+	// ErrSlugTaken is returned when a slug is already used by another link
+	ErrSlugTaken = errors.New("slug already taken")
+	// ErrRevisionNotFound is returned when a revision does not exist for the link
+	ErrRevisionNotFound = errors.New("revision not found")
+	// ErrUserNotFound is returned when no user matches the lookup
+	ErrUserNotFound = errors.New("user not found")
+	// ErrEmailTaken is returned when an email address is already registered
+	ErrEmailTaken = errors.New("email already in use")
+	// ErrUsernameTaken is returned when a username is already registered
+	ErrUsernameTaken = errors.New("username already in use")
+	// ErrRefreshTokenNotFound is returned when a refresh token is unknown or expired
+	ErrRefreshTokenNotFound = errors.New("refresh token not found")
+	// ErrRefreshTokenReused is returned when an already rotated or revoked
+	// refresh token is presented again
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+	// ErrAPIKeyNotFound is returned when no active API key matches
+	ErrAPIKeyNotFound = errors.New("API key not found")
+	// ErrPasswordResetTokenInvalid is returned when a password reset token is
+	// unknown, expired or already used
+	ErrPasswordResetTokenInvalid = errors.New("password reset token invalid")
+	// ErrTwoFactorNotFound is returned when the user has no TOTP enrolment,
+	// or no pending one where a pending one is required
+	ErrTwoFactorNotFound = errors.New("two-factor enrolment not found")
+	// ErrTwoFactorEnabled is returned when two-factor authentication is
+	// already enabled for the user
+	ErrTwoFactorEnabled = errors.New("two-factor authentication already enabled")
+	// ErrRecoveryCodeInvalid is returned when a recovery code is unknown or already used
+	ErrRecoveryCodeInvalid = errors.New("recovery code invalid")
+	// ErrWorkspaceNotFound is returned when a workspace does not exist or the
+	// user is not a member of it
+	ErrWorkspaceNotFound = errors.New("workspace not found")
+	// ErrWorkspaceMemberNotFound is returned when the user is not a member of the workspace
+	ErrWorkspaceMemberNotFound = errors.New("workspace member not found")
+	// ErrLastWorkspaceOwner is returned when a change would leave a workspace without an owner
+	ErrLastWorkspaceOwner = errors.New("workspace must keep an owner")
+	// ErrInvitationNotFound is returned when no pending invitation matches
+	ErrInvitationNotFound = errors.New("invitation not found")
+	// ErrInvitationInvalid is returned when an invitation token is unknown,
+	// expired, already accepted or addressed to a different email
+	ErrInvitationInvalid = errors.New("invitation invalid")
+	// ErrDomainNotFound is returned when no domain matches the lookup
+	ErrDomainNotFound = errors.New("domain not found")
+	// ErrDomainTaken is returned when the workspace already registered the
+	// hostname or another workspace has verified it
+	ErrDomainTaken = errors.New("domain already registered")
+	// ErrDomainInUse is returned when deleting a domain that links still use
+	ErrDomainInUse = errors.New("domain in use")
+	// ErrTagNotFound is returned when no tag of the workspace matches
+	ErrTagNotFound = errors.New("tag not found")
+	// ErrTagTaken is returned when renaming a tag to the name of another tag of the workspace
+	ErrTagTaken = errors.New("tag name already in use")
+)
+
+// LinkChange computes the new editable fields of a link from its current ones
+type LinkChange func(current models.LinkState) models.LinkState
+
+// LinkRepository stores shortened links and their change history. Slugs are
+// unique per domain; domainID names a custom domain, or is 0 for the default
+// short domain.
+type LinkRepository interface {
+	// CreateLink stores a new link with its tags and folder and returns it with
+	// its ID set. It returns ErrSlugTaken when any link on its domain,
+	// including a deleted one, uses the slug.
+	CreateLink(link models.Link) (models.Link, error)
+	// FilterAvailableSlugs returns the candidates not used by any link on the domain, in order
+	FilterAvailableSlugs(domainID int, candidates []string) ([]string, error)
+	// GetLinkBySlug returns the active link with the slug or ErrLinkNotFound
+	GetLinkBySlug(domainID int, slug string) (models.Link, error)
+	// GetMemberLinkBySlug is GetLinkBySlug that also returns ErrLinkForbidden
+	// unless userID is a member of the link's workspace with at least role
+	GetMemberLinkBySlug(domainID int, slug string, userID int, role string) (models.Link, error)
+	// ListLinksByUser returns the active links created by the user, newest first
+	ListLinksByUser(userID int) ([]models.Link, error)
+	// CountLinksByUser returns the number of active links created by the user
+	CountLinksByUser(userID int) (int, error)
+	// ListLinksByWorkspace returns up to page.Limit of the workspace's links
+	// matching filter, in page.Sort order after page.After, and the total
+	// number of links matching filter
+	ListLinksByWorkspace(workspaceID int, filter models.LinkFilter, page models.LinkPage) ([]models.Link, int, error)
+	// ConsumeClick atomically checks a link's expiry, click limit and password
+	// protection and counts the click when the link may be followed.
+	// Password-protected links are only counted when unlocked is true.
+	ConsumeClick(domainID int, slug string, unlocked bool) (models.Link, models.ClickOutcome, error)
+	// IncrementClickCount counts a click on an unrestricted link by ID. It
+	// reports false when the link is no longer active or has gained a click
+	// limit or password, in which case callers must use ConsumeClick.
+	IncrementClickCount(linkID int) (bool, error)
+	// UpdateLink applies change to a link in a workspace where userID is at
+	// least an editor and records a revision, and sets its tags and folder
+	// from organization in the same step. When change leaves the link
+	// untouched the revision is nil. It returns ErrLinkForbidden for other users.
+	UpdateLink(domainID int, slug string, userID int, action string, revertedRevisionID *int64,
+		change LinkChange, organization models.LinkOrganization) (models.Link, *models.LinkRevision, error)
+	// SoftDeleteLink marks a link in a workspace where userID is at least an
+	// editor as deleted. It returns ErrLinkForbidden for other users.
+	SoftDeleteLink(domainID int, slug string, userID int) error
+	// GetLinkRevisions returns the change history of a link, newest first
+	GetLinkRevisions(linkID int) ([]models.LinkRevision, error)
+	// GetLinkRevision returns one revision of the link or ErrRevisionNotFound
+	GetLinkRevision(linkID int, revisionID int64) (models.LinkRevision, error)
+}
+
+// UserRepository stores user accounts
+type UserRepository interface {
+	// CreateUser stores a new user together with their personal workspace and
+	// returns its ID. It returns ErrEmailTaken or ErrUsernameTaken when either
+	// is already registered.
+	CreateUser(username, email, passwordHash string) (int, error)
+	EmailExists(email string) (bool, error)
+	UsernameExists(username string) (bool, error)
+	// GetUserByEmail matches the email case-insensitively or returns ErrUserNotFound
+	GetUserByEmail(email string) (*models.User, error)
+	// GetUserByID returns the user or ErrUserNotFound
+	GetUserByID(userID int) (*models.User, error)
+	// MarkEmailVerified records that the user verified their email address. It
+	// keeps the original time when already verified and returns ErrUserNotFound
+	// for unknown users.
+	MarkEmailVerified(userID int) error
+}
+
+// AccessLogRepository stores link access events and aggregates them
+type AccessLogRepository interface {
+	// InsertAccessLogs writes a batch of entries. Entries whose link no longer
+	// exists are skipped.
+	InsertAccessLogs(logs []models.AccessLog) error
+	// GetAccessLogsByWorkspace returns the newest entries for links in the
+	// workspace, optionally narrowed to the link with ID linkID
+	GetAccessLogsByWorkspace(workspaceID int, linkID string, limit int) ([]models.AccessLog, error)
+	// GetAccessLogsByLink returns the newest entries for a link
+	GetAccessLogsByLink(linkID int, limit int) ([]models.AccessLog, error)
+	// GetLinkClickStats aggregates the clicks on a link between from
+	// (inclusive) and to (exclusive). The series is bucketed by hour, day or
+	// week in the named time zone, and each breakdown holds at most
+	// breakdownLimit values.
+	GetLinkClickStats(linkID int, from, to time.Time, bucket, timezone string, breakdownLimit int) (models.LinkStats, error)
+}
+
+// RefreshTokenRepository stores hashed refresh tokens
+type RefreshTokenRepository interface {
+	// CreateRefreshToken stores a new token and returns it with its ID set
+	CreateRefreshToken(token models.RefreshToken) (models.RefreshToken, error)
+	// RotateRefreshToken revokes the active token with hash tokenHash and
+	// stores next in its family and for its user. It returns
+	// ErrRefreshTokenNotFound for unknown or expired tokens. When the token was
+	// already revoked the whole family is revoked and ErrRefreshTokenReused is
+	// returned together with the presented token.
+	RotateRefreshToken(tokenHash string, next models.RefreshToken) (models.RefreshToken, error)
+	// RevokeRefreshTokenFamily revokes every token in the family of the token
+	// with hash tokenHash, provided it belongs to userID. It returns
+	// ErrRefreshTokenNotFound when no such token exists.
+	RevokeRefreshTokenFamily(tokenHash string, userID int) error
+}
+
+// APIKeyRepository stores hashed personal API keys
+type APIKeyRepository interface {
+	// CreateAPIKey stores a new key and returns it with its ID set
+	CreateAPIKey(key models.APIKey) (models.APIKey, error)
+	// ListAPIKeysByUser returns the user's active keys, newest first
+	ListAPIKeysByUser(userID int) ([]models.APIKey, error)
+	// GetAPIKeyByHash returns the active key with hash keyHash or ErrAPIKeyNotFound
+	GetAPIKeyByHash(keyHash string) (models.APIKey, error)
+	// TouchAPIKey records that the key was used at usedAt
+	TouchAPIKey(keyID int64, usedAt time.Time) error
+	// RevokeAPIKey revokes an active key owned by userID or returns ErrAPIKeyNotFound
+	RevokeAPIKey(keyID int64, userID int) error
+}
+
+// PasswordResetRepository stores hashed password reset tokens
+type PasswordResetRepository interface {
+	// CreatePasswordResetToken stores a new token and returns it with its ID set
+	CreatePasswordResetToken(token models.PasswordResetToken) (models.PasswordResetToken, error)
+	// ResetPassword redeems the unused, unexpired token with hash tokenHash
+	// and sets its user's password hash in one step. Every other reset token
+	// and every refresh token of the user is invalidated along with it, and
+	// the email address counts as verified since the token was emailed to it.
+	// It returns ErrPasswordResetTokenInvalid when no such token exists.
+	ResetPassword(tokenHash, passwordHash string) (userID int, err error)
+}
+
+// TwoFactorRepository stores TOTP secrets and hashed recovery codes. While
+// two-factor authentication is enabled the user's TwoFactorEnabled is set.
+type TwoFactorRepository interface {
+	// GetTwoFactor returns the user's enrolment, pending or enabled, or
+	// ErrTwoFactorNotFound
+	GetTwoFactor(userID int) (models.TwoFactor, error)
+	// StartTwoFactorEnrolment stores secret as the user's pending TOTP secret,
+	// replacing any earlier pending one. It returns ErrTwoFactorEnabled when
+	// two-factor authentication is already enabled.
+	StartTwoFactorEnrolment(userID int, secret string) error
+	// EnableTwoFactor enables the pending enrolment, confirmed with the code
+	// of time step step, and replaces the user's recovery codes with
+	// codeHashes. It returns ErrTwoFactorNotFound when nothing is pending.
+	EnableTwoFactor(userID int, step int64, codeHashes []string) error
+	// DisableTwoFactor removes the user's enrolment and recovery codes
+	DisableTwoFactor(userID int) error
+	// UseTOTPStep records that the user's code of time step step was
+	// accepted. It reports false when a code of this or a later step was
+	// already accepted, so no code is accepted twice.
+	UseTOTPStep(userID int, step int64) (bool, error)
+	// UseRecoveryCode marks the user's unused recovery code with hash
+	// codeHash as used or returns ErrRecoveryCodeInvalid
+	UseRecoveryCode(userID int, codeHash string) error
+}
+
+// WorkspaceRepository stores workspaces, their members and pending invitations
+type WorkspaceRepository interface {
+	// CreateWorkspace stores a new workspace with ownerID as its owner and
+	// returns it with the owner's role
+	CreateWorkspace(name string, ownerID int) (models.Workspace, error)
+	// GetWorkspace returns the workspace with the role userID has in it, or
+	// ErrWorkspaceNotFound when it does not exist or userID is not a member
+	GetWorkspace(workspaceID, userID int) (models.Workspace, error)
+	// GetPersonalWorkspace returns the workspace created with the user's
+	// account or ErrWorkspaceNotFound
+	GetPersonalWorkspace(userID int) (models.Workspace, error)
+	// ListWorkspacesByUser returns the workspaces userID is a member of with
+	// their role, the personal workspace first and the others oldest first
+	ListWorkspacesByUser(userID int) ([]models.Workspace, error)
+	// ListWorkspaceMembers returns the members of a workspace, oldest first
+	ListWorkspaceMembers(workspaceID int) ([]models.WorkspaceMember, error)
+	// SetWorkspaceMemberRole changes a member's role. It returns
+	// ErrWorkspaceMemberNotFound for non-members and ErrLastWorkspaceOwner
+	// when the workspace would be left without an owner.
+	SetWorkspaceMemberRole(workspaceID, userID int, role string) error
+	// RemoveWorkspaceMember removes a member from the workspace, with the
+	// same errors as SetWorkspaceMemberRole
+	RemoveWorkspaceMember(workspaceID, userID int) error
+	// CreateWorkspaceInvitation stores a new invitation and returns it with its ID set
+	CreateWorkspaceInvitation(invitation models.WorkspaceInvitation) (models.WorkspaceInvitation, error)
+	// ListWorkspaceInvitations returns the workspace's pending, unexpired
+	// invitations, newest first
+	ListWorkspaceInvitations(workspaceID int) ([]models.WorkspaceInvitation, error)
+	// RevokeWorkspaceInvitation deletes a pending invitation of the workspace
+	// or returns ErrInvitationNotFound
+	RevokeWorkspaceInvitation(workspaceID int, invitationID int64) error
+	// AcceptWorkspaceInvitation redeems the pending, unexpired invitation with
+	// hash tokenHash addressed to email, matched case-insensitively, and adds
+	// userID to its workspace with the invited role. Existing members keep the
+	// higher of the two roles. It returns the workspace with userID's role, or
+	// ErrInvitationInvalid when no such invitation exists.
+	AcceptWorkspaceInvitation(tokenHash string, userID int, email string) (models.Workspace, error)
+}
+
+// AdminRepository backs the instance administration API. Searches match a
+// case-insensitive substring and return one page, newest first, together
+// with the total number of matches.
+type AdminRepository interface {
+	// SearchUsers finds users whose username or email contains query
+	SearchUsers(query string, limit, offset int) ([]models.User, int, error)
+	// SetUserRole changes a user's role or returns ErrUserNotFound
+	SetUserRole(userID int, role string) error
+	// SetUserDisabled disables or re-enables an account. Disabling also
+	// revokes the user's refresh tokens. It returns ErrUserNotFound.
+	SetUserDisabled(userID int, disabled bool) error
+	// SearchLinks finds links that are not deleted whose slug or target URL contains query
+	SearchLinks(query string, limit, offset int) ([]models.Link, int, error)
+	// TakeDownLink stops a link from redirecting, recording the admin and
+	// reason, and returns it. It returns ErrLinkNotFound for unknown or deleted links.
+	TakeDownLink(domainID int, slug string, adminID int, reason string) (models.Link, error)
+	// RestoreLink lifts a takedown and returns the link, with the same errors as TakeDownLink
+	RestoreLink(domainID int, slug string) (models.Link, error)
+	// GetInstanceStats counts users, workspaces, links and clicks
+	GetInstanceStats() (models.InstanceStats, error)
+}
+
+// DomainRepository stores the custom short domains of workspaces
+type DomainRepository interface {
+	// CreateDomain stores a new unverified domain and returns it with its ID
+	// set. It returns ErrDomainTaken when the workspace already registered
+	// the hostname or another workspace has verified it.
+	CreateDomain(domain models.Domain) (models.Domain, error)
+	// ListDomainsByWorkspace returns the workspace's domains, oldest first
+	ListDomainsByWorkspace(workspaceID int) ([]models.Domain, error)
+	// GetDomain returns a domain of the workspace or ErrDomainNotFound
+	GetDomain(workspaceID, domainID int) (models.Domain, error)
+	// GetVerifiedDomainByHostname returns the verified domain with the
+	// hostname or ErrDomainNotFound
+	GetVerifiedDomainByHostname(hostname string) (models.Domain, error)
+	// MarkDomainVerified records that a domain of the workspace passed DNS
+	// verification and returns it. It keeps the original time when already
+	// verified, returns ErrDomainTaken when another workspace has verified
+	// the hostname and ErrDomainNotFound for unknown domains.
+	MarkDomainVerified(workspaceID, domainID int) (models.Domain, error)
+	// DeleteDomain removes a domain of the workspace together with its
+	// deleted links. It returns ErrDomainInUse while other links use it and
+	// ErrDomainNotFound for unknown domains.
+	DeleteDomain(workspaceID, domainID int) error
+}
+
+// TagRepository stores the tags and folders links are organised with. Both
+// belong to the link's workspace and are created by name on first use.
+type TagRepository interface {
+	// SetLinkTags replaces the tags of an active link with the named ones or
+	// returns ErrLinkNotFound
+	SetLinkTags(linkID int, names []string) error
+	// SetLinkFolder moves an active link into the named folder, or out of any
+	// folder when name is empty. It returns ErrLinkNotFound.
+	SetLinkFolder(linkID int, name string) error
+	// ListTags returns the workspace's tags by name with their link and click counts
+	ListTags(workspaceID int) ([]models.Tag, error)
+	// ListFolders returns the workspace's folders by name with their link and click counts
+	ListFolders(workspaceID int) ([]models.Folder, error)
+	// RenameTag renames a tag of the workspace and returns it. It returns
+	// ErrTagNotFound for unknown tags and ErrTagTaken when another tag has the name.
+	RenameTag(workspaceID, tagID int, name string) (models.Tag, error)
+	// MergeTags moves the links of tag tagID to tag targetID, deletes tagID
+	// and returns the target. It returns ErrTagNotFound when either is unknown.
+	MergeTags(workspaceID, tagID, targetID int) (models.Tag, error)
+}
+
+// Store provides every repository from one backend
+type Store interface {
+	LinkRepository
+	UserRepository
+	AccessLogRepository
+	RefreshTokenRepository
+	APIKeyRepository
+	PasswordResetRepository
+	TwoFactorRepository
+	WorkspaceRepository
+	AdminRepository
+	DomainRepository
+	TagRepository
+}
@@ -0,0 +1,206 @@
+package repotest
+
+import (
+	"errors"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testAdminUsers(t *testing.T, store repositories.Store) {
+	prefix := "a" + randomString(t, 10)
+	first, err := store.CreateUser(prefix+"x", prefix+"x@example.com", "hash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := store.CreateUser(prefix+"y", prefix+"y@example.com", "hash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := store.GetUserByID(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Role != models.UserRoleUser || user.DisabledAt.Valid {
+		t.Errorf("new user role = %q, disabled = %v, want an enabled user", user.Role, user.DisabledAt.Valid)
+	}
+
+	// Searches ignore case and match emails as well as usernames
+	users, total, err := store.SearchUsers(strings.ToUpper(user.Username), 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 || len(users) != 1 || users[0].ID != first {
+		t.Errorf("SearchUsers by username = %d users, total %d", len(users), total)
+	}
+	if users, total, err := store.SearchUsers(prefix, 1, 0); err != nil || total != 2 || len(users) != 1 || users[0].ID != second {
+		t.Errorf("SearchUsers first page = %+v, total %d, %v; want the newer of 2 users", users, total, err)
+	}
+	if users, _, err := store.SearchUsers(strings.ToUpper(prefix+"y@example"), 10, 0); err != nil || len(users) != 1 || users[0].ID != second {
+		t.Errorf("SearchUsers by email = %+v, %v", users, err)
+	}
+	if users, _, err := store.SearchUsers(user.Username, 10, 1); err != nil || len(users) != 0 {
+		t.Errorf("SearchUsers past the last match = %d users, %v", len(users), err)
+	}
+
+	if err := store.SetUserRole(first, models.UserRoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+	if user, err := store.GetUserByID(first); err != nil || user.Role != models.UserRoleAdmin {
+		t.Errorf("role after SetUserRole = %+v, %v", user, err)
+	}
+
+	refresh, err := store.CreateRefreshToken(newRefreshToken(t, second, time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetUserDisabled(second, true); err != nil {
+		t.Fatal(err)
+	}
+	if user, err := store.GetUserByID(second); err != nil || !user.DisabledAt.Valid {
+		t.Errorf("user after disabling = %+v, %v", user, err)
+	}
+	if _, err := store.RotateRefreshToken(refresh.TokenHash, newRefreshToken(t, 0, time.Hour)); !errors.Is(err, repositories.ErrRefreshTokenReused) {
+		t.Errorf("refresh token after disabling: got %v, want ErrRefreshTokenReused", err)
+	}
+
+	if err := store.SetUserDisabled(second, false); err != nil {
+		t.Fatal(err)
+	}
+	if user, err := store.GetUserByID(second); err != nil || user.DisabledAt.Valid {
+		t.Errorf("user after enabling = %+v, %v", user, err)
+	}
+
+	if err := store.SetUserDisabled(-1, true); !errors.Is(err, repositories.ErrUserNotFound) {
+		t.Errorf("disabling an unknown user: got %v, want ErrUserNotFound", err)
+	}
+	if err := store.SetUserRole(-1, models.UserRoleAdmin); !errors.Is(err, repositories.ErrUserNotFound) {
+		t.Errorf("promoting an unknown user: got %v, want ErrUserNotFound", err)
+	}
+}
+
+func testLinkTakedown(t *testing.T, store repositories.Store) {
+	adminID := CreateUser(t, store)
+	ownerID := CreateUser(t, store)
+	link := CreateLink(t, store, ownerID, func(l *models.Link) {
+		l.TargetURL = "https://example.com/" + l.Slug
+	})
+
+	links, total, err := store.SearchLinks(strings.ToUpper(link.Slug), 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 || len(links) != 1 || links[0].ID != link.ID {
+		t.Errorf("SearchLinks by slug = %d links, total %d", len(links), total)
+	}
+
+	downed, err := store.TakeDownLink(0, link.Slug, adminID, "phishing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !downed.TakenDownAt.Valid || downed.TakedownReason.String != "phishing" {
+		t.Errorf("TakeDownLink = %+v", downed)
+	}
+	if got, err := store.GetLinkBySlug(0, link.Slug); err != nil || got.Status(time.Now()) != models.LinkStatusTakenDown {
+		t.Errorf("status after takedown = %v, %v", got.Status(time.Now()), err)
+	}
+
+	if _, outcome, err := store.ConsumeClick(0, link.Slug, false); err != nil || outcome != models.ClickTakenDown {
+		t.Errorf("ConsumeClick on a taken down link = %v, %v; want ClickTakenDown", outcome, err)
+	}
+	if counted, err := store.IncrementClickCount(link.ID); err != nil || counted {
+		t.Errorf("IncrementClickCount on a taken down link = %v, %v; want false", counted, err)
+	}
+
+	// Taken down links stay searchable so they can be restored
+	if links, _, err := store.SearchLinks(link.TargetURL, 10, 0); err != nil || len(links) != 1 || !links[0].TakenDownAt.Valid {
+		t.Errorf("SearchLinks by target URL = %+v, %v", links, err)
+	}
+
+	restored, err := store.RestoreLink(0, link.Slug)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.TakenDownAt.Valid || restored.TakedownReason.Valid {
+		t.Errorf("RestoreLink = %+v", restored)
+	}
+	if _, outcome, err := store.ConsumeClick(0, link.Slug, false); err != nil || outcome != models.ClickAllowed {
+		t.Errorf("ConsumeClick after restoring = %v, %v; want ClickAllowed", outcome, err)
+	}
+
+	if _, err := store.TakeDownLink(0, "missing"+link.Slug, adminID, "spam"); !errors.Is(err, repositories.ErrLinkNotFound) {
+		t.Errorf("TakeDownLink for an unknown slug: got %v, want ErrLinkNotFound", err)
+	}
+	if err := store.SoftDeleteLink(0, link.Slug, ownerID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.RestoreLink(0, link.Slug); !errors.Is(err, repositories.ErrLinkNotFound) {
+		t.Errorf("RestoreLink for a deleted link: got %v, want ErrLinkNotFound", err)
+	}
+	if links, total, err := store.SearchLinks(link.Slug, 10, 0); err != nil || total != 0 || len(links) != 0 {
+		t.Errorf("SearchLinks found a deleted link: %d links, total %d, %v", len(links), total, err)
+	}
+}
+
+func testInstanceStats(t *testing.T, store repositories.Store) {
+	before, err := store.GetInstanceStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	userID := CreateUser(t, store)
+	if err := store.SetUserDisabled(userID, true); err != nil {
+		t.Fatal(err)
+	}
+	active := CreateLink(t, store, userID, nil)
+	downed := CreateLink(t, store, userID, nil)
+	deleted := CreateLink(t, store, userID, nil)
+	if _, err := store.TakeDownLink(0, downed.Slug, userID, "spam"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SoftDeleteLink(0, deleted.Slug, userID); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := store.ConsumeClick(0, active.Slug, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.InsertAccessLogs([]models.AccessLog{
+		{LinkID: int64(active.ID), EventType: models.EventClick, AccessedAt: time.Now(), IPAddress: "192.0.2.1"},
+		{LinkID: int64(active.ID), EventType: models.EventClick, AccessedAt: time.Now().Add(-48 * time.Hour), IPAddress: "192.0.2.1"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	after, err := store.GetInstanceStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Other tests may share the database, so only check the stats grew by at least as much
+	got := models.InstanceStats{
+		Users:             after.Users - before.Users,
+		DisabledUsers:     after.DisabledUsers - before.DisabledUsers,
+		UnverifiedUsers:   after.UnverifiedUsers - before.UnverifiedUsers,
+		Links:             after.Links - before.Links,
+		TakenDownLinks:    after.TakenDownLinks - before.TakenDownLinks,
+		DeletedLinks:      after.DeletedLinks - before.DeletedLinks,
+		TotalClicks:       after.TotalClicks - before.TotalClicks,
+		ClicksLast24Hours: after.ClicksLast24Hours - before.ClicksLast24Hours,
+	}
+	want := models.InstanceStats{
+		Users:             1,
+		DisabledUsers:     1,
+		UnverifiedUsers:   1,
+		Links:             2,
+		TakenDownLinks:    1,
+		DeletedLinks:      1,
+		TotalClicks:       1,
+		ClicksLast24Hours: 1,
+	}
+	if got.Users < want.Users || got.DisabledUsers < want.DisabledUsers || got.UnverifiedUsers < want.UnverifiedUsers ||
+		got.Links < want.Links || got.TakenDownLinks < want.TakenDownLinks || got.DeletedLinks < want.DeletedLinks ||
+		got.TotalClicks < want.TotalClicks || got.ClicksLast24Hours < want.ClicksLast24Hours {
+		t.Errorf("stats changed by %+v, want at least %+v", got, want)
+	}
+}
@@ -0,0 +1,83 @@
+package repotest
+
+import (
+	"errors"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func testAPIKeys(t *testing.T, store repositories.Store) {
+	userID := CreateUser(t, store)
+	otherUserID := CreateUser(t, store)
+
+	scopes := []string{models.ScopeLinksRead, models.ScopeAnalyticsRead}
+	first, err := store.CreateAPIKey(models.APIKey{
+		UserID:  userID,
+		Name:    "CI",
+		Prefix:  "lg_first",
+		KeyHash: randomString(t, 64),
+		Scopes:  scopes,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.ID == 0 || first.CreatedAt.IsZero() {
+		t.Errorf("CreateAPIKey = %+v, want ID and creation time set", first)
+	}
+	second, err := store.CreateAPIKey(models.APIKey{
+		UserID:  userID,
+		Name:    "Scripts",
+		Prefix:  "lg_second",
+		KeyHash: randomString(t, 64),
+		Scopes:  []string{models.ScopeLinksWrite},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := store.GetAPIKeyByHash(first.KeyHash)
+	if err != nil {
+		t.Fatalf("GetAPIKeyByHash: %v", err)
+	}
+	if found.ID != first.ID || found.UserID != userID || !reflect.DeepEqual(found.Scopes, scopes) || found.LastUsedAt.Valid {
+		t.Errorf("GetAPIKeyByHash = %+v, want key %d with scopes %v", found, first.ID, scopes)
+	}
+	if _, err := store.GetAPIKeyByHash(randomString(t, 64)); !errors.Is(err, repositories.ErrAPIKeyNotFound) {
+		t.Errorf("unknown hash: got %v, want ErrAPIKeyNotFound", err)
+	}
+
+	usedAt := time.Now().Truncate(time.Second)
+	if err := store.TouchAPIKey(first.ID, usedAt); err != nil {
+		t.Fatal(err)
+	}
+	if found, err := store.GetAPIKeyByHash(first.KeyHash); err != nil || !found.LastUsedAt.Time.Equal(usedAt) {
+		t.Errorf("last used at = %v, %v; want %v", found.LastUsedAt, err, usedAt)
+	}
+
+	keys, err := store.ListAPIKeysByUser(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 || keys[0].ID != second.ID || keys[1].ID != first.ID {
+		t.Errorf("ListAPIKeysByUser = %+v, want keys %d and %d newest first", keys, second.ID, first.ID)
+	}
+
+	if err := store.RevokeAPIKey(first.ID, otherUserID); !errors.Is(err, repositories.ErrAPIKeyNotFound) {
+		t.Errorf("revoking another user's key: got %v, want ErrAPIKeyNotFound", err)
+	}
+	if err := store.RevokeAPIKey(first.ID, userID); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.RevokeAPIKey(first.ID, userID); !errors.Is(err, repositories.ErrAPIKeyNotFound) {
+		t.Errorf("revoking twice: got %v, want ErrAPIKeyNotFound", err)
+	}
+	if _, err := store.GetAPIKeyByHash(first.KeyHash); !errors.Is(err, repositories.ErrAPIKeyNotFound) {
+		t.Errorf("revoked key: got %v, want ErrAPIKeyNotFound", err)
+	}
+	if keys, err := store.ListAPIKeysByUser(userID); err != nil || len(keys) != 1 || keys[0].ID != second.ID {
+		t.Errorf("ListAPIKeysByUser after revoke = %+v, %v", keys, err)
+	}
+}
@@ -0,0 +1,155 @@
+package repotest
+
+import (
+	"database/sql"
+	"errors"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"testing"
+)
+
+// CreateVerifiedDomain registers a random hostname for the workspace and
+// marks it verified
+func CreateVerifiedDomain(t testing.TB, store repositories.Store, workspaceID, userID int) models.Domain {
+	t.Helper()
+
+	domain := createDomain(t, store, workspaceID, userID, "d"+randomString(t, 10)+".example.com")
+	verified, err := store.MarkDomainVerified(workspaceID, domain.ID)
+	if err != nil {
+		t.Fatalf("MarkDomainVerified failed: %v", err)
+	}
+	return verified
+}
+
+func createDomain(t testing.TB, store repositories.Store, workspaceID, userID int, hostname string) models.Domain {
+	t.Helper()
+
+	domain, err := store.CreateDomain(models.Domain{
+		WorkspaceID:       workspaceID,
+		Hostname:          hostname,
+		VerificationToken: randomString(t, 32),
+		CreatedBy:         sql.NullInt32{Int32: int32(userID), Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("CreateDomain failed: %v", err)
+	}
+	return domain
+}
+
+func testDomainVerification(t *testing.T, store repositories.Store) {
+	userID := CreateUser(t, store)
+	otherID := CreateUser(t, store)
+	workspace, err := store.GetPersonalWorkspace(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := store.GetPersonalWorkspace(otherID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hostname := "d" + randomString(t, 10) + ".example.com"
+	domain := createDomain(t, store, workspace.ID, userID, hostname)
+	if domain.ID == 0 || domain.VerifiedAt.Valid || domain.CreatedAt.IsZero() {
+		t.Errorf("CreateDomain = %+v", domain)
+	}
+	if _, err := store.CreateDomain(models.Domain{WorkspaceID: workspace.ID, Hostname: hostname, VerificationToken: "x"}); !errors.Is(err, repositories.ErrDomainTaken) {
+		t.Errorf("registering a hostname twice: got %v, want ErrDomainTaken", err)
+	}
+
+	// Both workspaces may claim the hostname until one of them verifies it
+	rival := createDomain(t, store, other.ID, otherID, hostname)
+
+	if domains, err := store.ListDomainsByWorkspace(workspace.ID); err != nil || len(domains) != 1 || domains[0].ID != domain.ID {
+		t.Errorf("ListDomainsByWorkspace = %+v, %v", domains, err)
+	}
+	if _, err := store.GetDomain(other.ID, domain.ID); !errors.Is(err, repositories.ErrDomainNotFound) {
+		t.Errorf("GetDomain from another workspace: got %v, want ErrDomainNotFound", err)
+	}
+	if _, err := store.GetVerifiedDomainByHostname(hostname); !errors.Is(err, repositories.ErrDomainNotFound) {
+		t.Errorf("GetVerifiedDomainByHostname before verification: got %v, want ErrDomainNotFound", err)
+	}
+
+	verified, err := store.MarkDomainVerified(workspace.ID, domain.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !verified.VerifiedAt.Valid {
+		t.Errorf("MarkDomainVerified = %+v", verified)
+	}
+	if again, err := store.MarkDomainVerified(workspace.ID, domain.ID); err != nil || !again.VerifiedAt.Time.Equal(verified.VerifiedAt.Time) {
+		t.Errorf("verifying again = %+v, %v; want the original time", again, err)
+	}
+	if got, err := store.GetVerifiedDomainByHostname(hostname); err != nil || got.ID != domain.ID {
+		t.Errorf("GetVerifiedDomainByHostname = %+v, %v", got, err)
+	}
+
+	if _, err := store.MarkDomainVerified(other.ID, rival.ID); !errors.Is(err, repositories.ErrDomainTaken) {
+		t.Errorf("verifying a hostname verified elsewhere: got %v, want ErrDomainTaken", err)
+	}
+	if err := store.DeleteDomain(other.ID, rival.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.CreateDomain(models.Domain{WorkspaceID: other.ID, Hostname: hostname, VerificationToken: "x"}); !errors.Is(err, repositories.ErrDomainTaken) {
+		t.Errorf("registering a hostname verified elsewhere: got %v, want ErrDomainTaken", err)
+	}
+	if _, err := store.MarkDomainVerified(workspace.ID, -1); !errors.Is(err, repositories.ErrDomainNotFound) {
+		t.Errorf("verifying an unknown domain: got %v, want ErrDomainNotFound", err)
+	}
+}
+
+func testSlugsArePerDomain(t *testing.T, store repositories.Store) {
+	userID := CreateUser(t, store)
+	workspace, err := store.GetPersonalWorkspace(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	domain := CreateVerifiedDomain(t, store, workspace.ID, userID)
+
+	onDefault := CreateLink(t, store, userID, nil)
+	onDomain := CreateLink(t, store, userID, func(l *models.Link) {
+		l.Slug = onDefault.Slug
+		l.TargetURL = "https://example.com/custom"
+		l.DomainID = sql.NullInt32{Int32: int32(domain.ID), Valid: true}
+	})
+	if _, err := store.CreateLink(models.Link{Slug: onDefault.Slug, TargetURL: "https://example.com", DomainID: onDomain.DomainID}); !errors.Is(err, repositories.ErrSlugTaken) {
+		t.Errorf("reusing a slug on the same domain: got %v, want ErrSlugTaken", err)
+	}
+
+	got, err := store.GetLinkBySlug(domain.ID, onDefault.Slug)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != onDomain.ID || got.Domain.String != domain.Hostname {
+		t.Errorf("GetLinkBySlug on the domain = %+v, want link %d on %s", got, onDomain.ID, domain.Hostname)
+	}
+	if got, err := store.GetLinkBySlug(0, onDefault.Slug); err != nil || got.ID != onDefault.ID || got.DomainID.Valid {
+		t.Errorf("GetLinkBySlug on the default domain = %+v, %v", got, err)
+	}
+
+	free := "f" + randomString(t, 10)
+	if available, err := store.FilterAvailableSlugs(domain.ID, []string{onDefault.Slug, free}); err != nil || len(available) != 1 || available[0] != free {
+		t.Errorf("FilterAvailableSlugs on the domain = %v, %v; want only %s", available, err, free)
+	}
+
+	link, outcome, err := store.ConsumeClick(domain.ID, onDefault.Slug, false)
+	if err != nil || outcome != models.ClickAllowed || link.ID != onDomain.ID || link.Domain.String != domain.Hostname {
+		t.Errorf("ConsumeClick on the domain = %+v, %v, %v", link, outcome, err)
+	}
+
+	if err := store.DeleteDomain(workspace.ID, domain.ID); !errors.Is(err, repositories.ErrDomainInUse) {
+		t.Errorf("deleting a domain with links: got %v, want ErrDomainInUse", err)
+	}
+	if err := store.SoftDeleteLink(domain.ID, onDefault.Slug, userID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.GetLinkBySlug(0, onDefault.Slug); err != nil {
+		t.Errorf("deleting the link on the domain removed the default one: %v", err)
+	}
+	if err := store.DeleteDomain(workspace.ID, domain.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.GetDomain(workspace.ID, domain.ID); !errors.Is(err, repositories.ErrDomainNotFound) {
+		t.Errorf("GetDomain after deleting: got %v, want ErrDomainNotFound", err)
+	}
+}
@@ -0,0 +1,186 @@
+package repotest
+
+import (
+	"database/sql"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newestFirst is a page holding up to 1000 links, newest first
+var newestFirst = models.LinkPage{Sort: models.LinkSort{Field: models.LinkSortCreatedAt, Desc: true}, Limit: 1000}
+
+// listLinks returns the workspace's links matching filter, newest first
+func listLinks(t testing.TB, store repositories.Store, workspaceID int, filter models.LinkFilter) []models.Link {
+	t.Helper()
+
+	links, total, err := store.ListLinksByWorkspace(workspaceID, filter, newestFirst)
+	if err != nil {
+		t.Fatalf("ListLinksByWorkspace failed: %v", err)
+	}
+	if total != len(links) {
+		t.Errorf("ListLinksByWorkspace(%+v) returned %d links but a total of %d", filter, len(links), total)
+	}
+	return links
+}
+
+// linkIDs returns the IDs of links in order
+func linkIDs(links []models.Link) []int {
+	ids := []int{}
+	for _, link := range links {
+		ids = append(ids, link.ID)
+	}
+	return ids
+}
+
+func testListLinksFilters(t *testing.T, store repositories.Store) {
+	userID := CreateUser(t, store)
+	workspace, err := store.GetPersonalWorkspace(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+	createdAt := func(minutes int) func(*models.Link) {
+		return func(l *models.Link) { l.CreatedAt = base.Add(time.Duration(minutes) * time.Minute) }
+	}
+
+	active := CreateLink(t, store, userID, func(l *models.Link) {
+		createdAt(0)(l)
+		l.TargetURL = "https://example.com/Docs/guide"
+		l.ClickLimit = sql.NullInt32{Int32: 5, Valid: true}
+		l.ClickCount = 1
+	})
+	expired := CreateLink(t, store, userID, func(l *models.Link) {
+		createdAt(1)(l)
+		l.TargetURL = "https://shop.example/sale_100%"
+		l.ExpiresAt = sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true}
+	})
+	exhausted := CreateLink(t, store, userID, func(l *models.Link) {
+		createdAt(2)(l)
+		l.Slug = "searchme" + randomString(t, 6)
+		l.ClickLimit = sql.NullInt32{Int32: 2, Valid: true}
+		l.ClickCount = 2
+	})
+	deleted := CreateLink(t, store, userID, createdAt(3))
+	takenDown := CreateLink(t, store, userID, createdAt(4))
+
+	if err := store.SoftDeleteLink(0, deleted.Slug, userID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.TakeDownLink(0, takenDown.Slug, userID, "spam"); err != nil {
+		t.Fatal(err)
+	}
+	// Links of other workspaces never match
+	CreateLink(t, store, CreateUser(t, store), func(l *models.Link) { l.TargetURL = "https://example.com/Docs" })
+
+	hasClickLimit, noClickLimit := true, false
+	for name, tt := range map[string]struct {
+		filter models.LinkFilter
+		want   []models.Link
+	}{
+		"not deleted":       {models.LinkFilter{}, []models.Link{takenDown, exhausted, expired, active}},
+		"active":            {models.LinkFilter{Status: models.LinkStatusActive}, []models.Link{active}},
+		"expired":           {models.LinkFilter{Status: models.LinkStatusExpired}, []models.Link{expired}},
+		"exhausted":         {models.LinkFilter{Status: models.LinkStatusExhausted}, []models.Link{exhausted}},
+		"deleted":           {models.LinkFilter{Status: models.LinkStatusDeleted}, []models.Link{deleted}},
+		"taken down":        {models.LinkFilter{Status: models.LinkStatusTakenDown}, []models.Link{takenDown}},
+		"target URL search": {models.LinkFilter{Query: "docs"}, []models.Link{active}},
+		"slug search":       {models.LinkFilter{Query: "SEARCHME"}, []models.Link{exhausted}},
+		"literal wildcard":  {models.LinkFilter{Query: "%"}, []models.Link{expired}},
+		"created range": {models.LinkFilter{CreatedFrom: base.Add(time.Minute), CreatedTo: base.Add(3 * time.Minute)},
+			[]models.Link{exhausted, expired}},
+		"with click limit":    {models.LinkFilter{HasClickLimit: &hasClickLimit}, []models.Link{exhausted, active}},
+		"without click limit": {models.LinkFilter{HasClickLimit: &noClickLimit}, []models.Link{takenDown, expired}},
+		"combined":            {models.LinkFilter{Query: "example.com", HasClickLimit: &hasClickLimit, Status: models.LinkStatusActive}, []models.Link{active}},
+	} {
+		if ids, want := linkIDs(listLinks(t, store, workspace.ID, tt.filter)), linkIDs(tt.want); !reflect.DeepEqual(ids, want) {
+			t.Errorf("%s: got links %v, want %v", name, ids, want)
+		}
+	}
+}
+
+func testListLinksPages(t *testing.T, store repositories.Store) {
+	userID := CreateUser(t, store)
+	workspace, err := store.GetPersonalWorkspace(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+	soon, later := base.Add(2*time.Hour), base.Add(3*time.Hour)
+	clicks := []int{3, 1, 3, 0, 2}
+	expiries := []*time.Time{nil, &later, nil, &soon, &later}
+	var links []models.Link
+	for i := range clicks {
+		links = append(links, CreateLink(t, store, userID, func(l *models.Link) {
+			l.CreatedAt = base.Add(time.Duration(i) * time.Minute)
+			l.ClickCount = clicks[i]
+			if expiries[i] != nil {
+				l.ExpiresAt = sql.NullTime{Time: *expiries[i], Valid: true}
+			}
+		}))
+	}
+	ids := func(indexes ...int) []int {
+		want := []int{}
+		for _, i := range indexes {
+			want = append(want, links[i].ID)
+		}
+		return want
+	}
+
+	// Ties are broken by ID and links without an expiry sort last
+	for _, tt := range []struct {
+		sort models.LinkSort
+		want []int
+	}{
+		{models.LinkSort{Field: models.LinkSortCreatedAt, Desc: true}, ids(4, 3, 2, 1, 0)},
+		{models.LinkSort{Field: models.LinkSortCreatedAt}, ids(0, 1, 2, 3, 4)},
+		{models.LinkSort{Field: models.LinkSortClickCount}, ids(3, 1, 4, 0, 2)},
+		{models.LinkSort{Field: models.LinkSortClickCount, Desc: true}, ids(2, 0, 4, 1, 3)},
+		{models.LinkSort{Field: models.LinkSortExpiresAt}, ids(3, 1, 4, 0, 2)},
+		{models.LinkSort{Field: models.LinkSortExpiresAt, Desc: true}, ids(2, 0, 4, 1, 3)},
+	} {
+		got := []int{}
+		page := models.LinkPage{Sort: tt.sort, Limit: 2}
+		for pages := 0; pages < 5; pages++ {
+			links, total, err := store.ListLinksByWorkspace(workspace.ID, models.LinkFilter{}, page)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total != 5 {
+				t.Errorf("%+v: total = %d, want 5", tt.sort, total)
+			}
+			got = append(got, linkIDs(links)...)
+			if len(links) < page.Limit {
+				break
+			}
+			cursor, err := models.ParseLinkCursor(tt.sort.CursorAt(links[len(links)-1]).Encode())
+			if err != nil {
+				t.Fatal(err)
+			}
+			page.After = &cursor
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%+v: paged through %v, want %v", tt.sort, got, tt.want)
+		}
+	}
+
+	// A cursor keeps its place when links are added before it
+	page := models.LinkPage{Sort: models.LinkSort{Field: models.LinkSortCreatedAt, Desc: true}, Limit: 2}
+	first, _, err := store.ListLinksByWorkspace(workspace.ID, models.LinkFilter{}, page)
+	if err != nil || len(first) != 2 {
+		t.Fatalf("first page = %v, %v", linkIDs(first), err)
+	}
+	CreateLink(t, store, userID, nil)
+	cursor := page.Sort.CursorAt(first[1])
+	page.After = &cursor
+	second, total, err := store.ListLinksByWorkspace(workspace.ID, models.LinkFilter{}, page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(linkIDs(second), ids(2, 1)) || total != 6 {
+		t.Errorf("second page after an insert = %v of %d, want %v of 6", linkIDs(second), total, ids(2, 1))
+	}
+}
@@ -0,0 +1,98 @@
+package repotest
+
+import (
+	"errors"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"testing"
+	"time"
+)
+
+// createPasswordResetToken stores a token with a random hash for userID
+func createPasswordResetToken(t *testing.T, store repositories.Store, userID int, expiresIn time.Duration) models.PasswordResetToken {
+	t.Helper()
+
+	token, err := store.CreatePasswordResetToken(models.PasswordResetToken{
+		UserID:    userID,
+		TokenHash: randomString(t, 64),
+		ExpiresAt: time.Now().Add(expiresIn),
+	})
+	if err != nil {
+		t.Fatalf("CreatePasswordResetToken failed: %v", err)
+	}
+	return token
+}
+
+func testPasswordReset(t *testing.T, store repositories.Store) {
+	userID := CreateUser(t, store)
+
+	first := createPasswordResetToken(t, store, userID, time.Hour)
+	if first.ID == 0 {
+		t.Error("CreatePasswordResetToken did not set the ID")
+	}
+	second := createPasswordResetToken(t, store, userID, time.Hour)
+	refresh, err := store.CreateRefreshToken(newRefreshToken(t, userID, time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := store.ResetPassword(first.TokenHash, "new-hash")
+	if err != nil {
+		t.Fatalf("ResetPassword: %v", err)
+	}
+	if got != userID {
+		t.Errorf("ResetPassword user = %d, want %d", got, userID)
+	}
+
+	user, err := store.GetUserByID(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Password != "new-hash" || !user.EmailVerified {
+		t.Errorf("after reset user = %+v, want password hash new-hash and a verified email", user)
+	}
+
+	// The token is single-use and redeeming it invalidates the user's others
+	if _, err := store.ResetPassword(first.TokenHash, "other-hash"); !errors.Is(err, repositories.ErrPasswordResetTokenInvalid) {
+		t.Errorf("reused token: got %v, want ErrPasswordResetTokenInvalid", err)
+	}
+	if _, err := store.ResetPassword(second.TokenHash, "other-hash"); !errors.Is(err, repositories.ErrPasswordResetTokenInvalid) {
+		t.Errorf("superseded token: got %v, want ErrPasswordResetTokenInvalid", err)
+	}
+
+	// Existing sessions end with the reset
+	if _, err := store.RotateRefreshToken(refresh.TokenHash, newRefreshToken(t, 0, time.Hour)); !errors.Is(err, repositories.ErrRefreshTokenReused) {
+		t.Errorf("refresh token after reset: got %v, want ErrRefreshTokenReused", err)
+	}
+}
+
+func testPasswordResetRejectsInvalidTokens(t *testing.T, store repositories.Store) {
+	userID := CreateUser(t, store)
+	otherUserID := CreateUser(t, store)
+
+	expired := createPasswordResetToken(t, store, userID, -time.Minute)
+	if _, err := store.ResetPassword(expired.TokenHash, "new-hash"); !errors.Is(err, repositories.ErrPasswordResetTokenInvalid) {
+		t.Errorf("expired token: got %v, want ErrPasswordResetTokenInvalid", err)
+	}
+	if _, err := store.ResetPassword(randomString(t, 64), "new-hash"); !errors.Is(err, repositories.ErrPasswordResetTokenInvalid) {
+		t.Errorf("unknown token: got %v, want ErrPasswordResetTokenInvalid", err)
+	}
+
+	user, err := store.GetUserByID(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Password != "hash" {
+		t.Errorf("password changed by a rejected token: %q", user.Password)
+	}
+
+	// Another user's sessions and tokens are untouched by a reset
+	otherReset := createPasswordResetToken(t, store, otherUserID, time.Hour)
+	valid := createPasswordResetToken(t, store, userID, time.Hour)
+	if _, err := store.ResetPassword(valid.TokenHash, "new-hash"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.ResetPassword(otherReset.TokenHash, "other-hash"); err != nil {
+		t.Errorf("another user's token was invalidated: %v", err)
+	}
+}
@@ -0,0 +1,546 @@
+// Package repotest holds the contract tests every repositories.Store
+// implementation must pass. Stores may be shared between tests, for example
+// a PostgreSQL database, so each test creates its own users and slugs.
+package repotest
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Run runs the contract tests against the store returned by newStore
+func Run(t *testing.T, newStore func(t *testing.T) repositories.Store) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, store repositories.Store)
+	}{
+		{"Users", testUsers},
+		{"CreateAndListLinks", testCreateAndListLinks},
+		{"SlugAvailability", testSlugAvailability},
+		{"OwnershipAndSoftDelete", testOwnershipAndSoftDelete},
+		{"ConsumeClickOutcomes", testConsumeClickOutcomes},
+		{"ConsumeClickConcurrentLimit", testConsumeClickConcurrentLimit},
+		{"IncrementClickCount", testIncrementClickCount},
+		{"UpdateLinkRecordsRevisionsAndRollsBack", testUpdateLinkRecordsRevisionsAndRollsBack},
+		{"AccessLogs", testAccessLogs},
+		{"LinkClickStats", testLinkClickStats},
+		{"RefreshTokenRotation", testRefreshTokenRotation},
+		{"RefreshTokenReuseRevokesFamily", testRefreshTokenReuseRevokesFamily},
+		{"RevokeRefreshTokenFamily", testRevokeRefreshTokenFamily},
+		{"APIKeys", testAPIKeys},
+		{"PasswordReset", testPasswordReset},
+		{"PasswordResetRejectsInvalidTokens", testPasswordResetRejectsInvalidTokens},
+		{"TwoFactorEnrolment", testTwoFactorEnrolment},
+		{"TwoFactorCodesAreSingleUse", testTwoFactorCodesAreSingleUse},
+		{"Workspaces", testWorkspaces},
+		{"WorkspaceKeepsAnOwner", testWorkspaceKeepsAnOwner},
+		{"WorkspaceRolesAuthoriseLinks", testWorkspaceRolesAuthoriseLinks},
+		{"WorkspaceInvitations", testWorkspaceInvitations},
+		{"AdminUsers", testAdminUsers},
+		{"LinkTakedown", testLinkTakedown},
+		{"InstanceStats", testInstanceStats},
+		{"DomainVerification", testDomainVerification},
+		{"SlugsArePerDomain", testSlugsArePerDomain},
+		{"TagsAndFolders", testTagsAndFolders},
+		{"RenameAndMergeTags", testRenameAndMergeTags},
+		{"ListLinksFilters", testListLinksFilters},
+		{"ListLinksPages", testListLinksPages},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newStore(t))
+		})
+	}
+}
+
+// CreateUser registers a user with a random username and returns its ID
+func CreateUser(t testing.TB, users repositories.UserRepository) int {
+	t.Helper()
+
+	name := "u" + randomString(t, 10)
+	userID, err := users.CreateUser(name, name+"@example.com", "hash")
+	if err != nil {
+		t.Fatalf("CreateUser failed: %v", err)
+	}
+	return userID
+}
+
+// CreateLink stores a link with a random slug created by userID in their
+// personal workspace. configure may adjust the link before it is stored.
+func CreateLink(t testing.TB, store repositories.Store, userID int, configure func(*models.Link)) models.Link {
+	t.Helper()
+
+	workspace, err := store.GetPersonalWorkspace(userID)
+	if err != nil {
+		t.Fatalf("GetPersonalWorkspace failed: %v", err)
+	}
+
+	link := models.Link{
+		Slug:        "t" + randomString(t, 10),
+		TargetURL:   "https://example.com",
+		CreatedAt:   time.Now(),
+		UserID:      sql.NullInt32{Int32: int32(userID), Valid: true},
+		WorkspaceID: sql.NullInt32{Int32: int32(workspace.ID), Valid: true},
+	}
+	if configure != nil {
+		configure(&link)
+	}
+
+	created, err := store.CreateLink(link)
+	if err != nil {
+		t.Fatalf("CreateLink failed: %v", err)
+	}
+	return created
+}
+
+func randomString(t testing.TB, n int) string {
+	t.Helper()
+
+	b := make([]byte, (n+1)/2)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatalf("failed to generate random string: %v", err)
+	}
+	return hex.EncodeToString(b)[:n]
+}
+
+func testUsers(t *testing.T, store repositories.Store) {
+	name := "u" + randomString(t, 10)
+	email := name + "@example.com"
+
+	userID, err := store.CreateUser(name, email, "hash")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.CreateUser(name+"x", email, "hash"); !errors.Is(err, repositories.ErrEmailTaken) {
+		t.Errorf("duplicate email: got %v, want ErrEmailTaken", err)
+	}
+	if _, err := store.CreateUser(name, "x"+email, "hash"); !errors.Is(err, repositories.ErrUsernameTaken) {
+		t.Errorf("duplicate username: got %v, want ErrUsernameTaken", err)
+	}
+
+	if exists, err := store.EmailExists(email); err != nil || !exists {
+		t.Errorf("EmailExists = %v, %v", exists, err)
+	}
+	if exists, err := store.UsernameExists("missing" + name); err != nil || exists {
+		t.Errorf("UsernameExists for unknown name = %v, %v", exists, err)
+	}
+
+	user, err := store.GetUserByEmail("U" + email[1:])
+	if err != nil {
+		t.Fatalf("GetUserByEmail ignores case: %v", err)
+	}
+	if user.ID != userID || user.Username != name || user.Password != "hash" {
+		t.Errorf("GetUserByEmail = %+v", user)
+	}
+
+	if user, err := store.GetUserByID(userID); err != nil || user.Email != email {
+		t.Errorf("GetUserByID = %+v, %v", user, err)
+	}
+	if _, err := store.GetUserByEmail("missing" + email); !errors.Is(err, repositories.ErrUserNotFound) {
+		t.Errorf("unknown email: got %v, want ErrUserNotFound", err)
+	}
+
+	if user.EmailVerified {
+		t.Error("new user's email is already verified")
+	}
+	for i := 0; i < 2; i++ {
+		if err := store.MarkEmailVerified(userID); err != nil {
+			t.Fatalf("MarkEmailVerified: %v", err)
+		}
+	}
+	if user, err := store.GetUserByID(userID); err != nil || !user.EmailVerified {
+		t.Errorf("after MarkEmailVerified GetUserByID = %+v, %v", user, err)
+	}
+	if err := store.MarkEmailVerified(-1); !errors.Is(err, repositories.ErrUserNotFound) {
+		t.Errorf("MarkEmailVerified for unknown user: got %v, want ErrUserNotFound", err)
+	}
+}
+
+func testCreateAndListLinks(t *testing.T, store repositories.Store) {
+	userID := CreateUser(t, store)
+	otherUserID := CreateUser(t, store)
+
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	first := CreateLink(t, store, userID, func(link *models.Link) {
+		link.CreatedAt = time.Now().Add(-time.Minute)
+		link.ExpiresAt = sql.NullTime{Time: expiresAt, Valid: true}
+		link.ClickLimit = sql.NullInt32{Int32: 5, Valid: true}
+		link.PasswordHash = sql.NullString{String: "hash", Valid: true}
+	})
+	second := CreateLink(t, store, userID, nil)
+	CreateLink(t, store, otherUserID, nil)
+
+	if first.ID == 0 || first.ID == second.ID {
+		t.Fatalf("links were not given distinct IDs: %d and %d", first.ID, second.ID)
+	}
+
+	if _, err := store.CreateLink(models.Link{Slug: first.Slug, TargetURL: "https://example.org"}); !errors.Is(err, repositories.ErrSlugTaken) {
+		t.Errorf("duplicate slug: got %v, want ErrSlugTaken", err)
+	}
+
+	got, err := store.GetLinkBySlug(0, first.Slug)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != first.ID || got.TargetURL != "https://example.com" || !got.ExpiresAt.Time.Equal(expiresAt) ||
+		got.ClickLimit.Int32 != 5 || got.PasswordHash.String != "hash" || int(got.UserID.Int32) != userID ||
+		got.WorkspaceID != first.WorkspaceID {
+		t.Errorf("GetLinkBySlug = %+v", got)
+	}
+
+	links, err := store.ListLinksByUser(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(links) != 2 || links[0].ID != second.ID || links[1].ID != first.ID {
+		t.Errorf("ListLinksByUser returned %+v, want the two links newest first", links)
+	}
+	if count, err := store.CountLinksByUser(userID); err != nil || count != 2 {
+		t.Errorf("CountLinksByUser = %d, %v, want 2", count, err)
+	}
+
+	links = listLinks(t, store, int(first.WorkspaceID.Int32), models.LinkFilter{})
+	if len(links) != 2 || links[0].ID != second.ID || links[1].ID != first.ID {
+		t.Errorf("ListLinksByWorkspace returned %+v, want the two links newest first", links)
+	}
+}
+
+func testSlugAvailability(t *testing.T, store repositories.Store) {
+	userID := CreateUser(t, store)
+	active := CreateLink(t, store, userID, nil)
+	deleted := CreateLink(t, store, userID, nil)
+	if err := store.SoftDeleteLink(0, deleted.Slug, userID); err != nil {
+		t.Fatal(err)
+	}
+
+	free := "f" + randomString(t, 10)
+	available, err := store.FilterAvailableSlugs(0, []string{active.Slug, free, deleted.Slug})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(available) != 1 || available[0] != free {
+		t.Errorf("FilterAvailableSlugs = %v, want only %s", available, free)
+	}
+}
+
+func testOwnershipAndSoftDelete(t *testing.T, store repositories.Store) {
+	userID := CreateUser(t, store)
+	otherUserID := CreateUser(t, store)
+	link := CreateLink(t, store, userID, nil)
+
+	if _, err := store.GetMemberLinkBySlug(0, link.Slug, otherUserID, models.WorkspaceRoleViewer); !errors.Is(err, repositories.ErrLinkForbidden) {
+		t.Errorf("GetMemberLinkBySlug by another user: got %v, want ErrLinkForbidden", err)
+	}
+	if got, err := store.GetMemberLinkBySlug(0, link.Slug, userID, models.WorkspaceRoleOwner); err != nil || got.ID != link.ID {
+		t.Errorf("GetMemberLinkBySlug by the owner = %+v, %v", got, err)
+	}
+	if err := store.SoftDeleteLink(0, link.Slug, otherUserID); !errors.Is(err, repositories.ErrLinkForbidden) {
+		t.Errorf("SoftDeleteLink by another user: got %v, want ErrLinkForbidden", err)
+	}
+
+	if err := store.SoftDeleteLink(0, link.Slug, userID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.GetLinkBySlug(0, link.Slug); !errors.Is(err, repositories.ErrLinkNotFound) {
+		t.Errorf("deleted link: got %v, want ErrLinkNotFound", err)
+	}
+	if err := store.SoftDeleteLink(0, link.Slug, userID); !errors.Is(err, repositories.ErrLinkNotFound) {
+		t.Errorf("second delete: got %v, want ErrLinkNotFound", err)
+	}
+	if links, err := store.ListLinksByUser(userID); err != nil || len(links) != 0 {
+		t.Errorf("ListLinksByUser after delete = %v, %v", links, err)
+	}
+	if count, err := store.CountLinksByUser(userID); err != nil || count != 0 {
+		t.Errorf("CountLinksByUser after delete = %d, %v", count, err)
+	}
+}
+
+func testConsumeClickOutcomes(t *testing.T, store repositories.Store) {
+	userID := CreateUser(t, store)
+
+	unlimited := CreateLink(t, store, userID, nil)
+	expired := CreateLink(t, store, userID, func(link *models.Link) {
+		link.ExpiresAt = sql.NullTime{Time: time.Now().Add(-time.Hour), Valid: true}
+	})
+	protected := CreateLink(t, store, userID, func(link *models.Link) {
+		link.PasswordHash = sql.NullString{String: "hash", Valid: true}
+	})
+
+	tests := []struct {
+		name     string
+		slug     string
+		unlocked bool
+		want     models.ClickOutcome
+	}{
+		{"unlimited link", unlimited.Slug, false, models.ClickAllowed},
+		{"expired link", expired.Slug, false, models.ClickExpired},
+		{"unknown slug", "missing-" + randomString(t, 8), false, models.ClickNotFound},
+		{"protected link without unlock", protected.Slug, false, models.ClickLocked},
+		{"protected link after unlock", protected.Slug, true, models.ClickAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, outcome, err := store.ConsumeClick(0, tt.slug, tt.unlocked)
+			if err != nil {
+				t.Fatalf("ConsumeClick failed: %v", err)
+			}
+			if outcome != tt.want {
+				t.Errorf("got outcome %d, want %d", outcome, tt.want)
+			}
+		})
+	}
+
+	if err := store.SoftDeleteLink(0, unlimited.Slug, userID); err != nil {
+		t.Fatalf("SoftDeleteLink failed: %v", err)
+	}
+	if _, outcome, _ := store.ConsumeClick(0, unlimited.Slug, false); outcome != models.ClickNotFound {
+		t.Errorf("deleted link: got outcome %d, want not found", outcome)
+	}
+}
+
+func testConsumeClickConcurrentLimit(t *testing.T, store repositories.Store) {
+	userID := CreateUser(t, store)
+
+	for _, limit := range []int{1, 3, 10} {
+		link := CreateLink(t, store, userID, func(link *models.Link) {
+			link.ClickLimit = sql.NullInt32{Int32: int32(limit), Valid: true}
+		})
+
+		const attempts = 40
+		outcomes := make(chan models.ClickOutcome, attempts)
+		start := make(chan struct{})
+		var wg sync.WaitGroup
+		for i := 0; i < attempts; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+				_, outcome, err := store.ConsumeClick(0, link.Slug, false)
+				if err != nil {
+					t.Errorf("ConsumeClick failed: %v", err)
+					return
+				}
+				outcomes <- outcome
+			}()
+		}
+		close(start)
+		wg.Wait()
+		close(outcomes)
+
+		counts := map[models.ClickOutcome]int{}
+		for outcome := range outcomes {
+			counts[outcome]++
+		}
+
+		if counts[models.ClickAllowed] != limit {
+			t.Errorf("limit %d: got %d allowed clicks", limit, counts[models.ClickAllowed])
+		}
+		if counts[models.ClickExhausted] != attempts-limit {
+			t.Errorf("limit %d: got %d exhausted clicks, want %d", limit, counts[models.ClickExhausted], attempts-limit)
+		}
+
+		stored, err := store.GetLinkBySlug(0, link.Slug)
+		if err != nil {
+			t.Fatalf("GetLinkBySlug failed: %v", err)
+		}
+		if stored.ClickCount != limit {
+			t.Errorf("limit %d: stored click_count is %d", limit, stored.ClickCount)
+		}
+	}
+}
+
+func testIncrementClickCount(t *testing.T, store repositories.Store) {
+	userID := CreateUser(t, store)
+	unrestricted := CreateLink(t, store, userID, nil)
+	limited := CreateLink(t, store, userID, func(link *models.Link) {
+		link.ClickLimit = sql.NullInt32{Int32: 10, Valid: true}
+	})
+
+	if counted, err := store.IncrementClickCount(unrestricted.ID); err != nil || !counted {
+		t.Errorf("IncrementClickCount on unrestricted link = %v, %v", counted, err)
+	}
+	if counted, err := store.IncrementClickCount(limited.ID); err != nil || counted {
+		t.Errorf("IncrementClickCount on limited link = %v, %v; want false", counted, err)
+	}
+
+	if link, err := store.GetLinkBySlug(0, unrestricted.Slug); err != nil || link.ClickCount != 1 {
+		t.Errorf("click count after increment = %d, %v", link.ClickCount, err)
+	}
+}
+
+func testUpdateLinkRecordsRevisionsAndRollsBack(t *testing.T, store repositories.Store) {
+	userID := CreateUser(t, store)
+	otherUserID := CreateUser(t, store)
+	slug := CreateLink(t, store, userID, nil).Slug
+
+	newURL := "https://example.com/fixed"
+	limit := 10
+	update := models.UpdateLinkRequest{TargetURL: &newURL, ClickLimit: &limit}
+
+	if _, _, err := store.UpdateLink(0, slug, otherUserID, models.RevisionActionUpdate, nil, update.Apply, models.LinkOrganization{}); !errors.Is(err, repositories.ErrLinkForbidden) {
+		t.Fatalf("update by another user: got %v, want ErrLinkForbidden", err)
+	}
+
+	link, revision, err := store.UpdateLink(0, slug, userID, models.RevisionActionUpdate, nil, update.Apply, models.LinkOrganization{})
+	if err != nil {
+		t.Fatalf("UpdateLink failed: %v", err)
+	}
+	if link.TargetURL != newURL || !link.ClickLimit.Valid || link.ClickLimit.Int32 != int32(limit) {
+		t.Fatalf("link not updated: %+v", link)
+	}
+	if revision == nil || revision.OldValues.TargetURL != "https://example.com" || revision.NewValues.TargetURL != newURL {
+		t.Fatalf("unexpected revision: %+v", revision)
+	}
+
+	// Applying the same change again must not record an empty revision
+	if _, unchanged, err := store.UpdateLink(0, slug, userID, models.RevisionActionUpdate, nil, update.Apply, models.LinkOrganization{}); err != nil || unchanged != nil {
+		t.Fatalf("no-op update: got revision %+v, err %v", unchanged, err)
+	}
+
+	restore := func(models.LinkState) models.LinkState { return revision.OldValues }
+	link, rollback, err := store.UpdateLink(0, slug, userID, models.RevisionActionRollback, &revision.ID, restore, models.LinkOrganization{})
+	if err != nil {
+		t.Fatalf("rollback failed: %v", err)
+	}
+	if link.TargetURL != "https://example.com" || link.ClickLimit.Valid {
+		t.Fatalf("link not rolled back: %+v", link)
+	}
+	if rollback.RevertedRevisionID == nil || *rollback.RevertedRevisionID != revision.ID {
+		t.Fatalf("rollback does not reference reverted revision: %+v", rollback)
+	}
+
+	revisions, err := store.GetLinkRevisions(link.ID)
+	if err != nil {
+		t.Fatalf("GetLinkRevisions failed: %v", err)
+	}
+	if len(revisions) != 2 || revisions[0].Action != models.RevisionActionRollback {
+		t.Fatalf("unexpected history: %+v", revisions)
+	}
+
+	if got, err := store.GetLinkRevision(link.ID, revision.ID); err != nil || got.NewValues.TargetURL != newURL {
+		t.Errorf("GetLinkRevision = %+v, %v", got, err)
+	}
+	if _, err := store.GetLinkRevision(link.ID, rollback.ID+1000); !errors.Is(err, repositories.ErrRevisionNotFound) {
+		t.Errorf("unknown revision: got %v, want ErrRevisionNotFound", err)
+	}
+}
+
+func testAccessLogs(t *testing.T, store repositories.Store) {
+	userID := CreateUser(t, store)
+	first := CreateLink(t, store, userID, nil)
+	second := CreateLink(t, store, userID, nil)
+	foreign := CreateLink(t, store, CreateUser(t, store), nil)
+
+	now := time.Now().Truncate(time.Second)
+	entries := []models.AccessLog{
+		{LinkID: int64(first.ID), AccessedAt: now.Add(-3 * time.Minute), IPAddress: "10.0.0.1", Country: "GB", ASN: 20712},
+		{LinkID: int64(first.ID), AccessedAt: now.Add(-1 * time.Minute), IPAddress: "10.0.0.2", EventType: models.EventUnlock},
+		{LinkID: int64(second.ID), AccessedAt: now.Add(-2 * time.Minute), IPAddress: "10.0.0.3"},
+		{LinkID: int64(foreign.ID), AccessedAt: now, IPAddress: "10.0.0.4"},
+		{LinkID: -1, AccessedAt: now, IPAddress: "10.0.0.5"}, // unknown links are skipped
+	}
+	if err := store.InsertAccessLogs(entries); err != nil {
+		t.Fatal(err)
+	}
+
+	workspaceID := int(first.WorkspaceID.Int32)
+	logs, err := store.GetAccessLogsByWorkspace(workspaceID, "", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 3 || logs[0].IPAddress != "10.0.0.2" || logs[2].IPAddress != "10.0.0.1" {
+		t.Fatalf("GetAccessLogsByWorkspace = %+v, want the workspace's three entries newest first", logs)
+	}
+	if logs[0].EventType != models.EventUnlock || logs[1].EventType != models.EventClick {
+		t.Errorf("event types = %q, %q", logs[0].EventType, logs[1].EventType)
+	}
+	if logs[2].Country != "GB" || logs[2].ASN != 20712 || logs[2].ID == 0 {
+		t.Errorf("stored entry = %+v", logs[2])
+	}
+
+	if logs, err := store.GetAccessLogsByWorkspace(workspaceID, strconv.Itoa(second.ID), 10); err != nil || len(logs) != 1 {
+		t.Errorf("GetAccessLogsByWorkspace filtered by link = %+v, %v", logs, err)
+	}
+	if logs, err := store.GetAccessLogsByLink(first.ID, 1); err != nil || len(logs) != 1 || logs[0].IPAddress != "10.0.0.2" {
+		t.Errorf("GetAccessLogsByLink with limit 1 = %+v, %v", logs, err)
+	}
+}
+
+func testLinkClickStats(t *testing.T, store repositories.Store) {
+	userID := CreateUser(t, store)
+	link := CreateLink(t, store, userID, nil)
+
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	entries := []models.AccessLog{
+		{AccessedAt: day.Add(1 * time.Hour), IPAddress: "10.0.0.1", Country: "GB", DeviceType: "mobile", Browser: "Safari", OS: "iOS", Referer: "https://www.Example.com/a?b=c"},
+		{AccessedAt: day.Add(2 * time.Hour), IPAddress: "10.0.0.1", Country: "GB", DeviceType: "desktop", Browser: "Chrome", OS: "Windows", Referer: "https://news.site.org"},
+		{AccessedAt: day.Add(50 * time.Hour), IPAddress: "10.0.0.2", Country: "DE", DeviceType: "desktop", Browser: "Chrome", OS: "Linux"},
+		{AccessedAt: day.Add(51 * time.Hour), IPAddress: "10.0.0.3", EventType: models.EventUnlock},
+	}
+	for i := range entries {
+		entries[i].LinkID = int64(link.ID)
+	}
+	if err := store.InsertAccessLogs(entries); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := store.GetLinkClickStats(link.ID, day, day.Add(72*time.Hour), models.StatsBucketDay, "UTC", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stats.TotalClicks != 3 || stats.UniqueVisitors != 2 {
+		t.Errorf("totals = %d clicks, %d visitors; want 3, 2", stats.TotalClicks, stats.UniqueVisitors)
+	}
+
+	wantSeries := []int64{2, 0, 1}
+	if len(stats.Series) != len(wantSeries) {
+		t.Fatalf("got %d buckets, want %d", len(stats.Series), len(wantSeries))
+	}
+	for i, want := range wantSeries {
+		if !stats.Series[i].Bucket.Equal(day.Add(time.Duration(i) * 24 * time.Hour)) {
+			t.Errorf("bucket %d starts at %v", i, stats.Series[i].Bucket)
+		}
+		if stats.Series[i].Clicks != want {
+			t.Errorf("bucket %d has %d clicks, want %d", i, stats.Series[i].Clicks, want)
+		}
+	}
+
+	wantReferrers := map[string]int64{"example.com": 1, "news.site.org": 1, "direct": 1}
+	if len(stats.Breakdowns.Referrers) != len(wantReferrers) {
+		t.Fatalf("referrers = %+v", stats.Breakdowns.Referrers)
+	}
+	for _, entry := range stats.Breakdowns.Referrers {
+		if wantReferrers[entry.Value] != entry.Clicks {
+			t.Errorf("referrer %q has %d clicks", entry.Value, entry.Clicks)
+		}
+	}
+
+	if got := stats.Breakdowns.Countries; len(got) != 2 || got[0] != (models.StatsBreakdownEntry{Value: "GB", Clicks: 2}) {
+		t.Errorf("countries = %+v", got)
+	}
+
+	// Weekly buckets start on Monday in the requested time zone, where the
+	// first two clicks still fall on Sunday evening
+	weekly, err := store.GetLinkClickStats(link.ID, day, day.Add(72*time.Hour), models.StatsBucketWeek, "America/New_York", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newYork, _ := time.LoadLocation("America/New_York")
+	wantStarts := []time.Time{time.Date(2026, 2, 23, 0, 0, 0, 0, newYork), time.Date(2026, 3, 2, 0, 0, 0, 0, newYork)}
+	if len(weekly.Series) != 2 || !weekly.Series[0].Bucket.Equal(wantStarts[0]) || !weekly.Series[1].Bucket.Equal(wantStarts[1]) ||
+		weekly.Series[0].Clicks != 2 || weekly.Series[1].Clicks != 1 {
+		t.Errorf("weekly series = %+v", weekly.Series)
+	}
+}
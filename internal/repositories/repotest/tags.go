@@ -0,0 +1,212 @@
+package repotest
+
+import (
+	"database/sql"
+	"errors"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"reflect"
+	"testing"
+)
+
+func testTagsAndFolders(t *testing.T, store repositories.Store) {
+	userID := CreateUser(t, store)
+	workspace, err := store.GetPersonalWorkspace(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := CreateLink(t, store, userID, func(l *models.Link) {
+		l.Tags = []string{"sale", "docs"}
+		l.Folder = sql.NullString{String: "Campaigns", Valid: true}
+		l.ClickCount = 3
+	})
+	if !reflect.DeepEqual(first.Tags, []string{"docs", "sale"}) || first.Folder.String != "Campaigns" {
+		t.Errorf("created link has tags %v and folder %v, want sorted tags and the folder", first.Tags, first.Folder)
+	}
+	second := CreateLink(t, store, userID, func(l *models.Link) {
+		l.Tags = []string{"sale"}
+		l.ClickCount = 2
+	})
+
+	got, err := store.GetLinkBySlug(0, first.Slug)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Tags, []string{"docs", "sale"}) || got.Folder.String != "Campaigns" {
+		t.Errorf("GetLinkBySlug returned tags %v and folder %v", got.Tags, got.Folder)
+	}
+
+	for _, tt := range []struct {
+		filter models.LinkFilter
+		want   []int
+	}{
+		{models.LinkFilter{Tags: []string{"sale"}}, []int{second.ID, first.ID}},
+		{models.LinkFilter{Tags: []string{"sale", "docs"}}, []int{first.ID}},
+		{models.LinkFilter{Tags: []string{"missing"}}, []int{}},
+		{models.LinkFilter{Folder: "Campaigns"}, []int{first.ID}},
+		{models.LinkFilter{Folder: "campaigns"}, []int{}},
+	} {
+		if ids := linkIDs(listLinks(t, store, workspace.ID, tt.filter)); !reflect.DeepEqual(ids, tt.want) {
+			t.Errorf("ListLinksByWorkspace(%+v) = %v, want %v", tt.filter, ids, tt.want)
+		}
+	}
+
+	tags, err := store.ListTags(workspace.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 2 || tags[0].Name != "docs" || tags[0].LinkCount != 1 || tags[0].ClickCount != 3 ||
+		tags[1].Name != "sale" || tags[1].LinkCount != 2 || tags[1].ClickCount != 5 {
+		t.Errorf("ListTags = %+v", tags)
+	}
+
+	// Setting tags replaces them; moving out of the folder keeps the folder listed
+	if err := store.SetLinkTags(first.ID, []string{"launch"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetLinkFolder(first.ID, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetLinkFolder(second.ID, "Archive"); err != nil {
+		t.Fatal(err)
+	}
+	got, err = store.GetLinkBySlug(0, first.Slug)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Tags, []string{"launch"}) || got.Folder.Valid {
+		t.Errorf("after changes the link has tags %v and folder %v", got.Tags, got.Folder)
+	}
+
+	folders, err := store.ListFolders(workspace.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(folders) != 2 || folders[0].Name != "Archive" || folders[0].LinkCount != 1 || folders[0].ClickCount != 2 ||
+		folders[1].Name != "Campaigns" || folders[1].LinkCount != 0 {
+		t.Errorf("ListFolders = %+v", folders)
+	}
+
+	// UpdateLink organises the link in the same step as editing it
+	newTags, folder, targetURL := []string{"q3", "launch"}, "Archive", "https://example.com/launch"
+	update := models.UpdateLinkRequest{TargetURL: &targetURL}
+	updated, revision, err := store.UpdateLink(0, first.Slug, userID, models.RevisionActionUpdate, nil, update.Apply,
+		models.LinkOrganization{Tags: &newTags, Folder: &folder})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revision == nil || updated.TargetURL != targetURL || !reflect.DeepEqual(updated.Tags, []string{"launch", "q3"}) || updated.Folder.String != "Archive" {
+		t.Errorf("UpdateLink returned %+v with revision %v", updated, revision)
+	}
+	folder = ""
+	updated, revision, err = store.UpdateLink(0, first.Slug, userID, models.RevisionActionUpdate, nil, update.Apply,
+		models.LinkOrganization{Folder: &folder})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revision != nil || updated.Folder.Valid || len(updated.Tags) != 2 {
+		t.Errorf("UpdateLink of the folder alone returned %+v with revision %v", updated, revision)
+	}
+
+	// Deleted links are not counted or organised
+	if err := store.SoftDeleteLink(0, second.Slug, userID); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetLinkTags(second.ID, []string{"sale"}); !errors.Is(err, repositories.ErrLinkNotFound) {
+		t.Errorf("SetLinkTags on a deleted link: %v, want ErrLinkNotFound", err)
+	}
+	if err := store.SetLinkFolder(second.ID, ""); !errors.Is(err, repositories.ErrLinkNotFound) {
+		t.Errorf("SetLinkFolder on a deleted link: %v, want ErrLinkNotFound", err)
+	}
+	tags, err = store.ListTags(workspace.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tag := range tags {
+		if tag.Name == "sale" && (tag.LinkCount != 0 || tag.ClickCount != 0) {
+			t.Errorf("tag of a deleted link counted: %+v", tag)
+		}
+	}
+
+	// Other workspaces do not see the tags
+	otherID := CreateUser(t, store)
+	other, err := store.GetPersonalWorkspace(otherID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tags, err := store.ListTags(other.ID); err != nil || len(tags) != 0 {
+		t.Errorf("ListTags of another workspace = %+v, %v", tags, err)
+	}
+}
+
+func testRenameAndMergeTags(t *testing.T, store repositories.Store) {
+	userID := CreateUser(t, store)
+	workspace, err := store.GetPersonalWorkspace(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	both := CreateLink(t, store, userID, func(l *models.Link) { l.Tags = []string{"promo", "promotion"} })
+	promo := CreateLink(t, store, userID, func(l *models.Link) { l.Tags = []string{"promo"} })
+	CreateLink(t, store, userID, func(l *models.Link) { l.Tags = []string{"news"} })
+
+	tags, err := store.ListTags(workspace.ID)
+	if err != nil || len(tags) != 3 {
+		t.Fatalf("ListTags = %+v, %v", tags, err)
+	}
+	byName := make(map[string]models.Tag)
+	for _, tag := range tags {
+		byName[tag.Name] = tag
+	}
+
+	if _, err := store.RenameTag(workspace.ID, byName["news"].ID, "promo"); !errors.Is(err, repositories.ErrTagTaken) {
+		t.Errorf("rename to a used name: %v, want ErrTagTaken", err)
+	}
+	renamed, err := store.RenameTag(workspace.ID, byName["news"].ID, "updates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if renamed.Name != "updates" || renamed.LinkCount != 1 {
+		t.Errorf("RenameTag = %+v", renamed)
+	}
+
+	otherID := CreateUser(t, store)
+	other, err := store.GetPersonalWorkspace(otherID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.RenameTag(other.ID, byName["promo"].ID, "mine"); !errors.Is(err, repositories.ErrTagNotFound) {
+		t.Errorf("rename from another workspace: %v, want ErrTagNotFound", err)
+	}
+	if _, err := store.MergeTags(workspace.ID, byName["promotion"].ID, 0); !errors.Is(err, repositories.ErrTagNotFound) {
+		t.Errorf("merge into an unknown tag: %v, want ErrTagNotFound", err)
+	}
+
+	// Merging moves the links to the target without duplicating its tag
+	merged, err := store.MergeTags(workspace.ID, byName["promotion"].ID, byName["promo"].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.ID != byName["promo"].ID || merged.LinkCount != 2 {
+		t.Errorf("MergeTags = %+v, want promo with two links", merged)
+	}
+	got, err := store.GetLinkBySlug(0, both.Slug)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Tags, []string{"promo"}) {
+		t.Errorf("merged link has tags %v, want [promo]", got.Tags)
+	}
+
+	tags, err = store.ListTags(workspace.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 2 || tags[0].Name != "promo" || tags[1].Name != "updates" {
+		t.Errorf("ListTags after merge = %+v", tags)
+	}
+	if ids := linkIDs(listLinks(t, store, workspace.ID, models.LinkFilter{Tags: []string{"promo"}})); !reflect.DeepEqual(ids, []int{promo.ID, both.ID}) {
+		t.Errorf("links tagged promo = %v, want %v", ids, []int{promo.ID, both.ID})
+	}
+}
@@ -0,0 +1,113 @@
+package repotest
+
+import (
+	"errors"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"testing"
+	"time"
+)
+
+// newRefreshToken builds an unsaved token with a random hash and family
+func newRefreshToken(t *testing.T, userID int, expiresIn time.Duration) models.RefreshToken {
+	return models.RefreshToken{
+		UserID:    userID,
+		FamilyID:  randomString(t, 32),
+		TokenHash: randomString(t, 64),
+		ExpiresAt: time.Now().Add(expiresIn),
+	}
+}
+
+func testRefreshTokenRotation(t *testing.T, store repositories.Store) {
+	userID := CreateUser(t, store)
+
+	first, err := store.CreateRefreshToken(newRefreshToken(t, userID, time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.ID == 0 {
+		t.Error("CreateRefreshToken did not set the ID")
+	}
+
+	// The rotated token inherits user and family from the presented one
+	next := newRefreshToken(t, 0, time.Hour)
+	second, err := store.RotateRefreshToken(first.TokenHash, next)
+	if err != nil {
+		t.Fatalf("RotateRefreshToken: %v", err)
+	}
+	if second.UserID != userID || second.FamilyID != first.FamilyID || second.TokenHash != next.TokenHash {
+		t.Errorf("rotated token = %+v, want user %d in family %s", second, userID, first.FamilyID)
+	}
+
+	if _, err := store.RotateRefreshToken(randomString(t, 64), newRefreshToken(t, 0, time.Hour)); !errors.Is(err, repositories.ErrRefreshTokenNotFound) {
+		t.Errorf("unknown token: got %v, want ErrRefreshTokenNotFound", err)
+	}
+
+	expired, err := store.CreateRefreshToken(newRefreshToken(t, userID, -time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.RotateRefreshToken(expired.TokenHash, newRefreshToken(t, 0, time.Hour)); !errors.Is(err, repositories.ErrRefreshTokenNotFound) {
+		t.Errorf("expired token: got %v, want ErrRefreshTokenNotFound", err)
+	}
+}
+
+func testRefreshTokenReuseRevokesFamily(t *testing.T, store repositories.Store) {
+	userID := CreateUser(t, store)
+
+	first, err := store.CreateRefreshToken(newRefreshToken(t, userID, time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := store.RotateRefreshToken(first.TokenHash, newRefreshToken(t, 0, time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Presenting the rotated token again looks like theft: the family is revoked
+	reused, err := store.RotateRefreshToken(first.TokenHash, newRefreshToken(t, 0, time.Hour))
+	if !errors.Is(err, repositories.ErrRefreshTokenReused) {
+		t.Fatalf("reused token: got %v, want ErrRefreshTokenReused", err)
+	}
+	if reused.UserID != userID {
+		t.Errorf("reused token user = %d, want %d", reused.UserID, userID)
+	}
+
+	if _, err := store.RotateRefreshToken(second.TokenHash, newRefreshToken(t, 0, time.Hour)); !errors.Is(err, repositories.ErrRefreshTokenReused) {
+		t.Errorf("token from revoked family: got %v, want ErrRefreshTokenReused", err)
+	}
+}
+
+func testRevokeRefreshTokenFamily(t *testing.T, store repositories.Store) {
+	userID := CreateUser(t, store)
+	otherUserID := CreateUser(t, store)
+
+	first, err := store.CreateRefreshToken(newRefreshToken(t, userID, time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := store.RotateRefreshToken(first.TokenHash, newRefreshToken(t, 0, time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	unrelated, err := store.CreateRefreshToken(newRefreshToken(t, userID, time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.RevokeRefreshTokenFamily(second.TokenHash, otherUserID); !errors.Is(err, repositories.ErrRefreshTokenNotFound) {
+		t.Errorf("revoking another user's token: got %v, want ErrRefreshTokenNotFound", err)
+	}
+
+	// Revoking through an older token of the family also revokes the newest one
+	if err := store.RevokeRefreshTokenFamily(first.TokenHash, userID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.RotateRefreshToken(second.TokenHash, newRefreshToken(t, 0, time.Hour)); !errors.Is(err, repositories.ErrRefreshTokenReused) {
+		t.Errorf("token after revocation: got %v, want ErrRefreshTokenReused", err)
+	}
+
+	if _, err := store.RotateRefreshToken(unrelated.TokenHash, newRefreshToken(t, 0, time.Hour)); err != nil {
+		t.Errorf("token from another family was revoked: %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package repotest
+
+import (
+	"errors"
+	"link-guardian/internal/repositories"
+	"testing"
+)
+
+func testTwoFactorEnrolment(t *testing.T, store repositories.Store) {
+	userID := CreateUser(t, store)
+
+	if _, err := store.GetTwoFactor(userID); !errors.Is(err, repositories.ErrTwoFactorNotFound) {
+		t.Fatalf("GetTwoFactor before enrolment: got %v, want ErrTwoFactorNotFound", err)
+	}
+	if err := store.EnableTwoFactor(userID, 1, nil); !errors.Is(err, repositories.ErrTwoFactorNotFound) {
+		t.Errorf("EnableTwoFactor without enrolment: got %v, want ErrTwoFactorNotFound", err)
+	}
+
+	// Starting again replaces a pending secret
+	if err := store.StartTwoFactorEnrolment(userID, "FIRSTSECRET"); err != nil {
+		t.Fatalf("StartTwoFactorEnrolment: %v", err)
+	}
+	if err := store.StartTwoFactorEnrolment(userID, "SECONDSECRET"); err != nil {
+		t.Fatalf("StartTwoFactorEnrolment again: %v", err)
+	}
+	pending, err := store.GetTwoFactor(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pending.Secret != "SECONDSECRET" || pending.Enabled() {
+		t.Errorf("pending enrolment = %+v, want the second secret and not enabled", pending)
+	}
+	if user, err := store.GetUserByID(userID); err != nil || user.TwoFactorEnabled {
+		t.Errorf("pending enrolment enabled two-factor authentication: %+v, %v", user, err)
+	}
+
+	hashes := []string{randomString(t, 64), randomString(t, 64)}
+	if err := store.EnableTwoFactor(userID, 100, hashes); err != nil {
+		t.Fatalf("EnableTwoFactor: %v", err)
+	}
+	enabled, err := store.GetTwoFactor(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !enabled.Enabled() || enabled.LastUsedStep != 100 || enabled.RecoveryCodesLeft != 2 {
+		t.Errorf("enabled enrolment = %+v, want enabled at step 100 with 2 recovery codes", enabled)
+	}
+	if user, err := store.GetUserByID(userID); err != nil || !user.TwoFactorEnabled {
+		t.Errorf("user after enabling = %+v, %v; want TwoFactorEnabled", user, err)
+	}
+
+	if err := store.StartTwoFactorEnrolment(userID, "THIRDSECRET"); !errors.Is(err, repositories.ErrTwoFactorEnabled) {
+		t.Errorf("StartTwoFactorEnrolment while enabled: got %v, want ErrTwoFactorEnabled", err)
+	}
+	if err := store.EnableTwoFactor(userID, 101, nil); !errors.Is(err, repositories.ErrTwoFactorNotFound) {
+		t.Errorf("EnableTwoFactor while enabled: got %v, want ErrTwoFactorNotFound", err)
+	}
+
+	if err := store.DisableTwoFactor(userID); err != nil {
+		t.Fatalf("DisableTwoFactor: %v", err)
+	}
+	if _, err := store.GetTwoFactor(userID); !errors.Is(err, repositories.ErrTwoFactorNotFound) {
+		t.Errorf("GetTwoFactor after disabling: got %v, want ErrTwoFactorNotFound", err)
+	}
+	if err := store.UseRecoveryCode(userID, hashes[0]); !errors.Is(err, repositories.ErrRecoveryCodeInvalid) {
+		t.Errorf("recovery code after disabling: got %v, want ErrRecoveryCodeInvalid", err)
+	}
+	if user, err := store.GetUserByID(userID); err != nil || user.TwoFactorEnabled {
+		t.Errorf("user after disabling = %+v, %v; want TwoFactorEnabled false", user, err)
+	}
+}
+
+func testTwoFactorCodesAreSingleUse(t *testing.T, store repositories.Store) {
+	userID := CreateUser(t, store)
+	otherUserID := CreateUser(t, store)
+
+	hash := randomString(t, 64)
+	for _, id := range []int{userID, otherUserID} {
+		if err := store.StartTwoFactorEnrolment(id, "SECRET"); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.EnableTwoFactor(id, 100, []string{hash}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Only steps after the newest accepted one are accepted
+	for _, tc := range []struct {
+		step int64
+		want bool
+	}{{100, false}, {99, false}, {101, true}, {101, false}, {103, true}, {102, false}} {
+		got, err := store.UseTOTPStep(userID, tc.step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.want {
+			t.Errorf("UseTOTPStep(%d) = %v, want %v", tc.step, got, tc.want)
+		}
+	}
+
+	if err := store.UseRecoveryCode(userID, hash); err != nil {
+		t.Fatalf("UseRecoveryCode: %v", err)
+	}
+	if err := store.UseRecoveryCode(userID, hash); !errors.Is(err, repositories.ErrRecoveryCodeInvalid) {
+		t.Errorf("reused recovery code: got %v, want ErrRecoveryCodeInvalid", err)
+	}
+	if err := store.UseRecoveryCode(userID, randomString(t, 64)); !errors.Is(err, repositories.ErrRecoveryCodeInvalid) {
+		t.Errorf("unknown recovery code: got %v, want ErrRecoveryCodeInvalid", err)
+	}
+
+	// Another user's identical code and steps are their own
+	if err := store.UseRecoveryCode(otherUserID, hash); err != nil {
+		t.Errorf("another user's recovery code was used up: %v", err)
+	}
+	if ok, err := store.UseTOTPStep(otherUserID, 101); err != nil || !ok {
+		t.Errorf("UseTOTPStep for another user = %v, %v; want true", ok, err)
+	}
+
+	enrolment, err := store.GetTwoFactor(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if enrolment.RecoveryCodesLeft != 0 || enrolment.LastUsedStep != 103 {
+		t.Errorf("enrolment = %+v, want no recovery codes left and step 103", enrolment)
+	}
+}
@@ -0,0 +1,245 @@
+package repotest
+
+import (
+	"errors"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"strings"
+	"testing"
+	"time"
+)
+
+// createInvitation stores an invitation with a random token hash to join
+// workspaceID as role, sent to email
+func createInvitation(t *testing.T, store repositories.Store, workspaceID int, email, role string, expiresIn time.Duration) models.WorkspaceInvitation {
+	t.Helper()
+
+	invitation, err := store.CreateWorkspaceInvitation(models.WorkspaceInvitation{
+		WorkspaceID: workspaceID,
+		Email:       email,
+		Role:        role,
+		TokenHash:   randomString(t, 64),
+		ExpiresAt:   time.Now().Add(expiresIn),
+	})
+	if err != nil {
+		t.Fatalf("CreateWorkspaceInvitation failed: %v", err)
+	}
+	return invitation
+}
+
+// AddWorkspaceMember adds userID to the workspace as role through an invitation
+func AddWorkspaceMember(t *testing.T, store repositories.Store, workspaceID, userID int, role string) {
+	t.Helper()
+
+	user, err := store.GetUserByID(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	invitation := createInvitation(t, store, workspaceID, user.Email, role, time.Hour)
+	if _, err := store.AcceptWorkspaceInvitation(invitation.TokenHash, userID, user.Email); err != nil {
+		t.Fatalf("AcceptWorkspaceInvitation failed: %v", err)
+	}
+}
+
+func testWorkspaces(t *testing.T, store repositories.Store) {
+	userID := CreateUser(t, store)
+	otherUserID := CreateUser(t, store)
+
+	personal, err := store.GetPersonalWorkspace(userID)
+	if err != nil {
+		t.Fatalf("new user has no personal workspace: %v", err)
+	}
+	if !personal.Personal || personal.Role != models.WorkspaceRoleOwner || personal.Name != models.PersonalWorkspaceName {
+		t.Errorf("GetPersonalWorkspace = %+v", personal)
+	}
+
+	team, err := store.CreateWorkspace("Team", userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if team.ID == 0 || team.ID == personal.ID || team.Personal || team.Role != models.WorkspaceRoleOwner {
+		t.Errorf("CreateWorkspace = %+v", team)
+	}
+
+	if got, err := store.GetWorkspace(team.ID, userID); err != nil || got.Name != "Team" || got.Role != models.WorkspaceRoleOwner {
+		t.Errorf("GetWorkspace = %+v, %v", got, err)
+	}
+	if _, err := store.GetWorkspace(team.ID, otherUserID); !errors.Is(err, repositories.ErrWorkspaceNotFound) {
+		t.Errorf("GetWorkspace by a non-member: got %v, want ErrWorkspaceNotFound", err)
+	}
+
+	AddWorkspaceMember(t, store, team.ID, otherUserID, models.WorkspaceRoleViewer)
+
+	workspaces, err := store.ListWorkspacesByUser(otherUserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(workspaces) != 2 || !workspaces[0].Personal || workspaces[1].ID != team.ID || workspaces[1].Role != models.WorkspaceRoleViewer {
+		t.Errorf("ListWorkspacesByUser = %+v, want the personal workspace then the team as viewer", workspaces)
+	}
+
+	members, err := store.ListWorkspaceMembers(team.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 2 || members[0].UserID != userID || members[0].Role != models.WorkspaceRoleOwner ||
+		members[1].UserID != otherUserID || members[1].Email == "" {
+		t.Errorf("ListWorkspaceMembers = %+v", members)
+	}
+
+	if err := store.SetWorkspaceMemberRole(team.ID, otherUserID, models.WorkspaceRoleEditor); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := store.GetWorkspace(team.ID, otherUserID); err != nil || got.Role != models.WorkspaceRoleEditor {
+		t.Errorf("after SetWorkspaceMemberRole GetWorkspace = %+v, %v", got, err)
+	}
+
+	if err := store.RemoveWorkspaceMember(team.ID, otherUserID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.GetWorkspace(team.ID, otherUserID); !errors.Is(err, repositories.ErrWorkspaceNotFound) {
+		t.Errorf("GetWorkspace after removal: got %v, want ErrWorkspaceNotFound", err)
+	}
+	if err := store.RemoveWorkspaceMember(team.ID, otherUserID); !errors.Is(err, repositories.ErrWorkspaceMemberNotFound) {
+		t.Errorf("second removal: got %v, want ErrWorkspaceMemberNotFound", err)
+	}
+}
+
+func testWorkspaceKeepsAnOwner(t *testing.T, store repositories.Store) {
+	ownerID := CreateUser(t, store)
+	otherUserID := CreateUser(t, store)
+	team, err := store.CreateWorkspace("Team", ownerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.SetWorkspaceMemberRole(team.ID, ownerID, models.WorkspaceRoleEditor); !errors.Is(err, repositories.ErrLastWorkspaceOwner) {
+		t.Errorf("demoting the only owner: got %v, want ErrLastWorkspaceOwner", err)
+	}
+	if err := store.RemoveWorkspaceMember(team.ID, ownerID); !errors.Is(err, repositories.ErrLastWorkspaceOwner) {
+		t.Errorf("removing the only owner: got %v, want ErrLastWorkspaceOwner", err)
+	}
+
+	// With a second owner either may step down
+	AddWorkspaceMember(t, store, team.ID, otherUserID, models.WorkspaceRoleOwner)
+	if err := store.SetWorkspaceMemberRole(team.ID, ownerID, models.WorkspaceRoleViewer); err != nil {
+		t.Errorf("demoting one of two owners: %v", err)
+	}
+	if err := store.RemoveWorkspaceMember(team.ID, otherUserID); !errors.Is(err, repositories.ErrLastWorkspaceOwner) {
+		t.Errorf("removing the remaining owner: got %v, want ErrLastWorkspaceOwner", err)
+	}
+}
+
+func testWorkspaceRolesAuthoriseLinks(t *testing.T, store repositories.Store) {
+	ownerID := CreateUser(t, store)
+	editorID := CreateUser(t, store)
+	viewerID := CreateUser(t, store)
+	team, err := store.CreateWorkspace("Team", ownerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	AddWorkspaceMember(t, store, team.ID, editorID, models.WorkspaceRoleEditor)
+	AddWorkspaceMember(t, store, team.ID, viewerID, models.WorkspaceRoleViewer)
+
+	link := CreateLink(t, store, ownerID, func(link *models.Link) {
+		link.WorkspaceID.Int32 = int32(team.ID)
+	})
+
+	if _, err := store.GetMemberLinkBySlug(0, link.Slug, viewerID, models.WorkspaceRoleViewer); err != nil {
+		t.Errorf("viewer reading a link: %v", err)
+	}
+	if _, err := store.GetMemberLinkBySlug(0, link.Slug, viewerID, models.WorkspaceRoleEditor); !errors.Is(err, repositories.ErrLinkForbidden) {
+		t.Errorf("viewer as editor: got %v, want ErrLinkForbidden", err)
+	}
+
+	newURL := "https://example.com/edited"
+	update := models.UpdateLinkRequest{TargetURL: &newURL}
+	if _, _, err := store.UpdateLink(0, link.Slug, viewerID, models.RevisionActionUpdate, nil, update.Apply, models.LinkOrganization{}); !errors.Is(err, repositories.ErrLinkForbidden) {
+		t.Errorf("update by a viewer: got %v, want ErrLinkForbidden", err)
+	}
+	if err := store.SoftDeleteLink(0, link.Slug, viewerID); !errors.Is(err, repositories.ErrLinkForbidden) {
+		t.Errorf("delete by a viewer: got %v, want ErrLinkForbidden", err)
+	}
+
+	updated, revision, err := store.UpdateLink(0, link.Slug, editorID, models.RevisionActionUpdate, nil, update.Apply, models.LinkOrganization{})
+	if err != nil {
+		t.Fatalf("update by an editor: %v", err)
+	}
+	if updated.TargetURL != newURL || revision == nil || revision.UserID == nil || *revision.UserID != editorID {
+		t.Errorf("editor update = %+v, revision %+v", updated, revision)
+	}
+
+	// Links created by a member are listed with the workspace, not the member's personal links
+	if links := listLinks(t, store, team.ID, models.LinkFilter{}); len(links) != 1 || links[0].ID != link.ID {
+		t.Errorf("ListLinksByWorkspace = %+v", links)
+	}
+
+	if err := store.SoftDeleteLink(0, link.Slug, editorID); err != nil {
+		t.Errorf("delete by an editor: %v", err)
+	}
+}
+
+func testWorkspaceInvitations(t *testing.T, store repositories.Store) {
+	ownerID := CreateUser(t, store)
+	inviteeID := CreateUser(t, store)
+	invitee, err := store.GetUserByID(inviteeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	team, err := store.CreateWorkspace("Team", ownerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expired := createInvitation(t, store, team.ID, invitee.Email, models.WorkspaceRoleEditor, -time.Minute)
+	revoked := createInvitation(t, store, team.ID, invitee.Email, models.WorkspaceRoleEditor, time.Hour)
+	pending := createInvitation(t, store, team.ID, invitee.Email, models.WorkspaceRoleViewer, time.Hour)
+	if pending.ID == 0 || pending.ID == revoked.ID {
+		t.Fatalf("invitations were not given distinct IDs: %d and %d", revoked.ID, pending.ID)
+	}
+
+	invitations, err := store.ListWorkspaceInvitations(team.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(invitations) != 2 || invitations[0].ID != pending.ID || invitations[1].ID != revoked.ID {
+		t.Errorf("ListWorkspaceInvitations = %+v, want the two unexpired ones newest first", invitations)
+	}
+
+	if err := store.RevokeWorkspaceInvitation(team.ID+1000, revoked.ID); !errors.Is(err, repositories.ErrInvitationNotFound) {
+		t.Errorf("revoking through another workspace: got %v, want ErrInvitationNotFound", err)
+	}
+	if err := store.RevokeWorkspaceInvitation(team.ID, revoked.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, hash := range map[string]string{"expired": expired.TokenHash, "revoked": revoked.TokenHash, "unknown": randomString(t, 64)} {
+		if _, err := store.AcceptWorkspaceInvitation(hash, inviteeID, invitee.Email); !errors.Is(err, repositories.ErrInvitationInvalid) {
+			t.Errorf("%s invitation: got %v, want ErrInvitationInvalid", name, err)
+		}
+	}
+	if _, err := store.AcceptWorkspaceInvitation(pending.TokenHash, ownerID, "someone-else@example.com"); !errors.Is(err, repositories.ErrInvitationInvalid) {
+		t.Errorf("invitation for another address: got %v, want ErrInvitationInvalid", err)
+	}
+
+	workspace, err := store.AcceptWorkspaceInvitation(pending.TokenHash, inviteeID, strings.ToUpper(invitee.Email))
+	if err != nil {
+		t.Fatalf("AcceptWorkspaceInvitation: %v", err)
+	}
+	if workspace.ID != team.ID || workspace.Role != models.WorkspaceRoleViewer {
+		t.Errorf("accepted workspace = %+v", workspace)
+	}
+	if _, err := store.AcceptWorkspaceInvitation(pending.TokenHash, inviteeID, invitee.Email); !errors.Is(err, repositories.ErrInvitationInvalid) {
+		t.Errorf("reused invitation: got %v, want ErrInvitationInvalid", err)
+	}
+	if invitations, err := store.ListWorkspaceInvitations(team.ID); err != nil || len(invitations) != 0 {
+		t.Errorf("invitations after accepting = %+v, %v", invitations, err)
+	}
+
+	// A later invitation promotes the member but never demotes them
+	AddWorkspaceMember(t, store, team.ID, inviteeID, models.WorkspaceRoleEditor)
+	AddWorkspaceMember(t, store, team.ID, inviteeID, models.WorkspaceRoleViewer)
+	if got, err := store.GetWorkspace(team.ID, inviteeID); err != nil || got.Role != models.WorkspaceRoleEditor {
+		t.Errorf("role after further invitations = %+v, %v; want editor", got, err)
+	}
+}
@@ -1,25 +1,63 @@
 package auth
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// APIKeyPrefix starts every personal API key, which tells them apart from JWTs
+const APIKeyPrefix = "lg_"
+
+// apiKeyDisplayLength is how many leading characters of an API key are
+// stored in clear to identify it
+const apiKeyDisplayLength = len(APIKeyPrefix) + 8
+
+// accessTokenType marks JWTs that authenticate API requests, as opposed to
+// link unlock tokens signed with the same secret
+const accessTokenType = "access"
+
 // AuthService handles authentication operations
 type AuthService struct {
-	jwtSecret []byte
+	jwtSecret  []byte
+	accessTTL  time.Duration
+	refreshTTL time.Duration
 }
 
-// NewAuthService creates a new authentication service
-func NewAuthService(jwtSecret string) *AuthService {
+// AccessToken is a signed access token together with its ID and expiry
+type AccessToken struct {
+	Token     string
+	ID        string // jti claim, used to revoke the token before it expires
+	ExpiresAt time.Time
+}
+
+// NewAuthService creates a new authentication service issuing access tokens
+// valid for accessTTL and refresh tokens valid for refreshTTL
+func NewAuthService(jwtSecret string, accessTTL, refreshTTL time.Duration) *AuthService {
 	return &AuthService{
-		jwtSecret: []byte(jwtSecret),
+		jwtSecret:  []byte(jwtSecret),
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
 	}
 }
 
+// AccessTokenTTL returns how long access tokens are valid
+func (a *AuthService) AccessTokenTTL() time.Duration {
+	return a.accessTTL
+}
+
+// RefreshTokenTTL returns how long refresh tokens are valid
+func (a *AuthService) RefreshTokenTTL() time.Duration {
+	return a.refreshTTL
+}
+
 // HashPassword hashes a password using bcrypt
 func (a *AuthService) HashPassword(password string) (string, error) {
 	if len(password) < 8 {
@@ -39,22 +77,114 @@ func (a *AuthService) VerifyPassword(password, hashedPassword string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
 }
 
-// GenerateJWTToken generates a JWT token for a user
-func (a *AuthService) GenerateJWTToken(userID int, username string) (string, error) {
+// GenerateAccessToken generates a short-lived JWT access token for a user
+func (a *AuthService) GenerateAccessToken(userID int, username string) (AccessToken, error) {
+	tokenID, err := newOpaqueToken()
+	if err != nil {
+		return AccessToken{}, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(a.accessTTL)
 	claims := jwt.MapClaims{
 		"user_id":  userID,
 		"username": username,
-		"exp":      time.Now().Add(time.Hour * 24).Unix(), // 24 hour expiration
-		"iat":      time.Now().Unix(),
+		"typ":      accessTokenType,
+		"jti":      tokenID,
+		"exp":      expiresAt.Unix(),
+		"iat":      now.Unix(),
+		"iat_ms":   now.UnixMilli(), // Revocations cut off tokens by issue time, which iat has in seconds
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	tokenString, err := token.SignedString(a.jwtSecret)
 	if err != nil {
+		return AccessToken{}, fmt.Errorf("failed to generate token: %v", err)
+	}
+
+	return AccessToken{Token: tokenString, ID: tokenID, ExpiresAt: time.Unix(expiresAt.Unix(), 0)}, nil
+}
+
+// AccessTokenIssuedAt returns when the access token with claims was issued.
+// Tokens from before issue times had milliseconds report the start of their
+// second.
+func AccessTokenIssuedAt(claims jwt.MapClaims) time.Time {
+	if millis, ok := claims["iat_ms"].(float64); ok {
+		return time.UnixMilli(int64(millis))
+	}
+	if iat, err := claims.GetIssuedAt(); err == nil && iat != nil {
+		return iat.Time
+	}
+	return time.Time{}
+}
+
+// ValidateAccessToken validates an access token and returns its claims. It
+// rejects other tokens signed with the same secret and tokens without an ID.
+func (a *AuthService) ValidateAccessToken(tokenString string) (jwt.MapClaims, error) {
+	claims, err := a.ValidateJWTToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims["typ"] != accessTokenType {
+		return nil, fmt.Errorf("not an access token")
+	}
+	if jti, ok := claims["jti"].(string); !ok || jti == "" {
+		return nil, fmt.Errorf("access token has no ID")
+	}
+
+	return claims, nil
+}
+
+// opaqueTokenBytes is the entropy of opaque tokens and API keys
+const opaqueTokenBytes = 32
+
+// newOpaqueToken returns a random URL-safe string
+func newOpaqueToken() (string, error) {
+	b := make([]byte, opaqueTokenBytes)
+	if _, err := rand.Read(b); err != nil {
 		return "", fmt.Errorf("failed to generate token: %v", err)
 	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
 
-	return tokenString, nil
+// GenerateOpaqueToken generates a random token, such as a refresh, password
+// reset or invitation token, and the hash under which it is stored
+func (a *AuthService) GenerateOpaqueToken() (token, hash string, err error) {
+	token, err = newOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	return token, a.HashOpaqueToken(token), nil
+}
+
+// HashOpaqueToken returns the SHA-256 hex digest an opaque token or API key
+// is stored and looked up by
+func (a *AuthService) HashOpaqueToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateAPIKey generates a personal API key together with the prefix shown
+// to its owner and the hash under which it is stored
+func (a *AuthService) GenerateAPIKey() (key, prefix, hash string, err error) {
+	token, err := newOpaqueToken()
+	if err != nil {
+		return "", "", "", err
+	}
+
+	key = APIKeyPrefix + token
+	return key, key[:apiKeyDisplayLength], a.HashOpaqueToken(key), nil
+}
+
+// IsAPIKey reports whether a bearer credential is an API key rather than a JWT
+func (a *AuthService) IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, APIKeyPrefix)
+}
+
+// NewTokenFamilyID generates the ID shared by refresh tokens rotated from one login
+func (a *AuthService) NewTokenFamilyID() (string, error) {
+	return newOpaqueToken()
 }
 
 // ValidateJWTToken validates a JWT token and returns the claims
@@ -77,6 +207,112 @@ func (a *AuthService) ValidateJWTToken(tokenString string) (jwt.MapClaims, error
 	return nil, fmt.Errorf("invalid token")
 }
 
+// GenerateLinkUnlockToken generates a short-lived token proving that the
+// password of the link identified by slug was entered correctly
+func (a *AuthService) GenerateLinkUnlockToken(slug string, ttl time.Duration) (string, error) {
+	claims := jwt.MapClaims{
+		"purpose": "link_unlock",
+		"slug":    slug,
+		"exp":     time.Now().Add(ttl).Unix(),
+		"iat":     time.Now().Unix(),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString(a.jwtSecret)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate unlock token: %v", err)
+	}
+
+	return tokenString, nil
+}
+
+// ValidateLinkUnlockToken checks that an unlock token is valid for slug
+func (a *AuthService) ValidateLinkUnlockToken(tokenString, slug string) error {
+	claims, err := a.ValidateJWTToken(tokenString)
+	if err != nil {
+		return err
+	}
+
+	if claims["purpose"] != "link_unlock" || claims["slug"] != slug {
+		return fmt.Errorf("unlock token is not valid for this link")
+	}
+
+	return nil
+}
+
+// GenerateEmailVerificationToken generates a signed token proving that whoever
+// holds it received mail sent to email, the address of the user with ID userID
+func (a *AuthService) GenerateEmailVerificationToken(userID int, email string, ttl time.Duration) (string, error) {
+	claims := jwt.MapClaims{
+		"purpose": "email_verification",
+		"user_id": userID,
+		"email":   strings.ToLower(email),
+		"exp":     time.Now().Add(ttl).Unix(),
+		"iat":     time.Now().Unix(),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString(a.jwtSecret)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate verification token: %v", err)
+	}
+
+	return tokenString, nil
+}
+
+// ValidateEmailVerificationToken checks a verification token and returns the
+// user ID and email address it was issued for
+func (a *AuthService) ValidateEmailVerificationToken(tokenString string) (int, string, error) {
+	claims, err := a.ValidateJWTToken(tokenString)
+	if err != nil {
+		return 0, "", err
+	}
+
+	userID, okID := claims["user_id"].(float64)
+	email, okEmail := claims["email"].(string)
+	if claims["purpose"] != "email_verification" || !okID || !okEmail {
+		return 0, "", fmt.Errorf("not an email verification token")
+	}
+
+	return int(userID), email, nil
+}
+
+// GenerateTwoFactorChallenge generates a short-lived token proving that the
+// user with ID userID entered the correct password and must now enter a
+// second factor to log in
+func (a *AuthService) GenerateTwoFactorChallenge(userID int, ttl time.Duration) (string, error) {
+	claims := jwt.MapClaims{
+		"purpose": "2fa_challenge",
+		"user_id": userID,
+		"exp":     time.Now().Add(ttl).Unix(),
+		"iat":     time.Now().Unix(),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString(a.jwtSecret)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate two-factor challenge: %v", err)
+	}
+
+	return tokenString, nil
+}
+
+// ValidateTwoFactorChallenge checks a two-factor challenge token and returns
+// the user ID it was issued for
+func (a *AuthService) ValidateTwoFactorChallenge(tokenString string) (int, error) {
+	claims, err := a.ValidateJWTToken(tokenString)
+	if err != nil {
+		return 0, err
+	}
+
+	userID, ok := claims["user_id"].(float64)
+	if claims["purpose"] != "2fa_challenge" || !ok {
+		return 0, fmt.Errorf("not a two-factor challenge token")
+	}
+
+	return int(userID), nil
+}
+
 // ValidatePassword checks if password meets security requirements
 func (a *AuthService) ValidatePassword(password string) error {
 	if len(password) < 8 {
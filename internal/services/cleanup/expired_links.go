@@ -1,115 +1,268 @@
 package cleanup
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
+	"sync"
 	"time"
 )
 
+// advisoryLockName identifies the cleanup sweep lock shared by all replicas
+const advisoryLockName = "link-guardian:expired-link-cleanup"
+
+// RunResult describes a single cleanup run
+type RunResult struct {
+	StartedAt        time.Time     `json:"started_at"`
+	Duration         time.Duration `json:"duration_ns"`
+	Skipped          bool          `json:"skipped"` // Another replica held the lock
+	ExpiredLinks     int64         `json:"expired_links"`
+	ExhaustedLinks   int64         `json:"exhausted_links"`
+	PurgedLinks      int64         `json:"purged_links"`
+	PurgedAccessLogs int64         `json:"purged_access_logs"`
+	Error            string        `json:"error,omitempty"`
+}
+
+// Totals accumulates results across runs since the service was created
+type Totals struct {
+	Runs             int64 `json:"runs"`
+	SkippedRuns      int64 `json:"skipped_runs"`
+	FailedRuns       int64 `json:"failed_runs"`
+	ExpiredLinks     int64 `json:"expired_links"`
+	ExhaustedLinks   int64 `json:"exhausted_links"`
+	PurgedLinks      int64 `json:"purged_links"`
+	PurgedAccessLogs int64 `json:"purged_access_logs"`
+}
+
+// Status is a snapshot of the service state for the status endpoint and metrics
+type Status struct {
+	Running   bool       `json:"running"`
+	Interval  string     `json:"interval"`
+	Retention string     `json:"retention"`
+	LastRun   *RunResult `json:"last_run,omitempty"`
+	NextRunAt *time.Time `json:"next_run_at,omitempty"`
+	Totals    Totals     `json:"totals"`
+}
+
 type ExpiredLinkCleanupService struct {
-	db            *sql.DB
-	interval      time.Duration
-	stopChan      chan struct{}
-	isRunning     bool
-	cleanupBefore time.Time
+	db        *sql.DB
+	interval  time.Duration
+	retention time.Duration
+
+	mu        sync.Mutex
+	isRunning bool
+	cancel    context.CancelFunc
+	done      chan struct{}
+	lastRun   *RunResult
+	nextRunAt time.Time
+	totals    Totals
 }
 
-func NewExpiredLinkCleanupService(db *sql.DB, interval time.Duration) *ExpiredLinkCleanupService {
+// NewExpiredLinkCleanupService creates a service that soft-deletes expired
+// and exhausted links every interval, and hard-deletes links together with
+// their access logs once they have been soft-deleted for longer than retention.
+// A retention of zero disables the hard-delete phase.
+func NewExpiredLinkCleanupService(db *sql.DB, interval, retention time.Duration) *ExpiredLinkCleanupService {
 	return &ExpiredLinkCleanupService{
 		db:        db,
 		interval:  interval,
-		stopChan:  make(chan struct{}),
-		isRunning: false,
+		retention: retention,
 	}
 }
 
 func (s *ExpiredLinkCleanupService) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	if s.isRunning {
 		return
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
 	s.isRunning = true
-	go s.runCleanupLoop()
+	go s.runCleanupLoop(ctx, s.done)
 	log.Println("Expired link cleanup service started")
 }
 
-func (s *ExpiredLinkCleanupService) Stop() {
+// Stop cancels any sweep in progress and waits for the loop to exit or ctx to be done
+func (s *ExpiredLinkCleanupService) Stop(ctx context.Context) error {
+	s.mu.Lock()
 	if !s.isRunning {
-		return
+		s.mu.Unlock()
+		return nil
 	}
-
-	s.stopChan <- struct{}{}
 	s.isRunning = false
-	log.Println("Expired link cleanup service stopped")
+	s.cancel()
+	done := s.done
+	s.mu.Unlock()
+
+	select {
+	case <-done:
+		log.Println("Expired link cleanup service stopped")
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("cleanup service did not stop in time: %w", ctx.Err())
+	}
+}
+
+// Status returns the current state and the results of the last run
+func (s *ExpiredLinkCleanupService) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := Status{
+		Running:   s.isRunning,
+		Interval:  s.interval.String(),
+		Retention: s.retention.String(),
+		Totals:    s.totals,
+	}
+	if s.lastRun != nil {
+		lastRun := *s.lastRun
+		status.LastRun = &lastRun
+	}
+	if s.isRunning && !s.nextRunAt.IsZero() {
+		nextRunAt := s.nextRunAt
+		status.NextRunAt = &nextRunAt
+	}
+	return status
 }
 
-func (s *ExpiredLinkCleanupService) runCleanupLoop() {
+func (s *ExpiredLinkCleanupService) runCleanupLoop(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
 	ticker := time.NewTicker(s.interval)
 	defer ticker.Stop()
 
-	s.cleanupExpiredLinks()
+	s.RunOnce(ctx)
 
 	for {
 		select {
 		case <-ticker.C:
-			s.cleanupExpiredLinks()
-		case <-s.stopChan:
+			s.RunOnce(ctx)
+		case <-ctx.Done():
 			return
 		}
 	}
 }
 
-func (s *ExpiredLinkCleanupService) cleanupExpiredLinks() {
+// RunOnce performs a single cleanup run and records its result
+func (s *ExpiredLinkCleanupService) RunOnce(ctx context.Context) RunResult {
+	result := s.cleanupExpiredLinks(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastRun = &result
+	s.nextRunAt = result.StartedAt.Add(s.interval)
+	s.totals.Runs++
+	switch {
+	case result.Error != "":
+		s.totals.FailedRuns++
+	case result.Skipped:
+		s.totals.SkippedRuns++
+	default:
+		s.totals.ExpiredLinks += result.ExpiredLinks
+		s.totals.ExhaustedLinks += result.ExhaustedLinks
+		s.totals.PurgedLinks += result.PurgedLinks
+		s.totals.PurgedAccessLogs += result.PurgedAccessLogs
+	}
+
+	return result
+}
+
+func (s *ExpiredLinkCleanupService) cleanupExpiredLinks(ctx context.Context) RunResult {
 	log.Println("Running expired link cleanup...")
 
 	now := time.Now()
+	result := RunResult{StartedAt: now}
+	fail := func(format string, err error) RunResult {
+		log.Printf(format, err)
+		result.Error = err.Error()
+		result.Duration = time.Since(now)
+		return result
+	}
 
-	tx, err := s.db.Begin()
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		log.Printf("Error starting transaction for link cleanup: %v\n", err)
-		return
+		return fail("Error starting transaction for link cleanup: %v\n", err)
+	}
+	defer tx.Rollback()
+
+	// Only one replica sweeps at a time; the lock is released when the transaction ends
+	var locked bool
+	if err := tx.QueryRowContext(ctx, "SELECT pg_try_advisory_xact_lock(hashtext($1))", advisoryLockName).Scan(&locked); err != nil {
+		return fail("Error acquiring link cleanup lock: %v\n", err)
+	}
+	if !locked {
+		log.Println("Skipping link cleanup: another instance is running it")
+		result.Skipped = true
+		result.Duration = time.Since(now)
+		return result
 	}
 
 	timeExpiryQuery := `
-		UPDATE links 
-		SET deleted_at = $1 
-		WHERE 
-			(expires_at IS NOT NULL AND expires_at < $2) AND 
+		UPDATE links
+		SET deleted_at = $1
+		WHERE
+			(expires_at IS NOT NULL AND expires_at < $2) AND
 			deleted_at IS NULL
 	`
-	timeResult, err := tx.Exec(timeExpiryQuery, now, now)
+	timeResult, err := tx.ExecContext(ctx, timeExpiryQuery, now, now)
 	if err != nil {
-		tx.Rollback()
-		log.Printf("Error cleaning up time-expired links: %v\n", err)
-		return
+		return fail("Error cleaning up time-expired links: %v\n", err)
 	}
 
-	timeRowsAffected, _ := timeResult.RowsAffected()
+	result.ExpiredLinks, _ = timeResult.RowsAffected()
 
 	clickExpiryQuery := `
-		UPDATE links 
-		SET deleted_at = $1 
-		WHERE 
-			(click_limit IS NOT NULL AND click_count >= click_limit) AND 
+		UPDATE links
+		SET deleted_at = $1
+		WHERE
+			(click_limit IS NOT NULL AND click_count >= click_limit) AND
 			deleted_at IS NULL
 	`
-	clickResult, err := tx.Exec(clickExpiryQuery, now)
+	clickResult, err := tx.ExecContext(ctx, clickExpiryQuery, now)
 	if err != nil {
-		tx.Rollback()
-		log.Printf("Error cleaning up click-limited links: %v\n", err)
-		return
+		return fail("Error cleaning up click-limited links: %v\n", err)
 	}
 
-	clickRowsAffected, _ := clickResult.RowsAffected()
+	result.ExhaustedLinks, _ = clickResult.RowsAffected()
+
+	if s.retention > 0 {
+		purgeBefore := now.Add(-s.retention)
+
+		accessLogsQuery := `
+			DELETE FROM access_logs
+			WHERE link_id IN (
+				SELECT id FROM links WHERE deleted_at IS NOT NULL AND deleted_at < $1
+			)
+		`
+		logsResult, err := tx.ExecContext(ctx, accessLogsQuery, purgeBefore)
+		if err != nil {
+			return fail("Error purging access logs of deleted links: %v\n", err)
+		}
+
+		result.PurgedAccessLogs, _ = logsResult.RowsAffected()
+
+		linksQuery := "DELETE FROM links WHERE deleted_at IS NOT NULL AND deleted_at < $1"
+		linksResult, err := tx.ExecContext(ctx, linksQuery, purgeBefore)
+		if err != nil {
+			return fail("Error purging deleted links: %v\n", err)
+		}
+
+		result.PurgedLinks, _ = linksResult.RowsAffected()
+	}
 
 	if err := tx.Commit(); err != nil {
-		log.Printf("Error committing link cleanup transaction: %v\n", err)
-		return
+		return fail("Error committing link cleanup transaction: %v\n", err)
 	}
 
-	log.Printf("Cleanup complete: %d time-expired links and %d click-limited links marked as deleted\n",
-		timeRowsAffected, clickRowsAffected)
+	log.Printf("Cleanup complete: %d time-expired links and %d click-limited links marked as deleted, "+
+		"%d deleted links and %d access logs purged\n",
+		result.ExpiredLinks, result.ExhaustedLinks, result.PurgedLinks, result.PurgedAccessLogs)
+	result.Duration = time.Since(now)
+	return result
 }
 
 func (s *ExpiredLinkCleanupService) GetExpiredLinkCount() (int, error) {
@@ -117,10 +270,10 @@ func (s *ExpiredLinkCleanupService) GetExpiredLinkCount() (int, error) {
 	now := time.Now()
 
 	query := `
-		SELECT COUNT(*) FROM links 
-		WHERE 
-			((expires_at IS NOT NULL AND expires_at < $1) OR 
-			(click_limit IS NOT NULL AND click_count >= click_limit)) AND 
+		SELECT COUNT(*) FROM links
+		WHERE
+			((expires_at IS NOT NULL AND expires_at < $1) OR
+			(click_limit IS NOT NULL AND click_count >= click_limit)) AND
 			deleted_at IS NULL
 	`
 
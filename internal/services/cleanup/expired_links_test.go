@@ -0,0 +1,130 @@
+package cleanup
+
+import (
+	"context"
+	"database/sql"
+	"link-guardian/internal/testutil/pgtest"
+	"testing"
+	"time"
+)
+
+func insertLink(t *testing.T, conn *sql.DB, userID int, deletedAt, expiresAt sql.NullTime) int {
+	t.Helper()
+
+	var id int
+	err := conn.QueryRow(`INSERT INTO links (slug, target_url, expires_at, deleted_at, user_id)
+		VALUES ($1, 'https://example.com', $2, $3, $4) RETURNING id`,
+		"c"+pgtest.RandomString(t, 12), expiresAt, deletedAt, userID).Scan(&id)
+	if err != nil {
+		t.Fatalf("failed to insert link: %v", err)
+	}
+
+	if _, err := conn.Exec(`INSERT INTO access_logs (link_id, ip_address) VALUES ($1, '10.0.0.1'), ($1, '10.0.0.2')`, id); err != nil {
+		t.Fatalf("failed to insert access logs: %v", err)
+	}
+	return id
+}
+
+func linkExists(t *testing.T, conn *sql.DB, id int) (exists, deleted bool) {
+	t.Helper()
+
+	var deletedAt sql.NullTime
+	err := conn.QueryRow("SELECT deleted_at FROM links WHERE id = $1", id).Scan(&deletedAt)
+	if err == sql.ErrNoRows {
+		return false, false
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return true, deletedAt.Valid
+}
+
+func TestRunOnceSoftDeletesAndPurges(t *testing.T) {
+	conn := pgtest.Open(t)
+	userID := pgtest.CreateUser(t, conn)
+	now := time.Now()
+
+	active := insertLink(t, conn, userID, sql.NullTime{}, sql.NullTime{})
+	expired := insertLink(t, conn, userID, sql.NullTime{}, sql.NullTime{Time: now.Add(-time.Hour), Valid: true})
+	recentlyDeleted := insertLink(t, conn, userID, sql.NullTime{Time: now.Add(-24 * time.Hour), Valid: true}, sql.NullTime{})
+	longDeleted := insertLink(t, conn, userID, sql.NullTime{Time: now.Add(-40 * 24 * time.Hour), Valid: true}, sql.NullTime{})
+
+	service := NewExpiredLinkCleanupService(conn, time.Hour, 30*24*time.Hour)
+	result := service.RunOnce(context.Background())
+	if result.Error != "" || result.Skipped {
+		t.Fatalf("RunOnce = %+v", result)
+	}
+	if result.ExpiredLinks < 1 || result.PurgedLinks < 1 || result.PurgedAccessLogs < 2 {
+		t.Errorf("RunOnce counts = %+v", result)
+	}
+
+	if exists, deleted := linkExists(t, conn, active); !exists || deleted {
+		t.Error("active link was touched")
+	}
+	if exists, deleted := linkExists(t, conn, expired); !exists || !deleted {
+		t.Error("expired link was not soft-deleted")
+	}
+	if exists, _ := linkExists(t, conn, recentlyDeleted); !exists {
+		t.Error("link inside the retention period was purged")
+	}
+	if exists, _ := linkExists(t, conn, longDeleted); exists {
+		t.Error("link past the retention period was not purged")
+	}
+
+	var logs int
+	if err := conn.QueryRow("SELECT COUNT(*) FROM access_logs WHERE link_id = $1", longDeleted).Scan(&logs); err != nil {
+		t.Fatal(err)
+	}
+	if logs != 0 {
+		t.Errorf("%d access logs of the purged link remain", logs)
+	}
+
+	status := service.Status()
+	if status.LastRun == nil || status.Totals.Runs != 1 {
+		t.Errorf("Status = %+v", status)
+	}
+}
+
+func TestRunOnceSkipsWhileAnotherReplicaHoldsTheLock(t *testing.T) {
+	conn := pgtest.Open(t)
+
+	tx, err := conn.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext($1))", advisoryLockName); err != nil {
+		t.Fatal(err)
+	}
+
+	service := NewExpiredLinkCleanupService(conn, time.Hour, 0)
+	result := service.RunOnce(context.Background())
+	if !result.Skipped {
+		t.Errorf("RunOnce = %+v, want skipped", result)
+	}
+	if service.Status().Totals.SkippedRuns != 1 {
+		t.Errorf("skipped runs = %d, want 1", service.Status().Totals.SkippedRuns)
+	}
+}
+
+func TestStartStop(t *testing.T) {
+	conn := pgtest.Open(t)
+
+	service := NewExpiredLinkCleanupService(conn, time.Hour, 0)
+	for i := 0; i < 2; i++ {
+		service.Start()
+		service.Start()
+		if !service.Status().Running {
+			t.Fatal("service is not running after Start")
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := service.Stop(ctx); err != nil {
+			t.Fatal(err)
+		}
+		cancel()
+		if err := service.Stop(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
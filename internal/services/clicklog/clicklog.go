@@ -0,0 +1,445 @@
+package clicklog
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"link-guardian/internal/models"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy decides what happens to an event when the queue is full
+type OverflowPolicy string
+
+const (
+	// OverflowDrop discards the event
+	OverflowDrop OverflowPolicy = "drop"
+	// OverflowBlock waits for queue space until the request is cancelled
+	OverflowBlock OverflowPolicy = "block"
+	// OverflowSpill appends the event to a spill file that is replayed on the next start
+	OverflowSpill OverflowPolicy = "spill"
+)
+
+var (
+	// ErrQueueFull is returned when an event was dropped because the queue is full
+	ErrQueueFull = errors.New("click log queue is full")
+	// ErrClosed is returned for events enqueued after the pipeline was closed
+	ErrClosed = errors.New("click log pipeline is closed")
+)
+
+// Sink writes a batch of access log entries to storage
+type Sink func(logs []models.AccessLog) error
+
+// Enricher fills derived fields of an access log entry before it is written
+type Enricher func(entry *models.AccessLog)
+
+// Options configures a Pipeline
+type Options struct {
+	QueueSize     int
+	Workers       int
+	BatchSize     int
+	FlushInterval time.Duration
+	Overflow      OverflowPolicy
+	// SpillPath is the spill file of the spill policy. With any policy,
+	// events still queued when Close gives up are spilled there.
+	SpillPath string
+	Enrich    Enricher
+}
+
+// Stats is a snapshot of the pipeline counters
+type Stats struct {
+	QueueDepth    int    `json:"queue_depth"`
+	QueueCapacity int    `json:"queue_capacity"`
+	Enqueued      uint64 `json:"enqueued"`
+	Written       uint64 `json:"written"`
+	Dropped       uint64 `json:"dropped"`
+	Spilled       uint64 `json:"spilled"`
+	Replayed      uint64 `json:"replayed"`
+	Batches       uint64 `json:"batches"`
+	FailedBatches uint64 `json:"failed_batches"`
+}
+
+// Pipeline queues access log events in memory and writes them in batches
+// from background workers, keeping database writes off the redirect path
+type Pipeline struct {
+	sink    Sink
+	opts    Options
+	queue   chan models.AccessLog
+	wg      sync.WaitGroup
+	mu      sync.RWMutex
+	closed  bool
+	started bool
+	spillMu sync.Mutex
+	// timedOut is set once Close gave up waiting; batches that fail to
+	// write after that are spilled rather than dropped
+	timedOut atomic.Bool
+
+	enqueued      atomic.Uint64
+	written       atomic.Uint64
+	dropped       atomic.Uint64
+	spilled       atomic.Uint64
+	replayed      atomic.Uint64
+	batches       atomic.Uint64
+	failedBatches atomic.Uint64
+}
+
+// New creates a pipeline that writes batches through sink. Call Start to
+// launch the workers and Close to flush them.
+func New(sink Sink, opts Options) (*Pipeline, error) {
+	if sink == nil {
+		return nil, errors.New("click log sink is required")
+	}
+	if opts.QueueSize < 1 || opts.Workers < 1 || opts.BatchSize < 1 {
+		return nil, errors.New("click log queue size, workers and batch size must be positive")
+	}
+	if opts.FlushInterval <= 0 {
+		return nil, errors.New("click log flush interval must be positive")
+	}
+
+	switch opts.Overflow {
+	case OverflowDrop, OverflowBlock:
+	case OverflowSpill:
+		if opts.SpillPath == "" {
+			return nil, errors.New("click log spill policy requires a spill path")
+		}
+	default:
+		return nil, fmt.Errorf("unknown click log overflow policy %q", opts.Overflow)
+	}
+
+	return &Pipeline{
+		sink:  sink,
+		opts:  opts,
+		queue: make(chan models.AccessLog, opts.QueueSize),
+	}, nil
+}
+
+// Start replays any spilled events and launches the workers
+func (p *Pipeline) Start() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.started || p.closed {
+		return
+	}
+	p.started = true
+
+	if p.opts.SpillPath != "" {
+		if err := p.replaySpill(); err != nil {
+			log.Printf("Failed to replay spilled click events: %v", err)
+		}
+	}
+
+	for i := 0; i < p.opts.Workers; i++ {
+		p.wg.Add(1)
+		go p.run()
+	}
+
+	log.Printf("Click log pipeline started with %d workers", p.opts.Workers)
+}
+
+// Enqueue queues an access log entry. When the queue is full the overflow
+// policy applies; ErrQueueFull means the event was dropped.
+func (p *Pipeline) Enqueue(ctx context.Context, entry models.AccessLog) error {
+	if entry.AccessedAt.IsZero() {
+		entry.AccessedAt = time.Now()
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrClosed
+	}
+
+	select {
+	case p.queue <- entry:
+		p.enqueued.Add(1)
+		return nil
+	default:
+	}
+
+	switch p.opts.Overflow {
+	case OverflowBlock:
+		select {
+		case p.queue <- entry:
+			p.enqueued.Add(1)
+			return nil
+		case <-ctx.Done():
+			p.dropped.Add(1)
+			return ctx.Err()
+		}
+	case OverflowSpill:
+		if err := p.spill([]models.AccessLog{entry}); err != nil {
+			p.dropped.Add(1)
+			return err
+		}
+		return nil
+	default:
+		p.dropped.Add(1)
+		return ErrQueueFull
+	}
+}
+
+// WriteNow enriches and writes a single entry synchronously, for callers
+// that can no longer enqueue once the pipeline is closed
+func (p *Pipeline) WriteNow(entry models.AccessLog) error {
+	if entry.AccessedAt.IsZero() {
+		entry.AccessedAt = time.Now()
+	}
+	if p.opts.Enrich != nil {
+		p.opts.Enrich(&entry)
+	}
+
+	if err := p.sink([]models.AccessLog{entry}); err != nil {
+		return err
+	}
+
+	p.written.Add(1)
+	return nil
+}
+
+// Close stops accepting events and waits until the workers have written
+// everything still queued. If ctx is done first, the events still queued
+// are moved to the spill file so they are replayed on the next start.
+func (p *Pipeline) Close(ctx context.Context) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	close(p.queue)
+	if !p.started {
+		// Nothing is consuming the queue; drain it here
+		p.started = true
+		p.wg.Add(1)
+		go p.run()
+	}
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		log.Println("Click log pipeline flushed and stopped")
+		return nil
+	case <-ctx.Done():
+		p.timedOut.Store(true)
+		return p.spillQueue(ctx.Err())
+	}
+}
+
+// spillQueue takes the events left in the closed queue away from the
+// workers and spills them, after Close ran out of time because of cause
+func (p *Pipeline) spillQueue(cause error) error {
+	var entries []models.AccessLog
+	for entry := range p.queue {
+		entries = append(entries, entry)
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	if p.opts.SpillPath == "" {
+		p.dropped.Add(uint64(len(entries)))
+		return fmt.Errorf("click log pipeline did not flush in time, dropping %d queued events: %w", len(entries), cause)
+	}
+	if err := p.spill(entries); err != nil {
+		p.dropped.Add(uint64(len(entries)))
+		return fmt.Errorf("click log pipeline did not flush in time and failed to spill %d queued events: %w", len(entries), err)
+	}
+
+	log.Printf("Click log pipeline did not flush in time, spilled %d queued events to %s", len(entries), p.opts.SpillPath)
+	return nil
+}
+
+// Stats returns the current pipeline counters
+func (p *Pipeline) Stats() Stats {
+	return Stats{
+		QueueDepth:    len(p.queue),
+		QueueCapacity: cap(p.queue),
+		Enqueued:      p.enqueued.Load(),
+		Written:       p.written.Load(),
+		Dropped:       p.dropped.Load(),
+		Spilled:       p.spilled.Load(),
+		Replayed:      p.replayed.Load(),
+		Batches:       p.batches.Load(),
+		FailedBatches: p.failedBatches.Load(),
+	}
+}
+
+func (p *Pipeline) run() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.opts.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]models.AccessLog, 0, p.opts.BatchSize)
+	for {
+		select {
+		case entry, ok := <-p.queue:
+			if !ok {
+				p.flush(batch)
+				return
+			}
+			batch = append(batch, entry)
+			if len(batch) >= p.opts.BatchSize {
+				p.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				p.flush(batch)
+				batch = batch[:0]
+			}
+		}
+	}
+}
+
+func (p *Pipeline) flush(batch []models.AccessLog) {
+	if len(batch) == 0 {
+		return
+	}
+
+	if p.opts.Enrich != nil {
+		for i := range batch {
+			p.opts.Enrich(&batch[i])
+		}
+	}
+
+	p.batches.Add(1)
+	if err := p.sink(batch); err != nil {
+		p.failedBatches.Add(1)
+		log.Printf("Failed to write %d click events: %v", len(batch), err)
+
+		if p.opts.Overflow == OverflowSpill || (p.timedOut.Load() && p.opts.SpillPath != "") {
+			if err := p.spill(batch); err != nil {
+				log.Printf("Failed to spill %d click events: %v", len(batch), err)
+				p.dropped.Add(uint64(len(batch)))
+			}
+			return
+		}
+		p.dropped.Add(uint64(len(batch)))
+		return
+	}
+
+	p.written.Add(uint64(len(batch)))
+}
+
+// spill appends events to the spill file as JSON lines
+func (p *Pipeline) spill(entries []models.AccessLog) error {
+	p.spillMu.Lock()
+	defer p.spillMu.Unlock()
+
+	if err := p.writeSpillFile(entries, os.O_APPEND); err != nil {
+		return err
+	}
+
+	p.spilled.Add(uint64(len(entries)))
+	return nil
+}
+
+// writeSpillFile writes entries to the spill file, appending or truncating
+// according to mode. Callers must hold spillMu.
+func (p *Pipeline) writeSpillFile(entries []models.AccessLog, mode int) error {
+	if err := os.MkdirAll(filepath.Dir(p.opts.SpillPath), 0o755); err != nil {
+		return fmt.Errorf("failed to create spill directory: %w", err)
+	}
+
+	file, err := os.OpenFile(p.opts.SpillPath, os.O_CREATE|os.O_WRONLY|mode, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open spill file: %w", err)
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return fmt.Errorf("failed to encode spilled event: %w", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write spill file: %w", err)
+	}
+
+	return nil
+}
+
+// replaySpill writes spilled events straight to the sink. Events that
+// cannot be stored stay in the spill file for the next start.
+func (p *Pipeline) replaySpill() error {
+	p.spillMu.Lock()
+	defer p.spillMu.Unlock()
+
+	entries, err := p.readSpillFile()
+	if err != nil || len(entries) == 0 {
+		return err
+	}
+
+	for start := 0; start < len(entries); start += p.opts.BatchSize {
+		end := start + p.opts.BatchSize
+		if end > len(entries) {
+			end = len(entries)
+		}
+
+		batch := entries[start:end]
+		if p.opts.Enrich != nil {
+			for i := range batch {
+				p.opts.Enrich(&batch[i])
+			}
+		}
+
+		if err := p.sink(batch); err != nil {
+			if rewriteErr := p.writeSpillFile(entries[start:], os.O_TRUNC); rewriteErr != nil {
+				return fmt.Errorf("failed to keep unreplayed events: %w", rewriteErr)
+			}
+			return fmt.Errorf("failed to replay spilled events: %w", err)
+		}
+		p.replayed.Add(uint64(len(batch)))
+	}
+
+	if err := os.Remove(p.opts.SpillPath); err != nil {
+		return fmt.Errorf("failed to remove spill file: %w", err)
+	}
+
+	log.Printf("Replayed %d spilled click events", len(entries))
+	return nil
+}
+
+func (p *Pipeline) readSpillFile() ([]models.AccessLog, error) {
+	file, err := os.Open(p.opts.SpillPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open spill file: %w", err)
+	}
+	defer file.Close()
+
+	var entries []models.AccessLog
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry models.AccessLog
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			log.Printf("Skipping corrupt spilled click event: %v", err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read spill file: %w", err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,264 @@
+package clicklog
+
+import (
+	"context"
+	"errors"
+	"link-guardian/internal/models"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingSink collects written batches and can be made to fail
+type recordingSink struct {
+	mu      sync.Mutex
+	batches [][]models.AccessLog
+	fail    bool
+}
+
+func (s *recordingSink) write(logs []models.AccessLog) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail {
+		return errors.New("database unavailable")
+	}
+	s.batches = append(s.batches, append([]models.AccessLog(nil), logs...))
+	return nil
+}
+
+func (s *recordingSink) total() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, batch := range s.batches {
+		n += len(batch)
+	}
+	return n
+}
+
+func testOptions() Options {
+	return Options{
+		QueueSize:     100,
+		Workers:       1,
+		BatchSize:     10,
+		FlushInterval: time.Hour,
+		Overflow:      OverflowDrop,
+	}
+}
+
+func TestCloseFlushesQueuedEventsInBatches(t *testing.T) {
+	sink := &recordingSink{}
+	pipeline, err := New(sink.write, testOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pipeline.Start()
+
+	for i := 0; i < 25; i++ {
+		if err := pipeline.Enqueue(context.Background(), models.AccessLog{LinkID: int64(i)}); err != nil {
+			t.Fatalf("Enqueue: %v", err)
+		}
+	}
+
+	if err := pipeline.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if got := sink.total(); got != 25 {
+		t.Fatalf("wrote %d events, want 25", got)
+	}
+	for _, batch := range sink.batches {
+		if len(batch) > 10 {
+			t.Errorf("batch of %d events exceeds batch size 10", len(batch))
+		}
+	}
+	if err := pipeline.Enqueue(context.Background(), models.AccessLog{}); !errors.Is(err, ErrClosed) {
+		t.Errorf("Enqueue after Close = %v, want ErrClosed", err)
+	}
+}
+
+func TestFlushIntervalWritesPartialBatch(t *testing.T) {
+	sink := &recordingSink{}
+	opts := testOptions()
+	opts.FlushInterval = 10 * time.Millisecond
+	pipeline, err := New(sink.write, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pipeline.Start()
+	defer pipeline.Close(context.Background())
+
+	if err := pipeline.Enqueue(context.Background(), models.AccessLog{LinkID: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for sink.total() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("partial batch was not flushed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestDropPolicyWhenQueueIsFull(t *testing.T) {
+	sink := &recordingSink{}
+	opts := testOptions()
+	opts.QueueSize = 2
+	pipeline, err := New(sink.write, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Not started, so nothing drains the queue
+	for i := 0; i < 2; i++ {
+		if err := pipeline.Enqueue(context.Background(), models.AccessLog{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pipeline.Enqueue(context.Background(), models.AccessLog{}); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("Enqueue on full queue = %v, want ErrQueueFull", err)
+	}
+
+	stats := pipeline.Stats()
+	if stats.QueueDepth != 2 || stats.Dropped != 1 {
+		t.Errorf("stats = %+v, want depth 2 and 1 dropped", stats)
+	}
+
+	if err := pipeline.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := sink.total(); got != 2 {
+		t.Errorf("wrote %d events, want 2", got)
+	}
+}
+
+func TestBlockPolicyHonoursContext(t *testing.T) {
+	opts := testOptions()
+	opts.QueueSize = 1
+	opts.Overflow = OverflowBlock
+	pipeline, err := New((&recordingSink{}).write, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pipeline.Close(context.Background())
+
+	if err := pipeline.Enqueue(context.Background(), models.AccessLog{}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := pipeline.Enqueue(ctx, models.AccessLog{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("blocked Enqueue = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestSpillPolicyReplaysOnStart(t *testing.T) {
+	spillPath := filepath.Join(t.TempDir(), "spill.jsonl")
+	sink := &recordingSink{fail: true}
+	opts := testOptions()
+	opts.QueueSize = 1
+	opts.Overflow = OverflowSpill
+	opts.SpillPath = spillPath
+
+	pipeline, err := New(sink.write, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first event is queued, the second overflows to disk and the
+	// queued one is spilled when its write fails during Close
+	for i := 1; i <= 2; i++ {
+		if err := pipeline.Enqueue(context.Background(), models.AccessLog{LinkID: int64(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pipeline.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if stats := pipeline.Stats(); stats.Spilled != 2 {
+		t.Fatalf("spilled %d events, want 2", stats.Spilled)
+	}
+
+	sink.fail = false
+	restarted, err := New(sink.write, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restarted.Start()
+	defer restarted.Close(context.Background())
+
+	if got := sink.total(); got != 2 {
+		t.Fatalf("replayed %d events, want 2", got)
+	}
+	if stats := restarted.Stats(); stats.Replayed != 2 {
+		t.Errorf("replayed counter = %d, want 2", stats.Replayed)
+	}
+}
+
+func TestCloseSpillsQueueWhenOutOfTime(t *testing.T) {
+	spillPath := filepath.Join(t.TempDir(), "spill.jsonl")
+	opts := testOptions()
+	opts.BatchSize = 1
+	opts.SpillPath = spillPath
+
+	// The only worker is stuck writing the first event
+	writing, release := make(chan struct{}), make(chan struct{})
+	sink := &recordingSink{}
+	pipeline, err := New(func(logs []models.AccessLog) error {
+		close(writing)
+		<-release
+		return sink.write(logs)
+	}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pipeline.Start()
+	defer close(release)
+
+	for i := 1; i <= 3; i++ {
+		if err := pipeline.Enqueue(context.Background(), models.AccessLog{LinkID: int64(i)}); err != nil {
+			t.Fatal(err)
+		}
+		if i == 1 {
+			<-writing
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := pipeline.Close(ctx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if stats := pipeline.Stats(); stats.Spilled != 2 || stats.Dropped != 0 {
+		t.Fatalf("spilled %d and dropped %d events, want 2 spilled", stats.Spilled, stats.Dropped)
+	}
+
+	// The spill file is replayed on the next start even with the drop policy
+	restarted, err := New(sink.write, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restarted.Start()
+	defer restarted.Close(context.Background())
+
+	if got := sink.total(); got != 2 {
+		t.Errorf("replayed %d events, want 2", got)
+	}
+}
+
+func TestNewRejectsInvalidOptions(t *testing.T) {
+	opts := testOptions()
+	opts.Overflow = "discard"
+	if _, err := New((&recordingSink{}).write, opts); err == nil {
+		t.Error("expected error for unknown overflow policy")
+	}
+
+	opts = testOptions()
+	opts.Overflow = OverflowSpill
+	if _, err := New((&recordingSink{}).write, opts); err == nil {
+		t.Error("expected error for spill policy without a path")
+	}
+}
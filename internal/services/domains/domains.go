@@ -0,0 +1,183 @@
+// Package domains registers and verifies the custom short domains of
+// workspaces and builds the public URLs of links. A workspace proves it
+// controls a hostname by publishing a TXT record at _link-guardian.<hostname>
+// holding the domain's verification token.
+package domains
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrDefaultDomain is returned when registering the default short domain
+	ErrDefaultDomain = errors.New("the default short domain cannot be registered")
+	// ErrRecordNotFound is returned when no TXT record holds the verification token
+	ErrRecordNotFound = errors.New("verification record not found")
+)
+
+const (
+	recordPrefix = "_link-guardian."
+	valuePrefix  = "link-guardian-verification="
+	// lookupTimeout bounds each DNS lookup made while verifying a domain
+	lookupTimeout = 5 * time.Second
+)
+
+// Resolver looks up DNS TXT records; *net.Resolver implements it
+type Resolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// NewDNSResolver returns a resolver querying the DNS server at address
+// (host:port), or the system resolver when address is empty
+func NewDNSResolver(address string) Resolver {
+	if address == "" {
+		return net.DefaultResolver
+	}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, address)
+		},
+	}
+}
+
+// StaticResolver answers TXT lookups from a map of record names to values,
+// for tests and local development
+type StaticResolver map[string][]string
+
+// LookupTXT implements Resolver
+func (r StaticResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	if records, ok := r[name]; ok {
+		return records, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
+
+// Options configures a Service
+type Options struct {
+	PublicBaseURL string // Base URL of the default short domain, e.g. https://sho.rt; empty uses the request host
+}
+
+// Service verifies custom domains through a Resolver
+type Service struct {
+	resolver    Resolver
+	baseURL     string
+	defaultHost string
+}
+
+// NewService creates a domain service looking up TXT records with resolver
+func NewService(resolver Resolver, opts Options) (*Service, error) {
+	service := &Service{resolver: resolver}
+	if opts.PublicBaseURL != "" {
+		baseURL, err := url.Parse(opts.PublicBaseURL)
+		if err != nil || baseURL.Host == "" || (baseURL.Scheme != "http" && baseURL.Scheme != "https") {
+			return nil, fmt.Errorf("invalid public base URL %q", opts.PublicBaseURL)
+		}
+		service.baseURL = strings.TrimRight(baseURL.String(), "/")
+		service.defaultHost = NormalizeHostname(baseURL.Host)
+	}
+	return service, nil
+}
+
+// NormalizeHostname lowercases host and strips any port and trailing dot, so
+// Host headers and registered hostnames compare equal
+func NormalizeHostname(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+	return strings.TrimSuffix(host, ".")
+}
+
+// DefaultHost returns the hostname of the public base URL, or "" when none is configured
+func (s *Service) DefaultHost() string {
+	return s.defaultHost
+}
+
+// ShortURL returns the public redirect URL of link. Links on custom domains
+// are served from the domain root over HTTPS and other links under /l/ of the
+// public base URL. It returns "" for links on the default domain when no
+// public base URL is configured.
+func (s *Service) ShortURL(link models.Link) string {
+	slug := url.PathEscape(link.Slug)
+	if link.Domain.Valid {
+		return "https://" + link.Domain.String + "/" + slug
+	}
+	if s.baseURL == "" {
+		return ""
+	}
+	return s.baseURL + "/l/" + slug
+}
+
+// Record returns the TXT record that verifies domain
+func Record(domain models.Domain) models.DNSRecord {
+	return models.DNSRecord{
+		Type:  "TXT",
+		Name:  recordPrefix + domain.Hostname,
+		Value: valuePrefix + domain.VerificationToken,
+	}
+}
+
+// Register stores hostname as an unverified domain of the workspace with a
+// new verification token. It returns ErrDefaultDomain for the default short
+// domain and repositories.ErrDomainTaken when the hostname is not available.
+func (s *Service) Register(domains repositories.DomainRepository, workspaceID, userID int, hostname string) (models.Domain, error) {
+	hostname = NormalizeHostname(hostname)
+	if s.defaultHost != "" && hostname == s.defaultHost {
+		return models.Domain{}, ErrDefaultDomain
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return models.Domain{}, err
+	}
+
+	return domains.CreateDomain(models.Domain{
+		WorkspaceID:       workspaceID,
+		Hostname:          hostname,
+		VerificationToken: hex.EncodeToString(b),
+		CreatedBy:         sql.NullInt32{Int32: int32(userID), Valid: true},
+	})
+}
+
+// Verify looks up the TXT record of domain and marks the domain verified
+// when the record holds its token. Verified domains are returned unchanged.
+// It returns ErrRecordNotFound when no record holds the token, and
+// repositories.ErrDomainTaken when another workspace verified the hostname first.
+func (s *Service) Verify(ctx context.Context, domains repositories.DomainRepository, domain models.Domain) (models.Domain, error) {
+	if domain.VerifiedAt.Valid {
+		return domain, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, lookupTimeout)
+	defer cancel()
+
+	record := Record(domain)
+	values, err := s.resolver.LookupTXT(ctx, record.Name)
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return models.Domain{}, ErrRecordNotFound
+	}
+	if err != nil {
+		return models.Domain{}, fmt.Errorf("failed to look up %s: %w", record.Name, err)
+	}
+
+	for _, value := range values {
+		if strings.TrimSpace(value) == record.Value {
+			return domains.MarkDomainVerified(domain.WorkspaceID, domain.ID)
+		}
+	}
+	return models.Domain{}, ErrRecordNotFound
+}
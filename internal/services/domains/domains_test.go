@@ -0,0 +1,112 @@
+package domains
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"link-guardian/internal/repositories/memory"
+	"link-guardian/internal/repositories/repotest"
+	"testing"
+)
+
+func TestNormalizeHostname(t *testing.T) {
+	tests := map[string]string{
+		"Go.Example.COM":      "go.example.com",
+		"go.example.com:8443": "go.example.com",
+		"go.example.com.":     "go.example.com",
+		" go.example.com ":    "go.example.com",
+	}
+	for host, want := range tests {
+		if got := NormalizeHostname(host); got != want {
+			t.Errorf("NormalizeHostname(%q) = %q, want %q", host, got, want)
+		}
+	}
+}
+
+func TestShortURL(t *testing.T) {
+	service, err := NewService(StaticResolver{}, Options{PublicBaseURL: "https://sho.rt/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if service.DefaultHost() != "sho.rt" {
+		t.Errorf("DefaultHost = %q", service.DefaultHost())
+	}
+
+	if got := service.ShortURL(models.Link{Slug: "abc"}); got != "https://sho.rt/l/abc" {
+		t.Errorf("default domain short URL = %q", got)
+	}
+	custom := models.Link{Slug: "abc", Domain: sql.NullString{String: "go.example.com", Valid: true}}
+	if got := service.ShortURL(custom); got != "https://go.example.com/abc" {
+		t.Errorf("custom domain short URL = %q", got)
+	}
+
+	unconfigured, err := NewService(StaticResolver{}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := unconfigured.ShortURL(models.Link{Slug: "abc"}); got != "" {
+		t.Errorf("short URL without a base URL = %q, want the caller to fall back", got)
+	}
+
+	if _, err := NewService(StaticResolver{}, Options{PublicBaseURL: "sho.rt"}); err == nil {
+		t.Error("expected an error for a base URL without a scheme")
+	}
+}
+
+func TestRegisterAndVerify(t *testing.T) {
+	store := memory.NewStore()
+	userID := repotest.CreateUser(t, store)
+	workspace, err := store.GetPersonalWorkspace(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resolver := StaticResolver{}
+	service, err := NewService(resolver, Options{PublicBaseURL: "https://sho.rt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := service.Register(store, workspace.ID, userID, "SHO.RT"); !errors.Is(err, ErrDefaultDomain) {
+		t.Errorf("registering the default domain: got %v, want ErrDefaultDomain", err)
+	}
+
+	domain, err := service.Register(store, workspace.ID, userID, "Go.Example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if domain.Hostname != "go.example.com" || len(domain.VerificationToken) != 32 {
+		t.Errorf("Register = %+v", domain)
+	}
+	if _, err := service.Register(store, workspace.ID, userID, "go.example.com"); !errors.Is(err, repositories.ErrDomainTaken) {
+		t.Errorf("registering twice: got %v, want ErrDomainTaken", err)
+	}
+
+	record := Record(domain)
+	if record.Name != "_link-guardian.go.example.com" || record.Value != "link-guardian-verification="+domain.VerificationToken {
+		t.Errorf("Record = %+v", record)
+	}
+
+	ctx := context.Background()
+	if _, err := service.Verify(ctx, store, domain); !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("verifying without a record: got %v, want ErrRecordNotFound", err)
+	}
+	resolver[record.Name] = []string{"link-guardian-verification=wrong"}
+	if _, err := service.Verify(ctx, store, domain); !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("verifying with the wrong token: got %v, want ErrRecordNotFound", err)
+	}
+
+	resolver[record.Name] = []string{"v=spf1 -all", record.Value}
+	verified, err := service.Verify(ctx, store, domain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !verified.VerifiedAt.Valid {
+		t.Errorf("Verify = %+v", verified)
+	}
+	if got, err := store.GetVerifiedDomainByHostname("go.example.com"); err != nil || got.ID != domain.ID {
+		t.Errorf("stored domain after verifying = %+v, %v", got, err)
+	}
+}
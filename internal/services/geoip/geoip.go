@@ -0,0 +1,220 @@
+package geoip
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// Location is the geographic and network information known for an IP address
+type Location struct {
+	Country string // ISO 3166-1 alpha-2 code
+	Region  string
+	City    string
+	ASN     uint
+}
+
+// GeoResolver looks up the location of an IP address. Implementations must
+// not use the network so lookups are safe on hot paths.
+type GeoResolver interface {
+	Lookup(ip string) (Location, error)
+}
+
+// NoopResolver resolves every address to an empty location
+type NoopResolver struct{}
+
+// Lookup implements GeoResolver
+func (NoopResolver) Lookup(ip string) (Location, error) {
+	return Location{}, nil
+}
+
+// cityRecord matches the MaxMind GeoIP2/GeoLite2 City and DB-IP City Lite layout
+type cityRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	Subdivisions []struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"subdivisions"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+}
+
+// asnRecord matches the MaxMind GeoLite2 ASN and DB-IP ASN Lite layout
+type asnRecord struct {
+	ASN uint `maxminddb:"autonomous_system_number"`
+}
+
+// mmdbFile is a database file that is reopened when its modification time changes
+type mmdbFile struct {
+	path    string
+	reader  *maxminddb.Reader
+	modTime time.Time
+}
+
+// MMDBResolver resolves locations from local .mmdb files. A city database
+// supplies country, region and city, and an optional ASN database supplies
+// the autonomous system number. Files are reloaded when they change on disk.
+type MMDBResolver struct {
+	mu   sync.RWMutex
+	city *mmdbFile
+	asn  *mmdbFile
+
+	stopOnce sync.Once
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewMMDBResolver opens the city and ASN databases. Either path may be empty,
+// but not both.
+func NewMMDBResolver(cityPath, asnPath string) (*MMDBResolver, error) {
+	if cityPath == "" && asnPath == "" {
+		return nil, errors.New("at least one GeoIP database path is required")
+	}
+
+	resolver := &MMDBResolver{stopChan: make(chan struct{})}
+	var err error
+	if cityPath != "" {
+		if resolver.city, err = openMMDB(cityPath); err != nil {
+			return nil, err
+		}
+	}
+	if asnPath != "" {
+		if resolver.asn, err = openMMDB(asnPath); err != nil {
+			resolver.Close()
+			return nil, err
+		}
+	}
+
+	return resolver, nil
+}
+
+func openMMDB(path string) (*mmdbFile, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat GeoIP database %s: %w", path, err)
+	}
+
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open GeoIP database %s: %w", path, err)
+	}
+
+	return &mmdbFile{path: path, reader: reader, modTime: info.ModTime()}, nil
+}
+
+// Lookup implements GeoResolver
+func (r *MMDBResolver) Lookup(ip string) (Location, error) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return Location{}, fmt.Errorf("invalid IP address %q", ip)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var location Location
+	if r.city != nil {
+		var record cityRecord
+		if err := r.city.reader.Lookup(parsed, &record); err != nil {
+			return Location{}, fmt.Errorf("city lookup failed: %w", err)
+		}
+		location.Country = record.Country.ISOCode
+		location.City = record.City.Names["en"]
+		if len(record.Subdivisions) > 0 {
+			location.Region = record.Subdivisions[0].Names["en"]
+		}
+	}
+
+	if r.asn != nil {
+		var record asnRecord
+		if err := r.asn.reader.Lookup(parsed, &record); err != nil {
+			return Location{}, fmt.Errorf("ASN lookup failed: %w", err)
+		}
+		location.ASN = record.ASN
+	}
+
+	return location, nil
+}
+
+// WatchForChanges checks the database files every interval and reloads any
+// that were replaced, until Close is called
+func (r *MMDBResolver) WatchForChanges(interval time.Duration) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				r.Reload()
+			case <-r.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Reload reopens any database file whose modification time has changed.
+// A file that fails to open keeps the previously loaded version in use.
+func (r *MMDBResolver) Reload() {
+	r.mu.RLock()
+	files := []*mmdbFile{r.city, r.asn}
+	r.mu.RUnlock()
+
+	for i, current := range files {
+		if current == nil {
+			continue
+		}
+
+		info, err := os.Stat(current.path)
+		if err != nil || info.ModTime().Equal(current.modTime) {
+			continue
+		}
+
+		updated, err := openMMDB(current.path)
+		if err != nil {
+			log.Printf("Keeping previous GeoIP database: %v", err)
+			continue
+		}
+
+		r.mu.Lock()
+		if i == 0 {
+			r.city = updated
+		} else {
+			r.asn = updated
+		}
+		r.mu.Unlock()
+
+		current.reader.Close()
+		log.Printf("Reloaded GeoIP database %s", current.path)
+	}
+}
+
+// Close stops watching for changes and closes the database files
+func (r *MMDBResolver) Close() error {
+	r.stopOnce.Do(func() { close(r.stopChan) })
+	r.wg.Wait()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var errs []error
+	for _, file := range []*mmdbFile{r.city, r.asn} {
+		if file != nil {
+			errs = append(errs, file.reader.Close())
+		}
+	}
+	r.city, r.asn = nil, nil
+	return errors.Join(errs...)
+}
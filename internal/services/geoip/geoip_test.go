@@ -0,0 +1,129 @@
+package geoip
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/maxmind/mmdbwriter"
+	"github.com/maxmind/mmdbwriter/mmdbtype"
+)
+
+// writeDB writes an mmdb file mapping each CIDR to its record
+func writeDB(t *testing.T, path, dbType string, records map[string]mmdbtype.Map) {
+	t.Helper()
+
+	writer, err := mmdbwriter.New(mmdbwriter.Options{DatabaseType: dbType, RecordSize: 24})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for cidr, record := range records {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := writer.Insert(network, record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if _, err := writer.WriteTo(file); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func cityRecordFor(country, region, city string) mmdbtype.Map {
+	return mmdbtype.Map{
+		"country":      mmdbtype.Map{"iso_code": mmdbtype.String(country)},
+		"subdivisions": mmdbtype.Slice{mmdbtype.Map{"names": mmdbtype.Map{"en": mmdbtype.String(region)}}},
+		"city":         mmdbtype.Map{"names": mmdbtype.Map{"en": mmdbtype.String(city)}},
+	}
+}
+
+func TestMMDBResolverLookup(t *testing.T) {
+	dir := t.TempDir()
+	cityPath := filepath.Join(dir, "city.mmdb")
+	asnPath := filepath.Join(dir, "asn.mmdb")
+	writeDB(t, cityPath, "GeoLite2-City", map[string]mmdbtype.Map{
+		"81.2.69.0/24": cityRecordFor("GB", "England", "London"),
+	})
+	writeDB(t, asnPath, "GeoLite2-ASN", map[string]mmdbtype.Map{
+		"81.2.69.0/24": {"autonomous_system_number": mmdbtype.Uint32(20712)},
+	})
+
+	resolver, err := NewMMDBResolver(cityPath, asnPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resolver.Close()
+
+	got, err := resolver.Lookup("81.2.69.142")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Location{Country: "GB", Region: "England", City: "London", ASN: 20712}
+	if got != want {
+		t.Errorf("Lookup = %+v, want %+v", got, want)
+	}
+
+	if got, err := resolver.Lookup("8.8.8.8"); err != nil || got != (Location{}) {
+		t.Errorf("Lookup of unknown address = %+v, %v; want empty location", got, err)
+	}
+
+	if _, err := resolver.Lookup("not-an-ip"); err == nil {
+		t.Error("expected error for invalid IP")
+	}
+}
+
+func TestMMDBResolverReloadsChangedFile(t *testing.T) {
+	cityPath := filepath.Join(t.TempDir(), "city.mmdb")
+	writeDB(t, cityPath, "GeoLite2-City", map[string]mmdbtype.Map{
+		"81.2.69.0/24": cityRecordFor("GB", "England", "London"),
+	})
+
+	resolver, err := NewMMDBResolver(cityPath, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resolver.Close()
+
+	// Replace the file the way database updaters do, then make sure the
+	// modification time differs even on coarse-grained filesystems
+	updatedPath := cityPath + ".new"
+	writeDB(t, updatedPath, "GeoLite2-City", map[string]mmdbtype.Map{
+		"81.2.69.0/24": cityRecordFor("GB", "Scotland", "Edinburgh"),
+	})
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(updatedPath, future, future); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(updatedPath, cityPath); err != nil {
+		t.Fatal(err)
+	}
+
+	resolver.Reload()
+
+	got, err := resolver.Lookup("81.2.69.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.City != "Edinburgh" || got.Region != "Scotland" {
+		t.Errorf("after reload Lookup = %+v, want Edinburgh, Scotland", got)
+	}
+}
+
+func TestNewMMDBResolverRequiresAPath(t *testing.T) {
+	if _, err := NewMMDBResolver("", ""); err == nil {
+		t.Error("expected error without database paths")
+	}
+	if _, err := NewMMDBResolver(filepath.Join(t.TempDir(), "missing.mmdb"), ""); err == nil {
+		t.Error("expected error for a missing database file")
+	}
+}
@@ -0,0 +1,102 @@
+// Package invitations invites people to join a workspace by email and
+// redeems the invitations. Invitations carry a single-use token of which
+// only a hash is stored, and can only be accepted by the account with the
+// invited email address.
+package invitations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"link-guardian/internal/services/auth"
+	"link-guardian/internal/services/mailer"
+	"strings"
+	"time"
+)
+
+// ErrPersonalWorkspace is returned when inviting someone to a personal workspace
+var ErrPersonalWorkspace = errors.New("personal workspaces cannot be shared")
+
+// Options configures a Service
+type Options struct {
+	TokenTTL  time.Duration // How long an invitation stays valid
+	AcceptURL string        // Web app page the emailed link points to; the token is added as a query parameter
+}
+
+// Service sends and redeems workspace invitations
+type Service struct {
+	authService *auth.AuthService
+	mailer      mailer.Mailer
+	opts        Options
+}
+
+// NewService creates an invitation service sending its emails through m
+func NewService(authService *auth.AuthService, m mailer.Mailer, opts Options) *Service {
+	return &Service{authService: authService, mailer: m, opts: opts}
+}
+
+// Invite stores an invitation for email to join workspace as role and emails
+// the address a link to accept it. It returns ErrPersonalWorkspace for
+// personal workspaces.
+func (s *Service) Invite(ctx context.Context, workspaces repositories.WorkspaceRepository, workspace models.Workspace,
+	inviter models.User, email, role string) (models.WorkspaceInvitation, error) {
+	if workspace.Personal {
+		return models.WorkspaceInvitation{}, ErrPersonalWorkspace
+	}
+
+	token, hash, err := s.authService.GenerateOpaqueToken()
+	if err != nil {
+		return models.WorkspaceInvitation{}, err
+	}
+
+	invitation, err := workspaces.CreateWorkspaceInvitation(models.WorkspaceInvitation{
+		WorkspaceID: workspace.ID,
+		Email:       strings.ToLower(strings.TrimSpace(email)),
+		Role:        role,
+		TokenHash:   hash,
+		InvitedBy:   inviter.ID,
+		ExpiresAt:   time.Now().Add(s.opts.TokenTTL),
+	})
+	if err != nil {
+		return models.WorkspaceInvitation{}, err
+	}
+
+	link, err := mailer.LinkWithToken(s.opts.AcceptURL, token)
+	if err != nil {
+		return models.WorkspaceInvitation{}, err
+	}
+
+	err = s.mailer.Send(ctx, mailer.Message{
+		To:      invitation.Email,
+		Subject: fmt.Sprintf("%s invited you to %s on Link Guardian", inviter.Username, workspace.Name),
+		Body: fmt.Sprintf("Hi,\n\n"+
+			"%s invited you to join the workspace %s on Link Guardian as %s. "+
+			"To accept, open this link within %s:\n\n%s\n\n"+
+			"If you do not have an account yet, sign up with this email address first. "+
+			"If you were not expecting this invitation, you can ignore this email.\n",
+			inviter.Username, workspace.Name, articleFor(role), mailer.FormatDuration(s.opts.TokenTTL), link),
+	})
+	if err != nil {
+		return models.WorkspaceInvitation{}, err
+	}
+
+	return invitation, nil
+}
+
+// Accept adds user to the workspace an invitation token was sent for and
+// returns the workspace with their role. It returns
+// repositories.ErrInvitationInvalid for unknown, expired or accepted tokens
+// and for invitations to a different email address.
+func (s *Service) Accept(workspaces repositories.WorkspaceRepository, user models.User, token string) (models.Workspace, error) {
+	return workspaces.AcceptWorkspaceInvitation(s.authService.HashOpaqueToken(token), user.ID, user.Email)
+}
+
+// articleFor returns the role with its indefinite article, as in "an editor"
+func articleFor(role string) string {
+	if role == models.WorkspaceRoleOwner || role == models.WorkspaceRoleEditor {
+		return "an " + role
+	}
+	return "a " + role
+}
@@ -0,0 +1,85 @@
+package invitations
+
+import (
+	"context"
+	"errors"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"link-guardian/internal/repositories/memory"
+	"link-guardian/internal/services/auth"
+	"link-guardian/internal/testutil/mailtest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+var tokenPattern = regexp.MustCompile(`token=([^\s]+)`)
+
+func createUser(t *testing.T, store *memory.Store, name string) models.User {
+	t.Helper()
+	userID, err := store.CreateUser(name, name+"@example.com", "hash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, _ := store.GetUserByID(userID)
+	return *user
+}
+
+func TestInviteAndAccept(t *testing.T) {
+	store := memory.NewStore()
+	owner := createUser(t, store, "olivia")
+	invitee := createUser(t, store, "peggy")
+	team, err := store.CreateWorkspace("Marketing", owner.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mail := mailtest.NewRecordingMailer()
+	service := NewService(auth.NewAuthService("test-secret", time.Minute, time.Hour), mail, Options{
+		TokenTTL:  time.Hour,
+		AcceptURL: "http://app.test/accept-invitation",
+	})
+
+	invitation, err := service.Invite(context.Background(), store, team, owner, " Peggy@Example.com ", models.WorkspaceRoleEditor)
+	if err != nil {
+		t.Fatalf("Invite: %v", err)
+	}
+	if invitation.Email != "peggy@example.com" || invitation.InvitedBy != owner.ID {
+		t.Errorf("stored invitation = %+v", invitation)
+	}
+	msg := mail.Next(t, "")
+	if msg.To != "peggy@example.com" || !strings.Contains(msg.Body, "Marketing") {
+		t.Fatalf("sent %+v, want an invitation to peggy@example.com", msg)
+	}
+	mail.None(t, "")
+	match := tokenPattern.FindStringSubmatch(msg.Body)
+	if match == nil {
+		t.Fatalf("email body has no invitation link:\n%s", msg.Body)
+	}
+	token, err := url.QueryUnescape(match[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the invited address may accept
+	if _, err := service.Accept(store, owner, token); !errors.Is(err, repositories.ErrInvitationInvalid) {
+		t.Errorf("Accept by another user: got %v, want ErrInvitationInvalid", err)
+	}
+	workspace, err := service.Accept(store, invitee, token)
+	if err != nil {
+		t.Fatalf("Accept: %v", err)
+	}
+	if workspace.ID != team.ID || workspace.Role != models.WorkspaceRoleEditor {
+		t.Errorf("accepted workspace = %+v", workspace)
+	}
+
+	personal, err := store.GetPersonalWorkspace(owner.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Invite(context.Background(), store, personal, owner, "peggy@example.com", models.WorkspaceRoleViewer); !errors.Is(err, ErrPersonalWorkspace) {
+		t.Errorf("Invite to a personal workspace: got %v, want ErrPersonalWorkspace", err)
+	}
+}
@@ -0,0 +1,232 @@
+// Package loginguard protects the login endpoint against password guessing.
+// Attempts are counted per email address and per IP before the credential is
+// checked, so concurrent guesses cannot outrun the limits, and taken back when
+// it turns out right. Repeated failures for an email add a growing delay
+// before the next attempt, and after enough of them the account is locked for
+// a while and its owner notified. Emails without an account are treated
+// exactly the same, so responses do not reveal which addresses are registered.
+package loginguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"link-guardian/internal/models"
+	"link-guardian/internal/services/mailer"
+	"log"
+	"strings"
+	"time"
+)
+
+// Options configures the limits of a Guard
+type Options struct {
+	MaxAttempts   int           // Failures per email before the account is locked
+	Lockout       time.Duration // How long a locked account stays locked; also the window failures are counted in
+	FreeAttempts  int           // Failures per email before delays start
+	BaseDelay     time.Duration // Delay after the first delayed failure; it doubles with each further failure
+	MaxDelay      time.Duration // Upper bound of the delay
+	IPMaxAttempts int           // Failures per IP, across all emails, before the IP is blocked
+	IPWindow      time.Duration // Window IP failures are counted in and how long the IP is blocked
+}
+
+// Notifier tells the owner of an account that it was locked
+type Notifier interface {
+	NotifyLockout(ctx context.Context, user models.User, ip string, until time.Time) error
+}
+
+// LogNotifier writes lockout notifications to the server log
+type LogNotifier struct{}
+
+// NotifyLockout implements Notifier
+func (LogNotifier) NotifyLockout(ctx context.Context, user models.User, ip string, until time.Time) error {
+	log.Printf("🔒 Account %s (ID: %d) locked until %s after failed logins from IP %s",
+		user.Email, user.ID, until.Format(time.RFC3339), ip)
+	return nil
+}
+
+// MailNotifier emails lockout notifications to the account owner
+type MailNotifier struct {
+	Mailer mailer.Mailer
+}
+
+// NotifyLockout implements Notifier
+func (n MailNotifier) NotifyLockout(ctx context.Context, user models.User, ip string, until time.Time) error {
+	return n.Mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Your Link Guardian account was locked",
+		Body: fmt.Sprintf("Hi %s,\n\n"+
+			"After several failed login attempts from IP %s, logins to your account are blocked until %s.\n\n"+
+			"If these attempts were not yours, consider resetting your password once the lockout ends.\n",
+			user.Username, ip, until.UTC().Format(time.RFC1123)),
+	})
+}
+
+// Guard tracks failed logins and decides when to delay or refuse attempts
+type Guard struct {
+	store    AttemptStore
+	opts     Options
+	notifier Notifier
+}
+
+// New creates a guard keeping its state in store
+func New(store AttemptStore, opts Options, notifier Notifier) *Guard {
+	return &Guard{store: store, opts: opts, notifier: notifier}
+}
+
+func emailSubject(email string) string {
+	return "email:" + strings.ToLower(email)
+}
+
+func accountSubject(email string) string {
+	return "account:" + strings.ToLower(email)
+}
+
+func ipSubject(ip string) string {
+	return "ip:" + ip
+}
+
+// Check returns how long a login for email from ip must wait, or 0 when it may proceed
+func (g *Guard) Check(ctx context.Context, email, ip string) (time.Duration, error) {
+	var wait time.Duration
+	for _, subject := range []string{accountSubject(email), emailSubject(email), ipSubject(ip)} {
+		blockedFor, err := g.store.BlockedFor(ctx, subject)
+		if err != nil {
+			return 0, err
+		}
+		if blockedFor > wait {
+			wait = blockedFor
+		}
+	}
+	return wait, nil
+}
+
+// Attempt is a login attempt reserved by Begin. It counts as a failure until
+// RecordSuccess or Release say otherwise.
+type Attempt struct {
+	email      string
+	ip         string
+	failures   int64 // Failures of the email, counting this attempt
+	ipFailures int64 // Failures of the IP, counting this attempt
+	delayed    bool  // Whether the attempt holds the delay of the email
+}
+
+// Begin reserves a login attempt for email from ip before the credential is
+// verified. Counting the attempt up front means concurrent guesses cannot all
+// pass before the first failure is recorded: it returns how long the login
+// must wait instead when the email or IP is delayed, locked out or already
+// has as many attempts in flight as its limits allow.
+func (g *Guard) Begin(ctx context.Context, email, ip string) (*Attempt, time.Duration, error) {
+	wait, err := g.Check(ctx, email, ip)
+	if err != nil || wait > 0 {
+		return nil, wait, err
+	}
+
+	attempt := &Attempt{email: email, ip: ip}
+	attempt.ipFailures, err = g.store.IncrementFailures(ctx, ipSubject(ip), g.opts.IPWindow)
+	if err != nil {
+		return nil, 0, err
+	}
+	if attempt.ipFailures > int64(g.opts.IPMaxAttempts) {
+		return nil, g.opts.IPWindow, g.release(ctx, ipSubject(ip))
+	}
+
+	attempt.failures, err = g.store.IncrementFailures(ctx, emailSubject(email), g.opts.Lockout)
+	if err != nil {
+		return nil, 0, errors.Join(err, g.release(ctx, ipSubject(ip)))
+	}
+	if attempt.failures > int64(g.opts.MaxAttempts) {
+		return nil, g.opts.Lockout, g.Release(ctx, attempt)
+	}
+
+	// Past the free attempts only one attempt at a time may run; it holds the
+	// delay that follows its failure from the start
+	if delay := g.delayAfter(attempt.failures); delay > 0 {
+		acquired, err := g.store.TryBlock(ctx, emailSubject(email), delay)
+		if err != nil {
+			return nil, 0, errors.Join(err, g.Release(ctx, attempt))
+		}
+		if !acquired {
+			return nil, delay, g.Release(ctx, attempt)
+		}
+		attempt.delayed = true
+	}
+	return attempt, 0, nil
+}
+
+// RecordFailure records that the credential of attempt was wrong and returns
+// how long the next attempt must wait. user is the account the email belongs
+// to, or nil when there is none; only existing accounts are notified of a
+// lockout.
+func (g *Guard) RecordFailure(ctx context.Context, attempt *Attempt, user *models.User) (time.Duration, error) {
+	if attempt.ipFailures >= int64(g.opts.IPMaxAttempts) {
+		if err := g.store.Block(ctx, ipSubject(attempt.ip), g.opts.IPWindow); err != nil {
+			return 0, err
+		}
+	}
+
+	if attempt.failures >= int64(g.opts.MaxAttempts) {
+		if err := g.store.Block(ctx, accountSubject(attempt.email), g.opts.Lockout); err != nil {
+			return 0, err
+		}
+		if attempt.failures == int64(g.opts.MaxAttempts) && user != nil && g.notifier != nil {
+			until := time.Now().Add(g.opts.Lockout)
+			if err := g.notifier.NotifyLockout(ctx, *user, attempt.ip, until); err != nil {
+				log.Printf("Failed to send lockout notification to user ID %d: %v", user.ID, err)
+			}
+		}
+		return g.opts.Lockout, nil
+	}
+
+	// The delay was taken by Begin
+	return g.delayAfter(attempt.failures), nil
+}
+
+// Release stops counting attempt as a failure, for attempts that end without
+// the credential being judged wrong. Earlier failures of the email are kept.
+func (g *Guard) Release(ctx context.Context, attempt *Attempt) error {
+	err := errors.Join(g.release(ctx, ipSubject(attempt.ip)), g.release(ctx, emailSubject(attempt.email)))
+	if attempt.delayed {
+		if unblockErr := g.store.Unblock(ctx, emailSubject(attempt.email)); unblockErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to release login attempt: %w", unblockErr))
+		}
+	}
+	return err
+}
+
+func (g *Guard) release(ctx context.Context, subject string) error {
+	if err := g.store.ReleaseFailure(ctx, subject); err != nil {
+		return fmt.Errorf("failed to release login attempt: %w", err)
+	}
+	return nil
+}
+
+// delayAfter returns the delay imposed after the given number of failures
+func (g *Guard) delayAfter(failures int64) time.Duration {
+	over := failures - int64(g.opts.FreeAttempts)
+	if over <= 0 || g.opts.BaseDelay <= 0 {
+		return 0
+	}
+
+	delay := g.opts.BaseDelay
+	for i := int64(1); i < over && delay < g.opts.MaxDelay; i++ {
+		delay *= 2
+	}
+	if g.opts.MaxDelay > 0 && delay > g.opts.MaxDelay {
+		delay = g.opts.MaxDelay
+	}
+	return delay
+}
+
+// RecordSuccess forgets the failures of the email of attempt after a
+// successful login. Failures counted against the IP are kept.
+func (g *Guard) RecordSuccess(ctx context.Context, attempt *Attempt) error {
+	return errors.Join(g.release(ctx, ipSubject(attempt.ip)), g.store.Clear(ctx, emailSubject(attempt.email)))
+}
+
+// Unlock lifts a lockout of email and forgets its failures
+func (g *Guard) Unlock(ctx context.Context, email string) error {
+	if err := g.store.Clear(ctx, emailSubject(email), accountSubject(email)); err != nil {
+		return fmt.Errorf("failed to unlock %s: %w", email, err)
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package loginguard
+
+import (
+	"context"
+	"link-guardian/internal/models"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// recordingNotifier collects the users notified of a lockout
+type recordingNotifier struct {
+	mu    sync.Mutex
+	users []models.User
+}
+
+func (n *recordingNotifier) NotifyLockout(ctx context.Context, user models.User, ip string, until time.Time) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.users = append(n.users, user)
+	return nil
+}
+
+func testOptions() Options {
+	return Options{
+		MaxAttempts:   5,
+		Lockout:       15 * time.Minute,
+		FreeAttempts:  2,
+		BaseDelay:     time.Second,
+		MaxDelay:      3 * time.Second,
+		IPMaxAttempts: 8,
+		IPWindow:      time.Hour,
+	}
+}
+
+// newTestGuard returns a guard whose store runs on a clock the test advances
+func newTestGuard(opts Options, notifier Notifier) (*Guard, func(time.Duration)) {
+	store := NewMemoryStore()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+	return New(store, opts, notifier), func(d time.Duration) { now = now.Add(d) }
+}
+
+// fail makes a login attempt with a wrong credential and returns how long
+// the next attempt must wait
+func fail(t *testing.T, guard *Guard, email, ip string, user *models.User) time.Duration {
+	t.Helper()
+	ctx := context.Background()
+	attempt, wait, err := guard.Begin(ctx, email, ip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attempt == nil {
+		t.Fatalf("attempt for %s from %s refused for %v", email, ip, wait)
+	}
+	wait, err = guard.RecordFailure(ctx, attempt, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return wait
+}
+
+func TestFailuresDelayThenLockAccount(t *testing.T) {
+	ctx := context.Background()
+	notifier := &recordingNotifier{}
+	guard, advance := newTestGuard(testOptions(), notifier)
+	user := &models.User{ID: 7, Email: "alice@example.com"}
+
+	// Two free attempts, then doubling delays, then a lockout
+	want := []time.Duration{0, 0, time.Second, 2 * time.Second, 15 * time.Minute}
+	for i, wantWait := range want {
+		wait := fail(t, guard, "alice@example.com", "10.0.0.1", user)
+		if wait != wantWait {
+			t.Errorf("failure %d: wait = %v, want %v", i+1, wait, wantWait)
+		}
+		if wait > 0 && i < len(want)-1 {
+			if attempt, early, err := guard.Begin(ctx, "alice@example.com", "10.0.0.1"); err != nil || attempt != nil || early != wait {
+				t.Errorf("attempt within the delay of failure %d waits %v, %v; want it refused for %v", i+1, early, err, wait)
+			}
+			advance(wait)
+		}
+	}
+
+	if delay := guard.delayAfter(10); delay != 3*time.Second {
+		t.Errorf("delay after 10 failures = %v, want the 3s maximum", delay)
+	}
+
+	wait, err := guard.Check(ctx, "ALICE@example.com", "10.0.0.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wait < 14*time.Minute {
+		t.Errorf("Check after lockout = %v, want about 15m", wait)
+	}
+	if len(notifier.users) != 1 || notifier.users[0].ID != 7 {
+		t.Errorf("notified %+v, want user 7 once", notifier.users)
+	}
+
+	// Attempts while locked are refused without notifying again
+	if attempt, _, err := guard.Begin(ctx, "alice@example.com", "10.0.0.3"); err != nil || attempt != nil {
+		t.Errorf("attempt while locked = %+v, %v; want it refused", attempt, err)
+	}
+	if len(notifier.users) != 1 {
+		t.Errorf("notified %d times, want once", len(notifier.users))
+	}
+
+	if err := guard.Unlock(ctx, "alice@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if wait, err := guard.Check(ctx, "alice@example.com", "10.0.0.2"); err != nil || wait != 0 {
+		t.Errorf("Check after unlock = %v, %v; want 0", wait, err)
+	}
+}
+
+func TestUnknownEmailIsLockedWithoutNotification(t *testing.T) {
+	ctx := context.Background()
+	notifier := &recordingNotifier{}
+	guard, advance := newTestGuard(testOptions(), notifier)
+
+	for i := 0; i < testOptions().MaxAttempts; i++ {
+		if wait := fail(t, guard, "nobody@example.com", "10.0.0.1", nil); i < testOptions().MaxAttempts-1 {
+			advance(wait)
+		}
+	}
+
+	if wait, _ := guard.Check(ctx, "nobody@example.com", "10.0.0.2"); wait == 0 {
+		t.Error("unknown email was not locked like an existing account")
+	}
+	if len(notifier.users) != 0 {
+		t.Errorf("notified %+v for an unknown email", notifier.users)
+	}
+}
+
+func TestIPIsBlockedAcrossEmails(t *testing.T) {
+	ctx := context.Background()
+	guard := New(NewMemoryStore(), testOptions(), nil)
+
+	for i := 0; i < testOptions().IPMaxAttempts; i++ {
+		fail(t, guard, string(rune('a'+i))+"@example.com", "10.0.0.9", nil)
+	}
+
+	if wait, _ := guard.Check(ctx, "fresh@example.com", "10.0.0.9"); wait == 0 {
+		t.Error("IP was not blocked after spraying many emails")
+	}
+	if wait, _ := guard.Check(ctx, "fresh@example.com", "10.0.0.10"); wait != 0 {
+		t.Errorf("other IP waits %v, want 0", wait)
+	}
+}
+
+func TestSuccessClearsEmailFailures(t *testing.T) {
+	ctx := context.Background()
+	guard, advance := newTestGuard(testOptions(), nil)
+
+	for i := 0; i < 3; i++ {
+		advance(fail(t, guard, "bob@example.com", "10.0.0.1", nil))
+	}
+	attempt, _, err := guard.Begin(ctx, "bob@example.com", "10.0.0.1")
+	if err != nil || attempt == nil {
+		t.Fatalf("Begin = %+v, %v", attempt, err)
+	}
+	if err := guard.RecordSuccess(ctx, attempt); err != nil {
+		t.Fatal(err)
+	}
+
+	if wait, _ := guard.Check(ctx, "bob@example.com", "10.0.0.1"); wait != 0 {
+		t.Errorf("Check after success = %v, want 0", wait)
+	}
+	if wait := fail(t, guard, "bob@example.com", "10.0.0.1", nil); wait != 0 {
+		t.Errorf("first failure after success waits %v, want 0", wait)
+	}
+}
+
+func TestConcurrentAttemptsAreCapped(t *testing.T) {
+	ctx := context.Background()
+	opts := testOptions()
+	opts.BaseDelay = 0
+	guard := New(NewMemoryStore(), opts, nil)
+
+	// Every attempt passes the checks before any of them fails
+	var verified, refused atomic.Int64
+	var started, wg sync.WaitGroup
+	const concurrent = 50
+	started.Add(concurrent)
+	release := make(chan struct{})
+	for i := 0; i < concurrent; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			attempt, _, err := guard.Begin(ctx, "carol@example.com", "10.0.0.1")
+			started.Done()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if attempt == nil {
+				refused.Add(1)
+				return
+			}
+			verified.Add(1)
+			<-release
+			if _, err := guard.RecordFailure(ctx, attempt, nil); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	started.Wait()
+	close(release)
+	wg.Wait()
+
+	if got := verified.Load(); got != int64(opts.MaxAttempts) {
+		t.Errorf("%d concurrent attempts were verified, want %d", got, opts.MaxAttempts)
+	}
+	if refused.Load() != concurrent-verified.Load() {
+		t.Errorf("%d attempts refused, want the rest", refused.Load())
+	}
+	if wait, _ := guard.Check(ctx, "carol@example.com", "10.0.0.2"); wait == 0 {
+		t.Error("account was not locked after the concurrent failures")
+	}
+
+	// With delays, only one attempt past the free ones runs at a time
+	delayed := New(NewMemoryStore(), testOptions(), nil)
+	verified.Store(0)
+	for i := 0; i < concurrent; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if attempt, _, err := delayed.Begin(ctx, "dave@example.com", "10.0.0.1"); err == nil && attempt != nil {
+				verified.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if got, want := verified.Load(), int64(testOptions().FreeAttempts+1); got != want {
+		t.Errorf("%d concurrent attempts were verified with delays, want %d", got, want)
+	}
+}
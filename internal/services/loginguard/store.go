@@ -0,0 +1,172 @@
+package loginguard
+
+import (
+	"context"
+	"link-guardian/internal/repositories/redis"
+	"sync"
+	"time"
+)
+
+// AttemptStore counts failed logins and holds temporary blocks per subject,
+// such as an email address or an IP
+type AttemptStore interface {
+	// IncrementFailures counts a failure and returns the number of failures
+	// since the first one in the current window
+	IncrementFailures(ctx context.Context, subject string, window time.Duration) (int64, error)
+	// ReleaseFailure takes back one failure of subject within the current window
+	ReleaseFailure(ctx context.Context, subject string) error
+	// Block rejects logins for subject for the given duration
+	Block(ctx context.Context, subject string, duration time.Duration) error
+	// TryBlock blocks subject for the given duration unless it is already
+	// blocked, and reports whether it did
+	TryBlock(ctx context.Context, subject string, duration time.Duration) (bool, error)
+	// Unblock lifts a block of subject
+	Unblock(ctx context.Context, subject string) error
+	// BlockedFor returns how long subject remains blocked, or 0
+	BlockedFor(ctx context.Context, subject string) (time.Duration, error)
+	// Clear forgets the failures and blocks of each subject
+	Clear(ctx context.Context, subjects ...string) error
+}
+
+// RedisStore keeps attempts in Redis so limits hold across replicas
+type RedisStore struct{}
+
+// IncrementFailures implements AttemptStore
+func (RedisStore) IncrementFailures(ctx context.Context, subject string, window time.Duration) (int64, error) {
+	return redis.IncrementLoginFailures(ctx, subject, window)
+}
+
+// ReleaseFailure implements AttemptStore
+func (RedisStore) ReleaseFailure(ctx context.Context, subject string) error {
+	return redis.ReleaseLoginFailure(ctx, subject)
+}
+
+// Block implements AttemptStore
+func (RedisStore) Block(ctx context.Context, subject string, duration time.Duration) error {
+	return redis.BlockLogin(ctx, subject, duration)
+}
+
+// TryBlock implements AttemptStore
+func (RedisStore) TryBlock(ctx context.Context, subject string, duration time.Duration) (bool, error) {
+	return redis.TryBlockLogin(ctx, subject, duration)
+}
+
+// Unblock implements AttemptStore
+func (RedisStore) Unblock(ctx context.Context, subject string) error {
+	return redis.UnblockLogin(ctx, subject)
+}
+
+// BlockedFor implements AttemptStore
+func (RedisStore) BlockedFor(ctx context.Context, subject string) (time.Duration, error) {
+	return redis.LoginBlockedFor(ctx, subject)
+}
+
+// Clear implements AttemptStore
+func (RedisStore) Clear(ctx context.Context, subjects ...string) error {
+	return redis.ClearLoginFailures(ctx, subjects...)
+}
+
+// MemoryStore keeps attempts in process, for tests and single-instance development
+type MemoryStore struct {
+	mu       sync.Mutex
+	failures map[string]counter
+	blocks   map[string]time.Time
+	now      func() time.Time
+}
+
+type counter struct {
+	count   int64
+	expires time.Time
+}
+
+// NewMemoryStore creates an empty in-process store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		failures: map[string]counter{},
+		blocks:   map[string]time.Time{},
+		now:      time.Now,
+	}
+}
+
+// IncrementFailures implements AttemptStore
+func (s *MemoryStore) IncrementFailures(ctx context.Context, subject string, window time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	current := s.failures[subject]
+	if !current.expires.After(now) {
+		current = counter{expires: now.Add(window)}
+	}
+	current.count++
+	s.failures[subject] = current
+
+	return current.count, nil
+}
+
+// ReleaseFailure implements AttemptStore
+func (s *MemoryStore) ReleaseFailure(ctx context.Context, subject string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := s.failures[subject]
+	if current.expires.After(s.now()) && current.count > 0 {
+		current.count--
+		s.failures[subject] = current
+	}
+	return nil
+}
+
+// Block implements AttemptStore
+func (s *MemoryStore) Block(ctx context.Context, subject string, duration time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.blocks[subject] = s.now().Add(duration)
+	return nil
+}
+
+// TryBlock implements AttemptStore
+func (s *MemoryStore) TryBlock(ctx context.Context, subject string, duration time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if s.blocks[subject].After(now) {
+		return false, nil
+	}
+	s.blocks[subject] = now.Add(duration)
+	return true, nil
+}
+
+// Unblock implements AttemptStore
+func (s *MemoryStore) Unblock(ctx context.Context, subject string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.blocks, subject)
+	return nil
+}
+
+// BlockedFor implements AttemptStore
+func (s *MemoryStore) BlockedFor(ctx context.Context, subject string) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if remaining := s.blocks[subject].Sub(s.now()); remaining > 0 {
+		return remaining, nil
+	}
+	return 0, nil
+}
+
+// Clear implements AttemptStore
+func (s *MemoryStore) Clear(ctx context.Context, subjects ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, subject := range subjects {
+		delete(s.failures, subject)
+		delete(s.blocks, subject)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package mailer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileMailerWritesOneFilePerMessage(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	m, err := NewFileMailer(dir, "Link Guardian <no-reply@example.com>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, to := range []string{"a@example.com", "b@example.com"} {
+		if err := m.Send(context.Background(), Message{To: to, Subject: "Hello", Body: "line one\nline two"}); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("wrote %d files, want 2", len(files))
+	}
+
+	content, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"From: Link Guardian <no-reply@example.com>\r\n", "To: a@example.com\r\n", "Subject: Hello\r\n", "\r\n\r\nline one\r\nline two"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("message does not contain %q:\n%s", want, content)
+		}
+	}
+}
+
+func TestFormatStripsLineBreaksFromHeaders(t *testing.T) {
+	message := string(format("sender@example.com", Message{
+		To:      "victim@example.com\r\nBcc: attacker@example.com",
+		Subject: "Hi\nX-Injected: yes",
+	}))
+
+	headers, _, _ := strings.Cut(message, "\r\n\r\n")
+	for _, line := range strings.Split(headers, "\r\n") {
+		if strings.HasPrefix(line, "Bcc:") || strings.HasPrefix(line, "X-Injected:") {
+			t.Errorf("injected header line %q", line)
+		}
+	}
+}
+
+func TestNewSMTPMailerValidatesConfig(t *testing.T) {
+	if _, err := NewSMTPMailer(SMTPConfig{From: "no-reply@example.com"}); err == nil {
+		t.Error("expected error without a host")
+	}
+	if _, err := NewSMTPMailer(SMTPConfig{Host: "smtp.example.com", From: "not an address"}); err == nil {
+		t.Error("expected error for an invalid sender")
+	}
+
+	m, err := NewSMTPMailer(SMTPConfig{Host: "smtp.example.com", Port: 587, From: "Link Guardian <no-reply@example.com>"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.envelopeFrom != "no-reply@example.com" {
+		t.Errorf("envelope sender = %q, want no-reply@example.com", m.envelopeFrom)
+	}
+}
+
+func TestLinkWithToken(t *testing.T) {
+	got, err := LinkWithToken("https://app.example.com/verify-email?source=signup", "a+b/c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "https://app.example.com/verify-email?source=signup&token=a%2Bb%2Fc"; got != want {
+		t.Errorf("LinkWithToken = %q, want %q", got, want)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		time.Minute:      "1 minute",
+		30 * time.Minute: "30 minutes",
+		90 * time.Minute: "90 minutes",
+		time.Hour:        "1 hour",
+		48 * time.Hour:   "48 hours",
+	}
+	for d, want := range tests {
+		if got := FormatDuration(d); got != want {
+			t.Errorf("FormatDuration(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Package passwordreset issues single-use password reset tokens, emails them
+// to account owners and redeems them. Redeeming a token ends every existing
+// session of the account.
+package passwordreset
+
+import (
+	"context"
+	"fmt"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"link-guardian/internal/repositories/redis"
+	"link-guardian/internal/services/auth"
+	"link-guardian/internal/services/mailer"
+	"log"
+	"time"
+)
+
+// Options configures a Service
+type Options struct {
+	TokenTTL      time.Duration // How long a reset link stays valid
+	EmailInterval time.Duration // Minimum time between reset emails to one address
+	ResetURL      string        // Web app page the emailed link points to; the token is added as a query parameter
+}
+
+// Service issues and redeems password reset tokens
+type Service struct {
+	authService *auth.AuthService
+	mailer      mailer.Mailer
+	opts        Options
+}
+
+// NewService creates a password reset service sending its emails through m
+func NewService(authService *auth.AuthService, m mailer.Mailer, opts Options) *Service {
+	return &Service{authService: authService, mailer: m, opts: opts}
+}
+
+// SendResetEmail stores a new reset token for user and emails them a link
+// to redeem it. It reports false without sending anything when the address
+// was already sent an email within the email interval.
+func (s *Service) SendResetEmail(ctx context.Context, resets repositories.PasswordResetRepository, user models.User) (bool, error) {
+	allowed, err := redis.AllowEmail(ctx, "password-reset", user.Email, s.opts.EmailInterval)
+	if err != nil || !allowed {
+		return false, err
+	}
+
+	token, hash, err := s.authService.GenerateOpaqueToken()
+	if err != nil {
+		return false, err
+	}
+
+	_, err = resets.CreatePasswordResetToken(models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(s.opts.TokenTTL),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	link, err := mailer.LinkWithToken(s.opts.ResetURL, token)
+	if err != nil {
+		return false, err
+	}
+
+	err = s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your Link Guardian password",
+		Body: fmt.Sprintf("Hi %s,\n\n"+
+			"Someone asked to reset the password of your Link Guardian account. "+
+			"To choose a new password, open this link within %s:\n\n%s\n\n"+
+			"If you did not ask for this, you can ignore this email; your password has not been changed.\n",
+			user.Username, mailer.FormatDuration(s.opts.TokenTTL), link),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Reset sets a new password, which the caller has checked with
+// AuthService.ValidatePassword, for the owner of token and returns the
+// owner's ID. Refresh tokens are revoked together with the password change
+// and access tokens issued before it are rejected from then on. It returns
+// repositories.ErrPasswordResetTokenInvalid for unknown, expired or used tokens.
+func (s *Service) Reset(ctx context.Context, resets repositories.PasswordResetRepository, token, password string) (int, error) {
+	passwordHash, err := s.authService.HashPassword(password)
+	if err != nil {
+		return 0, err
+	}
+
+	userID, err := resets.ResetPassword(s.authService.HashOpaqueToken(token), passwordHash)
+	if err != nil {
+		return 0, err
+	}
+
+	// The password has changed at this point, so a failure here only leaves
+	// existing access tokens valid until they expire
+	if err := redis.DenyUserAccessTokens(ctx, userID, time.Now(), s.authService.AccessTokenTTL()); err != nil {
+		log.Printf("Failed to revoke access tokens of user ID %d after password reset: %v", userID, err)
+	}
+
+	return userID, nil
+}
@@ -0,0 +1,275 @@
+package qrcode
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg"
+	"image/png"
+	"os"
+	"strconv"
+	"strings"
+
+	skip2 "github.com/skip2/go-qrcode"
+)
+
+// Output formats supported by Generator.Render
+const (
+	FormatPNG = "png"
+	FormatSVG = "svg"
+)
+
+// Size and margin bounds accepted by Options.Validate
+const (
+	MinSize   = 64
+	MaxSize   = 2048
+	MaxMargin = 16
+)
+
+// logoScale is the share of the symbol width covered by the logo. Kept small
+// enough for level H error correction to recover the hidden modules.
+const logoScale = 0.22
+
+// Options controls how a QR code is rendered
+type Options struct {
+	Format     string
+	Size       int // Width and height in pixels
+	Margin     int // Quiet zone width in modules
+	Level      string
+	Foreground color.RGBA
+	Background color.RGBA
+	WithLogo   bool
+}
+
+// DefaultOptions returns black-on-white PNG options with a standard quiet zone
+func DefaultOptions() Options {
+	return Options{
+		Format:     FormatPNG,
+		Size:       256,
+		Margin:     4,
+		Level:      "M",
+		Foreground: color.RGBA{0, 0, 0, 255},
+		Background: color.RGBA{255, 255, 255, 255},
+	}
+}
+
+// Validate checks that the options are within supported bounds
+func (o Options) Validate() error {
+	if o.Format != FormatPNG && o.Format != FormatSVG {
+		return fmt.Errorf("format must be %q or %q", FormatPNG, FormatSVG)
+	}
+	if o.Size < MinSize || o.Size > MaxSize {
+		return fmt.Errorf("size must be between %d and %d pixels", MinSize, MaxSize)
+	}
+	if o.Margin < 0 || o.Margin > MaxMargin {
+		return fmt.Errorf("margin must be between 0 and %d modules", MaxMargin)
+	}
+	if _, err := recoveryLevel(o.Level); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ParseHexColor parses RGB, RRGGBB or RRGGBBAA hex colours, with or without a leading '#'
+func ParseHexColor(value string) (color.RGBA, error) {
+	hex := strings.TrimPrefix(value, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	if len(hex) != 8 {
+		return color.RGBA{}, fmt.Errorf("invalid colour %q", value)
+	}
+
+	n, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid colour %q", value)
+	}
+	return color.RGBA{R: uint8(n >> 24), G: uint8(n >> 16), B: uint8(n >> 8), A: uint8(n)}, nil
+}
+
+// Generator renders QR codes, optionally with a centred logo
+type Generator struct {
+	logo    image.Image
+	logoPNG []byte // logo re-encoded as PNG for embedding in SVG output
+}
+
+// NewGenerator creates a generator; logo may be nil when no logo is configured
+func NewGenerator(logo image.Image) (*Generator, error) {
+	g := &Generator{logo: logo}
+	if logo != nil {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, logo); err != nil {
+			return nil, fmt.Errorf("failed to encode QR logo: %w", err)
+		}
+		g.logoPNG = buf.Bytes()
+	}
+	return g, nil
+}
+
+// LoadLogo reads a PNG or JPEG logo from disk
+func LoadLogo(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open QR logo: %w", err)
+	}
+	defer f.Close()
+
+	logo, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode QR logo: %w", err)
+	}
+	return logo, nil
+}
+
+// HasLogo reports whether a logo is configured
+func (g *Generator) HasLogo() bool {
+	return g.logo != nil
+}
+
+// Render encodes content as a QR code and returns the image bytes and content type
+func (g *Generator) Render(content string, opts Options) ([]byte, string, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, "", err
+	}
+
+	withLogo := opts.WithLogo && g.logo != nil
+	level, _ := recoveryLevel(opts.Level)
+	if withLogo {
+		// The logo hides modules, so always use the highest error correction
+		level = skip2.Highest
+	}
+
+	code, err := skip2.New(content, level)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode QR code: %w", err)
+	}
+	code.DisableBorder = true
+	modules := code.Bitmap()
+
+	if opts.Format == FormatSVG {
+		svg, err := g.renderSVG(modules, opts, withLogo)
+		return svg, "image/svg+xml", err
+	}
+	img, err := g.renderPNG(modules, opts, withLogo)
+	return img, "image/png", err
+}
+
+func (g *Generator) renderPNG(modules [][]bool, opts Options, withLogo bool) ([]byte, error) {
+	total := len(modules) + 2*opts.Margin
+	scale := opts.Size / total
+	if scale < 1 {
+		return nil, fmt.Errorf("size %d is too small for this QR code; at least %d pixels are required", opts.Size, total)
+	}
+	offset := (opts.Size-scale*total)/2 + opts.Margin*scale
+
+	img := image.NewRGBA(image.Rect(0, 0, opts.Size, opts.Size))
+	draw.Draw(img, img.Bounds(), image.NewUniform(opts.Background), image.Point{}, draw.Src)
+
+	fg := image.NewUniform(opts.Foreground)
+	for y, row := range modules {
+		for x, set := range row {
+			if set {
+				rect := image.Rect(offset+x*scale, offset+y*scale, offset+(x+1)*scale, offset+(y+1)*scale)
+				draw.Draw(img, rect, fg, image.Point{}, draw.Src)
+			}
+		}
+	}
+
+	if withLogo {
+		symbol := len(modules) * scale
+		logoSize := int(float64(symbol) * logoScale)
+		pad := scale
+		start := (opts.Size - logoSize) / 2
+		padRect := image.Rect(start-pad, start-pad, start+logoSize+pad, start+logoSize+pad)
+		draw.Draw(img, padRect, image.NewUniform(opts.Background), image.Point{}, draw.Src)
+		logoRect := image.Rect(start, start, start+logoSize, start+logoSize)
+		draw.Draw(img, logoRect, resize(g.logo, logoSize), image.Point{}, draw.Over)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode PNG: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (g *Generator) renderSVG(modules [][]bool, opts Options, withLogo bool) ([]byte, error) {
+	total := len(modules) + 2*opts.Margin
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		opts.Size, opts.Size, total, total)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" %s/>`, total, total, svgFill(opts.Background))
+
+	buf.WriteString(`<path ` + svgFill(opts.Foreground) + ` d="`)
+	for y, row := range modules {
+		for x, set := range row {
+			if set {
+				fmt.Fprintf(&buf, "M%d %dh1v1h-1z", x+opts.Margin, y+opts.Margin)
+			}
+		}
+	}
+	buf.WriteString(`"/>`)
+
+	if withLogo {
+		logoSize := float64(len(modules)) * logoScale
+		start := (float64(total) - logoSize) / 2
+		fmt.Fprintf(&buf, `<rect x="%.2f" y="%.2f" width="%.2f" height="%.2f" %s/>`,
+			start-1, start-1, logoSize+2, logoSize+2, svgFill(opts.Background))
+		fmt.Fprintf(&buf, `<image x="%.2f" y="%.2f" width="%.2f" height="%.2f" href="data:image/png;base64,%s"/>`,
+			start, start, logoSize, logoSize, base64.StdEncoding.EncodeToString(g.logoPNG))
+	}
+
+	buf.WriteString(`</svg>`)
+	return buf.Bytes(), nil
+}
+
+func svgFill(c color.RGBA) string {
+	return fmt.Sprintf(`fill="#%02x%02x%02x" fill-opacity="%.3f"`, c.R, c.G, c.B, float64(c.A)/255)
+}
+
+func recoveryLevel(level string) (skip2.RecoveryLevel, error) {
+	switch strings.ToUpper(level) {
+	case "L":
+		return skip2.Low, nil
+	case "M":
+		return skip2.Medium, nil
+	case "Q":
+		return skip2.High, nil
+	case "H":
+		return skip2.Highest, nil
+	}
+	return 0, fmt.Errorf("error correction level must be one of L, M, Q or H")
+}
+
+// resize scales src to a size x size square using nearest-neighbour sampling,
+// preserving the aspect ratio and centring the result
+func resize(src image.Image, size int) image.Image {
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w == 0 || h == 0 {
+		return dst
+	}
+
+	scaledW, scaledH := size, size
+	if w > h {
+		scaledH = size * h / w
+	} else {
+		scaledW = size * w / h
+	}
+	offsetX, offsetY := (size-scaledW)/2, (size-scaledH)/2
+
+	for y := 0; y < scaledH; y++ {
+		for x := 0; x < scaledW; x++ {
+			dst.Set(offsetX+x, offsetY+y, src.At(b.Min.X+x*w/scaledW, b.Min.Y+y*h/scaledH))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,101 @@
+package qrcode
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestParseHexColor(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    color.RGBA
+		wantErr bool
+	}{
+		{"#000", color.RGBA{0, 0, 0, 255}, false},
+		{"ff8800", color.RGBA{255, 136, 0, 255}, false},
+		{"#11223344", color.RGBA{17, 34, 51, 68}, false},
+		{"zzzzzz", color.RGBA{}, true},
+		{"#12345", color.RGBA{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseHexColor(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseHexColor(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseHexColor(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRenderPNG(t *testing.T) {
+	logo := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	generator, err := NewGenerator(logo)
+	if err != nil {
+		t.Fatalf("NewGenerator failed: %v", err)
+	}
+
+	opts := DefaultOptions()
+	opts.Size = 300
+	opts.WithLogo = true
+	opts.Background = color.RGBA{255, 255, 0, 255}
+
+	data, contentType, err := generator.Render("https://example.com/l/abc", opts)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if contentType != "image/png" {
+		t.Errorf("content type = %q", contentType)
+	}
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("output is not a PNG: %v", err)
+	}
+	if img.Bounds().Dx() != 300 || img.Bounds().Dy() != 300 {
+		t.Errorf("image size = %v, want 300x300", img.Bounds())
+	}
+	if got := color.RGBAModel.Convert(img.At(0, 0)); got != opts.Background {
+		t.Errorf("corner pixel = %v, want background %v", got, opts.Background)
+	}
+}
+
+func TestRenderSVG(t *testing.T) {
+	generator, _ := NewGenerator(nil)
+
+	opts := DefaultOptions()
+	opts.Format = FormatSVG
+	opts.Foreground = color.RGBA{0x12, 0x34, 0x56, 255}
+
+	data, contentType, err := generator.Render("https://example.com/l/abc", opts)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if contentType != "image/svg+xml" {
+		t.Errorf("content type = %q", contentType)
+	}
+	svg := string(data)
+	if !strings.HasPrefix(svg, "<svg") || !strings.Contains(svg, `fill="#123456"`) {
+		t.Errorf("unexpected SVG output: %.120s", svg)
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Size = MaxSize + 1
+	if opts.Validate() == nil {
+		t.Error("expected oversized QR code to be rejected")
+	}
+
+	opts = DefaultOptions()
+	opts.Level = "X"
+	if opts.Validate() == nil {
+		t.Error("expected unknown error correction level to be rejected")
+	}
+}
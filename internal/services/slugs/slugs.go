@@ -0,0 +1,127 @@
+package slugs
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Charset lists the characters allowed in a slug
+const Charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_"
+
+// ErrReservedSlug is returned when a custom slug matches a reserved word
+var ErrReservedSlug = errors.New("slug is reserved")
+
+// Policy validates custom slugs against length, charset and reserved-word rules
+type Policy struct {
+	minLength int
+	maxLength int
+	reserved  map[string]struct{}
+}
+
+// NewPolicy creates a slug policy; reserved words are matched case-insensitively
+func NewPolicy(minLength, maxLength int, reserved []string) (*Policy, error) {
+	if minLength < 1 {
+		return nil, errors.New("slug minimum length must be positive")
+	}
+	if maxLength < minLength {
+		return nil, fmt.Errorf("slug maximum length %d is below the minimum length %d", maxLength, minLength)
+	}
+
+	p := &Policy{
+		minLength: minLength,
+		maxLength: maxLength,
+		reserved:  make(map[string]struct{}, len(reserved)),
+	}
+	for _, word := range reserved {
+		p.reserved[strings.ToLower(strings.TrimSpace(word))] = struct{}{}
+	}
+	return p, nil
+}
+
+// Validate checks that a custom slug may be used
+func (p *Policy) Validate(slug string) error {
+	if len(slug) < p.minLength || len(slug) > p.maxLength {
+		return fmt.Errorf("slug must be between %d and %d characters long", p.minLength, p.maxLength)
+	}
+	for _, r := range slug {
+		if !strings.ContainsRune(Charset, r) {
+			return fmt.Errorf("slug may only contain letters, digits, '-' and '_'")
+		}
+	}
+	if strings.HasPrefix(slug, "-") || strings.HasSuffix(slug, "-") {
+		return fmt.Errorf("slug must not start or end with '-'")
+	}
+	if p.IsReserved(slug) {
+		return ErrReservedSlug
+	}
+	return nil
+}
+
+// IsReserved reports whether slug matches a reserved word
+func (p *Policy) IsReserved(slug string) bool {
+	_, reserved := p.reserved[strings.ToLower(slug)]
+	return reserved
+}
+
+// Candidates returns up to n valid variations of slug that can be offered as
+// alternatives when it is taken. Availability must still be checked by the caller.
+func (p *Policy) Candidates(slug string, n int) []string {
+	// Leave room for the longest suffix, such as "-x7q"
+	base := sanitize(slug)
+	if room := max(p.maxLength-4, 0); len(base) > room {
+		base = base[:room]
+	}
+	base = strings.Trim(base, "-")
+	if base == "" {
+		base = "link"
+	}
+
+	seen := make(map[string]struct{})
+	var candidates []string
+	add := func(candidate string) {
+		if _, ok := seen[candidate]; ok || len(candidates) >= n {
+			return
+		}
+		if p.Validate(candidate) == nil {
+			seen[candidate] = struct{}{}
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	for i := 2; i <= 4; i++ {
+		add(fmt.Sprintf("%s-%d", base, i))
+	}
+	for attempts := 0; len(candidates) < n && attempts < n*4; attempts++ {
+		add(base + "-" + randomSuffix(3))
+	}
+	return candidates
+}
+
+// sanitize replaces characters outside the charset with '-'
+func sanitize(slug string) string {
+	var b strings.Builder
+	for _, r := range strings.TrimSpace(slug) {
+		if strings.ContainsRune(Charset, r) {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
+
+func randomSuffix(length int) string {
+	const alphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+	b := make([]byte, length)
+	for i := range b {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(alphabet))))
+		if err != nil {
+			return "x"
+		}
+		b[i] = alphabet[n.Int64()]
+	}
+	return string(b)
+}
@@ -0,0 +1,98 @@
+package slugs
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func newTestPolicy(t *testing.T, minLength, maxLength int) *Policy {
+	t.Helper()
+	policy, err := NewPolicy(minLength, maxLength, []string{"Admin", " login "})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return policy
+}
+
+func TestNewPolicyRejectsInvalidLengths(t *testing.T) {
+	for _, lengths := range [][2]int{{0, 10}, {-1, 10}, {5, 4}} {
+		if _, err := NewPolicy(lengths[0], lengths[1], nil); err == nil {
+			t.Errorf("NewPolicy(%d, %d) accepted invalid lengths", lengths[0], lengths[1])
+		}
+	}
+	if _, err := NewPolicy(1, 1, nil); err != nil {
+		t.Errorf("NewPolicy(1, 1) = %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	policy := newTestPolicy(t, 3, 10)
+	for slug, valid := range map[string]bool{
+		"abc":         true,
+		"my_link-2":   true,
+		"MixedCase":   true,
+		"ab":          false,
+		"abcdefghijk": false,
+		"has space":   false,
+		"emoji🙂":      false,
+		"-start":      false,
+		"end-":        false,
+	} {
+		if err := policy.Validate(slug); (err == nil) != valid {
+			t.Errorf("Validate(%q) = %v, want valid %v", slug, err, valid)
+		}
+	}
+
+	if err := policy.Validate("ADMIN"); !errors.Is(err, ErrReservedSlug) {
+		t.Errorf("Validate of a reserved word = %v, want ErrReservedSlug", err)
+	}
+}
+
+func TestIsReserved(t *testing.T) {
+	policy := newTestPolicy(t, 3, 10)
+	for slug, reserved := range map[string]bool{
+		"admin":  true,
+		"ADMIN":  true,
+		"login":  true,
+		"admins": false,
+		"links":  false,
+	} {
+		if got := policy.IsReserved(slug); got != reserved {
+			t.Errorf("IsReserved(%q) = %v, want %v", slug, got, reserved)
+		}
+	}
+}
+
+func TestCandidates(t *testing.T) {
+	policy := newTestPolicy(t, 3, 10)
+
+	candidates := policy.Candidates("summer sale!", 5)
+	if len(candidates) != 5 {
+		t.Fatalf("Candidates returned %v, want 5", candidates)
+	}
+	if candidates[0] != "summer-2" || candidates[1] != "summer-3" || candidates[2] != "summer-4" {
+		t.Errorf("Candidates = %v, want numbered variations of the truncated slug first", candidates)
+	}
+	seen := make(map[string]bool)
+	for _, candidate := range candidates {
+		if err := policy.Validate(candidate); err != nil {
+			t.Errorf("candidate %q is invalid: %v", candidate, err)
+		}
+		if seen[candidate] {
+			t.Errorf("candidate %q returned twice", candidate)
+		}
+		seen[candidate] = true
+	}
+
+	if candidates := policy.Candidates("---", 1); len(candidates) != 1 || !strings.HasPrefix(candidates[0], "link-") {
+		t.Errorf("Candidates of a slug without usable characters = %v", candidates)
+	}
+
+	// Policies too short for any suffix offer nothing instead of panicking
+	for maxLength := 1; maxLength <= 4; maxLength++ {
+		if candidates := newTestPolicy(t, 1, maxLength).Candidates("taken", 3); len(candidates) != 0 {
+			t.Errorf("Candidates with maximum length %d = %v, want none", maxLength, candidates)
+		}
+	}
+}
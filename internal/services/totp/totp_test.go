@@ -0,0 +1,82 @@
+package totp
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+// TestHOTPMatchesRFC6238Vectors checks the SHA-1 test vectors from RFC 6238
+// Appendix B, which use 8 digit codes
+func TestHOTPMatchesRFC6238Vectors(t *testing.T) {
+	key := []byte("12345678901234567890")
+	vectors := []struct {
+		unix int64
+		code string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+
+	for _, v := range vectors {
+		step := Step(time.Unix(v.unix, 0))
+		if got := hotp(key, uint64(step), 8); got != v.code {
+			t.Errorf("code at %d = %s, want %s", v.unix, got, v.code)
+		}
+	}
+}
+
+func TestMatchAllowsClockSkew(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Unix(1700000000, 0)
+	previous, err := Code(secret, Step(now)-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(previous) != Digits {
+		t.Fatalf("code %q has %d digits, want %d", previous, len(previous), Digits)
+	}
+
+	step, ok := Match(secret, previous, now, 1)
+	if !ok || step != Step(now)-1 {
+		t.Errorf("Match of previous step = %d, %v; want %d, true", step, ok, Step(now)-1)
+	}
+	if _, ok := Match(secret, previous, now, 0); ok {
+		t.Error("Match accepted the previous step without skew")
+	}
+
+	stale, err := Code(secret, Step(now)-2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := Match(secret, stale, now, 1); ok && stale != previous {
+		t.Error("Match accepted a code two steps old")
+	}
+	if _, ok := Match(secret, "12345", now, 1); ok {
+		t.Error("Match accepted a code of the wrong length")
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri, err := url.Parse(ProvisioningURI("Link Guardian", "alice@example.com", "JBSWY3DPEHPK3PXP"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Path != "/Link Guardian:alice@example.com" {
+		t.Errorf("unexpected URI %s", uri)
+	}
+	query := uri.Query()
+	if query.Get("secret") != "JBSWY3DPEHPK3PXP" || query.Get("issuer") != "Link Guardian" ||
+		query.Get("digits") != "6" || query.Get("period") != "30" {
+		t.Errorf("unexpected query %v", query)
+	}
+}
@@ -0,0 +1,209 @@
+// Package twofactor enrols users in TOTP two-factor authentication, checks
+// the second factor at login and issues the one-time recovery codes that
+// stand in for a lost authenticator.
+package twofactor
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"link-guardian/internal/services/auth"
+	"link-guardian/internal/services/totp"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidCode is returned for authenticator codes that do not match or
+	// were already used and for unknown or used recovery codes
+	ErrInvalidCode = errors.New("invalid two-factor code")
+	// ErrInvalidChallenge is returned for challenge tokens that are malformed or expired
+	ErrInvalidChallenge = errors.New("invalid two-factor challenge")
+)
+
+const (
+	// recoveryCodeCount is how many recovery codes enabling two-factor authentication issues
+	recoveryCodeCount = 10
+	// skew is how many time steps an authenticator's clock may be off by
+	skew = 1
+)
+
+// recoveryAlphabet has 32 symbols, so every random byte maps to one without bias
+const recoveryAlphabet = "abcdefghijklmnopqrstuvwxyz234567"
+
+// Options configures a Service
+type Options struct {
+	Issuer       string        // Name authenticator apps show next to the account
+	ChallengeTTL time.Duration // How long users have to enter the second factor after their password
+}
+
+// Service enrols users and checks their second factor
+type Service struct {
+	authService *auth.AuthService
+	opts        Options
+	now         func() time.Time
+}
+
+// Enrolment is a new TOTP secret together with the otpauth:// URI that adds
+// it to an authenticator app
+type Enrolment struct {
+	Secret string
+	URI    string
+}
+
+// NewService creates a two-factor authentication service
+func NewService(authService *auth.AuthService, opts Options) *Service {
+	return &Service{authService: authService, opts: opts, now: time.Now}
+}
+
+// ChallengeTTL returns how long challenge tokens are valid
+func (s *Service) ChallengeTTL() time.Duration {
+	return s.opts.ChallengeTTL
+}
+
+// Setup stores a new pending TOTP secret for user, replacing any earlier
+// pending one. It returns repositories.ErrTwoFactorEnabled when two-factor
+// authentication is already enabled.
+func (s *Service) Setup(repo repositories.TwoFactorRepository, user models.User) (Enrolment, error) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return Enrolment{}, err
+	}
+
+	if err := repo.StartTwoFactorEnrolment(user.ID, secret); err != nil {
+		return Enrolment{}, err
+	}
+
+	return Enrolment{Secret: secret, URI: totp.ProvisioningURI(s.opts.Issuer, user.Email, secret)}, nil
+}
+
+// Enable enables two-factor authentication once the user proves with code
+// that their authenticator holds the pending secret, and returns the
+// recovery codes, which are only ever shown this once. It returns
+// repositories.ErrTwoFactorNotFound when nothing is pending and
+// ErrInvalidCode when code does not match.
+func (s *Service) Enable(repo repositories.TwoFactorRepository, userID int, code string) ([]string, error) {
+	enrolment, err := repo.GetTwoFactor(userID)
+	if err != nil {
+		return nil, err
+	}
+	if enrolment.Enabled() {
+		return nil, repositories.ErrTwoFactorEnabled
+	}
+
+	step, ok := totp.Match(enrolment.Secret, normalizeCode(code), s.now(), skew)
+	if !ok {
+		return nil, ErrInvalidCode
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		if codes[i], err = generateRecoveryCode(); err != nil {
+			return nil, err
+		}
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+
+	if err := repo.EnableTwoFactor(userID, step, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Verify checks the second factor of a user with two-factor authentication
+// enabled. code is either a current authenticator code, which is then not
+// accepted again, or an unused recovery code, which is then used up. It
+// returns ErrInvalidCode when neither matches.
+func (s *Service) Verify(repo repositories.TwoFactorRepository, userID int, code string) error {
+	enrolment, err := repo.GetTwoFactor(userID)
+	if errors.Is(err, repositories.ErrTwoFactorNotFound) {
+		return ErrInvalidCode
+	}
+	if err != nil {
+		return err
+	}
+	if !enrolment.Enabled() {
+		return ErrInvalidCode
+	}
+
+	code = normalizeCode(code)
+	if isAuthenticatorCode(code) {
+		step, ok := totp.Match(enrolment.Secret, code, s.now(), skew)
+		if !ok {
+			return ErrInvalidCode
+		}
+		accepted, err := repo.UseTOTPStep(userID, step)
+		if err != nil {
+			return err
+		}
+		if !accepted {
+			return ErrInvalidCode
+		}
+		return nil
+	}
+
+	err = repo.UseRecoveryCode(userID, hashRecoveryCode(code))
+	if errors.Is(err, repositories.ErrRecoveryCodeInvalid) {
+		return ErrInvalidCode
+	}
+	return err
+}
+
+// Challenge issues the token that lets the user with ID userID finish
+// logging in with their second factor
+func (s *Service) Challenge(userID int) (string, error) {
+	return s.authService.GenerateTwoFactorChallenge(userID, s.opts.ChallengeTTL)
+}
+
+// ValidateChallenge returns the user ID a challenge token was issued for or
+// ErrInvalidChallenge
+func (s *Service) ValidateChallenge(token string) (int, error) {
+	userID, err := s.authService.ValidateTwoFactorChallenge(token)
+	if err != nil {
+		return 0, ErrInvalidChallenge
+	}
+	return userID, nil
+}
+
+// normalizeCode drops the separators users type or copy along with a code
+func normalizeCode(code string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(code))
+}
+
+func isAuthenticatorCode(code string) bool {
+	if len(code) != totp.Digits {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// generateRecoveryCode returns a random code formatted as xxxxx-xxxxx
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate recovery code: %v", err)
+	}
+
+	code := make([]byte, len(b))
+	for i, v := range b {
+		code[i] = recoveryAlphabet[int(v)%len(recoveryAlphabet)]
+	}
+	return string(code[:5]) + "-" + string(code[5:]), nil
+}
+
+// hashRecoveryCode returns the SHA-256 hex digest a recovery code is stored
+// and looked up by
+func hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(normalizeCode(code)))
+	return hex.EncodeToString(sum[:])
+}
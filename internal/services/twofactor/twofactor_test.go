@@ -0,0 +1,138 @@
+package twofactor
+
+import (
+	"errors"
+	"link-guardian/internal/repositories"
+	"link-guardian/internal/repositories/memory"
+	"link-guardian/internal/services/auth"
+	"link-guardian/internal/services/totp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestService returns a service whose clock reads the returned time
+func newTestService() (*Service, *time.Time) {
+	now := time.Unix(1700000000, 0)
+	service := NewService(auth.NewAuthService("test-secret", time.Minute, time.Hour), Options{
+		Issuer:       "Link Guardian",
+		ChallengeTTL: time.Minute,
+	})
+	service.now = func() time.Time { return now }
+	return service, &now
+}
+
+func codeAt(t *testing.T, secret string, at time.Time) string {
+	t.Helper()
+	code, err := totp.Code(secret, totp.Step(at))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+func TestEnableAndVerify(t *testing.T) {
+	store := memory.NewStore()
+	userID, err := store.CreateUser("grace", "grace@example.com", "hash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, _ := store.GetUserByID(userID)
+	service, now := newTestService()
+
+	enrolment, err := service.Setup(store, *user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(enrolment.URI, "otpauth://totp/") || !strings.Contains(enrolment.URI, enrolment.Secret) {
+		t.Errorf("unexpected provisioning URI %s", enrolment.URI)
+	}
+
+	if _, err := service.Enable(store, userID, codeAt(t, enrolment.Secret, now.Add(10*totp.Period))); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("Enable with a code from the future: got %v, want ErrInvalidCode", err)
+	}
+	codes, err := service.Enable(store, userID, codeAt(t, enrolment.Secret, *now))
+	if err != nil {
+		t.Fatalf("Enable: %v", err)
+	}
+	if len(codes) != recoveryCodeCount {
+		t.Fatalf("got %d recovery codes, want %d", len(codes), recoveryCodeCount)
+	}
+	if _, err := service.Setup(store, *user); !errors.Is(err, repositories.ErrTwoFactorEnabled) {
+		t.Errorf("Setup while enabled: got %v, want ErrTwoFactorEnabled", err)
+	}
+
+	// The code that enabled two-factor authentication cannot log in
+	if err := service.Verify(store, userID, codeAt(t, enrolment.Secret, *now)); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("Verify with the enrolment code: got %v, want ErrInvalidCode", err)
+	}
+
+	*now = now.Add(totp.Period)
+	code := codeAt(t, enrolment.Secret, *now)
+	if err := service.Verify(store, userID, code[:3]+" "+code[3:]); err != nil {
+		t.Errorf("Verify with the next code: %v", err)
+	}
+	if err := service.Verify(store, userID, code); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("Verify with a replayed code: got %v, want ErrInvalidCode", err)
+	}
+
+	// Recovery codes work once each, however they are typed
+	if err := service.Verify(store, userID, strings.ToUpper(strings.ReplaceAll(codes[0], "-", ""))); err != nil {
+		t.Errorf("Verify with a recovery code: %v", err)
+	}
+	if err := service.Verify(store, userID, codes[0]); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("Verify with a used recovery code: got %v, want ErrInvalidCode", err)
+	}
+
+	enabled, err := store.GetTwoFactor(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if enabled.RecoveryCodesLeft != recoveryCodeCount-1 {
+		t.Errorf("%d recovery codes left, want %d", enabled.RecoveryCodesLeft, recoveryCodeCount-1)
+	}
+}
+
+func TestVerifyWithoutTwoFactor(t *testing.T) {
+	store := memory.NewStore()
+	userID, err := store.CreateUser("heidi", "heidi@example.com", "hash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, _ := store.GetUserByID(userID)
+	service, now := newTestService()
+
+	if err := service.Verify(store, userID, "123456"); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("Verify without enrolment: got %v, want ErrInvalidCode", err)
+	}
+
+	// A pending enrolment is no second factor yet
+	enrolment, err := service.Setup(store, *user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := service.Verify(store, userID, codeAt(t, enrolment.Secret, *now)); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("Verify with a pending enrolment: got %v, want ErrInvalidCode", err)
+	}
+}
+
+func TestChallenge(t *testing.T) {
+	service, _ := newTestService()
+
+	token, err := service.Challenge(42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if userID, err := service.ValidateChallenge(token); err != nil || userID != 42 {
+		t.Errorf("ValidateChallenge = %d, %v; want 42", userID, err)
+	}
+
+	// Other tokens signed with the same secret are not challenges
+	access, err := service.authService.GenerateAccessToken(42, "grace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.ValidateChallenge(access.Token); !errors.Is(err, ErrInvalidChallenge) {
+		t.Errorf("ValidateChallenge with an access token: got %v, want ErrInvalidChallenge", err)
+	}
+}
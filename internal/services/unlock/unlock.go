@@ -0,0 +1,75 @@
+package unlock
+
+import (
+	"context"
+	"link-guardian/internal/repositories/redis"
+	"link-guardian/internal/services/auth"
+	"time"
+)
+
+// Service issues unlock cookies for password-protected links and limits
+// failed password attempts per link and IP. Links are named by their slug, or
+// by hostname/slug for links on custom domains.
+type Service struct {
+	authService *auth.AuthService
+	ttl         time.Duration
+	maxAttempts int
+	lockout     time.Duration
+}
+
+// NewService creates an unlock service. Unlock tokens live for ttl; after
+// maxAttempts failures an IP is blocked from a link for the lockout duration.
+func NewService(authService *auth.AuthService, ttl time.Duration, maxAttempts int, lockout time.Duration) *Service {
+	return &Service{
+		authService: authService,
+		ttl:         ttl,
+		maxAttempts: maxAttempts,
+		lockout:     lockout,
+	}
+}
+
+// CookieName returns the name of the unlock cookie for slug
+func CookieName(slug string) string {
+	return "lg_unlock_" + slug
+}
+
+// TTL returns how long an unlock stays valid
+func (s *Service) TTL() time.Duration {
+	return s.ttl
+}
+
+// IssueToken creates a signed unlock token for slug
+func (s *Service) IssueToken(slug string) (string, error) {
+	return s.authService.GenerateLinkUnlockToken(slug, s.ttl)
+}
+
+// IsUnlocked reports whether token unlocks slug
+func (s *Service) IsUnlocked(slug, token string) bool {
+	if token == "" {
+		return false
+	}
+	return s.authService.ValidateLinkUnlockToken(token, slug) == nil
+}
+
+// VerifyPassword checks a password against the link's stored hash
+func (s *Service) VerifyPassword(password, passwordHash string) bool {
+	return s.authService.VerifyPassword(password, passwordHash) == nil
+}
+
+// Attempt counts a password attempt for slug from ip before the password is
+// checked, so concurrent guesses cannot all pass before the first failure is
+// recorded. It returns false when ip has used up its attempts and the
+// password must not be checked, and whether this attempt is the last one
+// allowed. A correct password undoes the count with Reset.
+func (s *Service) Attempt(ctx context.Context, slug, ip string) (allowed, last bool, err error) {
+	attempts, err := redis.IncrementUnlockFailures(ctx, slug, ip, s.lockout)
+	if err != nil {
+		return false, false, err
+	}
+	return attempts <= int64(s.maxAttempts), attempts >= int64(s.maxAttempts), nil
+}
+
+// Reset clears failed attempts after a successful unlock
+func (s *Service) Reset(ctx context.Context, slug, ip string) error {
+	return redis.ResetUnlockFailures(ctx, slug, ip)
+}
@@ -0,0 +1,50 @@
+// Package useragent classifies visitors by their User-Agent header
+package useragent
+
+import "strings"
+
+// Parse extracts device, browser and OS information from a user agent string
+func Parse(userAgent string) (deviceType, browser, os string) {
+	userAgent = strings.ToLower(userAgent)
+
+	// Determine device type
+	if strings.Contains(userAgent, "mobile") || strings.Contains(userAgent, "android") || strings.Contains(userAgent, "iphone") {
+		deviceType = "mobile"
+	} else if strings.Contains(userAgent, "tablet") || strings.Contains(userAgent, "ipad") {
+		deviceType = "tablet"
+	} else {
+		deviceType = "desktop"
+	}
+
+	// Determine browser
+	if strings.Contains(userAgent, "firefox") {
+		browser = "Firefox"
+	} else if strings.Contains(userAgent, "chrome") && !strings.Contains(userAgent, "edg") {
+		browser = "Chrome"
+	} else if strings.Contains(userAgent, "safari") && !strings.Contains(userAgent, "chrome") {
+		browser = "Safari"
+	} else if strings.Contains(userAgent, "edg") {
+		browser = "Edge"
+	} else if strings.Contains(userAgent, "opera") {
+		browser = "Opera"
+	} else {
+		browser = "Other"
+	}
+
+	// Determine OS
+	if strings.Contains(userAgent, "windows") {
+		os = "Windows"
+	} else if strings.Contains(userAgent, "mac os") {
+		os = "macOS"
+	} else if strings.Contains(userAgent, "linux") {
+		os = "Linux"
+	} else if strings.Contains(userAgent, "android") {
+		os = "Android"
+	} else if strings.Contains(userAgent, "iphone") || strings.Contains(userAgent, "ipad") {
+		os = "iOS"
+	} else {
+		os = "Other"
+	}
+
+	return
+}
@@ -0,0 +1,120 @@
+// Package verification confirms that users own the email address they signed
+// up with and decides what accounts without a verified address may do.
+// Verification links carry a signed token, so nothing is stored until the
+// link is followed.
+package verification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories"
+	"link-guardian/internal/repositories/redis"
+	"link-guardian/internal/services/auth"
+	"link-guardian/internal/services/mailer"
+	"strings"
+	"time"
+)
+
+// ErrInvalidToken is returned for verification tokens that are malformed,
+// expired or issued for an address the user no longer has
+var ErrInvalidToken = errors.New("invalid verification token")
+
+// Options configures a Service
+type Options struct {
+	TokenTTL       time.Duration // How long a verification link stays valid
+	ResendInterval time.Duration // Minimum time between verification emails to one address
+	VerifyURL      string        // Web app page the emailed link points to; the token is added as a query parameter
+}
+
+// Service sends verification emails and redeems their links
+type Service struct {
+	authService *auth.AuthService
+	mailer      mailer.Mailer
+	opts        Options
+}
+
+// NewService creates a verification service sending its emails through m
+func NewService(authService *auth.AuthService, m mailer.Mailer, opts Options) *Service {
+	return &Service{authService: authService, mailer: m, opts: opts}
+}
+
+// SendVerificationEmail emails user a link to verify their address. It
+// reports false without sending anything when the address was already sent
+// a verification email within the resend interval.
+func (s *Service) SendVerificationEmail(ctx context.Context, user models.User) (bool, error) {
+	allowed, err := redis.AllowEmail(ctx, "verification", user.Email, s.opts.ResendInterval)
+	if err != nil || !allowed {
+		return false, err
+	}
+
+	token, err := s.authService.GenerateEmailVerificationToken(user.ID, user.Email, s.opts.TokenTTL)
+	if err != nil {
+		return false, err
+	}
+
+	link, err := mailer.LinkWithToken(s.opts.VerifyURL, token)
+	if err != nil {
+		return false, err
+	}
+
+	err = s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your Link Guardian email address",
+		Body: fmt.Sprintf("Hi %s,\n\n"+
+			"Please confirm that this is your email address by opening this link within %s:\n\n%s\n\n"+
+			"If you did not create a Link Guardian account, you can ignore this email.\n",
+			user.Username, mailer.FormatDuration(s.opts.TokenTTL), link),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Verify marks the email address of the user a verification token was issued
+// to as verified and returns that user. It returns ErrInvalidToken when the
+// token is not valid or the user's address has changed since it was issued.
+func (s *Service) Verify(users repositories.UserRepository, token string) (*models.User, error) {
+	userID, email, err := s.authService.ValidateEmailVerificationToken(token)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	user, err := users.GetUserByID(userID)
+	if errors.Is(err, repositories.ErrUserNotFound) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(user.Email, email) {
+		return nil, ErrInvalidToken
+	}
+
+	if err := users.MarkEmailVerified(user.ID); err != nil {
+		return nil, err
+	}
+	user.EmailVerified = true
+
+	return user, nil
+}
+
+// Policy decides what users whose email address is not verified may do.
+// Verified users are never restricted.
+type Policy struct {
+	MaxLinks     int  // Active links an unverified user may have; negative means no limit
+	AllowAPIKeys bool // Whether unverified users may create API keys
+}
+
+// CanCreateLink reports whether user may create another link while owning activeLinks
+func (p Policy) CanCreateLink(user models.User, activeLinks int) bool {
+	return user.EmailVerified || p.MaxLinks < 0 || activeLinks < p.MaxLinks
+}
+
+// CanCreateAPIKey reports whether user may create an API key
+func (p Policy) CanCreateAPIKey(user models.User) bool {
+	return user.EmailVerified || p.AllowAPIKeys
+}
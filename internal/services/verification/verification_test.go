@@ -0,0 +1,118 @@
+package verification
+
+import (
+	"context"
+	"errors"
+	"link-guardian/internal/models"
+	"link-guardian/internal/repositories/memory"
+	"link-guardian/internal/services/auth"
+	"link-guardian/internal/testutil/mailtest"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+)
+
+var tokenPattern = regexp.MustCompile(`token=([^\s]+)`)
+
+func TestVerifyWithEmailedToken(t *testing.T) {
+	store := memory.NewStore()
+	userID, err := store.CreateUser("frank", "frank@example.com", "hash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, _ := store.GetUserByID(userID)
+
+	mail := mailtest.NewRecordingMailer()
+	authService := auth.NewAuthService("test-secret", time.Minute, time.Hour)
+	service := NewService(authService, mail, Options{
+		TokenTTL:  time.Hour,
+		VerifyURL: "http://app.test/verify-email",
+	})
+
+	if sent, err := service.SendVerificationEmail(context.Background(), *user); err != nil || !sent {
+		t.Fatalf("SendVerificationEmail = %v, %v", sent, err)
+	}
+	msg := mail.Next(t, "")
+	if msg.To != "frank@example.com" {
+		t.Fatalf("sent %+v, want an email to frank@example.com", msg)
+	}
+	mail.None(t, "")
+	match := tokenPattern.FindStringSubmatch(msg.Body)
+	if match == nil {
+		t.Fatalf("email body has no verification link:\n%s", msg.Body)
+	}
+	token, err := url.QueryUnescape(match[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verified, err := service.Verify(store, token)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if verified.ID != userID || !verified.EmailVerified {
+		t.Errorf("Verify = %+v, want user %d verified", verified, userID)
+	}
+
+	// Verifying again is harmless
+	if _, err := service.Verify(store, token); err != nil {
+		t.Errorf("second Verify: %v", err)
+	}
+}
+
+func TestVerifyRejectsInvalidTokens(t *testing.T) {
+	store := memory.NewStore()
+	userID, err := store.CreateUser("grace", "grace@example.com", "hash")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authService := auth.NewAuthService("test-secret", time.Minute, time.Hour)
+	service := NewService(authService, mailtest.NewRecordingMailer(), Options{TokenTTL: time.Hour})
+
+	expired, _ := authService.GenerateEmailVerificationToken(userID, "grace@example.com", -time.Minute)
+	otherAddress, _ := authService.GenerateEmailVerificationToken(userID, "old@example.com", time.Hour)
+	unknownUser, _ := authService.GenerateEmailVerificationToken(userID+1, "grace@example.com", time.Hour)
+	unlock, _ := authService.GenerateLinkUnlockToken("slug", time.Hour)
+
+	tokens := map[string]string{
+		"malformed":     "not-a-token",
+		"expired":       expired,
+		"other address": otherAddress,
+		"unknown user":  unknownUser,
+		"unlock token":  unlock,
+	}
+	for name, token := range tokens {
+		if _, err := service.Verify(store, token); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s: got %v, want ErrInvalidToken", name, err)
+		}
+	}
+
+	if user, _ := store.GetUserByID(userID); user.EmailVerified {
+		t.Error("an invalid token verified the email")
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	unverified := models.User{}
+	verified := models.User{EmailVerified: true}
+
+	capped := Policy{MaxLinks: 2}
+	if !capped.CanCreateLink(unverified, 1) || capped.CanCreateLink(unverified, 2) {
+		t.Error("capped policy should allow 2 links for unverified users")
+	}
+	if !capped.CanCreateLink(verified, 100) {
+		t.Error("verified users should not be capped")
+	}
+	if capped.CanCreateAPIKey(unverified) || !capped.CanCreateAPIKey(verified) {
+		t.Error("API keys should require verification unless allowed")
+	}
+
+	if (Policy{MaxLinks: 0}).CanCreateLink(unverified, 0) {
+		t.Error("MaxLinks 0 should forbid link creation")
+	}
+	if !(Policy{MaxLinks: -1}).CanCreateLink(unverified, 1000) {
+		t.Error("negative MaxLinks should not limit links")
+	}
+}
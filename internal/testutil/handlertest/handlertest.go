@@ -0,0 +1,60 @@
+// Package handlertest provides the router scaffolding and request helpers
+// shared by the HTTP handler tests.
+package handlertest
+
+import (
+	"encoding/json"
+	"link-guardian/internal/handlers/middleware"
+	"link-guardian/internal/repositories"
+	"link-guardian/internal/services/auth"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuthService signs the tokens of test requests
+var AuthService = auth.NewAuthService("test-secret", 15*time.Minute, time.Hour)
+
+// NewRouter returns a router with the authentication service and the
+// repositories of store in the context, as the server sets them up
+func NewRouter(store repositories.Store) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.AuthServiceMiddleware(AuthService))
+	router.Use(middleware.RepositoriesMiddleware(store))
+	return router
+}
+
+// Token returns an access token for userID
+func Token(t testing.TB, userID int) string {
+	t.Helper()
+	token, err := AuthService.GenerateAccessToken(userID, "tester")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token.Token
+}
+
+// Request sends a request authenticated as userID
+func Request(t testing.TB, router http.Handler, userID int, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+Token(t, userID))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// Decode unmarshals the JSON response body into v
+func Decode(t testing.TB, w *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("invalid JSON response %q: %v", w.Body.String(), err)
+	}
+}
@@ -0,0 +1,59 @@
+// Package mailtest provides a mailer that hands sent messages to tests.
+package mailtest
+
+import (
+	"context"
+	"link-guardian/internal/services/mailer"
+	"strings"
+	"testing"
+	"time"
+)
+
+// RecordingMailer keeps sent messages until the test reads them
+type RecordingMailer struct {
+	sent chan mailer.Message
+}
+
+// NewRecordingMailer returns a mailer holding up to 10 unread messages
+func NewRecordingMailer() *RecordingMailer {
+	return &RecordingMailer{sent: make(chan mailer.Message, 10)}
+}
+
+func (m *RecordingMailer) Send(ctx context.Context, msg mailer.Message) error {
+	m.sent <- msg
+	return nil
+}
+
+// Next waits for the next message whose subject contains subject, skipping
+// others. Handlers may send messages after the response.
+func (m *RecordingMailer) Next(t testing.TB, subject string) mailer.Message {
+	t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case msg := <-m.sent:
+			if strings.Contains(msg.Subject, subject) {
+				return msg
+			}
+		case <-timeout:
+			t.Fatalf("no %q email was sent", subject)
+			return mailer.Message{}
+		}
+	}
+}
+
+// None fails if a message whose subject contains subject is sent shortly
+func (m *RecordingMailer) None(t testing.TB, subject string) {
+	t.Helper()
+	timeout := time.After(50 * time.Millisecond)
+	for {
+		select {
+		case msg := <-m.sent:
+			if strings.Contains(msg.Subject, subject) {
+				t.Errorf("unexpected email: %+v", msg)
+			}
+		case <-timeout:
+			return
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Package pgtest provides PostgreSQL fixtures for integration tests.
+//
+// Tests using it are skipped unless TEST_DATABASE_DSN points at a disposable
+// database, for example:
+//
+//	TEST_DATABASE_DSN="host=localhost user=postgres dbname=linkguardian_test sslmode=disable" go test ./...
+package pgtest
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"link-guardian/internal/migrations"
+	"os"
+	"testing"
+
+	_ "github.com/lib/pq"
+)
+
+// Open connects to the test database and applies the project migrations.
+// The connection is closed when the test finishes.
+func Open(t testing.TB) *sql.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN not set; skipping PostgreSQL integration test")
+	}
+
+	conn, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	if err := conn.Ping(); err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	applyMigrations(t, conn)
+	return conn
+}
+
+// CreateUser inserts a user with a random username and returns its ID
+func CreateUser(t testing.TB, conn *sql.DB) int {
+	t.Helper()
+
+	name := "u" + RandomString(t, 8)
+	var userID int
+	err := conn.QueryRow(`INSERT INTO users (username, email, password) VALUES ($1, $2, 'x') RETURNING id`,
+		name, name+"@example.com").Scan(&userID)
+	if err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+	return userID
+}
+
+// RandomString returns a random lowercase hex string of length n
+func RandomString(t testing.TB, n int) string {
+	t.Helper()
+
+	b := make([]byte, (n+1)/2)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatalf("failed to generate random string: %v", err)
+	}
+	return hex.EncodeToString(b)[:n]
+}
+
+func applyMigrations(t testing.TB, conn *sql.DB) {
+	t.Helper()
+
+	runner, err := migrations.NewRunner(conn)
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+	if _, err := runner.Up(context.Background()); err != nil {
+		t.Fatalf("failed to apply migrations: %v", err)
+	}
+}
@@ -0,0 +1,38 @@
+// Package redistest provides a Redis connection for integration tests.
+//
+// Tests using it are skipped unless TEST_REDIS_URL points at a disposable
+// Redis database, for example:
+//
+//	TEST_REDIS_URL="redis://localhost:6379/15" go test ./...
+package redistest
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Open connects to the test Redis database. The connection is closed when
+// the test finishes.
+func Open(t testing.TB) *goredis.Client {
+	t.Helper()
+
+	url := os.Getenv("TEST_REDIS_URL")
+	if url == "" {
+		t.Skip("TEST_REDIS_URL not set; skipping Redis integration test")
+	}
+
+	opt, err := goredis.ParseURL(url)
+	if err != nil {
+		t.Fatalf("failed to parse TEST_REDIS_URL: %v", err)
+	}
+	client := goredis.NewClient(opt)
+	t.Cleanup(func() { client.Close() })
+
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		t.Fatalf("failed to connect to test Redis: %v", err)
+	}
+	return client
+}
@@ -6,7 +6,14 @@ import { BrowserRouter, Routes, Route } from "react-router-dom";
 import Index from "./pages/Index";
 import Signup from "./pages/Signup";
 import Login from "./pages/Login";
+import ForgotPassword from "./pages/ForgotPassword";
+import ResetPassword from "./pages/ResetPassword";
+import VerifyEmail from "./pages/VerifyEmail";
 import Dashboard from "./pages/Dashboard";
+import Security from "./pages/Security";
+import Workspaces from "./pages/Workspaces";
+import AcceptInvitation from "./pages/AcceptInvitation";
+import Admin from "./pages/Admin";
 import CreateLink from "./pages/CreateLink";
 import Links from "./pages/Links";
 import Analytics from "./pages/Analytics";
@@ -24,7 +31,14 @@ const App = () => (
           <Route path="/" element={<Index />} />
           <Route path="/signup" element={<Signup />} />
           <Route path="/login" element={<Login />} />
+          <Route path="/forgot-password" element={<ForgotPassword />} />
+          <Route path="/reset-password" element={<ResetPassword />} />
+          <Route path="/verify-email" element={<VerifyEmail />} />
           <Route path="/dashboard" element={<Dashboard />} />
+          <Route path="/security" element={<Security />} />
+          <Route path="/workspaces" element={<Workspaces />} />
+          <Route path="/accept-invitation" element={<AcceptInvitation />} />
+          <Route path="/admin" element={<Admin />} />
           <Route path="/links/create" element={<CreateLink />} />
           <Route path="/links" element={<Links />} />
           <Route path="/analytics" element={<Analytics />} />
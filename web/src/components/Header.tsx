@@ -1,13 +1,16 @@
 import { Button } from "@/components/ui/button";
-import { Shield, LogOut } from "lucide-react";
+import { Shield, LogOut, KeyRound, Users, Gavel } from "lucide-react";
 import { useNavigate } from "react-router-dom";
 import { useToast } from "@/hooks/use-toast";
+import authService from "@/services/auth";
 
 const Header = () => {
   const navigate = useNavigate();
   const { toast } = useToast();
   const handleLogout = () => {
     localStorage.removeItem("token");
+    localStorage.removeItem("workspace_id");
+    localStorage.removeItem("user_role");
     toast({
       title: "Logged out",
       description: "You have been successfully logged out.",
@@ -22,14 +25,43 @@ const Header = () => {
           <h1 className="text-2xl font-bold text-white">
             LinkGuardian
           </h1>
-        </div>        <Button
-          onClick={handleLogout}
-          variant="outline"
-          className="border-gray-600 text-white bg-gray-800 hover:bg-gray-700 hover:text-white transition-all duration-300"
-        >
-          <LogOut className="w-4 h-4 mr-2" />
-          Logout
-        </Button>
+        </div>
+        <div className="flex items-center gap-2">
+          {authService.isAdmin() && (
+            <Button
+              onClick={() => navigate("/admin")}
+              variant="outline"
+              className="border-gray-600 text-white bg-gray-800 hover:bg-gray-700 hover:text-white transition-all duration-300"
+            >
+              <Gavel className="w-4 h-4 mr-2" />
+              Admin
+            </Button>
+          )}
+          <Button
+            onClick={() => navigate("/workspaces")}
+            variant="outline"
+            className="border-gray-600 text-white bg-gray-800 hover:bg-gray-700 hover:text-white transition-all duration-300"
+          >
+            <Users className="w-4 h-4 mr-2" />
+            Workspaces
+          </Button>
+          <Button
+            onClick={() => navigate("/security")}
+            variant="outline"
+            className="border-gray-600 text-white bg-gray-800 hover:bg-gray-700 hover:text-white transition-all duration-300"
+          >
+            <KeyRound className="w-4 h-4 mr-2" />
+            Security
+          </Button>
+          <Button
+            onClick={handleLogout}
+            variant="outline"
+            className="border-gray-600 text-white bg-gray-800 hover:bg-gray-700 hover:text-white transition-all duration-300"
+          >
+            <LogOut className="w-4 h-4 mr-2" />
+            Logout
+          </Button>
+        </div>
       </div>
     </div>
   );
@@ -0,0 +1,86 @@
+import { useEffect, useRef, useState } from "react";
+import { Card, CardContent, CardDescription, CardHeader, CardTitle } from "@/components/ui/card";
+import { Shield, CheckCircle, XCircle } from "lucide-react";
+import { Link as RouterLink, useSearchParams } from "react-router-dom";
+import authService from "@/services/auth";
+import workspaceService, { Workspace } from "@/services/workspaces";
+
+type Status = "accepting" | "accepted" | "failed" | "signed-out";
+
+const AcceptInvitation = () => {
+  const [searchParams] = useSearchParams();
+  const token = searchParams.get("token") ?? "";
+  const signedIn = authService.isAuthenticated();
+  const [status, setStatus] = useState<Status>(!token ? "failed" : signedIn ? "accepting" : "signed-out");
+  const [message, setMessage] = useState(token ? "" : "This invitation link is incomplete.");
+  const [workspace, setWorkspace] = useState<Workspace | null>(null);
+  const requested = useRef(false);
+
+  useEffect(() => {
+    // Strict mode runs effects twice in development; accept only once
+    if (!token || !signedIn || requested.current) return;
+    requested.current = true;
+
+    workspaceService.acceptInvitation(token)
+      .then((joined) => {
+        setWorkspace(joined);
+        workspaceService.setCurrentWorkspace(joined);
+        setStatus("accepted");
+      })
+      .catch((error: Error) => {
+        setStatus("failed");
+        setMessage(error.message);
+      });
+  }, [token, signedIn]);
+
+  return (
+    <div className="min-h-screen bg-black flex items-center justify-center p-4">
+      <div className="w-full max-w-md">
+        <div className="text-center mb-8">
+          <div className="flex justify-center items-center mb-4">
+            <Shield className="w-12 h-12 text-blue-500 mr-3" />
+            <h1 className="text-3xl font-bold text-white">
+              LinkGuardian
+            </h1>
+          </div>
+        </div>
+
+        <Card className="bg-gray-900 border-gray-800 shadow-2xl">
+          <CardHeader className="text-center">
+            <div className="flex justify-center mb-2">
+              {status === "accepted" && <CheckCircle className="w-10 h-10 text-green-500" />}
+              {status === "failed" && <XCircle className="w-10 h-10 text-red-500" />}
+            </div>
+            <CardTitle className="text-2xl font-bold text-white">
+              {status === "accepting" && "Joining..."}
+              {status === "accepted" && "Invitation Accepted"}
+              {status === "failed" && "Invitation Failed"}
+              {status === "signed-out" && "Sign In to Accept"}
+            </CardTitle>
+            <CardDescription className="text-gray-400">
+              {status === "accepting" && "Adding you to the workspace"}
+              {status === "accepted" && workspace && `You are now ${workspace.role} in ${workspace.name}.`}
+              {status === "failed" && message}
+              {status === "signed-out" &&
+                "Sign in, or sign up with the invited email address, then open the invitation link again."}
+            </CardDescription>
+          </CardHeader>
+          {status !== "accepting" && (
+            <CardContent>
+              <p className="text-center text-gray-400">
+                <RouterLink
+                  to={signedIn ? "/workspaces" : "/login"}
+                  className="text-blue-500 hover:text-blue-400 font-semibold transition-colors"
+                >
+                  {signedIn ? "Go to your workspaces" : "Sign in"}
+                </RouterLink>
+              </p>
+            </CardContent>
+          )}
+        </Card>
+      </div>
+    </div>
+  );
+};
+
+export default AcceptInvitation;
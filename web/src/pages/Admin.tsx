@@ -0,0 +1,219 @@
+import { useEffect, useState } from "react";
+import { Button } from "@/components/ui/button";
+import { Card, CardContent, CardDescription, CardHeader, CardTitle } from "@/components/ui/card";
+import { Input } from "@/components/ui/input";
+import { BarChart3, Link2, Search, Users } from "lucide-react";
+import Header from "@/components/Header";
+import { useToast } from "@/hooks/use-toast";
+import adminService, { AdminUser, InstanceStats } from "@/services/admin";
+import { Link } from "@/services/links";
+
+const inputClassName = "pl-10 bg-gray-800 border-gray-700 text-white placeholder-gray-500 focus:border-blue-500 focus:ring-blue-500";
+const buttonClassName = "border-gray-600 text-white bg-gray-800 hover:bg-gray-700 hover:text-white";
+const dangerClassName = "border-red-700 text-red-300 bg-gray-800 hover:bg-red-950";
+
+const statLabels: [keyof InstanceStats, string][] = [
+  ["users", "Users"],
+  ["admin_users", "Admins"],
+  ["disabled_users", "Disabled users"],
+  ["unverified_users", "Unverified users"],
+  ["shared_workspaces", "Shared workspaces"],
+  ["links", "Links"],
+  ["taken_down_links", "Taken down links"],
+  ["deleted_links", "Deleted links"],
+  ["total_clicks", "Total clicks"],
+  ["clicks_last_24h", "Clicks in the last 24 hours"],
+];
+
+const SearchForm = ({ placeholder, onSearch }: { placeholder: string; onSearch: (query: string) => void }) => {
+  const [query, setQuery] = useState("");
+  return (
+    <form
+      onSubmit={(e) => {
+        e.preventDefault();
+        onSearch(query.trim());
+      }}
+      className="flex gap-2"
+    >
+      <div className="relative flex-1">
+        <Search className="absolute left-3 top-3 w-4 h-4 text-gray-500" />
+        <Input value={query} onChange={(e) => setQuery(e.target.value)} className={inputClassName} placeholder={placeholder} />
+      </div>
+      <Button type="submit" className="bg-blue-600 hover:bg-blue-700 text-white font-semibold">
+        Search
+      </Button>
+    </form>
+  );
+};
+
+const Admin = () => {
+  const [stats, setStats] = useState<InstanceStats | null>(null);
+  const [users, setUsers] = useState<AdminUser[]>([]);
+  const [userTotal, setUserTotal] = useState(0);
+  const [userQuery, setUserQuery] = useState("");
+  const [links, setLinks] = useState<Link[]>([]);
+  const [linkTotal, setLinkTotal] = useState(0);
+  const [linkQuery, setLinkQuery] = useState("");
+  const { toast } = useToast();
+
+  const showError = (title: string, error: any) => {
+    toast({
+      title,
+      description: error.message || "Something went wrong. Please try again later.",
+      variant: "destructive",
+      duration: 5000,
+    });
+  };
+
+  const loadStats = async () => {
+    try {
+      setStats(await adminService.getStats());
+    } catch (error) {
+      showError("Failed to load stats", error);
+    }
+  };
+
+  const searchUsers = async (query: string) => {
+    try {
+      const page = await adminService.searchUsers(query);
+      setUsers(page.items);
+      setUserTotal(page.total);
+      setUserQuery(query);
+    } catch (error) {
+      showError("Failed to search users", error);
+    }
+  };
+
+  const searchLinks = async (query: string) => {
+    try {
+      const page = await adminService.searchLinks(query);
+      setLinks(page.items);
+      setLinkTotal(page.total);
+      setLinkQuery(query);
+    } catch (error) {
+      showError("Failed to search links", error);
+    }
+  };
+
+  useEffect(() => {
+    loadStats();
+    searchUsers("");
+    searchLinks("");
+  }, []);
+
+  const toggleDisabled = async (user: AdminUser) => {
+    if (!user.disabled && !window.confirm(`Disable ${user.username}? Their sessions end immediately.`)) return;
+    try {
+      await adminService.setUserDisabled(user.id, !user.disabled);
+      await Promise.all([searchUsers(userQuery), loadStats()]);
+    } catch (error: any) {
+      showError("Could not update user", error);
+    }
+  };
+
+  const toggleTakedown = async (link: Link) => {
+    try {
+      if (link.taken_down_at) {
+        await adminService.restoreLink(link.slug);
+      } else {
+        const reason = window.prompt(`Why is /${link.slug} being taken down? The owner sees this reason.`);
+        if (!reason?.trim()) return;
+        await adminService.takeDownLink(link.slug, reason.trim());
+      }
+      await Promise.all([searchLinks(linkQuery), loadStats()]);
+    } catch (error: any) {
+      showError("Could not update link", error);
+    }
+  };
+
+  return (
+    <div className="min-h-screen bg-black">
+      <Header />
+
+      <div className="container mx-auto px-4 py-8 max-w-4xl space-y-6">
+        <h2 className="text-3xl font-bold text-white mb-2">Administration</h2>
+
+        <Card className="bg-gray-900 border-gray-800">
+          <CardHeader>
+            <CardTitle className="text-white flex items-center">
+              <BarChart3 className="w-5 h-5 mr-2 text-blue-500" />
+              Instance
+            </CardTitle>
+          </CardHeader>
+          <CardContent className="grid grid-cols-2 md:grid-cols-5 gap-4">
+            {stats && statLabels.map(([key, label]) => (
+              <div key={key}>
+                <p className="text-2xl font-bold text-white">{stats[key].toLocaleString()}</p>
+                <p className="text-sm text-gray-400">{label}</p>
+              </div>
+            ))}
+          </CardContent>
+        </Card>
+
+        <Card className="bg-gray-900 border-gray-800">
+          <CardHeader>
+            <CardTitle className="text-white flex items-center">
+              <Users className="w-5 h-5 mr-2 text-blue-500" />
+              Users
+            </CardTitle>
+            <CardDescription className="text-gray-400">
+              Disabled accounts cannot log in or use API keys. Showing {users.length} of {userTotal}.
+            </CardDescription>
+          </CardHeader>
+          <CardContent className="space-y-3">
+            <SearchForm placeholder="Username or email" onSearch={searchUsers} />
+            {users.map((user) => (
+              <div key={user.id} className="flex items-center justify-between gap-2">
+                <div>
+                  <p className="text-white">
+                    {user.username}
+                    {user.role === "admin" && <span className="ml-2 text-sm text-blue-400">admin</span>}
+                  </p>
+                  <p className="text-sm text-gray-400">
+                    {user.email}
+                    {!user.email_verified && " · unverified"}
+                    {user.disabled && " · disabled"}
+                  </p>
+                </div>
+                <Button variant="outline" onClick={() => toggleDisabled(user)} className={user.disabled ? buttonClassName : dangerClassName}>
+                  {user.disabled ? "Enable" : "Disable"}
+                </Button>
+              </div>
+            ))}
+          </CardContent>
+        </Card>
+
+        <Card className="bg-gray-900 border-gray-800">
+          <CardHeader>
+            <CardTitle className="text-white flex items-center">
+              <Link2 className="w-5 h-5 mr-2 text-blue-500" />
+              Links
+            </CardTitle>
+            <CardDescription className="text-gray-400">
+              Taken down links answer 410 Gone until they are restored. Showing {links.length} of {linkTotal}.
+            </CardDescription>
+          </CardHeader>
+          <CardContent className="space-y-3">
+            <SearchForm placeholder="Slug or target URL" onSearch={searchLinks} />
+            {links.map((link) => (
+              <div key={link.id} className="flex items-center justify-between gap-2">
+                <div className="min-w-0">
+                  <p className="text-white">/{link.slug}</p>
+                  <p className="text-sm text-gray-400 truncate">{link.target_url}</p>
+                  {link.taken_down_at && (
+                    <p className="text-sm text-red-400">Taken down: {link.takedown_reason}</p>
+                  )}
+                </div>
+                <Button variant="outline" onClick={() => toggleTakedown(link)} className={link.taken_down_at ? buttonClassName : dangerClassName}>
+                  {link.taken_down_at ? "Restore" : "Take down"}
+                </Button>
+              </div>
+            ))}
+          </CardContent>
+        </Card>
+      </div>
+    </div>
+  );
+};
+
+export default Admin;
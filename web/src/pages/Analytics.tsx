@@ -10,7 +10,6 @@ import { Link, useLocation } from "react-router-dom";
 import Header from "@/components/Header";
 import logService, { AccessLog } from "@/services/logs";
 import linkService, { Link as LinkType } from "@/services/links";
-import authService from "@/services/auth";
 
 interface Log {
   id: number;
@@ -45,9 +44,8 @@ const Analytics = () => {
   }, []);  const fetchLogs = async () => {
     setIsLoadingLogs(true);
     try {
-      // Get the current user ID from the JWT token
-      const userId = authService.getCurrentUserId();
-      const logs = await logService.getLogsByUser(userId, parseInt(logLimit));
+      // Logs are scoped to the authenticated user by the API
+      const logs = await logService.getLogsByUser(parseInt(logLimit));
       
       // Map link_id to string and resolve slugs - ensure we have all links loaded
       if (links.length === 0) {
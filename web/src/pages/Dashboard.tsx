@@ -1,10 +1,12 @@
 import { Card, CardContent, CardDescription, CardHeader, CardTitle } from "@/components/ui/card";
 import { Button } from "@/components/ui/button";
-import { Link2, Plus, Eye, BarChart, Hash, Clock, TrendingUp } from "lucide-react";
+import { Link2, Plus, Eye, BarChart, Hash, Clock, TrendingUp, MailWarning } from "lucide-react";
 import { Link } from "react-router-dom";
 import Header from "@/components/Header";
 import { useEffect, useState } from "react";
 import linkService, { Link as LinkInterface } from "@/services/links";
+import authService from "@/services/auth";
+import { useToast } from "@/hooks/use-toast";
 
 // Utility function to decode JWT token
 const decodeJWT = (token: string) => {
@@ -25,6 +27,30 @@ const Dashboard = () => {
   const [links, setLinks] = useState<LinkInterface[]>([]);
   const [loading, setLoading] = useState(true);
   const [username, setUsername] = useState<string>("");
+  const [emailVerified, setEmailVerified] = useState(authService.isEmailVerified());
+  const [isResending, setIsResending] = useState(false);
+  const { toast } = useToast();
+
+  const resendVerification = async () => {
+    setIsResending(true);
+    try {
+      await authService.resendVerification();
+      toast({
+        title: "Verification email sent",
+        description: "Check your inbox for the verification link.",
+      });
+    } catch (error: any) {
+      setEmailVerified(authService.isEmailVerified());
+      toast({
+        title: "Could not send email",
+        description: error.message,
+        variant: "destructive",
+        duration: 5000,
+      });
+    } finally {
+      setIsResending(false);
+    }
+  };
 
   // Get username from JWT token
   useEffect(() => {
@@ -107,6 +133,23 @@ const Dashboard = () => {
           <p className="text-gray-400">Here's what's happening with your links today.</p>
         </div>
 
+        {!emailVerified && (
+          <div className="mb-8 flex flex-col sm:flex-row sm:items-center justify-between gap-4 rounded-lg border border-yellow-700 bg-yellow-950/40 p-4">
+            <div className="flex items-center text-yellow-200">
+              <MailWarning className="w-5 h-5 mr-3 shrink-0" />
+              <span>Verify your email address to lift the limits on new accounts.</span>
+            </div>
+            <Button
+              onClick={resendVerification}
+              disabled={isResending}
+              variant="outline"
+              className="border-yellow-700 text-yellow-200 hover:bg-yellow-900"
+            >
+              {isResending ? "Sending..." : "Resend email"}
+            </Button>
+          </div>
+        )}
+
         {/* Stats Cards */}
         <div className="grid md:grid-cols-2 lg:grid-cols-4 gap-6 mb-8">
           <Card className="bg-gray-900 border-gray-800 hover:bg-gray-800 transition-all duration-300">
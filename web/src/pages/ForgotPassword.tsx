@@ -0,0 +1,110 @@
+import { useState } from "react";
+import { Button } from "@/components/ui/button";
+import { Card, CardContent, CardDescription, CardHeader, CardTitle } from "@/components/ui/card";
+import { Input } from "@/components/ui/input";
+import { Label } from "@/components/ui/label";
+import { Shield, Mail, ArrowLeft } from "lucide-react";
+import { Link as RouterLink } from "react-router-dom";
+import { useToast } from "@/hooks/use-toast";
+import authService from "@/services/auth";
+
+const ForgotPassword = () => {
+  const [email, setEmail] = useState("");
+  const [isLoading, setIsLoading] = useState(false);
+  const [isSent, setIsSent] = useState(false);
+  const { toast } = useToast();
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setIsLoading(true);
+
+    try {
+      await authService.forgotPassword(email);
+      setIsSent(true);
+    } catch (error: any) {
+      toast({
+        title: "Request failed",
+        description: error.message || "Something went wrong. Please try again later.",
+        variant: "destructive",
+        duration: 5000,
+      });
+    } finally {
+      setIsLoading(false);
+    }
+  };
+
+  return (
+    <div className="min-h-screen bg-black flex items-center justify-center p-4">
+      <div className="w-full max-w-md">
+        <div className="text-center mb-8">
+          <RouterLink to="/login" className="inline-flex items-center text-gray-400 hover:text-gray-300 transition-colors mb-4">
+            <ArrowLeft className="w-4 h-4 mr-2" />
+            Back to Login
+          </RouterLink>
+          <div className="flex justify-center items-center mb-4">
+            <Shield className="w-12 h-12 text-blue-500 mr-3" />
+            <h1 className="text-3xl font-bold text-white">
+              LinkGuardian
+            </h1>
+          </div>
+        </div>
+
+        <Card className="bg-gray-900 border-gray-800 shadow-2xl">
+          <CardHeader className="text-center">
+            <CardTitle className="text-2xl font-bold text-white">Forgot Password</CardTitle>
+            <CardDescription className="text-gray-400">
+              {isSent
+                ? "If an account exists for this email, a reset link is on its way."
+                : "Enter your email and we'll send you a link to reset your password"}
+            </CardDescription>
+          </CardHeader>
+          <CardContent>
+            {isSent ? (
+              <p className="text-center text-gray-400">
+                The link expires after a while. Didn't get it?{" "}
+                <button
+                  type="button"
+                  onClick={() => setIsSent(false)}
+                  className="text-blue-500 hover:text-blue-400 font-semibold transition-colors"
+                >
+                  Try again
+                </button>
+              </p>
+            ) : (
+              <form onSubmit={handleSubmit} className="space-y-4">
+                <div className="space-y-2">
+                  <Label htmlFor="email" className="text-white font-medium">
+                    Email
+                  </Label>
+                  <div className="relative">
+                    <Mail className="absolute left-3 top-3 w-4 h-4 text-gray-500" />
+                    <Input
+                      id="email"
+                      name="email"
+                      type="email"
+                      required
+                      value={email}
+                      onChange={(e) => setEmail(e.target.value)}
+                      className="pl-10 bg-gray-800 border-gray-700 text-white placeholder-gray-500 focus:border-blue-500 focus:ring-blue-500"
+                      placeholder="your@email.com"
+                    />
+                  </div>
+                </div>
+
+                <Button
+                  type="submit"
+                  disabled={isLoading}
+                  className="w-full bg-blue-600 hover:bg-blue-700 text-white font-semibold py-3 rounded-lg transition-all duration-300 hover:scale-105"
+                >
+                  {isLoading ? "Sending..." : "Send Reset Link"}
+                </Button>
+              </form>
+            )}
+          </CardContent>
+        </Card>
+      </div>
+    </div>
+  );
+};
+
+export default ForgotPassword;
@@ -3,26 +3,62 @@ import { Button } from "@/components/ui/button";
 import { Card, CardContent, CardDescription, CardHeader, CardTitle } from "@/components/ui/card";
 import { Input } from "@/components/ui/input";
 import { Label } from "@/components/ui/label";
+import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from "@/components/ui/select";
 import { Edit, Calendar, Hash, ArrowLeft, Copy, Trash2, BarChart } from "lucide-react";
 import { Link, useParams, useNavigate } from "react-router-dom";
 import { useToast } from "@/hooks/use-toast";
 import Header from "@/components/Header";
+import linkService, { LinkStats, StatsBucket, StatsBreakdownEntry } from "@/services/links";
 
 interface LinkData {
   slug: string;
   target_url: string;
   created_at: string;
-  expires_at?: string;
-  click_limit?: number;
+  expires_at?: string | null;
+  click_limit?: number | null;
   clicks: number;
 }
 
+// Format an ISO timestamp for a datetime-local input
+const toDateTimeLocal = (iso?: string | null) => {
+  if (!iso) return "";
+  const date = new Date(iso);
+  return new Date(date.getTime() - date.getTimezoneOffset() * 60000).toISOString().slice(0, 16);
+};
+
+const statusStyles: Record<LinkStats["status"], string> = {
+  active: "bg-green-900 text-green-300",
+  expired: "bg-yellow-900 text-yellow-300",
+  exhausted: "bg-orange-900 text-orange-300",
+  deleted: "bg-red-900 text-red-300",
+};
+
+const Breakdown = ({ title, entries }: { title: string; entries: StatsBreakdownEntry[] }) => (
+  <div>
+    <h4 className="text-gray-300 text-sm font-medium mb-2">{title}</h4>
+    {entries.length === 0 ? (
+      <p className="text-gray-500 text-sm">No data</p>
+    ) : (
+      <ul className="space-y-1">
+        {entries.map((entry) => (
+          <li key={entry.value} className="flex justify-between text-sm">
+            <span className="text-white truncate mr-2">{entry.value}</span>
+            <span className="text-blue-400">{entry.clicks}</span>
+          </li>
+        ))}
+      </ul>
+    )}
+  </div>
+);
+
 const LinkDetails = () => {
   const { slug } = useParams<{ slug: string }>();
   const navigate = useNavigate();
   const { toast } = useToast();
   
   const [linkData, setLinkData] = useState<LinkData | null>(null);
+  const [stats, setStats] = useState<LinkStats | null>(null);
+  const [bucket, setBucket] = useState<StatsBucket>("day");
   const [isEditing, setIsEditing] = useState(false);
   const [editForm, setEditForm] = useState({
     target_url: "",
@@ -30,39 +66,88 @@ const LinkDetails = () => {
     click_limit: ""
   });
 
-  useEffect(() => {
-    // Mock data - in real app, fetch based on slug
-    const mockData: LinkData = {
-      slug: slug || "",
-      target_url: "https://example.com",
-      created_at: "2024-01-15T10:30:00Z",
-      expires_at: "2024-02-15T10:30:00Z",
-      click_limit: 100,
-      clicks: 45
-    };
-    
-    setLinkData(mockData);
+  const applyLink = (link: LinkStats["link"]) => {
+    setLinkData({
+      slug: link.slug,
+      target_url: link.target_url,
+      created_at: link.created_at,
+      expires_at: link.expires_at,
+      click_limit: link.click_limit,
+      clicks: link.click_count
+    });
     setEditForm({
-      target_url: mockData.target_url,
-      expires_at: mockData.expires_at || "",
-      click_limit: mockData.click_limit?.toString() || ""
+      target_url: link.target_url,
+      expires_at: toDateTimeLocal(link.expires_at),
+      click_limit: link.click_limit?.toString() || ""
     });
-  }, [slug]);
+  };
 
-  const handleSave = () => {
-    toast({
-      title: "Link updated",
-      description: "Your link has been updated successfully.",
-    });
-    setIsEditing(false);
+  useEffect(() => {
+    if (!slug) return;
+
+    const fetchStats = async () => {
+      try {
+        const tz = Intl.DateTimeFormat().resolvedOptions().timeZone;
+        const result = await linkService.getLinkStats(slug, { bucket, tz });
+        setStats(result);
+        applyLink(result.link);
+      } catch (error: any) {
+        toast({
+          title: "Failed to load link",
+          description: error.message,
+          variant: "destructive",
+        });
+      }
+    };
+
+    fetchStats();
+  }, [slug, bucket]);
+
+  const handleSave = async () => {
+    if (!slug) return;
+
+    try {
+      const updated = await linkService.updateLink(slug, {
+        target_url: editForm.target_url,
+        ...(editForm.expires_at
+          ? { expires_at: new Date(editForm.expires_at).toISOString() }
+          : { clear_expires_at: true }),
+        ...(editForm.click_limit
+          ? { click_limit: parseInt(editForm.click_limit) }
+          : { clear_click_limit: true }),
+      });
+      applyLink(updated);
+      toast({
+        title: "Link updated",
+        description: "Your link has been updated successfully.",
+      });
+      setIsEditing(false);
+    } catch (error: any) {
+      toast({
+        title: "Update failed",
+        description: error.message,
+        variant: "destructive",
+      });
+    }
   };
 
-  const handleDelete = () => {
-    toast({
-      title: "Link deleted",
-      description: "Your link has been deleted successfully.",
-    });
-    navigate("/links");
+  const handleDelete = async () => {
+    if (!slug) return;
+
+    try {
+      await linkService.deleteLink(slug);
+      toast({
+        title: "Link deleted",
+        description: "Your link has been deleted successfully.",
+      });
+      navigate("/links");
+    } catch (error: any) {
+      toast({
+        title: "Delete failed",
+        description: error.message,
+        variant: "destructive",
+      });
+    }
   };
 
   const copyToClipboard = (text: string) => {
@@ -205,6 +290,59 @@ const LinkDetails = () => {
             </CardContent>
           </Card>
 
+          {/* Statistics */}
+          {stats && (
+            <Card className="bg-gray-900 border-gray-800">
+              <CardHeader>
+                <CardTitle className="text-white flex items-center justify-between">
+                  <span className="flex items-center">
+                    <BarChart className="w-5 h-5 mr-2 text-blue-500" />
+                    Statistics
+                    <span className={`ml-3 px-2 py-0.5 rounded text-xs font-medium ${statusStyles[stats.status]}`}>
+                      {stats.status}
+                    </span>
+                  </span>
+                  <Select value={bucket} onValueChange={(value) => setBucket(value as StatsBucket)}>
+                    <SelectTrigger className="w-32 bg-gray-800 border-gray-700 text-white">
+                      <SelectValue />
+                    </SelectTrigger>
+                    <SelectContent>
+                      <SelectItem value="hour">Last 24 hours</SelectItem>
+                      <SelectItem value="day">Last 30 days</SelectItem>
+                      <SelectItem value="week">Last 12 weeks</SelectItem>
+                    </SelectContent>
+                  </Select>
+                </CardTitle>
+                <CardDescription className="text-gray-400">
+                  {stats.total_clicks} clicks from {stats.unique_visitors} unique visitors
+                </CardDescription>
+              </CardHeader>
+              <CardContent className="space-y-6">
+                <div className="flex items-end gap-px h-32 bg-gray-800 rounded border border-gray-700 p-2">
+                  {stats.series.map((point) => {
+                    const max = Math.max(1, ...stats.series.map((p) => p.clicks));
+                    return (
+                      <div
+                        key={point.bucket}
+                        title={`${new Date(point.bucket).toLocaleString()}: ${point.clicks} clicks`}
+                        className="flex-1 bg-blue-500 rounded-t"
+                        style={{ height: `${(point.clicks / max) * 100}%` }}
+                      />
+                    );
+                  })}
+                </div>
+
+                <div className="grid md:grid-cols-3 gap-6">
+                  <Breakdown title="Countries" entries={stats.breakdowns.countries} />
+                  <Breakdown title="Referrers" entries={stats.breakdowns.referrers} />
+                  <Breakdown title="Devices" entries={stats.breakdowns.device_types} />
+                  <Breakdown title="Browsers" entries={stats.breakdowns.browsers} />
+                  <Breakdown title="Operating Systems" entries={stats.breakdowns.operating_systems} />
+                </div>
+              </CardContent>
+            </Card>
+          )}
+
           {/* Actions */}
           <Card className="bg-gray-900 border-gray-800">
             <CardHeader>
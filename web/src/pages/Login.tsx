@@ -3,7 +3,7 @@ import { Button } from "@/components/ui/button";
 import { Card, CardContent, CardDescription, CardHeader, CardTitle } from "@/components/ui/card";
 import { Input } from "@/components/ui/input";
 import { Label } from "@/components/ui/label";
-import { Shield, Mail, Lock, ArrowLeft } from "lucide-react";
+import { Shield, Mail, Lock, ArrowLeft, KeyRound } from "lucide-react";
 import { Link as RouterLink, useNavigate } from "react-router-dom";
 import { useToast } from "@/hooks/use-toast";
 import authService from "@/services/auth";
@@ -14,6 +14,8 @@ const Login = () => {
     password: ""
   });
   const [isLoading, setIsLoading] = useState(false);
+  const [challengeToken, setChallengeToken] = useState<string | null>(null);
+  const [code, setCode] = useState("");
   const navigate = useNavigate();
   const { toast } = useToast();
 
@@ -28,7 +30,11 @@ const Login = () => {
     setIsLoading(true);
 
     try {
-      await authService.login(formData);
+      const result = await authService.login(formData);
+      if ("twoFactorRequired" in result) {
+        setChallengeToken(result.challengeToken);
+        return;
+      }
       toast({
         title: "Welcome back",
         description: "Successfully logged in to your account.",
@@ -63,6 +69,34 @@ const Login = () => {
     }
   };
 
+  const handleCodeSubmit = async (e: React.FormEvent) => {
+    e.preventDefault();
+    if (!challengeToken) return;
+    setIsLoading(true);
+
+    try {
+      await authService.loginWithTwoFactor(challengeToken, code);
+      toast({
+        title: "Welcome back",
+        description: "Successfully logged in to your account.",
+      });
+      navigate("/dashboard");
+    } catch (error: any) {
+      toast({
+        title: "Verification failed",
+        description: error.message,
+        variant: "destructive",
+        duration: 5000,
+      });
+      if (error.message.includes("login again")) {
+        setChallengeToken(null);
+      }
+    } finally {
+      setCode("");
+      setIsLoading(false);
+    }
+  };
+
   return (
     <div className="min-h-screen bg-black flex items-center justify-center p-4">
       <div className="w-full max-w-md">
@@ -87,6 +121,40 @@ const Login = () => {
             </CardDescription>
           </CardHeader>
           <CardContent>
+            {challengeToken ? (
+            <form onSubmit={handleCodeSubmit} className="space-y-4">
+              <div className="space-y-2">
+                <Label htmlFor="code" className="text-white font-medium">
+                  Authentication code
+                </Label>
+                <div className="relative">
+                  <KeyRound className="absolute left-3 top-3 w-4 h-4 text-gray-500" />
+                  <Input
+                    id="code"
+                    name="code"
+                    required
+                    autoFocus
+                    autoComplete="one-time-code"
+                    value={code}
+                    onChange={(e) => setCode(e.target.value)}
+                    className="pl-10 bg-gray-800 border-gray-700 text-white placeholder-gray-500 focus:border-blue-500 focus:ring-blue-500"
+                    placeholder="123456 or a recovery code"
+                  />
+                </div>
+                <p className="text-sm text-gray-400">
+                  Enter the code from your authenticator app, or one of your recovery codes.
+                </p>
+              </div>
+
+              <Button
+                type="submit"
+                disabled={isLoading}
+                className="w-full bg-blue-600 hover:bg-blue-700 text-white font-semibold py-3 rounded-lg transition-all duration-300 hover:scale-105"
+              >
+                {isLoading ? "Verifying..." : "Verify"}
+              </Button>
+            </form>
+            ) : (
             <form onSubmit={handleSubmit} className="space-y-4">
               <div className="space-y-2">
                 <Label htmlFor="email" className="text-white font-medium">
@@ -108,9 +176,17 @@ const Login = () => {
               </div>
 
               <div className="space-y-2">
-                <Label htmlFor="password" className="text-white font-medium">
-                  Password
-                </Label>
+                <div className="flex items-center justify-between">
+                  <Label htmlFor="password" className="text-white font-medium">
+                    Password
+                  </Label>
+                  <RouterLink
+                    to="/forgot-password"
+                    className="text-sm text-blue-500 hover:text-blue-400 transition-colors"
+                  >
+                    Forgot password?
+                  </RouterLink>
+                </div>
                 <div className="relative">
                   <Lock className="absolute left-3 top-3 w-4 h-4 text-gray-500" />
                   <Input
@@ -134,6 +210,7 @@ const Login = () => {
                 {isLoading ? "Signing In..." : "Sign In"}
               </Button>
             </form>
+            )}
 
             <div className="mt-6 text-center">
               <p className="text-gray-400">
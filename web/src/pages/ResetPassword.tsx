@@ -0,0 +1,154 @@
+import { useState } from "react";
+import { Button } from "@/components/ui/button";
+import { Card, CardContent, CardDescription, CardHeader, CardTitle } from "@/components/ui/card";
+import { Input } from "@/components/ui/input";
+import { Label } from "@/components/ui/label";
+import { Shield, Lock, ArrowLeft } from "lucide-react";
+import { Link as RouterLink, useNavigate, useSearchParams } from "react-router-dom";
+import { useToast } from "@/hooks/use-toast";
+import authService from "@/services/auth";
+
+const ResetPassword = () => {
+  const [searchParams] = useSearchParams();
+  const token = searchParams.get("token") ?? "";
+  const [formData, setFormData] = useState({
+    password: "",
+    confirmPassword: ""
+  });
+  const [isLoading, setIsLoading] = useState(false);
+  const navigate = useNavigate();
+  const { toast } = useToast();
+
+  const handleInputChange = (e: React.ChangeEvent<HTMLInputElement>) => {
+    setFormData({
+      ...formData,
+      [e.target.name]: e.target.value
+    });
+  };
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault();
+
+    if (formData.password !== formData.confirmPassword) {
+      toast({
+        title: "Passwords don't match",
+        description: "Please enter the same password twice.",
+        variant: "destructive",
+        duration: 5000,
+      });
+      return;
+    }
+
+    setIsLoading(true);
+    try {
+      await authService.resetPassword(token, formData.password);
+      toast({
+        title: "Password reset",
+        description: "Please sign in with your new password.",
+      });
+      navigate("/login");
+    } catch (error: any) {
+      toast({
+        title: "Reset failed",
+        description: error.message || "Something went wrong. Please try again later.",
+        variant: "destructive",
+        duration: 5000,
+      });
+    } finally {
+      setIsLoading(false);
+    }
+  };
+
+  return (
+    <div className="min-h-screen bg-black flex items-center justify-center p-4">
+      <div className="w-full max-w-md">
+        <div className="text-center mb-8">
+          <RouterLink to="/login" className="inline-flex items-center text-gray-400 hover:text-gray-300 transition-colors mb-4">
+            <ArrowLeft className="w-4 h-4 mr-2" />
+            Back to Login
+          </RouterLink>
+          <div className="flex justify-center items-center mb-4">
+            <Shield className="w-12 h-12 text-blue-500 mr-3" />
+            <h1 className="text-3xl font-bold text-white">
+              LinkGuardian
+            </h1>
+          </div>
+        </div>
+
+        <Card className="bg-gray-900 border-gray-800 shadow-2xl">
+          <CardHeader className="text-center">
+            <CardTitle className="text-2xl font-bold text-white">Choose a New Password</CardTitle>
+            <CardDescription className="text-gray-400">
+              {token
+                ? "You'll be signed out everywhere once it's changed"
+                : "This reset link is incomplete. Please use the link from your email."}
+            </CardDescription>
+          </CardHeader>
+          <CardContent>
+            {token ? (
+              <form onSubmit={handleSubmit} className="space-y-4">
+                <div className="space-y-2">
+                  <Label htmlFor="password" className="text-white font-medium">
+                    New Password
+                  </Label>
+                  <div className="relative">
+                    <Lock className="absolute left-3 top-3 w-4 h-4 text-gray-500" />
+                    <Input
+                      id="password"
+                      name="password"
+                      type="password"
+                      required
+                      minLength={8}
+                      value={formData.password}
+                      onChange={handleInputChange}
+                      className="pl-10 bg-gray-800 border-gray-700 text-white placeholder-gray-500 focus:border-blue-500 focus:ring-blue-500"
+                      placeholder="At least 8 characters"
+                    />
+                  </div>
+                </div>
+
+                <div className="space-y-2">
+                  <Label htmlFor="confirmPassword" className="text-white font-medium">
+                    Confirm Password
+                  </Label>
+                  <div className="relative">
+                    <Lock className="absolute left-3 top-3 w-4 h-4 text-gray-500" />
+                    <Input
+                      id="confirmPassword"
+                      name="confirmPassword"
+                      type="password"
+                      required
+                      value={formData.confirmPassword}
+                      onChange={handleInputChange}
+                      className="pl-10 bg-gray-800 border-gray-700 text-white placeholder-gray-500 focus:border-blue-500 focus:ring-blue-500"
+                      placeholder="Repeat your new password"
+                    />
+                  </div>
+                </div>
+
+                <Button
+                  type="submit"
+                  disabled={isLoading}
+                  className="w-full bg-blue-600 hover:bg-blue-700 text-white font-semibold py-3 rounded-lg transition-all duration-300 hover:scale-105"
+                >
+                  {isLoading ? "Saving..." : "Reset Password"}
+                </Button>
+              </form>
+            ) : (
+              <p className="text-center text-gray-400">
+                <RouterLink
+                  to="/forgot-password"
+                  className="text-blue-500 hover:text-blue-400 font-semibold transition-colors"
+                >
+                  Request a new reset link
+                </RouterLink>
+              </p>
+            )}
+          </CardContent>
+        </Card>
+      </div>
+    </div>
+  );
+};
+
+export default ResetPassword;
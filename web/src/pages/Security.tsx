@@ -0,0 +1,228 @@
+import { useEffect, useState } from "react";
+import { Button } from "@/components/ui/button";
+import { Card, CardContent, CardDescription, CardHeader, CardTitle } from "@/components/ui/card";
+import { Input } from "@/components/ui/input";
+import { Label } from "@/components/ui/label";
+import { ShieldCheck, ShieldOff, KeyRound, Lock } from "lucide-react";
+import Header from "@/components/Header";
+import { useToast } from "@/hooks/use-toast";
+import authService, { TwoFactorSetup, TwoFactorStatus } from "@/services/auth";
+
+const inputClassName = "pl-10 bg-gray-800 border-gray-700 text-white placeholder-gray-500 focus:border-blue-500 focus:ring-blue-500";
+
+const Security = () => {
+  const [status, setStatus] = useState<TwoFactorStatus | null>(null);
+  const [setup, setSetup] = useState<TwoFactorSetup | null>(null);
+  const [recoveryCodes, setRecoveryCodes] = useState<string[]>([]);
+  const [code, setCode] = useState("");
+  const [password, setPassword] = useState("");
+  const [isLoading, setIsLoading] = useState(false);
+  const { toast } = useToast();
+
+  const loadStatus = async () => {
+    try {
+      setStatus(await authService.getTwoFactorStatus());
+    } catch (error) {
+      console.error("Failed to load two-factor status:", error);
+    }
+  };
+
+  useEffect(() => {
+    loadStatus();
+  }, []);
+
+  const showError = (title: string, error: any) => {
+    toast({
+      title,
+      description: error.message || "Something went wrong. Please try again later.",
+      variant: "destructive",
+      duration: 5000,
+    });
+  };
+
+  const startSetup = async () => {
+    setIsLoading(true);
+    try {
+      setSetup(await authService.setupTwoFactor());
+    } catch (error: any) {
+      showError("Setup failed", error);
+    } finally {
+      setIsLoading(false);
+    }
+  };
+
+  const enable = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setIsLoading(true);
+    try {
+      setRecoveryCodes(await authService.enableTwoFactor(code));
+      setSetup(null);
+      toast({
+        title: "Two-factor authentication enabled",
+        description: "Save your recovery codes before leaving this page.",
+      });
+      await loadStatus();
+    } catch (error: any) {
+      showError("Could not enable two-factor authentication", error);
+    } finally {
+      setCode("");
+      setIsLoading(false);
+    }
+  };
+
+  const disable = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setIsLoading(true);
+    try {
+      await authService.disableTwoFactor(password, code);
+      setRecoveryCodes([]);
+      toast({
+        title: "Two-factor authentication disabled",
+        description: "Your password alone now signs you in.",
+      });
+      await loadStatus();
+    } catch (error: any) {
+      showError("Could not disable two-factor authentication", error);
+    } finally {
+      setCode("");
+      setPassword("");
+      setIsLoading(false);
+    }
+  };
+
+  return (
+    <div className="min-h-screen bg-black">
+      <Header />
+
+      <div className="container mx-auto px-4 py-8 max-w-2xl">
+        <h2 className="text-3xl font-bold text-white mb-8">Security</h2>
+
+        <Card className="bg-gray-900 border-gray-800">
+          <CardHeader>
+            <CardTitle className="text-white flex items-center">
+              {status?.enabled ? (
+                <ShieldCheck className="w-5 h-5 mr-2 text-green-500" />
+              ) : (
+                <ShieldOff className="w-5 h-5 mr-2 text-gray-500" />
+              )}
+              Two-factor authentication
+            </CardTitle>
+            <CardDescription className="text-gray-400">
+              {status?.enabled
+                ? `Enabled. ${status.recovery_codes_left} recovery codes left.`
+                : "Require a code from an authenticator app in addition to your password."}
+            </CardDescription>
+          </CardHeader>
+          <CardContent className="space-y-6">
+            {recoveryCodes.length > 0 && (
+              <div className="rounded-lg border border-yellow-700 bg-yellow-950/40 p-4">
+                <p className="text-yellow-200 mb-3">
+                  Store these recovery codes somewhere safe. Each one signs you in once if you lose your
+                  authenticator, and they will not be shown again.
+                </p>
+                <div className="grid grid-cols-2 gap-2 font-mono text-white">
+                  {recoveryCodes.map((recoveryCode) => (
+                    <span key={recoveryCode}>{recoveryCode}</span>
+                  ))}
+                </div>
+              </div>
+            )}
+
+            {status && !status.enabled && !setup && (
+              <Button
+                onClick={startSetup}
+                disabled={isLoading}
+                className="bg-blue-600 hover:bg-blue-700 text-white font-semibold"
+              >
+                Set up two-factor authentication
+              </Button>
+            )}
+
+            {setup && (
+              <form onSubmit={enable} className="space-y-4">
+                <p className="text-gray-300">
+                  Scan this QR code with your authenticator app, or enter the key by hand.
+                </p>
+                <img src={setup.qr_code} alt="Two-factor QR code" className="w-48 h-48 rounded bg-white" />
+                <p className="font-mono text-sm text-gray-300 break-all">{setup.secret}</p>
+                <div className="space-y-2">
+                  <Label htmlFor="code" className="text-white font-medium">
+                    Code from the app
+                  </Label>
+                  <div className="relative">
+                    <KeyRound className="absolute left-3 top-3 w-4 h-4 text-gray-500" />
+                    <Input
+                      id="code"
+                      required
+                      autoComplete="one-time-code"
+                      value={code}
+                      onChange={(e) => setCode(e.target.value)}
+                      className={inputClassName}
+                      placeholder="123456"
+                    />
+                  </div>
+                </div>
+                <Button
+                  type="submit"
+                  disabled={isLoading}
+                  className="bg-blue-600 hover:bg-blue-700 text-white font-semibold"
+                >
+                  {isLoading ? "Enabling..." : "Enable"}
+                </Button>
+              </form>
+            )}
+
+            {status?.enabled && (
+              <form onSubmit={disable} className="space-y-4">
+                <div className="space-y-2">
+                  <Label htmlFor="password" className="text-white font-medium">
+                    Password
+                  </Label>
+                  <div className="relative">
+                    <Lock className="absolute left-3 top-3 w-4 h-4 text-gray-500" />
+                    <Input
+                      id="password"
+                      type="password"
+                      required
+                      value={password}
+                      onChange={(e) => setPassword(e.target.value)}
+                      className={inputClassName}
+                      placeholder="Your password"
+                    />
+                  </div>
+                </div>
+                <div className="space-y-2">
+                  <Label htmlFor="disable-code" className="text-white font-medium">
+                    Authentication code
+                  </Label>
+                  <div className="relative">
+                    <KeyRound className="absolute left-3 top-3 w-4 h-4 text-gray-500" />
+                    <Input
+                      id="disable-code"
+                      required
+                      autoComplete="one-time-code"
+                      value={code}
+                      onChange={(e) => setCode(e.target.value)}
+                      className={inputClassName}
+                      placeholder="123456 or a recovery code"
+                    />
+                  </div>
+                </div>
+                <Button
+                  type="submit"
+                  disabled={isLoading}
+                  variant="outline"
+                  className="border-red-700 text-red-300 bg-gray-800 hover:bg-red-950"
+                >
+                  {isLoading ? "Disabling..." : "Disable two-factor authentication"}
+                </Button>
+              </form>
+            )}
+          </CardContent>
+        </Card>
+      </div>
+    </div>
+  );
+};
+
+export default Security;
@@ -33,7 +33,7 @@ const Signup = () => {
       
       toast({
         title: "Welcome aboard!",
-        description: "Your account has been created successfully.",
+        description: "Your account has been created. Check your inbox to verify your email address.",
       });
       
       // Redirect to dashboard instead of login since we now have the token
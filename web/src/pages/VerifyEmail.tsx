@@ -0,0 +1,78 @@
+import { useEffect, useRef, useState } from "react";
+import { Card, CardContent, CardDescription, CardHeader, CardTitle } from "@/components/ui/card";
+import { Shield, CheckCircle, XCircle } from "lucide-react";
+import { Link as RouterLink, useSearchParams } from "react-router-dom";
+import authService from "@/services/auth";
+
+type Status = "verifying" | "verified" | "failed";
+
+const VerifyEmail = () => {
+  const [searchParams] = useSearchParams();
+  const token = searchParams.get("token") ?? "";
+  const [status, setStatus] = useState<Status>(token ? "verifying" : "failed");
+  const [message, setMessage] = useState(token ? "" : "This verification link is incomplete.");
+  const requested = useRef(false);
+
+  useEffect(() => {
+    // Strict mode runs effects twice in development; verify only once
+    if (!token || requested.current) return;
+    requested.current = true;
+
+    authService.verifyEmail(token)
+      .then(() => setStatus("verified"))
+      .catch((error: Error) => {
+        setStatus("failed");
+        setMessage(error.message);
+      });
+  }, [token]);
+
+  const continuePath = authService.isAuthenticated() ? "/dashboard" : "/login";
+
+  return (
+    <div className="min-h-screen bg-black flex items-center justify-center p-4">
+      <div className="w-full max-w-md">
+        <div className="text-center mb-8">
+          <div className="flex justify-center items-center mb-4">
+            <Shield className="w-12 h-12 text-blue-500 mr-3" />
+            <h1 className="text-3xl font-bold text-white">
+              LinkGuardian
+            </h1>
+          </div>
+        </div>
+
+        <Card className="bg-gray-900 border-gray-800 shadow-2xl">
+          <CardHeader className="text-center">
+            <div className="flex justify-center mb-2">
+              {status === "verified" && <CheckCircle className="w-10 h-10 text-green-500" />}
+              {status === "failed" && <XCircle className="w-10 h-10 text-red-500" />}
+            </div>
+            <CardTitle className="text-2xl font-bold text-white">
+              {status === "verifying" && "Verifying..."}
+              {status === "verified" && "Email Verified"}
+              {status === "failed" && "Verification Failed"}
+            </CardTitle>
+            <CardDescription className="text-gray-400">
+              {status === "verifying" && "Confirming your email address"}
+              {status === "verified" && "Thanks! Your account now has full access."}
+              {status === "failed" && message}
+            </CardDescription>
+          </CardHeader>
+          {status !== "verifying" && (
+            <CardContent>
+              <p className="text-center text-gray-400">
+                <RouterLink
+                  to={continuePath}
+                  className="text-blue-500 hover:text-blue-400 font-semibold transition-colors"
+                >
+                  {continuePath === "/dashboard" ? "Go to your dashboard" : "Sign in"}
+                </RouterLink>
+              </p>
+            </CardContent>
+          )}
+        </Card>
+      </div>
+    </div>
+  );
+};
+
+export default VerifyEmail;
@@ -0,0 +1,452 @@
+import { useEffect, useState } from "react";
+import { Button } from "@/components/ui/button";
+import { Card, CardContent, CardDescription, CardHeader, CardTitle } from "@/components/ui/card";
+import { Input } from "@/components/ui/input";
+import { Label } from "@/components/ui/label";
+import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from "@/components/ui/select";
+import { Users, Mail, Plus, Check, Globe } from "lucide-react";
+import Header from "@/components/Header";
+import { useToast } from "@/hooks/use-toast";
+import authService from "@/services/auth";
+import domainService, { Domain } from "@/services/domains";
+import workspaceService, {
+  Workspace,
+  WorkspaceInvitation,
+  WorkspaceMember,
+  WorkspaceRole,
+} from "@/services/workspaces";
+
+const inputClassName = "pl-10 bg-gray-800 border-gray-700 text-white placeholder-gray-500 focus:border-blue-500 focus:ring-blue-500";
+const roles: WorkspaceRole[] = ["owner", "editor", "viewer"];
+
+const RoleSelect = ({ value, onChange }: { value: WorkspaceRole; onChange: (role: WorkspaceRole) => void }) => (
+  <Select value={value} onValueChange={(role) => onChange(role as WorkspaceRole)}>
+    <SelectTrigger className="w-28 bg-gray-800 border-gray-700 text-white">
+      <SelectValue />
+    </SelectTrigger>
+    <SelectContent>
+      {roles.map((role) => (
+        <SelectItem key={role} value={role}>{role}</SelectItem>
+      ))}
+    </SelectContent>
+  </Select>
+);
+
+const Workspaces = () => {
+  const [workspaces, setWorkspaces] = useState<Workspace[]>([]);
+  const [selected, setSelected] = useState<Workspace | null>(null);
+  const [members, setMembers] = useState<WorkspaceMember[]>([]);
+  const [invitations, setInvitations] = useState<WorkspaceInvitation[]>([]);
+  const [currentId, setCurrentId] = useState(workspaceService.getCurrentWorkspaceId());
+  const [newName, setNewName] = useState("");
+  const [inviteEmail, setInviteEmail] = useState("");
+  const [inviteRole, setInviteRole] = useState<WorkspaceRole>("editor");
+  const [domains, setDomains] = useState<Domain[]>([]);
+  const [newHostname, setNewHostname] = useState("");
+  const [isLoading, setIsLoading] = useState(false);
+  const { toast } = useToast();
+  const userId = Number(authService.getCurrentUserId());
+
+  const showError = (title: string, error: any) => {
+    toast({
+      title,
+      description: error.message || "Something went wrong. Please try again later.",
+      variant: "destructive",
+      duration: 5000,
+    });
+  };
+
+  const loadWorkspaces = async () => {
+    try {
+      const list = await workspaceService.getWorkspaces();
+      setWorkspaces(list);
+      setSelected((previous) => list.find((w) => w.id === previous?.id)
+        ?? list.find((w) => (currentId === null ? w.personal : w.id === currentId))
+        ?? list[0] ?? null);
+    } catch (error) {
+      showError("Failed to load workspaces", error);
+    }
+  };
+
+  const loadDetails = async (workspace: Workspace) => {
+    try {
+      setMembers(await workspaceService.getMembers(workspace.id));
+      setInvitations(workspace.role === "owner" && !workspace.personal
+        ? await workspaceService.getInvitations(workspace.id)
+        : []);
+      setDomains(await domainService.getDomains(workspace.id));
+    } catch (error) {
+      showError("Failed to load workspace", error);
+    }
+  };
+
+  useEffect(() => {
+    loadWorkspaces();
+  }, []);
+
+  useEffect(() => {
+    if (selected) loadDetails(selected);
+  }, [selected]);
+
+  const isCurrent = (workspace: Workspace) =>
+    currentId === null ? workspace.personal : workspace.id === currentId;
+
+  const switchTo = (workspace: Workspace) => {
+    workspaceService.setCurrentWorkspace(workspace);
+    setCurrentId(workspaceService.getCurrentWorkspaceId());
+    toast({
+      title: "Workspace switched",
+      description: `Links and analytics now show ${workspace.name}.`,
+    });
+  };
+
+  const create = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setIsLoading(true);
+    try {
+      const workspace = await workspaceService.createWorkspace(newName);
+      setNewName("");
+      setSelected(workspace);
+      await loadWorkspaces();
+    } catch (error: any) {
+      showError("Could not create workspace", error);
+    } finally {
+      setIsLoading(false);
+    }
+  };
+
+  const invite = async (e: React.FormEvent) => {
+    e.preventDefault();
+    if (!selected) return;
+    setIsLoading(true);
+    try {
+      await workspaceService.invite(selected.id, inviteEmail, inviteRole);
+      toast({ title: "Invitation sent", description: `${inviteEmail} was invited as ${inviteRole}.` });
+      setInviteEmail("");
+      await loadDetails(selected);
+    } catch (error: any) {
+      showError("Could not send invitation", error);
+    } finally {
+      setIsLoading(false);
+    }
+  };
+
+  const changeRole = async (member: WorkspaceMember, role: WorkspaceRole) => {
+    if (!selected) return;
+    try {
+      await workspaceService.setMemberRole(selected.id, member.user_id, role);
+      await loadDetails(selected);
+    } catch (error: any) {
+      showError("Could not change role", error);
+    }
+  };
+
+  const remove = async (member: WorkspaceMember) => {
+    if (!selected) return;
+    const leaving = member.user_id === userId;
+    if (!window.confirm(leaving ? `Leave ${selected.name}?` : `Remove ${member.username} from ${selected.name}?`)) return;
+    try {
+      await workspaceService.removeMember(selected.id, member.user_id);
+      if (leaving) {
+        if (isCurrent(selected)) {
+          localStorage.removeItem("workspace_id");
+          setCurrentId(null);
+        }
+        setSelected(null);
+        await loadWorkspaces();
+      } else {
+        await loadDetails(selected);
+      }
+    } catch (error: any) {
+      showError(leaving ? "Could not leave workspace" : "Could not remove member", error);
+    }
+  };
+
+  const revoke = async (invitation: WorkspaceInvitation) => {
+    if (!selected) return;
+    try {
+      await workspaceService.revokeInvitation(selected.id, invitation.id);
+      await loadDetails(selected);
+    } catch (error: any) {
+      showError("Could not revoke invitation", error);
+    }
+  };
+
+  const addDomain = async (e: React.FormEvent) => {
+    e.preventDefault();
+    if (!selected) return;
+    setIsLoading(true);
+    try {
+      await domainService.addDomain(selected.id, newHostname);
+      setNewHostname("");
+      await loadDetails(selected);
+    } catch (error: any) {
+      showError("Could not add domain", error);
+    } finally {
+      setIsLoading(false);
+    }
+  };
+
+  const verifyDomain = async (domain: Domain) => {
+    if (!selected) return;
+    try {
+      await domainService.verifyDomain(selected.id, domain.id);
+      toast({ title: "Domain verified", description: `Links can now be created on ${domain.hostname}.` });
+      await loadDetails(selected);
+    } catch (error: any) {
+      showError("Could not verify domain", error);
+    }
+  };
+
+  const deleteDomain = async (domain: Domain) => {
+    if (!selected || !window.confirm(`Remove ${domain.hostname}?`)) return;
+    try {
+      await domainService.deleteDomain(selected.id, domain.id);
+      await loadDetails(selected);
+    } catch (error: any) {
+      showError("Could not remove domain", error);
+    }
+  };
+
+  const isOwner = selected?.role === "owner";
+
+  return (
+    <div className="min-h-screen bg-black">
+      <Header />
+
+      <div className="container mx-auto px-4 py-8 max-w-3xl space-y-6">
+        <h2 className="text-3xl font-bold text-white mb-2">Workspaces</h2>
+
+        <Card className="bg-gray-900 border-gray-800">
+          <CardHeader>
+            <CardTitle className="text-white flex items-center">
+              <Users className="w-5 h-5 mr-2 text-blue-500" />
+              Your workspaces
+            </CardTitle>
+            <CardDescription className="text-gray-400">
+              Links and analytics show the current workspace. Members of shared workspaces see the same links.
+            </CardDescription>
+          </CardHeader>
+          <CardContent className="space-y-4">
+            {workspaces.map((workspace) => (
+              <div
+                key={workspace.id}
+                className={`flex items-center justify-between rounded-lg border p-3 cursor-pointer ${
+                  selected?.id === workspace.id ? "border-blue-600 bg-gray-800" : "border-gray-800"
+                }`}
+                onClick={() => setSelected(workspace)}
+              >
+                <div>
+                  <p className="text-white font-medium">{workspace.name}</p>
+                  <p className="text-sm text-gray-400">
+                    {workspace.personal ? "Personal" : "Shared"} · {workspace.role}
+                  </p>
+                </div>
+                {isCurrent(workspace) ? (
+                  <span className="flex items-center text-green-500 text-sm">
+                    <Check className="w-4 h-4 mr-1" />
+                    Current
+                  </span>
+                ) : (
+                  <Button
+                    variant="outline"
+                    onClick={(e) => {
+                      e.stopPropagation();
+                      switchTo(workspace);
+                    }}
+                    className="border-gray-600 text-white bg-gray-800 hover:bg-gray-700 hover:text-white"
+                  >
+                    Switch
+                  </Button>
+                )}
+              </div>
+            ))}
+
+            <form onSubmit={create} className="flex gap-2">
+              <div className="relative flex-1">
+                <Plus className="absolute left-3 top-3 w-4 h-4 text-gray-500" />
+                <Input
+                  required
+                  maxLength={100}
+                  value={newName}
+                  onChange={(e) => setNewName(e.target.value)}
+                  className={inputClassName}
+                  placeholder="New workspace name"
+                />
+              </div>
+              <Button type="submit" disabled={isLoading} className="bg-blue-600 hover:bg-blue-700 text-white font-semibold">
+                Create
+              </Button>
+            </form>
+          </CardContent>
+        </Card>
+
+        {selected && (
+          <Card className="bg-gray-900 border-gray-800">
+            <CardHeader>
+              <CardTitle className="text-white">{selected.name} members</CardTitle>
+              <CardDescription className="text-gray-400">
+                Viewers see links and analytics, editors also change links, owners also manage members.
+              </CardDescription>
+            </CardHeader>
+            <CardContent className="space-y-3">
+              {members.map((member) => (
+                <div key={member.user_id} className="flex items-center justify-between gap-2">
+                  <div>
+                    <p className="text-white">{member.username}</p>
+                    <p className="text-sm text-gray-400">{member.email}</p>
+                  </div>
+                  <div className="flex items-center gap-2">
+                    {isOwner && !selected.personal ? (
+                      <RoleSelect value={member.role} onChange={(role) => changeRole(member, role)} />
+                    ) : (
+                      <span className="text-gray-300">{member.role}</span>
+                    )}
+                    {!selected.personal && (isOwner || member.user_id === userId) && (
+                      <Button
+                        variant="outline"
+                        onClick={() => remove(member)}
+                        className="border-red-700 text-red-300 bg-gray-800 hover:bg-red-950"
+                      >
+                        {member.user_id === userId ? "Leave" : "Remove"}
+                      </Button>
+                    )}
+                  </div>
+                </div>
+              ))}
+            </CardContent>
+          </Card>
+        )}
+
+        {selected && isOwner && !selected.personal && (
+          <Card className="bg-gray-900 border-gray-800">
+            <CardHeader>
+              <CardTitle className="text-white flex items-center">
+                <Mail className="w-5 h-5 mr-2 text-blue-500" />
+                Invitations
+              </CardTitle>
+              <CardDescription className="text-gray-400">
+                Invitees get an email with a link and join once they accept it from an account with that address.
+              </CardDescription>
+            </CardHeader>
+            <CardContent className="space-y-4">
+              {invitations.map((invitation) => (
+                <div key={invitation.id} className="flex items-center justify-between">
+                  <div>
+                    <p className="text-white">{invitation.email}</p>
+                    <p className="text-sm text-gray-400">
+                      {invitation.role} · expires {new Date(invitation.expires_at).toLocaleString()}
+                    </p>
+                  </div>
+                  <Button
+                    variant="outline"
+                    onClick={() => revoke(invitation)}
+                    className="border-gray-600 text-white bg-gray-800 hover:bg-gray-700 hover:text-white"
+                  >
+                    Revoke
+                  </Button>
+                </div>
+              ))}
+
+              <form onSubmit={invite} className="space-y-2">
+                <Label htmlFor="invite-email" className="text-white font-medium">
+                  Invite by email
+                </Label>
+                <div className="flex gap-2">
+                  <div className="relative flex-1">
+                    <Mail className="absolute left-3 top-3 w-4 h-4 text-gray-500" />
+                    <Input
+                      id="invite-email"
+                      type="email"
+                      required
+                      value={inviteEmail}
+                      onChange={(e) => setInviteEmail(e.target.value)}
+                      className={inputClassName}
+                      placeholder="colleague@example.com"
+                    />
+                  </div>
+                  <RoleSelect value={inviteRole} onChange={setInviteRole} />
+                  <Button type="submit" disabled={isLoading} className="bg-blue-600 hover:bg-blue-700 text-white font-semibold">
+                    {isLoading ? "Sending..." : "Invite"}
+                  </Button>
+                </div>
+              </form>
+            </CardContent>
+          </Card>
+        )}
+
+        {selected && (
+          <Card className="bg-gray-900 border-gray-800">
+            <CardHeader>
+              <CardTitle className="text-white flex items-center">
+                <Globe className="w-5 h-5 mr-2 text-blue-500" />
+                Custom domains
+              </CardTitle>
+              <CardDescription className="text-gray-400">
+                Publish the TXT record shown for a domain, then verify it to create links on it.
+              </CardDescription>
+            </CardHeader>
+            <CardContent className="space-y-4">
+              {domains.map((domain) => (
+                <div key={domain.id} className="space-y-2">
+                  <div className="flex items-center justify-between">
+                    <div>
+                      <p className="text-white">{domain.hostname}</p>
+                      <p className="text-sm text-gray-400">{domain.verified ? "Verified" : "Awaiting verification"}</p>
+                    </div>
+                    {isOwner && (
+                      <div className="flex gap-2">
+                        {!domain.verified && (
+                          <Button
+                            variant="outline"
+                            onClick={() => verifyDomain(domain)}
+                            className="border-gray-600 text-white bg-gray-800 hover:bg-gray-700 hover:text-white"
+                          >
+                            Verify
+                          </Button>
+                        )}
+                        <Button
+                          variant="outline"
+                          onClick={() => deleteDomain(domain)}
+                          className="border-red-700 text-red-300 bg-gray-800 hover:bg-red-950"
+                        >
+                          Remove
+                        </Button>
+                      </div>
+                    )}
+                  </div>
+                  {domain.verification_record && (
+                    <p className="text-xs text-gray-400 font-mono break-all">
+                      {domain.verification_record.type} {domain.verification_record.name} "{domain.verification_record.value}"
+                    </p>
+                  )}
+                </div>
+              ))}
+
+              {isOwner && (
+                <form onSubmit={addDomain} className="flex gap-2">
+                  <div className="relative flex-1">
+                    <Globe className="absolute left-3 top-3 w-4 h-4 text-gray-500" />
+                    <Input
+                      required
+                      maxLength={253}
+                      value={newHostname}
+                      onChange={(e) => setNewHostname(e.target.value)}
+                      className={inputClassName}
+                      placeholder="go.example.com"
+                    />
+                  </div>
+                  <Button type="submit" disabled={isLoading} className="bg-blue-600 hover:bg-blue-700 text-white font-semibold">
+                    Add
+                  </Button>
+                </form>
+              )}
+            </CardContent>
+          </Card>
+        )}
+      </div>
+    </div>
+  );
+};
+
+export default Workspaces;
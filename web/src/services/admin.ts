@@ -0,0 +1,143 @@
+import api from './api';
+import { Link } from './links';
+
+export interface AdminUser {
+  id: number;
+  username: string;
+  email: string;
+  role: 'user' | 'admin';
+  email_verified: boolean;
+  two_factor_enabled: boolean;
+  disabled: boolean;
+  disabled_at?: string;
+  created_at: string;
+}
+
+export interface InstanceStats {
+  users: number;
+  admin_users: number;
+  disabled_users: number;
+  unverified_users: number;
+  shared_workspaces: number;
+  links: number;
+  taken_down_links: number;
+  deleted_links: number;
+  total_clicks: number;
+  clicks_last_24h: number;
+}
+
+// One page of search results, newest first, with the total number of matches
+export interface SearchPage<T> {
+  items: T[];
+  total: number;
+}
+
+// Admin service class; every call requires the admin role
+class AdminService {
+  /**
+   * Search users by username or email
+   * @param query - Substring to match, or empty for all users
+   * @param offset - Number of matches to skip
+   */
+  async searchUsers(query: string, offset = 0): Promise<SearchPage<AdminUser>> {
+    try {
+      const response = await api.get<{ users: AdminUser[], total: number }>('/admin/users', {
+        params: { q: query, offset },
+      });
+      return { items: response.data.users || [], total: response.data.total };
+    } catch (error: any) {
+      const errorMessage = error.response?.data?.error || 'Failed to fetch users';
+      throw new Error(errorMessage);
+    }
+  }
+
+  /**
+   * Disable or re-enable an account
+   * @param userId - ID of the user
+   * @param disabled - Whether the account should be disabled
+   */
+  async setUserDisabled(userId: number, disabled: boolean): Promise<void> {
+    try {
+      await api.post(`/admin/users/${userId}/${disabled ? 'disable' : 'enable'}`);
+    } catch (error: any) {
+      const errorMessage = error.response?.data?.error || 'Failed to update user';
+      throw new Error(errorMessage);
+    }
+  }
+
+  /**
+   * Lift a lockout after failed logins to an account
+   * @param userId - ID of the user
+   */
+  async unlockLogin(userId: number): Promise<void> {
+    try {
+      await api.post(`/admin/users/${userId}/unlock-login`);
+    } catch (error: any) {
+      const errorMessage = error.response?.data?.error || 'Failed to unlock logins';
+      throw new Error(errorMessage);
+    }
+  }
+
+  /**
+   * Search all links by slug or target URL
+   * @param query - Substring to match, or empty for all links
+   * @param offset - Number of matches to skip
+   */
+  async searchLinks(query: string, offset = 0): Promise<SearchPage<Link>> {
+    try {
+      const response = await api.get<{ links: Link[], total: number }>('/admin/links', {
+        params: { q: query, offset },
+      });
+      return { items: response.data.links || [], total: response.data.total };
+    } catch (error: any) {
+      const errorMessage = error.response?.data?.error || 'Failed to fetch links';
+      throw new Error(errorMessage);
+    }
+  }
+
+  /**
+   * Take a link down so it stops redirecting
+   * @param slug - Slug of the link
+   * @param reason - Reason shown to the link's owner
+   */
+  async takeDownLink(slug: string, reason: string): Promise<Link> {
+    try {
+      const response = await api.post<{ link: Link }>(`/admin/links/${encodeURIComponent(slug)}/takedown`, { reason });
+      return response.data.link;
+    } catch (error: any) {
+      const errorMessage = error.response?.data?.error || 'Failed to take down link';
+      throw new Error(errorMessage);
+    }
+  }
+
+  /**
+   * Restore a taken down link
+   * @param slug - Slug of the link
+   */
+  async restoreLink(slug: string): Promise<Link> {
+    try {
+      const response = await api.delete<{ link: Link }>(`/admin/links/${encodeURIComponent(slug)}/takedown`);
+      return response.data.link;
+    } catch (error: any) {
+      const errorMessage = error.response?.data?.error || 'Failed to restore link';
+      throw new Error(errorMessage);
+    }
+  }
+
+  /**
+   * Get instance-wide user, workspace, link and click counts
+   */
+  async getStats(): Promise<InstanceStats> {
+    try {
+      const response = await api.get<{ stats: InstanceStats }>('/admin/stats');
+      return response.data.stats;
+    } catch (error: any) {
+      const errorMessage = error.response?.data?.error || 'Failed to fetch stats';
+      throw new Error(errorMessage);
+    }
+  }
+}
+
+// Create and export a singleton instance
+const adminService = new AdminService();
+export default adminService;
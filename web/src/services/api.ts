@@ -19,6 +19,11 @@ api.interceptors.request.use(
     if (token && config.headers) {
       config.headers.Authorization = `Bearer ${token}`;
     }
+    // Links and logs act on the selected workspace, the personal one when unset
+    const workspaceId = localStorage.getItem('workspace_id');
+    if (workspaceId && config.headers) {
+      config.headers['X-Workspace-ID'] = workspaceId;
+    }
     return config;
   },
   (error: AxiosError) => {
@@ -26,16 +31,58 @@ api.interceptors.request.use(
   }
 );
 
+// A single refresh shared by every request that failed while it was in flight
+let refreshPromise: Promise<string> | null = null;
+
+// Exchange the stored refresh token for a new token pair; refresh tokens are
+// single-use, so concurrent 401s must not each send their own refresh
+const refreshAccessToken = (): Promise<string> => {
+  if (!refreshPromise) {
+    const refreshToken = localStorage.getItem('refresh_token');
+    refreshPromise = (refreshToken
+      ? axios.post<{ token: string; refresh_token: string }>(`${API_URL}/auth/refresh`, { refresh_token: refreshToken })
+      : Promise.reject(new Error('No refresh token'))
+    )
+      .then((response) => {
+        localStorage.setItem('token', response.data.token);
+        localStorage.setItem('refresh_token', response.data.refresh_token);
+        return response.data.token;
+      })
+      .finally(() => {
+        refreshPromise = null;
+      });
+  }
+  return refreshPromise;
+};
+
 // Response interceptor for handling common errors
 api.interceptors.response.use(
   (response: AxiosResponse) => {
     return response;
   },
-  (error: AxiosError) => {
+  async (error: AxiosError) => {
+    const request = error.config as (InternalAxiosRequestConfig & { _retried?: boolean }) | undefined;
+
+    // Failed login attempts are reported by the login form itself
+    const isLoginAttempt = request?.url?.startsWith('/login') ?? false;
+
     // Handle common errors here (e.g., 401 Unauthorized, 403 Forbidden)
-    if (error.response?.status === 401) {
-      // Clear token and redirect to login if unauthorized
+    if (error.response?.status === 401 && !isLoginAttempt) {
+      // Access tokens are short-lived: refresh once and retry the request
+      if (request && !request._retried && localStorage.getItem('refresh_token')) {
+        request._retried = true;
+        try {
+          const token = await refreshAccessToken();
+          request.headers.Authorization = `Bearer ${token}`;
+          return api(request);
+        } catch {
+          // Fall through to a fresh login
+        }
+      }
+
+      // Clear tokens and redirect to login if unauthorized
       localStorage.removeItem('token');
+      localStorage.removeItem('refresh_token');
       window.location.href = '/login';
     }
     
@@ -27,6 +27,53 @@ export interface AuthResponse {
   user: User;
 }
 
+// A correct password for an account with two-factor authentication only
+// earns a challenge, which loginWithTwoFactor exchanges for a session
+export interface TwoFactorChallenge {
+  twoFactorRequired: true;
+  challengeToken: string;
+  expiresIn: number;
+}
+
+export interface TwoFactorStatus {
+  enabled: boolean;
+  recovery_codes_left: number;
+}
+
+export interface TwoFactorSetup {
+  secret: string;
+  otpauth_url: string;
+  qr_code: string;
+}
+
+// Tokens returned by signup, login and refresh; signup and login also
+// describe the user
+interface TokenResponse {
+  token: string;
+  refresh_token: string;
+  expires_in: number;
+  user?: { email_verified?: boolean; role?: string };
+}
+
+// Login response for accounts with two-factor authentication
+interface ChallengeResponse {
+  two_factor_required: true;
+  challenge_token: string;
+  expires_in: number;
+}
+
+// Store the short-lived access token and the refresh token that renews it
+const saveTokens = (tokens: TokenResponse): void => {
+  localStorage.setItem('token', tokens.token);
+  localStorage.setItem('refresh_token', tokens.refresh_token);
+  if (tokens.user?.email_verified !== undefined) {
+    localStorage.setItem('email_verified', String(tokens.user.email_verified));
+  }
+  if (tokens.user?.role !== undefined) {
+    localStorage.setItem('user_role', tokens.user.role);
+  }
+};
+
 // Auth service class
 class AuthService {  /**
    * Register a new user
@@ -35,11 +82,11 @@ class AuthService {  /**
    */
   async signup(userData: SignupRequest): Promise<AuthResponse> {
     try {
-      const response = await api.post<{ token: string }>('/signup', userData);
+      const response = await api.post<TokenResponse>('/signup', userData);
       
-      // Save token to localStorage if successful
+      // Save tokens to localStorage if successful
       if (response.data.token) {
-        localStorage.setItem('token', response.data.token);
+        saveTokens(response.data);
         
         // Since backend doesn't return user info, we'll create a simplified response
         return {
@@ -64,15 +111,24 @@ class AuthService {  /**
   /**
    * Login an existing user
    * @param credentials - User login credentials
-   * @returns Promise with auth response containing token and user info
-   */  async login(credentials: LoginRequest): Promise<AuthResponse> {
+   * @returns Promise with auth response containing token and user info, or
+   * a challenge when the account has two-factor authentication
+   */  async login(credentials: LoginRequest): Promise<AuthResponse | TwoFactorChallenge> {
     try {
       console.log('Attempting login with API URL:', import.meta.env.VITE_API_URL);
-      const response = await api.post<{ token: string }>('/login', credentials);
+      const response = await api.post<TokenResponse | ChallengeResponse>('/login', credentials);
+
+      if ('two_factor_required' in response.data) {
+        return {
+          twoFactorRequired: true,
+          challengeToken: response.data.challenge_token,
+          expiresIn: response.data.expires_in
+        };
+      }
       
-      // Save token to localStorage if successful
+      // Save tokens to localStorage if successful
       if (response.data.token) {
-        localStorage.setItem('token', response.data.token);
+        saveTokens(response.data);
         
         // Since backend doesn't return user info, we'll create a simplified response
         return {
@@ -103,10 +159,164 @@ class AuthService {  /**
   }
 
   /**
-   * Logout the current user
+   * Finish a login that returned a two-factor challenge
+   * @param challengeToken - Token from the login response
+   * @param code - Code from the authenticator app, or a recovery code
+   */
+  async loginWithTwoFactor(challengeToken: string, code: string): Promise<void> {
+    try {
+      const response = await api.post<TokenResponse>('/login/2fa', { challenge_token: challengeToken, code });
+      saveTokens(response.data);
+    } catch (error: any) {
+      const errorMessage = error.response?.data?.message || 'Failed to verify the code';
+      throw new Error(errorMessage);
+    }
+  }
+
+  /**
+   * Check whether the logged-in user has two-factor authentication enabled
+   */
+  async getTwoFactorStatus(): Promise<TwoFactorStatus> {
+    const response = await api.get<TwoFactorStatus>('/auth/2fa');
+    return response.data;
+  }
+
+  /**
+   * Start two-factor enrolment
+   * @returns The new secret together with a QR code to scan into an authenticator app
+   */
+  async setupTwoFactor(): Promise<TwoFactorSetup> {
+    try {
+      const response = await api.post<TwoFactorSetup>('/auth/2fa/setup');
+      return response.data;
+    } catch (error: any) {
+      const errorMessage = error.response?.data?.message || 'Failed to start two-factor setup';
+      throw new Error(errorMessage);
+    }
+  }
+
+  /**
+   * Enable two-factor authentication with a code from the authenticator app
+   * @param code - Current code from the authenticator app
+   * @returns Recovery codes, which the server never shows again
+   */
+  async enableTwoFactor(code: string): Promise<string[]> {
+    try {
+      const response = await api.post<{ recovery_codes: string[] }>('/auth/2fa/enable', { code });
+      return response.data.recovery_codes;
+    } catch (error: any) {
+      const errorMessage = error.response?.data?.message || 'Failed to enable two-factor authentication';
+      throw new Error(errorMessage);
+    }
+  }
+
+  /**
+   * Disable two-factor authentication
+   * @param password - The account password
+   * @param code - Code from the authenticator app, or a recovery code
+   */
+  async disableTwoFactor(password: string, code: string): Promise<void> {
+    try {
+      await api.post('/auth/2fa/disable', { password, code });
+    } catch (error: any) {
+      const errorMessage = error.response?.data?.message || 'Failed to disable two-factor authentication';
+      throw new Error(errorMessage);
+    }
+  }
+
+  /**
+   * Logout the current user, revoking the session on the server
+   */
+  async logout(): Promise<void> {
+    const refreshToken = localStorage.getItem('refresh_token');
+    try {
+      if (this.getToken()) {
+        await api.post('/auth/logout', { refresh_token: refreshToken ?? '' });
+      }
+    } catch (error) {
+      console.error('Logout request failed:', error);
+    } finally {
+      localStorage.removeItem('token');
+      localStorage.removeItem('refresh_token');
+      localStorage.removeItem('email_verified');
+      localStorage.removeItem('workspace_id');
+      localStorage.removeItem('user_role');
+    }
+  }
+
+  /**
+   * Ask for a password reset email. The server answers the same way whether
+   * or not the email is registered.
+   * @param email - Address of the account
+   */
+  async forgotPassword(email: string): Promise<void> {
+    try {
+      await api.post('/auth/forgot', { email });
+    } catch (error: any) {
+      const errorMessage = error.response?.data?.message || 'Failed to request a password reset';
+      throw new Error(errorMessage);
+    }
+  }
+
+  /**
+   * Set a new password using the token from a reset email. Every existing
+   * session ends, so the user has to log in again.
+   * @param token - Token from the reset link
+   * @param password - The new password
+   */
+  async resetPassword(token: string, password: string): Promise<void> {
+    try {
+      await api.post('/auth/reset', { token, password });
+      localStorage.removeItem('token');
+      localStorage.removeItem('refresh_token');
+    } catch (error: any) {
+      const errorMessage = error.response?.data?.message || 'Failed to reset password';
+      throw new Error(errorMessage);
+    }
+  }
+
+  /**
+   * Verify the email address using the token from a verification email
+   * @param token - Token from the verification link
+   */
+  async verifyEmail(token: string): Promise<void> {
+    try {
+      await api.post('/auth/verify', { token });
+      localStorage.setItem('email_verified', 'true');
+    } catch (error: any) {
+      const errorMessage = error.response?.data?.message || 'Failed to verify email address';
+      throw new Error(errorMessage);
+    }
+  }
+
+  /**
+   * Send the logged-in user another verification email
+   */
+  async resendVerification(): Promise<void> {
+    try {
+      await api.post('/auth/verify/resend');
+    } catch (error: any) {
+      if (error.response?.status === 409) {
+        localStorage.setItem('email_verified', 'true');
+      }
+      const errorMessage = error.response?.data?.message || 'Failed to send verification email';
+      throw new Error(errorMessage);
+    }
+  }
+
+  /**
+   * Check whether the logged-in user's email address is known to be unverified
+   * @returns false only when the server reported the address as unverified
+   */
+  isEmailVerified(): boolean {
+    return localStorage.getItem('email_verified') !== 'false';
+  }
+
+  /**
+   * Check whether the logged-in user is an instance admin
    */
-  logout(): void {
-    localStorage.removeItem('token');
+  isAdmin(): boolean {
+    return localStorage.getItem('user_role') === 'admin';
   }
 
   /**
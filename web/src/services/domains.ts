@@ -0,0 +1,83 @@
+import api from './api';
+
+export interface DNSRecord {
+  type: string;
+  name: string;
+  value: string;
+}
+
+export interface Domain {
+  id: number;
+  workspace_id: number;
+  hostname: string;
+  verified: boolean;
+  verified_at?: string;
+  created_at: string;
+  verification_record?: DNSRecord;
+}
+
+// Custom domain service class
+class DomainService {
+  /**
+   * Get the custom domains of a workspace
+   * @param workspaceId - The workspace
+   */
+  async getDomains(workspaceId: number): Promise<Domain[]> {
+    try {
+      const response = await api.get<{ domains: Domain[], count: number }>(`/workspaces/${workspaceId}/domains`);
+      return response.data.domains || [];
+    } catch (error: any) {
+      const errorMessage = error.response?.data?.error || 'Failed to fetch domains';
+      throw new Error(errorMessage);
+    }
+  }
+
+  /**
+   * Add a custom domain; only owners may
+   * @param workspaceId - The workspace
+   * @param hostname - Hostname such as go.example.com
+   * @returns Promise with the domain and the TXT record that verifies it
+   */
+  async addDomain(workspaceId: number, hostname: string): Promise<Domain> {
+    try {
+      const response = await api.post<{ domain: Domain, message: string }>(`/workspaces/${workspaceId}/domains`, { hostname });
+      return response.data.domain;
+    } catch (error: any) {
+      const errorMessage = error.response?.data?.error || 'Failed to add domain';
+      throw new Error(errorMessage);
+    }
+  }
+
+  /**
+   * Look up the verification record of a domain and verify it
+   * @param workspaceId - The workspace
+   * @param domainId - The domain
+   */
+  async verifyDomain(workspaceId: number, domainId: number): Promise<Domain> {
+    try {
+      const response = await api.post<{ domain: Domain, message: string }>(`/workspaces/${workspaceId}/domains/${domainId}/verify`);
+      return response.data.domain;
+    } catch (error: any) {
+      const errorMessage = error.response?.data?.error || 'Failed to verify domain';
+      throw new Error(errorMessage);
+    }
+  }
+
+  /**
+   * Remove a custom domain that has no links
+   * @param workspaceId - The workspace
+   * @param domainId - The domain
+   */
+  async deleteDomain(workspaceId: number, domainId: number): Promise<void> {
+    try {
+      await api.delete(`/workspaces/${workspaceId}/domains/${domainId}`);
+    } catch (error: any) {
+      const errorMessage = error.response?.data?.message || error.response?.data?.error || 'Failed to delete domain';
+      throw new Error(errorMessage);
+    }
+  }
+}
+
+// Create and export a singleton instance
+const domainService = new DomainService();
+export default domainService;
@@ -11,13 +11,117 @@ export interface Link {
   click_count: number;
   deleted_at?: string | null;
   user_id?: number | null;
+  workspace_id?: number | null;
+  domain_id?: number | null;
+  domain?: string | null;
+  tags: string[];
+  folder?: string | null;
+  password_protected?: boolean;
+  taken_down_at?: string | null;
+  takedown_reason?: string | null;
 }
 
 // Link request interfaces
 export interface CreateLinkRequest {
   target_url: string;
+  slug?: string;
   expires_at?: string | null;
   click_limit?: number | null;
+  password?: string;
+  domain?: string;
+  tags?: string[];
+  folder?: string;
+}
+
+export interface UpdateLinkRequest {
+  target_url?: string;
+  expires_at?: string;
+  click_limit?: number;
+  clear_expires_at?: boolean;
+  clear_click_limit?: boolean;
+  tags?: string[];
+  folder?: string;
+}
+
+export interface LinkFilter {
+  q?: string;
+  tags?: string[];
+  folder?: string;
+  status?: 'active' | 'expired' | 'exhausted' | 'deleted' | 'taken_down';
+  created_from?: string;
+  created_to?: string;
+  has_click_limit?: boolean;
+}
+
+export interface LinkPageQuery extends LinkFilter {
+  sort?: 'created_at' | 'click_count' | 'expires_at';
+  order?: 'asc' | 'desc';
+  limit?: number;
+  cursor?: string;
+}
+
+export interface LinkPage {
+  links: Link[];
+  total: number;
+  next_cursor: string | null;
+}
+
+export interface LinkState {
+  target_url: string;
+  expires_at: string | null;
+  click_limit: number | null;
+}
+
+export interface LinkRevision {
+  id: number;
+  link_id: number;
+  user_id: number | null;
+  action: 'update' | 'rollback';
+  old_values: LinkState;
+  new_values: LinkState;
+  reverted_revision_id?: number;
+  created_at: string;
+}
+
+export interface SlugAvailability {
+  slug: string;
+  available: boolean;
+  reason?: 'invalid' | 'reserved' | 'taken';
+  message?: string;
+  suggestions?: string[];
+}
+
+export type StatsBucket = 'hour' | 'day' | 'week';
+
+export interface StatsBreakdownEntry {
+  value: string;
+  clicks: number;
+}
+
+export interface LinkStats {
+  link: Link;
+  status: 'active' | 'expired' | 'exhausted' | 'deleted';
+  from: string;
+  to: string;
+  bucket: StatsBucket;
+  timezone: string;
+  total_clicks: number;
+  unique_visitors: number;
+  series: { bucket: string; clicks: number }[];
+  breakdowns: {
+    countries: StatsBreakdownEntry[];
+    device_types: StatsBreakdownEntry[];
+    browsers: StatsBreakdownEntry[];
+    operating_systems: StatsBreakdownEntry[];
+    referrers: StatsBreakdownEntry[];
+  };
+}
+
+export interface LinkStatsQuery {
+  from?: string;
+  to?: string;
+  bucket?: StatsBucket;
+  tz?: string;
 }
 
 // Link service class
@@ -36,19 +140,124 @@ class LinkService {  /**
     }
   }
   /**
-   * Get all links for the authenticated user
-   * @returns Promise with array of links
+   * Get one page of the workspace's links
+   * @param query - Filters, sort order and the cursor of the page to fetch
+   * @returns Promise with the links, total number of matches and the next page's cursor
    */
-  async getLinks(): Promise<Link[]> {
+  async getLinkPage(query: LinkPageQuery = {}): Promise<LinkPage> {
     try {
-      const response = await api.get<{ links: Link[], message: string, count: number }>('/links');
-      return response.data.links || [];
+      const params = new URLSearchParams();
+      const { tags, ...rest } = query;
+      tags?.forEach(tag => params.append('tag', tag));
+      Object.entries(rest).forEach(([key, value]) => {
+        if (value !== undefined && value !== '') params.append(key, String(value));
+      });
+      const response = await api.get<LinkPage & { message: string, count: number }>('/links', { params });
+      return {
+        links: response.data.links || [],
+        total: response.data.total,
+        next_cursor: response.data.next_cursor,
+      };
     } catch (error: any) {
       const errorMessage = error.response?.data?.error || 'Failed to fetch links';
       throw new Error(errorMessage);
     }
   }
 
+  /**
+   * Get all links for the authenticated user, following every page
+   * @param filter - Optional filters such as tags the links must all have and the folder they must be in
+   * @returns Promise with array of links
+   */
+  async getLinks(filter: LinkFilter = {}): Promise<Link[]> {
+    const links: Link[] = [];
+    let cursor: string | undefined;
+    do {
+      const page = await this.getLinkPage({ ...filter, limit: 200, cursor });
+      links.push(...page.links);
+      cursor = page.next_cursor ?? undefined;
+    } while (cursor);
+    return links;
+  }
+
+  /**
+   * Check whether a custom slug can be used
+   * @param slug - The custom slug to check
+   * @returns Promise with availability and suggested alternatives
+   */
+  async checkSlugAvailability(slug: string): Promise<SlugAvailability> {
+    try {
+      const response = await api.get<SlugAvailability>('/links/slug-availability', { params: { slug } });
+      return response.data;
+    } catch (error: any) {
+      const errorMessage = error.response?.data?.error || 'Failed to check slug availability';
+      throw new Error(errorMessage);
+    }
+  }
+
+  /**
+   * Edit a link's target URL, expiry or click limit
+   * @param slug - The unique slug of the link to edit
+   * @param changes - Fields to change
+   * @returns Promise with the updated link
+   */
+  async updateLink(slug: string, changes: UpdateLinkRequest): Promise<Link> {
+    try {
+      const response = await api.patch<{ link: Link, message: string }>(`/links/${slug}`, changes);
+      return response.data.link;
+    } catch (error: any) {
+      const errorMessage = error.response?.data?.error || 'Failed to update link';
+      throw new Error(errorMessage);
+    }
+  }
+
+  /**
+   * Get the change history of a link
+   * @param slug - The unique slug of the link
+   * @returns Promise with revisions, newest first
+   */
+  async getLinkHistory(slug: string): Promise<LinkRevision[]> {
+    try {
+      const response = await api.get<{ revisions: LinkRevision[], count: number }>(`/links/${slug}/history`);
+      return response.data.revisions || [];
+    } catch (error: any) {
+      const errorMessage = error.response?.data?.error || 'Failed to fetch link history';
+      throw new Error(errorMessage);
+    }
+  }
+
+  /**
+   * Restore a link to the values it had before a revision
+   * @param slug - The unique slug of the link
+   * @param revisionId - The revision to undo
+   * @returns Promise with the restored link
+   */
+  async rollbackLink(slug: string, revisionId: number): Promise<Link> {
+    try {
+      const response = await api.post<{ link: Link, message: string }>(`/links/${slug}/history/${revisionId}/rollback`);
+      return response.data.link;
+    } catch (error: any) {
+      const errorMessage = error.response?.data?.error || 'Failed to roll back link';
+      throw new Error(errorMessage);
+    }
+  }
+
+  /**
+   * Get aggregated click statistics and the current status of a link
+   * @param slug - The unique slug of the link
+   * @param query - Optional range, bucket size and time zone
+   * @returns Promise with the link and its statistics
+   */
+  async getLinkStats(slug: string, query: LinkStatsQuery = {}): Promise<LinkStats> {
+    try {
+      const response = await api.get<LinkStats>(`/links/${slug}/stats`, { params: query });
+      return response.data;
+    } catch (error: any) {
+      const errorMessage = error.response?.data?.error || 'Failed to fetch link stats';
+      throw new Error(errorMessage);
+    }
+  }
+
   /**
    * Delete a link by its slug
    * @param slug - The unique slug of the link to delete
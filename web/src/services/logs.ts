@@ -9,7 +9,9 @@ export interface AccessLog {
   user_agent: string;
   referer?: string;
   country?: string;
+  region?: string;
   city?: string;
+  asn?: number;
   device_type?: string;
   browser?: string;
   os?: string;
@@ -37,14 +39,13 @@ class LogService {  /**
   }
 
   /**
-   * Get access logs for a specific user
-   * @param userID - User ID to fetch logs for
+   * Get access logs for all links owned by the authenticated user
    * @param limit - Optional limit for number of logs to return
    * @returns Promise with array of access logs
    */
-  async getLogsByUser(userID: string, limit?: number): Promise<AccessLog[]> {
+  async getLogsByUser(limit?: number): Promise<AccessLog[]> {
     try {
-      const params: Record<string, string> = { user_id: userID };
+      const params: Record<string, string> = {};
       if (limit) params.limit = limit.toString();
 
       const response = await api.get<{ logs: AccessLog[], message: string, count: number }>('/logs/user', { params });
@@ -54,6 +55,25 @@ class LogService {  /**
       throw new Error(errorMessage);
     }
   }
+
+  /**
+   * Get access logs for a single link owned by the authenticated user
+   * @param slug - Slug of the link to fetch logs for
+   * @param limit - Optional limit for number of logs to return
+   * @returns Promise with array of access logs
+   */
+  async getLinkLogs(slug: string, limit?: number): Promise<AccessLog[]> {
+    try {
+      const params: Record<string, string> = {};
+      if (limit) params.limit = limit.toString();
+
+      const response = await api.get<{ logs: AccessLog[], slug: string, count: number }>(`/links/${slug}/logs`, { params });
+      return response.data.logs || [];
+    } catch (error: any) {
+      const errorMessage = error.response?.data?.error || 'Failed to fetch link access logs';
+      throw new Error(errorMessage);
+    }
+  }
 }
 
 // Create and export a singleton instance
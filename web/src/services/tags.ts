@@ -0,0 +1,82 @@
+import api from './api';
+
+export interface Tag {
+  id: number;
+  workspace_id: number;
+  name: string;
+  link_count: number;
+  click_count: number;
+  created_at: string;
+}
+
+export interface Folder {
+  id: number;
+  workspace_id: number;
+  name: string;
+  link_count: number;
+  click_count: number;
+  created_at: string;
+}
+
+// Tag and folder service class
+class TagService {
+  /**
+   * Get the tags of the current workspace with their link and click counts
+   */
+  async getTags(): Promise<Tag[]> {
+    try {
+      const response = await api.get<{ tags: Tag[], count: number }>('/tags');
+      return response.data.tags || [];
+    } catch (error: any) {
+      const errorMessage = error.response?.data?.error || 'Failed to fetch tags';
+      throw new Error(errorMessage);
+    }
+  }
+
+  /**
+   * Get the folders of the current workspace with their link and click counts
+   */
+  async getFolders(): Promise<Folder[]> {
+    try {
+      const response = await api.get<{ folders: Folder[], count: number }>('/folders');
+      return response.data.folders || [];
+    } catch (error: any) {
+      const errorMessage = error.response?.data?.error || 'Failed to fetch folders';
+      throw new Error(errorMessage);
+    }
+  }
+
+  /**
+   * Rename a tag; fails when another tag has the name
+   * @param tagId - The tag
+   * @param name - The new name
+   */
+  async renameTag(tagId: number, name: string): Promise<Tag> {
+    try {
+      const response = await api.patch<{ tag: Tag, message: string }>(`/tags/${tagId}`, { name });
+      return response.data.tag;
+    } catch (error: any) {
+      const errorMessage = error.response?.data?.error || 'Failed to rename tag';
+      throw new Error(errorMessage);
+    }
+  }
+
+  /**
+   * Move the links of a tag to another tag and delete it
+   * @param tagId - The tag to merge away
+   * @param targetId - The tag that takes over its links
+   */
+  async mergeTag(tagId: number, targetId: number): Promise<Tag> {
+    try {
+      const response = await api.post<{ tag: Tag, message: string }>(`/tags/${tagId}/merge`, { target_id: targetId });
+      return response.data.tag;
+    } catch (error: any) {
+      const errorMessage = error.response?.data?.error || 'Failed to merge tags';
+      throw new Error(errorMessage);
+    }
+  }
+}
+
+// Create and export a singleton instance
+const tagService = new TagService();
+export default tagService;
@@ -0,0 +1,186 @@
+import api from './api';
+
+export type WorkspaceRole = 'owner' | 'editor' | 'viewer';
+
+export interface Workspace {
+  id: number;
+  name: string;
+  personal: boolean;
+  created_at: string;
+  role: WorkspaceRole;
+}
+
+export interface WorkspaceMember {
+  user_id: number;
+  username: string;
+  email: string;
+  role: WorkspaceRole;
+  joined_at: string;
+}
+
+export interface WorkspaceInvitation {
+  id: number;
+  workspace_id: number;
+  email: string;
+  role: WorkspaceRole;
+  invited_by: number;
+  expires_at: string;
+  created_at: string;
+}
+
+// Workspace service class
+class WorkspaceService {
+  /**
+   * Get the workspaces the user is a member of, the personal one first
+   */
+  async getWorkspaces(): Promise<Workspace[]> {
+    try {
+      const response = await api.get<{ workspaces: Workspace[], count: number }>('/workspaces');
+      return response.data.workspaces || [];
+    } catch (error: any) {
+      const errorMessage = error.response?.data?.error || 'Failed to fetch workspaces';
+      throw new Error(errorMessage);
+    }
+  }
+
+  /**
+   * Create a shared workspace owned by the user
+   * @param name - Name of the workspace
+   */
+  async createWorkspace(name: string): Promise<Workspace> {
+    try {
+      const response = await api.post<{ workspace: Workspace, message: string }>('/workspaces', { name });
+      return response.data.workspace;
+    } catch (error: any) {
+      const errorMessage = error.response?.data?.error || 'Failed to create workspace';
+      throw new Error(errorMessage);
+    }
+  }
+
+  /**
+   * Get the members of a workspace
+   * @param workspaceId - The workspace
+   */
+  async getMembers(workspaceId: number): Promise<WorkspaceMember[]> {
+    try {
+      const response = await api.get<{ members: WorkspaceMember[], count: number }>(`/workspaces/${workspaceId}/members`);
+      return response.data.members || [];
+    } catch (error: any) {
+      const errorMessage = error.response?.data?.error || 'Failed to fetch workspace members';
+      throw new Error(errorMessage);
+    }
+  }
+
+  /**
+   * Change a member's role; only owners may
+   * @param workspaceId - The workspace
+   * @param userId - The member
+   * @param role - The new role
+   */
+  async setMemberRole(workspaceId: number, userId: number, role: WorkspaceRole): Promise<void> {
+    try {
+      await api.patch(`/workspaces/${workspaceId}/members/${userId}`, { role });
+    } catch (error: any) {
+      const errorMessage = error.response?.data?.message || error.response?.data?.error || 'Failed to update member';
+      throw new Error(errorMessage);
+    }
+  }
+
+  /**
+   * Remove a member from a workspace, or leave it when userId is the current user
+   * @param workspaceId - The workspace
+   * @param userId - The member
+   */
+  async removeMember(workspaceId: number, userId: number): Promise<void> {
+    try {
+      await api.delete(`/workspaces/${workspaceId}/members/${userId}`);
+    } catch (error: any) {
+      const errorMessage = error.response?.data?.message || error.response?.data?.error || 'Failed to remove member';
+      throw new Error(errorMessage);
+    }
+  }
+
+  /**
+   * Get the pending invitations of a workspace; only owners may
+   * @param workspaceId - The workspace
+   */
+  async getInvitations(workspaceId: number): Promise<WorkspaceInvitation[]> {
+    try {
+      const response = await api.get<{ invitations: WorkspaceInvitation[], count: number }>(`/workspaces/${workspaceId}/invitations`);
+      return response.data.invitations || [];
+    } catch (error: any) {
+      const errorMessage = error.response?.data?.error || 'Failed to fetch invitations';
+      throw new Error(errorMessage);
+    }
+  }
+
+  /**
+   * Email an invitation to join a workspace
+   * @param workspaceId - The workspace
+   * @param email - Address to invite
+   * @param role - Role the invitee gets on accepting
+   */
+  async invite(workspaceId: number, email: string, role: WorkspaceRole): Promise<WorkspaceInvitation> {
+    try {
+      const response = await api.post<{ invitation: WorkspaceInvitation, message: string }>(
+        `/workspaces/${workspaceId}/invitations`, { email, role });
+      return response.data.invitation;
+    } catch (error: any) {
+      const errorMessage = error.response?.data?.message || error.response?.data?.error || 'Failed to send invitation';
+      throw new Error(errorMessage);
+    }
+  }
+
+  /**
+   * Revoke a pending invitation
+   * @param workspaceId - The workspace
+   * @param invitationId - The invitation
+   */
+  async revokeInvitation(workspaceId: number, invitationId: number): Promise<void> {
+    try {
+      await api.delete(`/workspaces/${workspaceId}/invitations/${invitationId}`);
+    } catch (error: any) {
+      const errorMessage = error.response?.data?.error || 'Failed to revoke invitation';
+      throw new Error(errorMessage);
+    }
+  }
+
+  /**
+   * Join a workspace with the token from an invitation email
+   * @param token - Token from the invitation link
+   * @returns Promise with the joined workspace
+   */
+  async acceptInvitation(token: string): Promise<Workspace> {
+    try {
+      const response = await api.post<{ workspace: Workspace, message: string }>('/invitations/accept', { token });
+      return response.data.workspace;
+    } catch (error: any) {
+      const errorMessage = error.response?.data?.message || 'Failed to accept invitation';
+      throw new Error(errorMessage);
+    }
+  }
+
+  /**
+   * Get the workspace links and logs are shown for, or null for the personal one
+   */
+  getCurrentWorkspaceId(): number | null {
+    const workspaceId = localStorage.getItem('workspace_id');
+    return workspaceId ? Number(workspaceId) : null;
+  }
+
+  /**
+   * Select the workspace links and logs are shown for
+   * @param workspace - The workspace to switch to
+   */
+  setCurrentWorkspace(workspace: Workspace): void {
+    if (workspace.personal) {
+      localStorage.removeItem('workspace_id');
+    } else {
+      localStorage.setItem('workspace_id', String(workspace.id));
+    }
+  }
+}
+
+// Create and export a singleton instance
+const workspaceService = new WorkspaceService();
+export default workspaceService;